@@ -31,15 +31,20 @@ func init() {
 func setup(c *caddy.Controller) error {
 	c.Next()
 	args := c.RemainingArgs()
-	assert.True(len(args) == 1, "exactly one argument is required")
-
+	assert.True(len(args) == 1, "the network hash is required")
 	networkHash := args[0]
-	p := &ZeusDns{}
-	server, err := New(p, networkHash)
+
+	if err := SocketFileEnvironmentManager.Setup(); err != nil {
+		return err
+	}
+	listen, err := SocketFileEnvironmentManager.Listen()
 	if err != nil {
 		return err
 	}
 
+	p := &ZeusDns{}
+	server := New(p, networkHash, listen)
+
 	c.OnStartup(func() error {
 		go func() {
 			if err := server.Run(); err != nil {
@@ -58,46 +63,63 @@ func setup(c *caddy.Controller) error {
 	return nil
 }
 
+// Addresses the DNS answers the domains with, a domain with replicas has one address for each of them
+type ipMap struct {
+	ipv4 map[string][]string
+	ipv6 map[string][]string
+}
+
 type ZeusDns struct {
-	next  plugin.Handler
-	mu    sync.Mutex
-	ipMap map[string]string
+	next plugin.Handler
+	mu   sync.Mutex
+	ips  *ipMap
 }
 
-func (z *ZeusDns) setIpMap(m map[string]string) {
+func (z *ZeusDns) setIpMap(m *ipMap) {
 	z.mu.Lock()
 	defer z.mu.Unlock()
-	z.ipMap = m
+	z.ips = m
 }
 
 func (z *ZeusDns) Name() string { return pluginName }
 
+// Answers A and AAAA queries of the domains which are set, with a record for every replica of the domain,
+// the client picks one. A domain without addresses of the queried family is answered without records.
+// All other queries are passed to the next plugin.
 func (z *ZeusDns) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
 	z.mu.Lock()
 	defer z.mu.Unlock()
 
 	q := r.Question[0]
 	name := strings.TrimSuffix(q.Name, ".")
+	if z.ips == nil || (q.Qtype != dns.TypeA && q.Qtype != dns.TypeAAAA) {
+		return plugin.NextOrFailure(z.Name(), z.next, ctx, w, r)
+	}
+	ipv4, ok4 := z.ips.ipv4[name]
+	ipv6, ok6 := z.ips.ipv6[name]
+	if !ok4 && !ok6 {
+		return plugin.NextOrFailure(z.Name(), z.next, ctx, w, r)
+	}
+
+	msg := new(dns.Msg)
+	msg.SetReply(r)
+	msg.Authoritative = true
+	header := dns.RR_Header{
+		Name:   q.Name,
+		Rrtype: q.Qtype,
+		Class:  dns.ClassINET,
+		Ttl:    timeToLive,
+	}
+
 	if q.Qtype == dns.TypeA {
-		if ip, ok := z.ipMap[name]; ok {
-			msg := new(dns.Msg)
-			msg.SetReply(r)
-			msg.Authoritative = true
-
-			a := &dns.A{
-				Hdr: dns.RR_Header{
-					Name:   q.Name,
-					Rrtype: dns.TypeA,
-					Class:  dns.ClassINET,
-					Ttl:    timeToLive,
-				},
-				A: net.ParseIP(ip),
-			}
-			msg.Answer = append(msg.Answer, a)
-			w.WriteMsg(msg)
-			return dns.RcodeSuccess, nil
+		for _, ip := range ipv4 {
+			msg.Answer = append(msg.Answer, &dns.A{Hdr: header, A: net.ParseIP(ip)})
+		}
+	} else {
+		for _, ip := range ipv6 {
+			msg.Answer = append(msg.Answer, &dns.AAAA{Hdr: header, AAAA: net.ParseIP(ip)})
 		}
 	}
-
-	return plugin.NextOrFailure(z.Name(), z.next, ctx, w, r)
+	w.WriteMsg(msg)
+	return dns.RcodeSuccess, nil
 }
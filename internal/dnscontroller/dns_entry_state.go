@@ -5,53 +5,54 @@ package dnscontroller
 
 import (
 	"slices"
-
-	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
 
 type dnsEntryState struct {
-	// maps the domain to the ip address which the DNS is pointing to
-	entries map[string]string
-	// the ip ring which is used to assign ip addresses to the domains
-	ring *ipRing
+	// maps the domain to the ip addresses which the DNS is pointing to, one for every replica of the domain
+	entries map[string][]string
+	// maps the domain to its IPv6 addresses on a dual stack network
+	entriesV6 map[string][]string
 }
 
-func newDNSEntryState(network uint8, identifier uint8) *dnsEntryState {
+func newDNSEntryState() *dnsEntryState {
 	return &dnsEntryState{
-		entries: make(map[string]string),
-		ring:    newIpRing(network, identifier),
+		entries:   make(map[string][]string),
+		entriesV6: make(map[string][]string),
 	}
 }
 
-func (d *dnsEntryState) update(domains []string) {
-	// to reduce the actual update which are need to be done, we dont change domains ip addresses
-	// if they are already set. else we would have the possiblity that we reassign a domain a different ip
-	for domain, ip := range d.entries {
-		if !slices.Contains(domains, domain) {
-			ok := d.ring.returnToPool(ip)
-			assert.True(ok, "ip must be returned to the pool")
-
-			delete(d.entries, domain)
-		}
+// Updates the addresses of the domains to the ones of the entries. A partial update keeps the domains
+// which are not listed, otherwise they are removed. A domain without addresses is removed.
+func (d *dnsEntryState) update(entries []*DNSSetEntryRequest, partial bool) {
+	if !partial {
+		clear(d.entries)
+		clear(d.entriesV6)
 	}
 
-	for _, domain := range domains {
-		_, ok := d.entries[domain]
-		if ok {
-			continue
+	for _, e := range entries {
+		delete(d.entries, e.Domain)
+		delete(d.entriesV6, e.Domain)
+		if len(e.IPv4) != 0 {
+			d.entries[e.Domain] = slices.Clone(e.IPv4)
+		}
+		if len(e.IPv6) != 0 {
+			d.entriesV6[e.Domain] = slices.Clone(e.IPv6)
 		}
-
-		ip := d.ring.next()
-		d.entries[domain] = ip
 	}
 }
 
-func (d *dnsEntryState) appendTo(ipMap map[string]string) map[string]string {
-	for domain, ip := range d.entries {
-		_, ok := ipMap[domain]
-		assert.False(ok, "domain must not be in ipMap")
-		ipMap[domain] = ip
+// Returns a copy of the entries, which the DNS answers A and AAAA queries with.
+func (d *dnsEntryState) ipMap() *ipMap {
+	m := &ipMap{
+		ipv4: make(map[string][]string, len(d.entries)),
+		ipv6: make(map[string][]string, len(d.entriesV6)),
+	}
+	for domain, ips := range d.entries {
+		m.ipv4[domain] = slices.Clone(ips)
+	}
+	for domain, ips := range d.entriesV6 {
+		m.ipv6[domain] = slices.Clone(ips)
 	}
 
-	return ipMap
+	return m
 }
@@ -5,8 +5,15 @@ package dnscontroller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
 	"math/big"
 	"net"
+	"net/netip"
+	"slices"
 
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 	log "github.com/raphaeldichler/zeus/internal/util/logger"
@@ -22,11 +29,6 @@ var SocketFileEnvironmentManager = socket.NewFileEnvironmentManager(
   0666,
 )
 
-const (
-  internalDNSIdentifier uint8 = 0
-  externalDNSIdentifier uint8 = 100
-)
-
 type Controller struct {
 	UnimplementedDNSControllerServer
 	server   *grpc.Server
@@ -35,93 +37,124 @@ type Controller struct {
 
 	plg *ZeusDns
 
-  networkHash string
-	internalDNS *dnsEntryState
-	externalDNS *dnsEntryState
+	networkHash string
+	entries     *dnsEntryState
 }
 
+// Creates the controller of the network, the DNS answers with the addresses which are set by the runtime.
 func New(
-  dnsPlugin *ZeusDns,
-  networkHash string,
-) (*Controller, error) {
-  listen, err := SocketFileEnvironmentManager.Listen()
-  if err != nil {
-    return nil, err
-  }
-
-  network := networkHashToIpPart(networkHash)
+	dnsPlugin *ZeusDns,
+	networkHash string,
+	listener net.Listener,
+) *Controller {
 	s := grpc.NewServer()
 	srv := &Controller{
 		server:      s,
-		listener:    listen,
+		listener:    listener,
 		log:         log.New("dns", "controller"),
 		plg:         dnsPlugin,
-    networkHash: networkHash,
-		internalDNS: newDNSEntryState(network, internalDNSIdentifier),
-		externalDNS: newDNSEntryState(network, externalDNSIdentifier),
+		networkHash: networkHash,
+		entries:     newDNSEntryState(),
 	}
 	RegisterDNSControllerServer(s, srv)
 
-	return srv, nil
+	return srv
 }
 
 func (self *Controller) Run() error {
 	return self.server.Serve(self.listener)
 }
 
+// Sets the addresses the DNS answers the domains with. The entries replace all domains which were set
+// before, unless the request is partial.
 func (c *Controller) SetDNSEntry(
 	ctx context.Context,
 	req *DNSSetRequest,
 ) (*DNSSetResponse, error) {
-  if req.NetworkHash != c.networkHash {
-    return nil, status.Error(codes.Unknown, "network part differs from controller")
-  }
-
-	var (
-		internal []string = nil
-		external []string = nil
-	)
-	for _, e := range req.Entries {
-		switch e.Type {
-		case DNSEntryType_Internal:
-			internal = append(internal, e.Domain)
-		case DNSEntryType_External:
-			external = append(external, e.Domain)
-		}
+	if req.NetworkHash != c.networkHash {
+		return nil, status.Error(codes.Unknown, "network part differs from controller")
+	}
+	if err := validateEntries(req.Entries); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	c.internalDNS.update(internal)
-	c.externalDNS.update(external)
-
-	ipMap := make(map[string]string)
-	ipMap = c.internalDNS.appendTo(ipMap)
-	ipMap = c.externalDNS.appendTo(ipMap)
-	c.plg.setIpMap(ipMap)
+	c.entries.update(req.Entries, req.Partial)
+	c.plg.setIpMap(c.entries.ipMap())
 
 	return c.toResponse(), nil
 }
 
+// Validates that every domain is listed once and its addresses are addresses of their family.
+func validateEntries(entries []*DNSSetEntryRequest) error {
+	domains := make(map[string]struct{})
+	for _, e := range entries {
+		if e.Domain == "" {
+			return errors.New("domain must not be empty")
+		}
+		if _, ok := domains[e.Domain]; ok {
+			return fmt.Errorf("domain '%s' is listed multiple times", e.Domain)
+		}
+		domains[e.Domain] = struct{}{}
+
+		for _, ip := range e.IPv4 {
+			if addr, err := netip.ParseAddr(ip); err != nil || !addr.Is4() {
+				return fmt.Errorf("address '%s' of domain '%s' is no IPv4 address", ip, e.Domain)
+			}
+		}
+		for _, ip := range e.IPv6 {
+			if addr, err := netip.ParseAddr(ip); err != nil || !addr.Is6() || addr.Is4In6() {
+				return fmt.Errorf("address '%s' of domain '%s' is no IPv6 address", ip, e.Domain)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *Controller) toResponse() *DNSSetResponse {
 	r := new(DNSSetResponse)
 
-	for _, entries := range []map[string]string{
-		c.internalDNS.entries,
-		c.externalDNS.entries,
+	// a domain with replicas is listed with each of its addresses
+	for _, entries := range []map[string][]string{
+		c.entries.entries,
+		c.entries.entriesV6,
 	} {
-		for domain, ip := range entries {
-			e := &DNSEntry{
-				Domain: domain,
-				IP:     ip,
+		for _, domain := range slices.Sorted(maps.Keys(entries)) {
+			for _, ip := range entries[domain] {
+				e := &DNSEntry{
+					Domain: domain,
+					IP:     ip,
+				}
+				r.DNSEntries = append(r.DNSEntries, e)
 			}
-			r.DNSEntries = append(r.DNSEntries, e)
 		}
-
 	}
 
 	return r
 }
 
-func networkHashToIpPart(networkHash string) uint8 {
+// Returns the IPv6 address inside the prefix of a dual stack network which belongs to the IPv4 address,
+// the last two octets of the IPv4 address form the last 16 bits, e.g. 10.42.0.7 maps to {prefix}::7.
+func IPv6Of(prefix netip.Prefix, ipv4 netip.Addr) netip.Addr {
+	assert.True(prefix.Addr().Is6() && prefix.Bits() <= 112, "prefix must be an IPv6 prefix of at most 112 bits")
+	assert.True(ipv4.Is4(), "address must be an IPv4 address")
+
+	octets := prefix.Masked().Addr().As16()
+	v4 := ipv4.As4()
+	octets[14] = v4[2]
+	octets[15] = v4[3]
+
+	return netip.AddrFrom16(octets)
+}
+
+// Returns the hash which identifies the network of the application.
+func NetworkHash(application string) string {
+	sum := sha256.Sum256([]byte(application))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns the second octet of the preferred subnet 10.{part}.0.0/16 of the network, in range [1, 254].
+func NetworkHashToIpPart(networkHash string) uint8 {
 	n := new(big.Int)
 	n.SetString(networkHash, 16)
 
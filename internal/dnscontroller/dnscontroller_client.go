@@ -14,9 +14,10 @@ type Client struct {
 	conn *grpc.ClientConn
 }
 
-func NewClient() *Client {
+// Creates a client of the DNS controller which listens on the socket.
+func NewClient(socketPath string) *Client {
 	conn, err := grpc.NewClient(
-    SocketFileEnvironmentManager.UnixSocketURI(),
+		"unix://"+socketPath,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	)
 	// the configuration of the client should be correct
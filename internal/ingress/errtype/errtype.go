@@ -4,13 +4,15 @@
 package errtype
 
 import (
-	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"fmt"
+
 	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
 
 func FailedObtainCertificate(
-  host string, 
-  err error,
+	host string,
+	err error,
 ) record.IngressErrorEntryRecord {
 	assert.True(err != nil, "the error must exist")
 	return record.IngressErrorEntryRecord{
@@ -20,9 +22,19 @@ func FailedObtainCertificate(
 	}
 }
 
+// Returns an entry which matches all FailedObtainCertificate errors of the host. Use it with HasError.
+func FailedObtainCertificateQuery(
+	host string,
+) record.IngressErrorEntryRecord {
+	return record.IngressErrorEntryRecord{
+		Type:       "FailedObtainCertificate",
+		Identifier: host,
+	}
+}
+
 func FailedInteractionWithNginxController(
-  domain string,
-  err error,
+	domain string,
+	err error,
 ) record.IngressErrorEntryRecord {
 	assert.True(err != nil, "the error must exist")
 
@@ -32,3 +44,29 @@ func FailedInteractionWithNginxController(
 		Message:    err.Error(),
 	}
 }
+
+// The ingress references a service or a port of a service which does not exist.
+func UnresolvedServiceEndpoint(
+	host string,
+	service string,
+	port string,
+) record.IngressErrorEntryRecord {
+	return record.IngressErrorEntryRecord{
+		Type:       "UnresolvedServiceEndpoint",
+		Identifier: host,
+		Message:    fmt.Sprintf("service '%s' has no port '%s'", service, port),
+	}
+}
+
+// The address of the DNS which resolves the services inside the network of the application is unknown.
+func UnknownNetworkResolver(
+	err error,
+) record.IngressErrorEntryRecord {
+	assert.True(err != nil, "the error must exist")
+
+	return record.IngressErrorEntryRecord{
+		Type:       "UnknownNetworkResolver",
+		Identifier: "*",
+		Message:    err.Error(),
+	}
+}
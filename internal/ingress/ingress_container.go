@@ -4,6 +4,8 @@
 package ingress
 
 import (
+	"context"
+
 	runtimeErr "github.com/raphaeldichler/zeus/internal/runtime/errtype"
 	"github.com/raphaeldichler/zeus/internal/nginxcontroller"
 	"github.com/raphaeldichler/zeus/internal/record"
@@ -13,52 +15,36 @@ import (
 
 const ZeusRootPath string = "/run/zeus/"
 
-
-
-// Ensures that an ingress container is create and running.
+// Ensures that an ingress container with the image of the ingress is created and running.
 //
-// If no container exists a new one is created and started.
+// If no such container exists a new one is created and started. Containers of a previous image keep
+// serving next to it, until shutdownPreviousIngressContainers stops them once the new one is configured.
 // On fatal errors no container will be set and the application state will be updated accrodingly.
 //
 // Note: It is assumed that an existing path to the socket mount exists, if a new container must be created.
 func SelectOrCreateIngressContainer(
+	ctx context.Context,
 	state *record.ApplicationRecord,
 ) optional.Optional[runtime.Container] {
 	optionalContainer, err := runtime.TrySelectOneContainer(
+		ctx,
 		state.Metadata.Application,
 		runtime.ObjectTypeLabel(runtime.IngressObject),
+		runtime.ObjectImageLabel(state.Ingress.Metadata.Image),
 		runtime.ApplicationNameLabel(state.Metadata.Application),
 	)
 	if err != nil {
 		state.Ingress.SetError(
-      runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerSelectContainer, err),
+			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerSelectContainer, err),
 		)
 
 		return optional.Empty[runtime.Container]()
 	}
-
-	optionalContainer = optionalContainer.IfPresent(func(t *runtime.Container) *runtime.Container {
-		if t.Image() == state.Ingress.Metadata.Image {
-			return t
-		}
-
-		// to enable auto updates we remove an ingress container
-		// if it has a different image. in the next steps
-		// the code will see a nil container and create a new one with
-		// the specified version
-		if err := t.Shutdown(); err != nil {
-      state.Ingress.SetError(
-        runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerStopContainer, err),
-      )
-		}
-
-		return nil
-	})
 	if optionalContainer.IsPresent() {
 		return optionalContainer
 	}
 
-	container, ok := nginxcontroller.CreateContainer(state)
+	container, ok := nginxcontroller.CreateContainer(ctx, state)
 	if !ok {
 		return optional.Empty[runtime.Container]()
 	}
@@ -66,10 +52,50 @@ func SelectOrCreateIngressContainer(
 	return optional.Of(container)
 }
 
+// Shuts the ingress containers down which run another image than the ingress, e.g. after an update of
+// the image. Must only be called once the container of the current image serves the config.
+func shutdownPreviousIngressContainers(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+) {
+	selected, err := runtime.SelectContainer(
+		ctx,
+		runtime.ObjectTypeLabel(runtime.IngressObject),
+		runtime.ApplicationNameLabel(state.Metadata.Application),
+	)
+	if err != nil {
+		state.Ingress.SetError(
+			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerSelectContainer, err),
+		)
+		return
+	}
+
+	for _, s := range selected {
+		container, err := s.NewContainer(ctx, state.Metadata.Application)
+		if err != nil {
+			state.Ingress.SetError(
+				runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerSelectContainer, err),
+			)
+			return
+		}
+		if container.Image() == state.Ingress.Metadata.Image {
+			continue
+		}
+
+		if err := container.Shutdown(ctx); err != nil {
+			state.Ingress.SetError(
+				runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerStopContainer, err),
+			)
+		}
+	}
+}
+
 func SelectIngressContainer(
+	ctx context.Context,
 	state *record.ApplicationRecord,
 ) optional.Optional[runtime.Container] {
 	optionalContainer, err := runtime.TrySelectOneContainer(
+		ctx,
 		state.Metadata.Application,
 		runtime.ObjectTypeLabel(runtime.IngressObject),
 		runtime.ApplicationNameLabel(state.Metadata.Application),
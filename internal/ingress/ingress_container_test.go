@@ -4,7 +4,12 @@
 package ingress
 
 import (
+	"context"
 	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
 
 func TestIngressContainerSelectAndCreate(t *testing.T) {
@@ -18,14 +23,14 @@ func TestIngressContainerSelectAndCreate(t *testing.T) {
 		)
 		defer func() {
 			if c != nil {
-				c.Shutdown()
+				c.Shutdown(context.Background())
 			}
 			if network != nil {
-				network.Cleanup()
+				network.Cleanup(context.Background())
 			}
 		}()
 
-		network, err := runtime.CreateNewNetwork(application)
+		network, err := runtime.CreateNewNetwork(context.Background(), application)
 		assert.ErrNil(err)
 		assert.NotNil(network, "must create network")
 
@@ -37,7 +42,7 @@ func TestIngressContainerSelectAndCreate(t *testing.T) {
 		state.Ingress.Metadata.Name = application
 		state.Ingress.Metadata.CreateTime = time.Now()
 
-		c, ok := SelectOrCreateIngressContainer(&state)
+		c, ok := SelectOrCreateIngressContainer(context.Background(), &state)
 		assert.True(ok, "must create valid container")
 		if !state.Ingress.Errors.NoErrors() {
 			t.Fatalf("wanted to get no errors, but got '%v'", state)
@@ -46,10 +51,56 @@ func TestIngressContainerSelectAndCreate(t *testing.T) {
 			t.Fatalf("select or create should return a non nil value")
 		}
 
-		c1, ok := SelectOrCreateIngressContainer(&state)
+		c1, ok := SelectOrCreateIngressContainer(context.Background(), &state)
 		assert.True(ok, "must create valid container")
 		if !c.Equal(c1) {
 			t.Errorf("reselecting must return same container, but was not")
 		}
 	*/
 }
+
+func TestIngressContainerServesUntilReplacementIsConfigured(t *testing.T) {
+	fake := runtime.NewFakeBackend()
+	fake.AddImage("coredns:v1")
+	fake.AddImage("nginx:v1")
+	fake.AddImage("nginx:v2")
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+	ctx := context.Background()
+
+	_, err := runtime.CreateNewNetwork(ctx, "poseidon")
+	assert.ErrNil(err)
+	state := record.New("poseidon", record.Development)
+	state.Ingress = record.NewIngressRecord()
+	state.Ingress.Metadata.Image = "nginx:v1"
+	first := SelectOrCreateIngressContainer(ctx, state)
+	if first.IsEmpty() {
+		t.Fatalf("expected ingress container to be created, got %v", state.Ingress.Errors)
+	}
+
+	// the fake rejects a second container which publishes the same ports of the host
+	state.Ingress.Metadata.Image = "nginx:v2"
+	second := SelectOrCreateIngressContainer(ctx, state)
+	if second.IsEmpty() {
+		t.Fatalf("expected ingress container of the new image to be created, got %v", state.Ingress.Errors)
+	}
+	selected, err := runtime.SelectContainer(ctx, runtime.ObjectTypeLabel(runtime.IngressObject))
+	assert.ErrNil(err)
+	if len(selected) != 2 {
+		t.Fatalf("expected previous ingress container to serve next to the new one, got %d containers", len(selected))
+	}
+
+	shutdownPreviousIngressContainers(ctx, state)
+	if !state.Ingress.NoErrors() {
+		t.Fatalf("expected previous ingress container to be shut down, got %v", state.Ingress.Errors)
+	}
+	current := SelectIngressContainer(ctx, state)
+	if current.IsEmpty() || !current.Get().Equal(second.Get()) {
+		t.Errorf("expected only the ingress container of the new image to remain")
+	}
+	ports, err := runtime.SelectContainer(ctx, runtime.ObjectTypeLabel(runtime.IngressPortsObject))
+	assert.ErrNil(err)
+	if len(ports) != 1 {
+		t.Errorf("expected the ports to stay published by one container, got %d", len(ports))
+	}
+}
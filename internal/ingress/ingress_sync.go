@@ -6,11 +6,14 @@ package ingress
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/raphaeldichler/zeus/internal/ingress/errtype"
 	"github.com/raphaeldichler/zeus/internal/nginxcontroller"
 	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
 )
 
 const (
@@ -28,17 +31,20 @@ Sync:
   3) update state
 */
 
-func Sync(state *record.ApplicationRecord) {
+func Sync(ctx context.Context, state *record.ApplicationRecord) {
 	log := state.Logger("ingress-daemon")
-	log.Info("Starting syncing ingress controllers, image: '%s'", state.Ingress.Metadata.Image)
+	log.Info("Starting syncing ingress controllers")
 
 	defer log.Info("Completed syncing ingress controllers")
 	if !state.Ingress.Enabled() {
 		log.Info("Ingress is disabled, skipping")
 		return
 	}
+	log.Info("Ingress uses image '%s'", state.Ingress.Metadata.Image)
+	// errors only describe the outcome of the latest run, otherwise they pile up with every resync
+	state.Ingress.Errors = nil
 
-	optionalContainer := SelectOrCreateIngressContainer(state)
+	optionalContainer := SelectOrCreateIngressContainer(ctx, state)
 	if optionalContainer.IsEmpty() {
 		return
 	}
@@ -51,51 +57,178 @@ func Sync(state *record.ApplicationRecord) {
 	}
 
 	for _, server := range state.Ingress.Servers {
-		tls := server.Tls
-		if tls == nil {
-			continue
-		}
-		if tls.State == record.TlsRenew && tls.Expires.Sub(time.Now()) > TlsRenewThreshold {
-			continue
-		}
+		generateCertificate(ctx, client, generationType, state, server)
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-		defer cancel()
-		resp, err := client.GenerateCertificates(ctx, &nginxcontroller.GenerateCertificateRequest{
-			Type:             generationType,
-			CertificateEmail: tls.CertificateEmail,
-			Domain:           server.Host,
-		})
-		if err != nil {
-			state.Ingress.SetError(
-				errtype.FailedInteractionWithNginxController(server.Host, err),
-			)
+	resolver, err := runtime.NetworkResolver(ctx, state.Metadata.Application)
+	if err != nil {
+		state.Ingress.SetError(errtype.UnknownNetworkResolver(err))
+		return
+	}
+
+	configCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+	_, err = client.SetIngressConfig(configCtx, buildIngressConfigRequest(state, resolver))
+	if err != nil {
+		state.Ingress.SetError(
+			errtype.FailedInteractionWithNginxController("*", err),
+		)
+		return
+	}
+
+	// the containers of a previous image served until the current one took over
+	shutdownPreviousIngressContainers(ctx, state)
+}
+
+// Obtains a new certificate for the server, if it has tls enabled and its certificate is due for renewal.
+func generateCertificate(
+	ctx context.Context,
+	client *nginxcontroller.Client,
+	generationType nginxcontroller.GenerateCertificateType,
+	state *record.ApplicationRecord,
+	server *record.ServerRecord,
+) {
+	tls := server.Tls
+	if tls == nil {
+		return
+	}
+	if tls.State == record.TlsRenew && tls.Expires.Sub(time.Now()) > TlsRenewThreshold {
+		return
+	}
+
+	certCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+	resp, err := client.GenerateCertificates(certCtx, &nginxcontroller.GenerateCertificateRequest{
+		Type:             generationType,
+		CertificateEmail: tls.CertificateEmail,
+		Domain:           server.Host,
+	})
+	if err != nil {
+		state.Ingress.SetError(
+			errtype.FailedInteractionWithNginxController(server.Host, err),
+		)
+		return
+	}
+	if resp.Fullchain == "" || resp.Privkey == "" {
+		state.Ingress.SetError(
+			errtype.FailedObtainCertificate(server.Host, errors.New("no certificate obtained")),
+		)
+		return
+	}
+
+	tls.FullchainPem = []byte(resp.Fullchain)
+	tls.PrivkeyPem = []byte(resp.Privkey)
+	tls.State = record.TlsRenew
+	tls.Expires = time.Now().Add(TlsNewRenewThreshold)
+}
+
+// Returns the entries of a location which forwards the request to the endpoint.
+//
+// The endpoint is stored in a variable, which makes nginx resolve the hostname on each request
+// instead of once on startup. Therefore, the config stays valid even if a service is (re)created later.
+// An endpoint which names an upstream of the config is not resolved.
+func proxyEntries(endpoint string) []string {
+	return []string{
+		"set $upstream " + endpoint,
+		"proxy_pass $upstream",
+		"proxy_http_version 1.1",
+		"proxy_set_header Host $host",
+		"proxy_set_header X-Real-IP $remote_addr",
+		"proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for",
+		"proxy_set_header X-Forwarded-Proto $scheme",
+		"proxy_set_header X-Forwarded-Host $host",
+	}
+}
+
+// Returns the identifier of the service inside the config. Names of nginx variables cannot contain '-',
+// which service names can.
+func upstreamIdentifier(service record.RecordKey) string {
+	return "zeus_" + strings.ReplaceAll(string(service), "-", "_")
+}
+
+// Returns the servers of the upstream of the port of the service, the static addresses of its ready replicas.
+// On a dual stack network the replicas are reached by their IPv6 address. A rollout moves the static
+// addresses to the new containers, which switches the ingress with its next config.
+func upstreamServers(state *record.ApplicationRecord, spec *record.ServiceSpec, port string) []string {
+	var servers []string = nil
+	for replica := range spec.ReplicaCount() {
+		if !state.Service.ReplicaReady(spec.ServiceName, replica) {
 			continue
 		}
-		if resp.Fullchain == "" || resp.Privkey == "" {
-			state.Ingress.SetError(
-				errtype.FailedObtainCertificate(server.Host, errors.New("no certificate obtained")),
-			)
+		if address := state.Network.ReplicaAddressV6(spec.ServiceName, replica); address != "" {
+			servers = append(servers, "server ["+address+"]:"+port)
 			continue
 		}
+		if address := state.Network.ReplicaAddress(spec.ServiceName, replica); address != "" {
+			servers = append(servers, "server "+address+":"+port)
+		}
+	}
+
+	return servers
+}
 
-		tls.FullchainPem = []byte(resp.Fullchain)
-		tls.PrivkeyPem = []byte(resp.Privkey)
-		tls.State = record.TlsRenew
-		tls.Expires = time.Now().Add(TlsNewRenewThreshold)
+// Returns the entries of the upstream which select the replica of the service by its balancing method.
+// Without any of them nginx picks the replicas round-robin.
+func upstreamBalanceEntries(spec *record.ServiceSpec) []string {
+	switch spec.BalanceMethod() {
+	case record.BalanceLeastConn:
+		return []string{"least_conn"}
+	case record.BalanceIPHash:
+		return []string{"ip_hash"}
+	case record.BalanceSticky:
+		return []string{"hash $" + upstreamIdentifier(spec.ServiceName) + "_sticky consistent"}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	defer cancel()
-	_, err := client.SetIngressConfig(ctx, buildIngressConfigRequest(state))
-	if err != nil {
-		state.Ingress.SetError(
-			errtype.FailedInteractionWithNginxController("*", err),
+	return nil
+}
+
+// Adds the upstreams which balance the requests over the replicas of the services to the config, every
+// upstream is added once, no matter how many locations forward to it.
+type upstreamSet struct {
+	req   *nginxcontroller.IngressRequestBuilder
+	added map[string]bool
+}
+
+// Returns the entries of a location which forwards the request to the port of the service. Services with
+// static addresses are reached by an upstream of their ready replicas, all others by their hostname, which
+// resolves to their ready replicas as well.
+func (self *upstreamSet) locationEntries(
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	endpoint string,
+) []string {
+	port := endpoint[strings.LastIndex(endpoint, ":")+1:]
+	servers := upstreamServers(state, spec, port)
+	if len(servers) == 0 {
+		return proxyEntries(endpoint)
+	}
+
+	identifier := upstreamIdentifier(spec.ServiceName)
+	name := identifier + "_" + port
+	if !self.added[name] {
+		self.added[name] = true
+		self.req.AddHttpBlock("upstream "+name, slices.Concat(upstreamBalanceEntries(spec), servers)...)
+	}
+	entries := proxyEntries("http://" + name)
+	if spec.BalanceMethod() != record.BalanceSticky {
+		return entries
+	}
+
+	// clients without the cookie are hashed by the id of their first request, which the cookie remembers
+	if !self.added[identifier] {
+		self.added[identifier] = true
+		self.req.AddHttpBlock(
+			"map $cookie_"+identifier+" $"+identifier+"_sticky",
+			`"" $request_id`,
+			"default $cookie_"+identifier,
 		)
 	}
+	return append(entries, `add_header Set-Cookie "`+identifier+"=$"+identifier+`_sticky; Path=/; HttpOnly"`)
 }
 
-func buildIngressConfigRequest(state *record.ApplicationRecord) *nginxcontroller.IngressRequest {
+// Builds the config of the ingress, nginx resolves the services with the DNS of the container engine at the
+// resolver address, see runtime.NetworkResolver.
+func buildIngressConfigRequest(state *record.ApplicationRecord, resolver string) *nginxcontroller.IngressRequest {
 	req := nginxcontroller.NewIngressRequestBuilder()
 
 	req.AddEventEntries("worker_connections 1024")
@@ -110,8 +243,11 @@ func buildIngressConfigRequest(state *record.ApplicationRecord) *nginxcontroller
 		"keepalive_timeout 65",
 		"sendfile on",
 		"gzip on",
+		// the dns of the container engine resolves the services inside the application network
+		"resolver "+resolver+" valid=10s",
 	)
 
+	upstreams := &upstreamSet{req: req, added: make(map[string]bool)}
 	for _, server := range state.Ingress.Servers {
 		if state.Ingress.HasError(errtype.FailedObtainCertificateQuery(server.Host)) {
 			continue
@@ -135,11 +271,32 @@ func buildIngressConfigRequest(state *record.ApplicationRecord) *nginxcontroller
 				matching = nginxcontroller.Matching_Exact
 			}
 
+			endpoint := state.Service.GetEndpoint(loc.Service, loc.Port)
+			if endpoint == "" {
+				state.Ingress.SetError(
+					errtype.UnresolvedServiceEndpoint(server.Host, string(loc.Service), loc.Port),
+				)
+				s.AddLocation(
+					loc.Path,
+					matching,
+					"return 503",
+				)
+				continue
+			}
+			// the service exists but is not healthy yet, which is expected and therefore not an error
+			if !state.Service.Ready(loc.Service) {
+				s.AddLocation(
+					loc.Path,
+					matching,
+					"return 503",
+				)
+				continue
+			}
+
 			s.AddLocation(
 				loc.Path,
 				matching,
-				"return 200 'http-content'",
-				"add_header Content-Type text/plain",
+				upstreams.locationEntries(state, state.Service.Get(loc.Service), endpoint)...,
 			)
 		}
 	}
@@ -4,6 +4,7 @@
 package ingress
 
 import (
+	"context"
 	"fmt"
 	"math/rand/v2"
 	"os"
@@ -107,13 +108,13 @@ func TestIngressSync(t *testing.T) {
 		},
 	}
 
-	network, err := runtime.CreateNewNetwork(state.Metadata.Application)
+	network, err := runtime.CreateNewNetwork(context.Background(), state.Metadata.Application)
 	assert.ErrNil(err)
 	assert.NotNil(network, "must create network")
 
-	socketPath := nginxcontroller.HostSocketDirectory(state.Metadata.Application)
+	socketPath := nginxcontroller.HostSocketDirectory()
 	err = os.MkdirAll(socketPath, 0777)
 	assert.ErrNil(err)
 
-	Sync(state)
+	Sync(context.Background(), state)
 }
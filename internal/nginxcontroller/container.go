@@ -4,6 +4,8 @@
 package nginxcontroller
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -18,8 +20,16 @@ import (
 )
 
 var (
-	nginxRunningCheckBackoff = time.Millisecond * 500
-	hostSocketRoot           = "/run"
+	// nginx is healthy once it wrote its pid file
+	nginxHealthCheck = runtime.HealthCheck{
+		Test:          runtime.ExecProbe("test", "-e", NginxPidFilePath),
+		Interval:      time.Second * 10,
+		Timeout:       time.Second * 2,
+		StartPeriod:   time.Second * 10,
+		StartInterval: time.Millisecond * 500,
+		Retries:       3,
+	}
+	hostSocketRoot = "/run"
 )
 
 func init() {
@@ -29,39 +39,40 @@ func init() {
 }
 
 // Returns the directory which is be used to store the socket for IPC
-// between the container and the application.
+// between the container and the application. The path is resolved through the
+// mapping of the backend, see runtime.HostPath.
 func HostSocketDirectory() string {
-	return filepath.Join(hostSocketRoot, "zeus", "ingress")
+	return runtime.HostPath(filepath.Join(hostSocketRoot, "zeus", "ingress"))
 }
 
-// Creates an Ingress container which will be conntected to the network.
+// Creates an Ingress container which shares the network of the container which publishes the ports of the
+// ingress. The nginx of every ingress container binds the ports with reuseport, therefore a new ingress
+// container serves next to the previous one, until the previous one is shut down.
 //
 // If an error happends the error is written into the state and it returns nil, false. If
 // the container creation succeeds a it returns a container, true.
-func CreateContainer(state *record.ApplicationRecord) (container *runtime.Container, ok bool) {
-	network, err := runtime.TrySelectApplicationNetwork(
-		state.Metadata.Application,
-	)
+func CreateContainer(ctx context.Context, state *record.ApplicationRecord) (container *runtime.Container, ok bool) {
+	ports, err := selectOrCreatePortsContainer(ctx, state)
 	if err != nil {
 		state.Ingress.SetError(
 			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerCreateContainer, err),
 		)
+		return nil, false
 	}
-	assert.NotNil(network, "network must not be nil")
 
 	container, err = runtime.CreateNewContainer(
+		ctx,
 		state.Metadata.Application,
-		runtime.WithImage(state.Ingress.Metadata.Image),
+		runtime.WithImage(state.Ingress.Metadata.ImageReference()),
 		runtime.WithPulling(),
-		runtime.WithExposeTcpPort("80", "80"),
-		runtime.WithExposeTcpPort("443", "443"),
-		runtime.WithConnectedToNetwork(network),
+		runtime.WithNetworkOf(ports),
 		runtime.WithLabels(
 			runtime.ObjectTypeLabel(runtime.IngressObject),
 			runtime.ObjectImageLabel(state.Ingress.Metadata.Image),
 			runtime.ApplicationNameLabel(state.Metadata.Application),
 		),
 		runtime.WithMount(HostSocketDirectory(), SocketMountPath),
+		runtime.WithHealthCheck(nginxHealthCheck),
 	)
 	if err != nil {
 		state.Ingress.SetError(
@@ -70,30 +81,69 @@ func CreateContainer(state *record.ApplicationRecord) (container *runtime.Contai
 		return nil, false
 	}
 
-	for runs := 0; ; {
-		exists, err := container.ExitsPath(NginxPidFilePath)
-		if runs == 3 {
-			state.Ingress.SetError(
-				runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerInspectContainer, err),
-			)
-			return nil, false
-		}
-		if err != nil {
-			runs += 1
-		}
-		if exists {
-			break
-		}
-		time.Sleep(nginxRunningCheckBackoff)
+	health, err := container.WaitHealthy(ctx, nginxHealthCheck.StartPeriod)
+	if err == nil && health != runtime.HealthHealthy {
+		err = fmt.Errorf("ingress container is %s", health)
+	}
+	if err != nil {
+		state.Ingress.SetError(
+			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerInspectContainer, err),
+		)
+		return nil, false
 	}
 
 	return container, true
 }
 
-func ValidateContainer(c *runtime.Container, state *record.ApplicationRecord) bool {
+// Selects the container which publishes the ports of the ingress, or creates it if none exists. It only
+// holds the network the ingress containers share and is kept while they are replaced.
+//
+// The container gets labeled with:
+//   - zeus.object.type=ingress-ports
+//   - zeus.application.name={application}
+func selectOrCreatePortsContainer(ctx context.Context, state *record.ApplicationRecord) (*runtime.Container, error) {
+	optionalContainer, err := runtime.TrySelectOneContainer(
+		ctx,
+		state.Metadata.Application,
+		runtime.ObjectTypeLabel(runtime.IngressPortsObject),
+		runtime.ApplicationNameLabel(state.Metadata.Application),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if optionalContainer.IsPresent() {
+		return optionalContainer.Get(), nil
+	}
+
+	network, err := runtime.TrySelectApplicationNetwork(
+		ctx,
+		state.Metadata.Application,
+	)
+	if err != nil {
+		return nil, err
+	}
+	assert.NotNil(network, "network must not be nil")
+
+	return runtime.CreateNewContainer(
+		ctx,
+		state.Metadata.Application,
+		runtime.WithImage(state.Ingress.Metadata.ImageReference()),
+		runtime.WithPulling(),
+		runtime.WithEntrypoint("sleep", "infinity"),
+		runtime.WithExposeTcpPort("80", "80"),
+		runtime.WithExposeTcpPort("443", "443"),
+		runtime.WithConnectedToNetwork(network),
+		runtime.WithLabels(
+			runtime.ObjectTypeLabel(runtime.IngressPortsObject),
+			runtime.ApplicationNameLabel(state.Metadata.Application),
+		),
+	)
+}
+
+func ValidateContainer(ctx context.Context, c *runtime.Container, state *record.ApplicationRecord) bool {
 	assert.NotNil(c, "at this state the container was set correctly")
 
-	inspect, err := c.Inspect()
+	inspect, err := c.Inspect(ctx)
 	if err != nil {
 		state.Ingress.SetError(
 			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerInspectContainer, err),
@@ -118,7 +168,28 @@ func ValidateContainer(c *runtime.Container, state *record.ApplicationRecord) bo
 		return false
 	}
 
-	portBindings := inspect.HostConfig.PortBindings
+	// the ports are published by the container whose network the ingress container shares
+	optionalPorts, err := runtime.TrySelectOneContainer(
+		ctx,
+		state.Metadata.Application,
+		runtime.ObjectTypeLabel(runtime.IngressPortsObject),
+		runtime.ApplicationNameLabel(state.Metadata.Application),
+	)
+	if err != nil || optionalPorts.IsEmpty() {
+		return false
+	}
+	ports, err := optionalPorts.Get().Inspect(ctx)
+	if err != nil {
+		state.Ingress.SetError(
+			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerInspectContainer, err),
+		)
+		return false
+	}
+	if inspect.HostConfig.NetworkMode.ConnectedContainer() != ports.ID {
+		return false
+	}
+
+	portBindings := ports.HostConfig.PortBindings
 	if portBindings == nil {
 		return false
 	}
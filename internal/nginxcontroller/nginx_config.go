@@ -70,8 +70,13 @@ func (self *IngressRequest) storeAsNginxConfig(d directory) error {
 		w.writeln(e, ";")
 	}
 
+	for _, block := range self.HttpBlocks {
+		block.write(w)
+	}
+
+	bound := make(map[string]bool)
 	for _, server := range self.Servers {
-		if err := server.write(d, w); err != nil {
+		if err := server.write(d, w, bound); err != nil {
 			return err
 		}
 	}
@@ -132,6 +137,20 @@ func (self *Location) write(w *ConfigBuilder) {
 	w.writeln("}")
 }
 
+func (self *HttpBlock) write(w *ConfigBuilder) {
+	assert.IsAsciiString(self.Name, "only ascii chars allowed inside the config")
+	w.writeln(self.Name, " {")
+	w.intend()
+
+	for _, e := range self.Entries {
+		assert.EndsNotWith(e, ';', "cannot end with ';' already appended")
+		w.writeln(e, ";")
+	}
+
+	w.unintend()
+	w.writeln("}")
+}
+
 func (self *IngressRequest) setHTTPLocation(
 	domain string,
 	loc *Location,
@@ -188,21 +207,30 @@ func (self *IngressRequest) deleteHTTPLocation(
 	return nil
 }
 
-func (self *Server) write(d directory, w *ConfigBuilder) error {
+// Writes the server into the config. The first server which listens on an address binds it with reuseport,
+// the option applies to the socket of the address and can only be set once. It lets the nginx of a new
+// ingress container bind the ports next to the one of the previous container, see CreateContainer.
+func (self *Server) write(d directory, w *ConfigBuilder, bound map[string]bool) error {
 	w.writeln("server {")
 	w.intend()
 
 	tls := self.Tls
-	listenIPv6 := "listen [::]:80;"
-	listen := "listen 80;"
+	port, options := "80", ""
 	if tls != nil {
-		listenIPv6 = "listen [::]:443 ssl;"
-		listen = "listen 443 ssl;"
+		port, options = "443", " ssl"
 	}
-
-	w.writeln(listen)
+	addresses := []string{port}
 	if self.IPv6 {
-		w.writeln(listenIPv6)
+		addresses = append(addresses, "[::]:"+port)
+	}
+
+	for _, address := range addresses {
+		reuseport := ""
+		if !bound[address] {
+			bound[address] = true
+			reuseport = " reuseport"
+		}
+		w.writeln("listen ", address, options, reuseport, ";")
 	}
 
 	w.writeln("server_name ", self.Domain, ";")
@@ -98,7 +98,7 @@ func runNginxcontroller(t *testing.T, state *record.ApplicationRecord) func() {
 	state.Metadata.Application = state.Metadata.Application + "-" + id()
 	t.Logf("Run nginxcontroller as application %s", state.Metadata.Application)
 
-	network, err := runtime.CreateNewNetwork(state.Metadata.Application)
+	network, err := runtime.CreateNewNetwork(context.Background(), state.Metadata.Application)
 	assert.ErrNil(err)
 	assert.NotNil(network, "must create network")
 
@@ -106,16 +106,25 @@ func runNginxcontroller(t *testing.T, state *record.ApplicationRecord) func() {
 	state.Ingress.Metadata.Image = image
 	state.Ingress.Metadata.CreateTime = time.Now()
 
-	socketPath := HostSocketDirectory(state.Metadata.Application)
+	socketPath := HostSocketDirectory()
 	err = os.MkdirAll(socketPath, 0777)
 	assert.ErrNil(err)
 
-	container, ok := CreateContainer(state)
+	container, ok := CreateContainer(context.Background(), state)
 	assert.True(ok, "container failed to create")
 
 	return func() {
-		container.Shutdown()
-		network.Cleanup()
+		container.Shutdown(context.Background())
+		ports, err := runtime.TrySelectOneContainer(
+			context.Background(),
+			state.Metadata.Application,
+			runtime.ObjectTypeLabel(runtime.IngressPortsObject),
+			runtime.ApplicationNameLabel(state.Metadata.Application),
+		)
+		if err == nil && ports.IsPresent() {
+			ports.Get().Shutdown(context.Background())
+		}
+		network.Cleanup(context.Background())
 	}
 }
 
@@ -240,9 +249,9 @@ func TestNginxcontrollerHTTPServer(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
-	_, err = client.SetIngressConfig(ctx, &IngressRequest{
+	_, err := client.SetIngressConfig(ctx, &IngressRequest{
 		Servers: []*Server{
 			{
 				Domain: "localhost",
@@ -268,9 +277,9 @@ func TestNginxcontrollerHTTPServerPrefixMatching(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
-	_, err = client.SetIngressConfig(ctx, &IngressRequest{
+	_, err := client.SetIngressConfig(ctx, &IngressRequest{
 		Servers: []*Server{
 			{
 				Domain: "localhost",
@@ -298,10 +307,9 @@ func TestNginxcontrollerHTTPServerExactMatching(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
-	_, err = client.SetIngressConfig(ctx, &IngressRequest{
+	_, err := client.SetIngressConfig(ctx, &IngressRequest{
 		Servers: []*Server{
 			{
 				Domain: "localhost",
@@ -336,10 +344,9 @@ func TestNginxcontrollerHTTPServerResetting(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
-	_, err = client.SetIngressConfig(ctx, &IngressRequest{
+	_, err := client.SetIngressConfig(ctx, &IngressRequest{
 		Servers: []*Server{
 			{
 				Domain: "localhost",
@@ -382,10 +389,9 @@ func TestNginxcontrollerHTTPServerMultipleLocation(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
-	_, err = client.SetIngressConfig(ctx, &IngressRequest{
+	_, err := client.SetIngressConfig(ctx, &IngressRequest{
 		Servers: []*Server{
 			{
 				Domain: "localhost",
@@ -417,10 +423,9 @@ func TestNginxcontrollerHTTPServerSubdomains(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
-	_, err = client.SetIngressConfig(ctx, &IngressRequest{
+	_, err := client.SetIngressConfig(ctx, &IngressRequest{
 		Servers: []*Server{
 			{
 				Domain: "localhost",
@@ -457,8 +462,7 @@ func TestNginxcontrollerGenerateCertificate(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
 	resp, err := client.GenerateCertificates(ctx, &GenerateCertificateRequest{
 		Type:             GenerateCertificateType_SelfSigned,
@@ -483,8 +487,7 @@ func TestNginxcontrollerHTTPSServer(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
 	resp, err := client.GenerateCertificates(ctx, &GenerateCertificateRequest{
 		Type:             GenerateCertificateType_SelfSigned,
@@ -531,8 +534,7 @@ func TestNginxcontrollerHTTPSAndHTTPServerOnSameDomain(t *testing.T) {
 	c := runNginxcontroller(t, state)
 	defer c()
 
-	client, err := NewClient(state.Metadata.Application)
-	assert.ErrNil(err)
+	client := NewClient(state.Metadata.Application)
 	ctx := context.Background()
 	resp, err := client.GenerateCertificates(ctx, &GenerateCertificateRequest{
 		Type:             GenerateCertificateType_SelfSigned,
@@ -12,6 +12,7 @@ type IngressRequestBuilder struct {
 	general []string
 	event   []string
 	http    []string
+	blocks  []*HttpBlock
 }
 
 type ServerRequestBuilder struct {
@@ -34,6 +35,14 @@ func (i *IngressRequestBuilder) AddHttpEntries(entries ...string) {
 	i.http = append(i.http, entries...)
 }
 
+// Adds a block to the http context, like an upstream, whose name is followed by its entries.
+func (i *IngressRequestBuilder) AddHttpBlock(name string, entries ...string) {
+	i.blocks = append(i.blocks, &HttpBlock{
+		Name:    name,
+		Entries: entries,
+	})
+}
+
 func (i *IngressRequestBuilder) AddServer(
 	domain string,
 	ipv6 bool,
@@ -56,6 +65,7 @@ func (i *IngressRequestBuilder) Build() *IngressRequest {
 		GeneralEntries: i.general,
 		EventEntries:   i.event,
 		HttpEntries:    i.http,
+		HttpBlocks:     i.blocks,
 		Servers:        i.servers,
 	}
 }
@@ -30,6 +30,17 @@ type RecordIngress struct {
 type IngressMetadataRecord struct {
 	CreateTime time.Time
 	Image      string
+	// Immutable reference the image tag was resolved to on apply, the container runs this image
+	Digest string
+}
+
+// Returns the reference of the image the ingress runs, the digest if the tag was resolved.
+func (self *IngressMetadataRecord) ImageReference() string {
+	if self.Digest != "" {
+		return self.Digest
+	}
+
+	return self.Image
 }
 
 type ServerRecord struct {
@@ -47,6 +58,8 @@ type PathRecord struct {
 	Path     string
 	Matching string
 	Service  RecordKey
+	// name of the port of the service which receives the traffic
+	Port string
 }
 
 type TlsRecord struct {
@@ -70,11 +83,14 @@ func (self *RecordIngress) Enabled() bool {
 	return len(self.Servers) > 0
 }
 
+// Image of the ingress of every application
+const DefaultIngressImage = "zeus-nginx:v0.1"
+
 func NewIngressRecord() *RecordIngress {
 	return &RecordIngress{
 		Metadata: IngressMetadataRecord{
 			CreateTime: time.Now(),
-			Image:      "zeus-nginx:v0.1",
+			Image:      DefaultIngressImage,
 		},
 	}
 }
@@ -0,0 +1,187 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import (
+	"slices"
+	"time"
+)
+
+// Concurrency policies of a job, which decide what happens if a run is due while another one is running.
+const (
+	// Starts the run next to the running ones
+	ConcurrencyAllow = "allow"
+	// Skips the run
+	ConcurrencyForbid = "forbid"
+	// Stops the running ones and starts the run
+	ConcurrencyReplace = "replace"
+)
+
+// States of a run of a job
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobSpec describes a command which runs to completion in a one-off container on the application network,
+// either on its schedule or when it is triggered.
+type JobSpec struct {
+	Name  string
+	Image string
+	// Immutable reference the image tag was resolved to on apply, the runs use this image
+	Digest string
+	// Replaces the command of the image, if empty the command of the image is run
+	Command []string
+	// environment variable name to value
+	Env     map[string]string
+	Volumes []ServiceVolume
+	// Cron expression of the runs, jobs without one only run when triggered
+	Schedule string
+	// One of allow, forbid or replace, defaults to forbid
+	Concurrency string
+	// Runs which take longer are stopped and failed, zero for no limit
+	Timeout time.Duration
+	// Number of finished runs which are kept with their container, per outcome
+	SuccessfulHistory int
+	FailedHistory     int
+	// Time the schedule was last checked, the next run is the first one the schedule matches afterwards
+	LastScheduled time.Time
+}
+
+// Returns the reference of the image the job runs, the digest if the tag was resolved.
+func (self *JobSpec) ImageReference() string {
+	if self.Digest != "" {
+		return self.Digest
+	}
+
+	return self.Image
+}
+
+// Returns the concurrency policy of the job, forbid if none is set.
+func (self *JobSpec) ConcurrencyPolicy() string {
+	if self.Concurrency == "" {
+		return ConcurrencyForbid
+	}
+
+	return self.Concurrency
+}
+
+// JobRun is a single execution of a job.
+type JobRun struct {
+	// Unique among the runs of the job, e.g. '20250601T120000Z'
+	ID  string
+	Job string
+	// Started by the schedule of the job, otherwise it was triggered
+	Scheduled bool
+	State     string
+	// Exit code of the container, only set once the run finished
+	ExitCode int
+	// Why the run failed without exiting by itself, e.g. it timed out or was replaced
+	Reason     string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Returns the job with the name, or nil if it does not exist.
+func (self *ApplicationRecord) Job(name string) *JobSpec {
+	for idx := range self.Jobs {
+		if self.Jobs[idx].Name == name {
+			return &self.Jobs[idx]
+		}
+	}
+
+	return nil
+}
+
+// Adds the job or replaces the existing job of the same name.
+func (self *ApplicationRecord) SetJob(job JobSpec) {
+	if existing := self.Job(job.Name); existing != nil {
+		*existing = job
+		return
+	}
+
+	self.Jobs = append(self.Jobs, job)
+}
+
+// Removes the job, its runs are kept until they are pruned.
+func (self *ApplicationRecord) RemoveJob(name string) {
+	self.Jobs = slices.DeleteFunc(self.Jobs, func(j JobSpec) bool {
+		return j.Name == name
+	})
+}
+
+func (self *ApplicationRecord) AddJobRun(run JobRun) {
+	self.JobRuns = append(self.JobRuns, run)
+}
+
+// Returns the run of the job, or nil if it does not exist.
+func (self *ApplicationRecord) JobRun(job string, id string) *JobRun {
+	for idx := range self.JobRuns {
+		if self.JobRuns[idx].Job == job && self.JobRuns[idx].ID == id {
+			return &self.JobRuns[idx]
+		}
+	}
+
+	return nil
+}
+
+// Returns the runs of the job, oldest first.
+func (self *ApplicationRecord) RunsOfJob(job string) []JobRun {
+	var runs []JobRun = nil
+	for _, run := range self.JobRuns {
+		if run.Job == job {
+			runs = append(runs, run)
+		}
+	}
+	slices.SortStableFunc(runs, func(a, b JobRun) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return runs
+}
+
+// Returns the latest run of the job, or nil if it never ran.
+func (self *ApplicationRecord) LatestJobRun(job string) *JobRun {
+	runs := self.RunsOfJob(job)
+	if len(runs) == 0 {
+		return nil
+	}
+
+	return self.JobRun(job, runs[len(runs)-1].ID)
+}
+
+func (self *ApplicationRecord) RemoveJobRun(job string, id string) {
+	self.JobRuns = slices.DeleteFunc(self.JobRuns, func(r JobRun) bool {
+		return r.Job == job && r.ID == id
+	})
+}
+
+// Returns the finished runs of the job which exceed its history limits, oldest first. All finished runs
+// of a job which no longer exists are expired.
+func (self *ApplicationRecord) ExpiredJobRuns(job string) []JobRun {
+	succeeded, failed := 0, 0
+	if spec := self.Job(job); spec != nil {
+		succeeded, failed = spec.SuccessfulHistory, spec.FailedHistory
+	}
+
+	finished := make(map[string][]JobRun)
+	for _, run := range self.RunsOfJob(job) {
+		if run.State != JobRunRunning {
+			finished[run.State] = append(finished[run.State], run)
+		}
+	}
+
+	var expired []JobRun = nil
+	for state, limit := range map[string]int{JobRunSucceeded: succeeded, JobRunFailed: failed} {
+		if runs := finished[state]; len(runs) > limit {
+			expired = append(expired, runs[:len(runs)-limit]...)
+		}
+	}
+	slices.SortStableFunc(expired, func(a, b JobRun) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return expired
+}
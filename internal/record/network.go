@@ -0,0 +1,46 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+// NetworkRecord is the address plan of the application network. It outlives the network, a recreated
+// network keeps its subnet and the services keep their addresses.
+type NetworkRecord struct {
+	// Subnet of the network, e.g. '10.42.0.0/16', empty until the network was created
+	Subnet string
+	// Static address of every service inside the subnet
+	Addresses map[RecordKey]string
+
+	// The network is dual stack, which is decided when the application is created
+	IPv6 bool
+	// IPv6 subnet of a dual stack network, e.g. 'fd12:3456:789a:2a::/64', empty until the network was created
+	SubnetV6 string
+	// Static IPv6 address of every service inside the IPv6 subnet, mirrors the IPv4 address
+	AddressesV6 map[RecordKey]string
+
+	// Static addresses of the further replicas of every service, the first replica has the address of
+	// the service
+	ReplicaAddresses   map[RecordKey][]string
+	ReplicaAddressesV6 map[RecordKey][]string
+}
+
+// Only the address plan is synced, which is assigned by the runtime. Whether the network is dual stack
+// is decided by the api.
+func (self *NetworkRecord) Sync(other *NetworkRecord) {
+	self.Subnet = other.Subnet
+	self.Addresses = other.Addresses
+	self.SubnetV6 = other.SubnetV6
+	self.AddressesV6 = other.AddressesV6
+	self.ReplicaAddresses = other.ReplicaAddresses
+	self.ReplicaAddressesV6 = other.ReplicaAddressesV6
+}
+
+// Returns the static address of the service, empty if the service has none.
+func (self *NetworkRecord) Address(service RecordKey) string {
+	return self.Addresses[service]
+}
+
+// Returns the static IPv6 address of the service, empty if the service has none.
+func (self *NetworkRecord) AddressV6(service RecordKey) string {
+	return self.AddressesV6[service]
+}
@@ -6,11 +6,15 @@ package record
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
 
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 	"github.com/raphaeldichler/zeus/internal/util/logger"
 )
 
+// The application was enabled or disabled while its state was synchronized
+var ErrEnabledChanged = errors.New("application was enabled or disabled in the meantime")
+
 type DeploymentType int
 
 const (
@@ -39,6 +43,18 @@ type ApplicationRecord struct {
 	Metadata ApplicationMetadata
 	Ingress  *RecordIngress
 	Service  RecordService
+	// Subnet of the application network and the addresses of the services inside it
+	Network NetworkRecord
+	// Objects which changed outside of zeus and were restored, oldest first
+	Repairs []RepairRecord
+	// Snapshots of the volumes, oldest first
+	Snapshots []SnapshotRecord
+	Schedules []BackupSchedule
+	// Credentials of the private registries the images of the services are pulled from
+	Registries []RegistryCredential
+	Jobs       []JobSpec
+	// Runs of the jobs which are running or kept by the history of their job
+	JobRuns []JobRun
 }
 
 type ApplicationMetadata struct {
@@ -61,10 +77,14 @@ func (self *ApplicationRecord) Logger(daemon string) *log.Logger {
 	return log.New(self.Metadata.Application, daemon)
 }
 
+// Encodes the record to be stored, its secrets are encrypted, see LoadSecretKey.
 func (self *ApplicationRecord) ToGob() []byte {
+	stored := *self
+	stored.Registries = sealRegistries(self.Registries)
+
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
-	err := enc.Encode(self)
+	err := enc.Encode(&stored)
 	assert.ErrNil(err)
 
 	return buf.Bytes()
@@ -76,6 +96,7 @@ func FromGob(data []byte) *ApplicationRecord {
 	dec := gob.NewDecoder(buf)
 	err := dec.Decode(out)
 	assert.ErrNil(err)
+	out.Registries = openRegistries(out.Logger("record"), out.Registries)
 
 	return out
 }
@@ -85,10 +106,30 @@ func (self *ApplicationRecord) Apply() error {
 	return nil
 }
 
-func (self *ApplicationRecord) Sync(other *ApplicationRecord) {
+// Takes the state of the daemons over from the other record of the same application. Returns
+// ErrEnabledChanged if the application was enabled or disabled since the other record was read.
+func (self *ApplicationRecord) Sync(other *ApplicationRecord) error {
 	assert.True(self.Metadata.Application == other.Metadata.Application, "application must be the same")
 	assert.True(self.Metadata.Deployment == other.Metadata.Deployment, "deployment must be the same")
-	assert.True(self.Metadata.Enabled == other.Metadata.Enabled, "enabled must be the same")
+	if self.Metadata.Enabled != other.Metadata.Enabled {
+		return ErrEnabledChanged
+	}
+
+	if self.Ingress != nil && other.Ingress != nil {
+		self.Ingress.Sync(other.Ingress)
+	}
+	self.Service.Sync(&other.Service)
+	self.Network.Sync(&other.Network)
+	self.Repairs = other.Repairs
+
+	return nil
+}
+
+// Reports if the last synchronization of all daemons completed without errors.
+func (self *ApplicationRecord) NoErrors() bool {
+	if self.Ingress != nil && !self.Ingress.NoErrors() {
+		return false
+	}
 
-	self.Ingress.Sync(other.Ingress)
+	return self.Service.NoErrors()
 }
@@ -0,0 +1,73 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import (
+	"slices"
+
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+// RegistryCredential authenticates the pulls of the private images of a registry.
+type RegistryCredential struct {
+	// Host of the registry, e.g. ghcr.io or docker.io
+	Registry string
+	Username string
+	// Password or access token, it is never returned by the API and stored encrypted
+	Password string
+}
+
+// Returns the credential of the registry, or nil if the application has none.
+func (self *ApplicationRecord) Registry(registry string) *RegistryCredential {
+	for idx := range self.Registries {
+		if self.Registries[idx].Registry == registry {
+			return &self.Registries[idx]
+		}
+	}
+
+	return nil
+}
+
+// Adds the credential or replaces the existing credential of the registry.
+func (self *ApplicationRecord) SetRegistry(credential RegistryCredential) {
+	if existing := self.Registry(credential.Registry); existing != nil {
+		*existing = credential
+		return
+	}
+
+	self.Registries = append(self.Registries, credential)
+}
+
+func (self *ApplicationRecord) RemoveRegistry(registry string) {
+	self.Registries = slices.DeleteFunc(self.Registries, func(c RegistryCredential) bool {
+		return c.Registry == registry
+	})
+}
+
+// Returns copies of the credentials whose passwords are encrypted.
+func sealRegistries(credentials []RegistryCredential) []RegistryCredential {
+	if credentials == nil {
+		return nil
+	}
+
+	sealed := slices.Clone(credentials)
+	for idx := range sealed {
+		sealed[idx].Password = sealSecret(sealed[idx].Password)
+	}
+	return sealed
+}
+
+// Decrypts the passwords of the stored credentials. A password which was encrypted with another key
+// is dropped, the pulls of the registry fail until it is set again.
+func openRegistries(logger *log.Logger, credentials []RegistryCredential) []RegistryCredential {
+	for idx := range credentials {
+		password, err := openSecret(credentials[idx].Password)
+		if err != nil {
+			logger.Error("Failed to decrypt the password of registry '%s', login again: %v", credentials[idx].Registry, err)
+		}
+		credentials[idx].Password = password
+	}
+
+	return credentials
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryPasswordIsStoredEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.key")
+	if err := LoadSecretKey(path); err != nil {
+		t.Fatalf("expected secret key to be created, got %q", err)
+	}
+	key, err := os.ReadFile(path)
+	if err != nil || len(key) != SecretKeySize {
+		t.Fatalf("expected key of %d bytes to be stored, got %d (%v)", SecretKeySize, len(key), err)
+	}
+
+	state := New("poseidon", Development)
+	state.SetRegistry(RegistryCredential{Registry: "ghcr.io", Username: "rick", Password: "never-gonna-give-you-up"})
+	blob := state.ToGob()
+	if bytes.Contains(blob, []byte("never-gonna-give-you-up")) {
+		t.Fatalf("expected the password not to be stored in plaintext")
+	}
+	if state.Registry("ghcr.io").Password != "never-gonna-give-you-up" {
+		t.Errorf("expected the record to keep the plaintext password in memory")
+	}
+
+	// the daemon loads the same key after a restart
+	if err := LoadSecretKey(path); err != nil {
+		t.Fatalf("expected secret key to be loaded, got %q", err)
+	}
+	if got := FromGob(blob).Registry("ghcr.io").Password; got != "never-gonna-give-you-up" {
+		t.Errorf("expected the stored password to be decrypted, got '%s'", got)
+	}
+
+	// a replaced key cannot decrypt the password, the credential is kept without it
+	if err := LoadSecretKey(filepath.Join(t.TempDir(), "secret.key")); err != nil {
+		t.Fatalf("expected secret key to be created, got %q", err)
+	}
+	if credential := FromGob(blob).Registry("ghcr.io"); credential == nil || credential.Password != "" {
+		t.Errorf("expected the password of another key to be dropped, got %v", credential)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import "time"
+
+// Number of repairs which are kept per application, older ones are dropped
+const maxRepairs = 20
+
+// RepairRecord describes a managed object which changed outside of zeus and was restored.
+type RepairRecord struct {
+	// Type of the object, e.g. ingress, dns, service or network
+	Object     string
+	Name       string
+	Action     string
+	DetectedAt time.Time
+	RepairedAt time.Time
+}
+
+// Adds the repair and keeps only the latest repairs.
+func (self *ApplicationRecord) AddRepair(entry RepairRecord) {
+	self.Repairs = append(self.Repairs, entry)
+	if len(self.Repairs) > maxRepairs {
+		self.Repairs = self.Repairs[len(self.Repairs)-maxRepairs:]
+	}
+}
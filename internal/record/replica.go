@@ -0,0 +1,57 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+// Methods the ingress spreads the requests of a service over its replicas with
+const (
+	// Every replica gets the next request in turn
+	BalanceRoundRobin = "round-robin"
+	// The replica with the fewest active connections gets the request
+	BalanceLeastConn = "least-conn"
+	// Requests of a client address always reach the same replica
+	BalanceIPHash = "ip-hash"
+	// Requests of a client always reach the same replica, which is remembered by a cookie
+	BalanceSticky = "sticky"
+)
+
+// Returns the number of containers which run the service, at least one.
+func (self *ServiceSpec) ReplicaCount() int {
+	return max(self.Replicas, 1)
+}
+
+// Returns the method the ingress spreads the requests over the replicas with, round-robin if none is set.
+func (self *ServiceSpec) BalanceMethod() string {
+	if self.Balance == "" {
+		return BalanceRoundRobin
+	}
+
+	return self.Balance
+}
+
+// Returns the static address of the replica of the service, empty if the replica has none. The first
+// replica has the address of the service.
+func (self *NetworkRecord) ReplicaAddress(service RecordKey, replica int) string {
+	if replica == 0 {
+		return self.Address(service)
+	}
+
+	return replicaAddress(self.ReplicaAddresses[service], replica)
+}
+
+// Returns the static IPv6 address of the replica of the service, empty if the replica has none.
+func (self *NetworkRecord) ReplicaAddressV6(service RecordKey, replica int) string {
+	if replica == 0 {
+		return self.AddressV6(service)
+	}
+
+	return replicaAddress(self.ReplicaAddressesV6[service], replica)
+}
+
+func replicaAddress(addresses []string, replica int) string {
+	if replica < 1 || replica > len(addresses) {
+		return ""
+	}
+
+	return addresses[replica-1]
+}
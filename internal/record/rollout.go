@@ -0,0 +1,82 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import "time"
+
+// Strategies which replace the containers of a service once its specification changed
+const (
+	// Starts the new containers next to the old ones and switches the traffic once they are ready
+	StrategyRolling = "rolling"
+	// Stops the old containers before the new ones are started, e.g. for services which must not run twice
+	StrategyRecreate = "recreate"
+)
+
+// States of a rollout
+const (
+	// The new containers are started or the old ones are drained
+	RolloutProgressing = "progressing"
+	RolloutComplete    = "complete"
+	// A new container did not become ready, the old ones keep serving
+	RolloutRolledBack = "rolled-back"
+)
+
+// ServiceRollout is a rolling update of a service from the containers of one specification to another.
+type ServiceRollout struct {
+	// Hash of the specification the service is rolled out to
+	Hash string
+	// Hash of the specification of the replaced containers
+	PreviousHash string
+	State        string
+	// Why the rollout was rolled back
+	Reason    string
+	StartedAt time.Time
+	// Time the traffic switched to the new containers, the replaced containers are drained afterwards
+	SwitchedAt time.Time
+	FinishedAt time.Time
+	// Replaced containers which are stopped once they are drained
+	Draining []string
+	// Static addresses of the replaced containers, they are not handed out again before the containers are stopped
+	DrainingAddresses []string
+}
+
+// Returns the strategy which replaces the containers of the service, rolling if none is set.
+func (self *ServiceSpec) UpdateStrategy() string {
+	if self.Strategy == "" {
+		return StrategyRolling
+	}
+
+	return self.Strategy
+}
+
+// Returns the latest rollout of the service, or nil if it was never rolled out.
+func (self *RecordService) Rollout(service RecordKey) *ServiceRollout {
+	return self.Rollouts[service]
+}
+
+func (self *RecordService) SetRollout(service RecordKey, rollout ServiceRollout) {
+	if self.Rollouts == nil {
+		self.Rollouts = make(map[RecordKey]*ServiceRollout)
+	}
+	self.Rollouts[service] = &rollout
+}
+
+// Reports if the rollout to the current specification of the service was rolled back, the service keeps
+// running its previous containers until the specification changes again.
+func (self *RecordService) RolledBack(spec *ServiceSpec) bool {
+	rollout, ok := self.Rollouts[spec.ServiceName]
+	return ok && rollout.State == RolloutRolledBack && rollout.Hash == spec.Hash()
+}
+
+// Remembers the specification the service had before it changed to the given one. Applying an unchanged
+// specification keeps the remembered one.
+func (self *RecordService) SetPrevious(previous ServiceSpec, current *ServiceSpec) {
+	if previous.Hash() == current.Hash() {
+		return
+	}
+	if self.Previous == nil {
+		self.Previous = make(map[RecordKey]*ServiceSpec)
+	}
+	self.Previous[previous.ServiceName] = &previous
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+// Size of the AES-256 key which encrypts the secrets of the records
+const SecretKeySize = 32
+
+var (
+	secretMu sync.Mutex
+	// Key which encrypts the secrets before the record is stored, e.g. the passwords of registries.
+	// Until the daemon loads its key, the stored secrets are only readable by the running process.
+	secretKey = newSecretKey()
+)
+
+func newSecretKey() []byte {
+	key := make([]byte, SecretKeySize)
+	_, err := rand.Read(key)
+	assert.ErrNil(err)
+
+	return key
+}
+
+// Loads the key which encrypts the secrets of all records from the file, the file is created with a
+// new key if it does not exist. Secrets which were stored with another key cannot be read anymore.
+func LoadSecretKey(path string) error {
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key = newSecretKey()
+		err = os.WriteFile(path, key, 0600)
+	}
+	if err != nil {
+		return err
+	}
+	if len(key) != SecretKeySize {
+		return fmt.Errorf("secret key '%s' must have %d bytes, got %d", path, SecretKeySize, len(key))
+	}
+
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretKey = key
+	return nil
+}
+
+func secretCipher() cipher.AEAD {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+
+	block, err := aes.NewCipher(secretKey)
+	assert.ErrNil(err)
+	aead, err := cipher.NewGCM(block)
+	assert.ErrNil(err)
+
+	return aead
+}
+
+// Encrypts the secret, the result is stored instead of it.
+func sealSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	aead := secretCipher()
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	assert.ErrNil(err)
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil))
+}
+
+// Decrypts the stored secret. Fails if it was encrypted with another key.
+func openSecret(sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	aead := secretCipher()
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("secret is too short")
+	}
+	secret, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
@@ -3,28 +3,422 @@
 
 package record
 
-type RecordService struct{
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"time"
 
-  Services []ServiceSpec
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+type RecordService struct {
+	Services []ServiceSpec
+	Errors   []*ServiceErrorEntryRecord
+	// State of the services as observed by the runtime
+	Status map[RecordKey]*ServiceStatus
+	// Exits of the service containers, kept across synchronizations
+	Crashes map[RecordKey]*ServiceCrash
+	// Latest rollout of every service as observed by the runtime
+	Rollouts map[RecordKey]*ServiceRollout
+	// Specification every service had before it was last changed, restored by an undo of the rollout
+	Previous map[RecordKey]*ServiceSpec
 }
 
 type ServiceSpec struct {
-  ServiceName RecordKey
-  Network *ServiceNetwork
-  Container *ServiceContainer
+	ServiceName RecordKey
+	Network     *ServiceNetwork
+	Container   *ServiceContainer
+	// Containers which run to completion one after another before the container is started. The container
+	// is only started once all of them exited successfully.
+	InitContainers []ServiceAuxiliaryContainer `json:",omitempty"`
+	// Containers which run next to the container and share its network, they are started and stopped with it
+	Sidecars []ServiceAuxiliaryContainer `json:",omitempty"`
+	// One of rolling or recreate, defaults to rolling. It decides how the containers are replaced, not
+	// what they run, therefore it is not part of the hash.
+	Strategy string `json:"-"`
+	// Number of containers which run the service, at least one. Scaling the service starts or stops
+	// containers without replacing the others, therefore it is not part of the hash.
+	Replicas int `json:"-"`
+	// One of round-robin, least-conn, ip-hash or sticky, defaults to round-robin. It decides how the
+	// ingress spreads the requests over the replicas.
+	Balance string `json:"-"`
 }
 
 type ServiceNetwork struct {
-  // Domain name of the service
-  Name string
-  // port name to port number
-  PortMapping map[string]string
+	// Domain name of the service
+	Name string
+	// port name to port number
+	PortMapping map[string]string
 }
 
 type ServiceContainer struct {
 	Image string
+	// environment variable name to value
+	Env map[string]string
+	// Probe which decides if the container can receive traffic. If nil, the container is ready once it runs.
+	Health *ServiceHealth `json:",omitempty"`
+	// Limits of the host resources the container may use. If nil, the container is unlimited.
+	Resources *ServiceResources `json:",omitempty"`
+	Security  *ServiceSecurity  `json:",omitempty"`
+	// Named volumes of the application which are mounted into the container
+	Volumes []ServiceVolume `json:",omitempty"`
+	// One of always, on-failure or never. If empty, the container is always restarted.
+	Restart string `json:",omitempty"`
+	// Immutable reference the image tag was resolved to on apply, the container runs this image
+	Digest string `json:",omitempty"`
+}
+
+// Returns the reference of the image the container runs, the digest if the tag was resolved.
+func (self *ServiceContainer) ImageReference() string {
+	if self.Digest != "" {
+		return self.Digest
+	}
+
+	return self.Image
+}
+
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+// Returns the restart policy of the container.
+func (self *ServiceContainer) RestartPolicy() string {
+	if self.Restart == "" {
+		return RestartAlways
+	}
+
+	return self.Restart
+}
+
+// ServiceAuxiliaryContainer is an init container or a sidecar of a service.
+type ServiceAuxiliaryContainer struct {
+	// Unique among the init containers and sidecars of the service
+	Name  string
+	Image string
+	// Replaces the command of the image, if empty the command of the image is run
+	Command []string `json:",omitempty"`
+	// environment variable name to value
+	Env     map[string]string `json:",omitempty"`
+	Volumes []ServiceVolume   `json:",omitempty"`
+	// Immutable reference the image tag was resolved to on apply, the container runs this image
+	Digest string `json:",omitempty"`
+}
+
+// Returns the reference of the image the container runs, the digest if the tag was resolved.
+func (self *ServiceAuxiliaryContainer) ImageReference() string {
+	if self.Digest != "" {
+		return self.Digest
+	}
+
+	return self.Image
+}
+
+type ServiceVolume struct {
+	// Name of the volume inside the application, volumes are shared by all services which mount them
+	Name     string
+	Path     string
+	ReadOnly bool
+}
+
+// Limits which are zero are not applied.
+type ServiceResources struct {
+	// Memory in bytes
+	Memory int64
+	// CPU quota in units of 1e-9 CPUs
+	NanoCPUs int64
+	// Maximum number of processes and threads
+	Pids int64
+}
+
+type ServiceSecurity struct {
+	ReadOnlyRootFilesystem bool
+	// Linux capabilities without the CAP_ prefix, e.g. NET_RAW or ALL
+	DropCapabilities []string
+	NoNewPrivileges  bool
+	// Name or id of the user and group the process runs as. If empty, the one of the image is used.
+	User  string
+	Group string
+	Tmpfs []ServiceTmpfs
+}
+
+type ServiceTmpfs struct {
+	Path string
+	// Size in bytes, zero uses the default of the container engine
+	Size int64
+}
+
+const (
+	HealthProbeExec = "exec"
+	HealthProbeHTTP = "http"
+	HealthProbeTCP  = "tcp"
+)
+
+type ServiceHealth struct {
+	// One of exec, http or tcp
+	Probe string
+	// Command of the exec probe, exit code 0 means healthy
+	Command []string
+	// Path of the http probe
+	Path string
+	// Container port of the http and tcp probe
+	Port        string
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	// Consecutive failures until the container is unhealthy
+	Retries int
+}
+
+// Health of a service, the values match the ones of the container engine
+const (
+	ServiceHealthNone      = "none"
+	ServiceHealthStarting  = "starting"
+	ServiceHealthHealthy   = "healthy"
+	ServiceHealthUnhealthy = "unhealthy"
+)
+
+// State of a service, a service is only ready if its container runs
+const (
+	ServiceStateRunning = "running"
+	// The container exited and is restarted once its backoff passed
+	ServiceStateCrashLoopBackOff = "CrashLoopBackOff"
+	// The container exited and is not restarted by its restart policy
+	ServiceStateExited = "exited"
+)
+
+// ServiceStatus describes the first ready replica of the service, or the first replica if none is ready.
+type ServiceStatus struct {
+	ContainerID string
+	Health      string
+	State       string
+	// Status of every replica by its index
+	Replicas []ServiceStatus `json:",omitempty"`
+}
+
+// ServiceCrash describes the consecutive exits of the container of a service.
+type ServiceCrash struct {
+	// Hash of the specification of the exited container, the crash is forgotten once the specification changes
+	Hash string
+	// Name of the init container or sidecar which exited, empty if the container of the service exited
+	Container string
+	// Consecutive exits of the container, reset once a container ran long enough
+	Restarts int
+	ExitCode int
+	ExitedAt time.Time
+	// Last lines of the output of the exited container
+	Logs []string
+	// The container is not restarted before
+	BackoffUntil time.Time
+}
+
+type ServiceErrorEntryRecord struct {
+	Service    RecordKey
+	Type       string
+	Identifier string
+	Message    string
+}
+
+// Returns a hash over the complete specification. Two specifications with the same
+// hash will result in the same container.
+func (self *ServiceSpec) Hash() string {
+	// json is used as it orders the keys of maps, which makes the hash stable
+	blob, err := json.Marshal(self)
+	assert.ErrNil(err)
+
+	sum := sha256.Sum256(blob)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Returns the volumes which are mounted by the container, the init containers, and the sidecars of the service.
+func (self *ServiceSpec) Volumes() []ServiceVolume {
+	var volumes []ServiceVolume = nil
+	if self.Container != nil {
+		volumes = append(volumes, self.Container.Volumes...)
+	}
+	for _, aux := range self.InitContainers {
+		volumes = append(volumes, aux.Volumes...)
+	}
+	for _, aux := range self.Sidecars {
+		volumes = append(volumes, aux.Volumes...)
+	}
+
+	return volumes
 }
 
-func (self *RecordService) GetEndpoint(service RecordKey) string {
-	return ""
+// Returns the name under which the service can be resolved inside the application network.
+func (self *ServiceSpec) Hostname() string {
+	if self.Network != nil && self.Network.Name != "" {
+		return self.Network.Name
+	}
+
+	return string(self.ServiceName)
+}
+
+// Returns the service with the given name or nil if it does not exist.
+func (self *RecordService) Get(service RecordKey) *ServiceSpec {
+	for idx := range self.Services {
+		if self.Services[idx].ServiceName == service {
+			return &self.Services[idx]
+		}
+	}
+
+	return nil
+}
+
+// Returns the endpoint (http://{hostname}:{port}) of the named port of the service inside the application network.
+// If the port name is empty and the service defines exactly one port, this port is used.
+//
+// An empty string is returned if the service or the port does not exist.
+func (self *RecordService) GetEndpoint(service RecordKey, port string) string {
+	spec := self.Get(service)
+	if spec == nil || spec.Network == nil {
+		return ""
+	}
+
+	portMapping := spec.Network.PortMapping
+	if port == "" && len(portMapping) == 1 {
+		for name := range portMapping {
+			port = name
+		}
+	}
+
+	number, ok := portMapping[port]
+	if !ok {
+		return ""
+	}
+
+	return "http://" + spec.Hostname() + ":" + number
+}
+
+func (self *ServiceStatus) ready() bool {
+	return self.State == ServiceStateRunning &&
+		(self.Health == ServiceHealthNone || self.Health == ServiceHealthHealthy)
+}
+
+// Reports if at least one replica of the service runs and passes its health check, only ready services
+// receive traffic.
+func (self *RecordService) Ready(service RecordKey) bool {
+	status, ok := self.Status[service]
+	return ok && status.ready()
+}
+
+// Reports if the replica of the service runs and passes its health check.
+func (self *RecordService) ReplicaReady(service RecordKey, replica int) bool {
+	status, ok := self.Status[service]
+	if !ok {
+		return false
+	}
+	if len(status.Replicas) == 0 {
+		return replica == 0 && status.ready()
+	}
+
+	return replica < len(status.Replicas) && status.Replicas[replica].ready()
+}
+
+// Sets the status of the first replica of the service.
+func (self *RecordService) SetStatus(service RecordKey, status ServiceStatus) {
+	self.SetReplicaStatus(service, 0, status)
+}
+
+// Sets the status of the replica of the service, the status of the service follows its first ready replica.
+func (self *RecordService) SetReplicaStatus(service RecordKey, replica int, status ServiceStatus) {
+	if self.Status == nil {
+		self.Status = make(map[RecordKey]*ServiceStatus)
+	}
+	current, ok := self.Status[service]
+	if !ok {
+		current = &ServiceStatus{}
+		self.Status[service] = current
+	}
+	for len(current.Replicas) <= replica {
+		current.Replicas = append(current.Replicas, ServiceStatus{})
+	}
+	status.Replicas = nil
+	current.Replicas[replica] = status
+
+	first := current.Replicas[0]
+	for _, r := range current.Replicas {
+		if r.ready() {
+			first = r
+			break
+		}
+	}
+	current.ContainerID = first.ContainerID
+	current.Health = first.Health
+	current.State = first.State
+}
+
+func (self *RecordService) NoErrors() bool {
+	return len(self.Errors) == 0
+}
+
+func (self *RecordService) SetError(entry ServiceErrorEntryRecord) {
+	self.Errors = append(self.Errors, &ServiceErrorEntryRecord{
+		Service:    entry.Service,
+		Type:       entry.Type,
+		Identifier: entry.Identifier,
+		Message:    entry.Message,
+	})
+}
+
+// Only the state which is observed by the runtime is synced, the specification is owned by the api.
+func (self *RecordService) Sync(other *RecordService) {
+	self.Errors = other.Errors
+	self.Status = other.Status
+	self.Crashes = other.Crashes
+	self.Rollouts = other.Rollouts
+}
+
+// Returns the crash of the service if it happened with the current specification, otherwise nil.
+func (self *RecordService) Crash(spec *ServiceSpec) *ServiceCrash {
+	crash, ok := self.Crashes[spec.ServiceName]
+	if !ok || crash.Hash != spec.Hash() {
+		return nil
+	}
+
+	return crash
+}
+
+func (self *RecordService) SetCrash(service RecordKey, crash ServiceCrash) {
+	if self.Crashes == nil {
+		self.Crashes = make(map[RecordKey]*ServiceCrash)
+	}
+	self.Crashes[service] = &crash
+}
+
+// Returns the earliest time at which a crashed service is restarted. Returns false if no service waits for its restart.
+func (self *RecordService) NextRestart(now time.Time) (time.Time, bool) {
+	var next time.Time
+	for service, status := range self.Status {
+		crash, ok := self.Crashes[service]
+		backoff := status.State == ServiceStateCrashLoopBackOff ||
+			slices.ContainsFunc(status.Replicas, func(r ServiceStatus) bool { return r.State == ServiceStateCrashLoopBackOff })
+		if !ok || !backoff || !crash.BackoffUntil.After(now) {
+			continue
+		}
+		if next.IsZero() || crash.BackoffUntil.Before(next) {
+			next = crash.BackoffUntil
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// Reports the services which mount the volume.
+func (self *RecordService) VolumeUsers(volume string) []RecordKey {
+	var users []RecordKey = nil
+	for _, spec := range self.Services {
+		for _, v := range spec.Volumes() {
+			if v.Name == volume {
+				users = append(users, spec.ServiceName)
+				break
+			}
+		}
+	}
+
+	return users
 }
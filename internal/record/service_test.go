@@ -0,0 +1,78 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServiceGetEndpoint(t *testing.T) {
+	services := RecordService{
+		Services: []ServiceSpec{
+			{
+				ServiceName: "rickroll",
+				Network: &ServiceNetwork{
+					Name: "rroll",
+					PortMapping: map[string]string{
+						"application": "8000",
+						"grafana":     "3000",
+					},
+				},
+				Container: &ServiceContainer{Image: "rickroll:v1.12"},
+			},
+			{
+				ServiceName: "poseidon",
+				Network: &ServiceNetwork{
+					PortMapping: map[string]string{"application": "80"},
+				},
+				Container: &ServiceContainer{Image: "poseidon:v1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		service  RecordKey
+		port     string
+		endpoint string
+	}{
+		{name: "named.port", service: "rickroll", port: "grafana", endpoint: "http://rroll:3000"},
+		{name: "default.hostname", service: "poseidon", port: "application", endpoint: "http://poseidon:80"},
+		{name: "single.port", service: "poseidon", port: "", endpoint: "http://poseidon:80"},
+		{name: "ambiguous.port", service: "rickroll", port: "", endpoint: ""},
+		{name: "unknown.port", service: "rickroll", port: "metrics", endpoint: ""},
+		{name: "unknown.service", service: "hades", port: "application", endpoint: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := services.GetEndpoint(tt.service, tt.port)
+			if endpoint != tt.endpoint {
+				t.Errorf("expected endpoint '%s', got '%s'", tt.endpoint, endpoint)
+			}
+		})
+	}
+}
+
+func TestServiceReplicaStatus(t *testing.T) {
+	services := RecordService{}
+	services.SetReplicaStatus("rickroll", 1, ServiceStatus{ContainerID: "b", Health: ServiceHealthHealthy, State: ServiceStateRunning})
+	services.SetReplicaStatus("rickroll", 0, ServiceStatus{ContainerID: "a", Health: ServiceHealthStarting, State: ServiceStateRunning})
+
+	if status := services.Status["rickroll"]; status.ContainerID != "b" || len(status.Replicas) != 2 {
+		t.Errorf("expected status to follow the ready replica, got %v", status)
+	}
+	if !services.Ready("rickroll") || services.ReplicaReady("rickroll", 0) || !services.ReplicaReady("rickroll", 1) {
+		t.Errorf("expected only the second replica to be ready")
+	}
+
+	// a replica which waits for its restart is retried although another one serves
+	now := time.Now()
+	services.Crashes = map[RecordKey]*ServiceCrash{"rickroll": {BackoffUntil: now.Add(time.Minute)}}
+	services.SetReplicaStatus("rickroll", 0, ServiceStatus{Health: ServiceHealthNone, State: ServiceStateCrashLoopBackOff})
+	if next, ok := services.NextRestart(now); !ok || !next.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected restart of the crashed replica, got %v", next)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package record
+
+import (
+	"slices"
+	"time"
+)
+
+// SnapshotRecord describes an archive of the content of a volume, which is kept in a snapshot store.
+type SnapshotRecord struct {
+	ID     string
+	Volume string
+	// Kind of store which keeps the archive, e.g. directory or s3
+	Target string
+	// Key of the archive inside the store
+	Key string
+	// Size of the compressed archive in bytes
+	Size int64
+	// SHA-256 of the compressed archive, hex encoded
+	Checksum  string
+	CreatedAt time.Time
+	// Taken by the backup schedule of the volume, only those snapshots are subject to its retention
+	Scheduled bool
+}
+
+// BackupSchedule takes a snapshot of the volume periodically.
+type BackupSchedule struct {
+	Volume   string
+	Interval time.Duration
+	// Number of scheduled snapshots which are kept, older ones are removed
+	Retain  int
+	LastRun time.Time
+}
+
+// Reports if the next snapshot of the schedule is due.
+func (self *BackupSchedule) Due(now time.Time) bool {
+	return !now.Before(self.LastRun.Add(self.Interval))
+}
+
+func (self *ApplicationRecord) AddSnapshot(snapshot SnapshotRecord) {
+	self.Snapshots = append(self.Snapshots, snapshot)
+}
+
+// Returns the snapshot with the ID, or nil if it does not exist.
+func (self *ApplicationRecord) Snapshot(id string) *SnapshotRecord {
+	for idx := range self.Snapshots {
+		if self.Snapshots[idx].ID == id {
+			return &self.Snapshots[idx]
+		}
+	}
+
+	return nil
+}
+
+func (self *ApplicationRecord) RemoveSnapshot(id string) {
+	self.Snapshots = slices.DeleteFunc(self.Snapshots, func(s SnapshotRecord) bool {
+		return s.ID == id
+	})
+}
+
+// Returns the scheduled snapshots of the volume which exceed the retention, oldest first.
+func (self *ApplicationRecord) ExpiredSnapshots(volume string, retain int) []SnapshotRecord {
+	var scheduled []SnapshotRecord = nil
+	for _, s := range self.Snapshots {
+		if s.Volume == volume && s.Scheduled {
+			scheduled = append(scheduled, s)
+		}
+	}
+	if len(scheduled) <= retain {
+		return nil
+	}
+
+	slices.SortFunc(scheduled, func(a, b SnapshotRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return scheduled[:len(scheduled)-retain]
+}
+
+// Returns the backup schedule of the volume, or nil if it has none.
+func (self *ApplicationRecord) Schedule(volume string) *BackupSchedule {
+	for idx := range self.Schedules {
+		if self.Schedules[idx].Volume == volume {
+			return &self.Schedules[idx]
+		}
+	}
+
+	return nil
+}
+
+// Adds the schedule or replaces the existing schedule of the volume.
+func (self *ApplicationRecord) SetSchedule(schedule BackupSchedule) {
+	if existing := self.Schedule(schedule.Volume); existing != nil {
+		*existing = schedule
+		return
+	}
+
+	self.Schedules = append(self.Schedules, schedule)
+}
+
+func (self *ApplicationRecord) RemoveSchedule(volume string) {
+	self.Schedules = slices.DeleteFunc(self.Schedules, func(s BackupSchedule) bool {
+		return s.Volume == volume
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// Backend is the container engine which runs the containers and networks of zeus.
+//
+// The types of the docker engine API are used to describe containers and networks, other engines
+// are expected to translate them.
+type Backend interface {
+	// Reports if the image exists on the machine
+	ImageExists(ctx context.Context, ref string) (bool, error)
+	// Pulls the image and returns after the pull completed, the progress is reported while pulling
+	ImagePull(ctx context.Context, ref string, options PullOptions) error
+	// Returns the ID and the repository digests of the image, the reference may be a tag, digest or ID
+	ImageInspect(ctx context.Context, ref string) (image.InspectResponse, error)
+
+	// Creates the container and returns its ID, the container is not started
+	ContainerCreate(
+		ctx context.Context,
+		config *container.Config,
+		hostConfig *container.HostConfig,
+		networkConfig *network.NetworkingConfig,
+	) (string, error)
+	ContainerStart(ctx context.Context, containerID string) error
+	ContainerStop(ctx context.Context, containerID string) error
+	// Waits until the container is no longer running and returns its exit code
+	ContainerWait(ctx context.Context, containerID string) (int, error)
+	// Removes the container, even if it is running
+	ContainerRemove(ctx context.Context, containerID string) error
+	// Runs the command inside the container and waits until it exits
+	ContainerExec(ctx context.Context, containerID string, cmd []string) (*ExecResult, error)
+	// Creates an exec session and attaches to it. The caller must close the connection of the session.
+	// The output is multiplexed in the format of the docker engine, unless a TTY is allocated.
+	ContainerExecAttach(ctx context.Context, containerID string, options container.ExecOptions) (string, types.HijackedResponse, error)
+	ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error
+	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
+	// Extracts the tar archive into the directory of the container
+	CopyToContainer(ctx context.Context, containerID string, dstPath string, content io.Reader) error
+	// Streams the file or directory of the container as tar archive, the entries are relative to the
+	// parent directory of the path. Works on containers which are not running.
+	CopyFromContainer(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error)
+	// Returns the stat of the file or directory in the container, nil if the path does not exist
+	ContainerStatPath(ctx context.Context, containerID string, p string) (*container.PathStat, error)
+	// Streams stdout and stderr of the container, multiplexed in the format of the docker engine
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	// Returns the resource usage of the running container, the CPU usage of the previous read is included
+	ContainerStats(ctx context.Context, containerID string) (container.StatsResponse, error)
+	// Returns the disk usage of the images and volumes on the machine
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
+
+	// Creates a bridged network and returns its ID. The container engine picks the subnet if ipam is nil
+	NetworkCreate(ctx context.Context, name string, labels map[string]string, ipam *network.IPAM) (string, error)
+	NetworkRemove(ctx context.Context, networkID string) error
+	// Connects the container to the network, the container is reachable by the aliases and the static
+	// address of the endpoint inside the network
+	NetworkConnect(ctx context.Context, networkID string, containerID string, endpoint *network.EndpointSettings) error
+	NetworkDisconnect(ctx context.Context, networkID string, containerID string) error
+	NetworkList(ctx context.Context, options network.ListOptions) ([]network.Summary, error)
+
+	// Creates the named volume, creating an existing volume is a no-op
+	VolumeCreate(ctx context.Context, name string, labels map[string]string) error
+	// Removes the named volume, fails if a container uses it
+	VolumeRemove(ctx context.Context, name string) error
+	VolumeList(ctx context.Context, options volume.ListOptions) ([]*volume.Volume, error)
+
+	// Streams the events matching the filters until the context is cancelled
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+}
+
+// Result of a command which was executed inside a container
+type ExecResult struct {
+	ExitCode int
+	// Combined stdout and stderr of the command
+	Output []byte
+}
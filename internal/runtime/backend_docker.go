@@ -0,0 +1,371 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Backend which talks to the docker daemon
+type dockerBackend struct {
+	client *client.Client
+}
+
+var _ Backend = (*dockerBackend)(nil)
+
+func NewDockerBackend(opts ...client.Opt) (Backend, error) {
+	opts = append([]client.Opt{client.WithAPIVersionNegotiation()}, opts...)
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockerBackend{client: cli}, nil
+}
+
+func (self *dockerBackend) ImageExists(ctx context.Context, ref string) (bool, error) {
+	_, err := self.client.ImageInspect(ctx, ref)
+	if err == nil {
+		return true, nil
+	}
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (self *dockerBackend) ImageInspect(ctx context.Context, ref string) (image.InspectResponse, error) {
+	return self.client.ImageInspect(ctx, ref)
+}
+
+// Message of the progress stream of a pull
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	// A failed pull is only reported in the stream, the request itself succeeds
+	Error string `json:"error"`
+}
+
+func (self *dockerBackend) ImagePull(ctx context.Context, ref string, options PullOptions) error {
+	r, err := self.client.ImagePull(ctx, ref, image.PullOptions{RegistryAuth: options.RegistryAuth})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// the pull completes once the progress stream is consumed
+	decoder := json.NewDecoder(r)
+	for {
+		var msg pullMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("pull of image '%s' failed: %s", ref, msg.Error)
+		}
+
+		options.progress(PullProgress{
+			Layer:   msg.ID,
+			Status:  msg.Status,
+			Current: msg.ProgressDetail.Current,
+			Total:   msg.ProgressDetail.Total,
+		})
+	}
+}
+
+func (self *dockerBackend) ContainerCreate(
+	ctx context.Context,
+	config *container.Config,
+	hostConfig *container.HostConfig,
+	networkConfig *network.NetworkingConfig,
+) (string, error) {
+	cont, err := self.client.ContainerCreate(ctx, config, hostConfig, networkConfig, nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	return cont.ID, nil
+}
+
+func (self *dockerBackend) ContainerStart(ctx context.Context, containerID string) error {
+	return self.client.ContainerStart(ctx, containerID, container.StartOptions{})
+}
+
+func (self *dockerBackend) ContainerStop(ctx context.Context, containerID string) error {
+	return self.client.ContainerStop(ctx, containerID, container.StopOptions{Timeout: nil})
+}
+
+func (self *dockerBackend) ContainerWait(ctx context.Context, containerID string) (int, error) {
+	resultC, errC := self.client.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
+	select {
+	case result := <-resultC:
+		if result.Error != nil {
+			return 0, errors.New(result.Error.Message)
+		}
+		return int(result.StatusCode), nil
+	case err := <-errC:
+		return 0, err
+	}
+}
+
+func (self *dockerBackend) ContainerRemove(ctx context.Context, containerID string) error {
+	return self.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true})
+}
+
+func (self *dockerBackend) ContainerLogs(
+	ctx context.Context,
+	containerID string,
+	options container.LogsOptions,
+) (io.ReadCloser, error) {
+	return self.client.ContainerLogs(ctx, containerID, options)
+}
+
+func (self *dockerBackend) ContainerExec(
+	ctx context.Context,
+	containerID string,
+	cmd []string,
+) (*ExecResult, error) {
+	resp, err := self.client.ContainerExecCreate(
+		ctx,
+		containerID,
+		container.ExecOptions{
+			Cmd:          cmd,
+			AttachStdin:  true,
+			AttachStdout: true,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	attach, err := self.client.ContainerExecAttach(ctx, resp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer attach.Close()
+
+	var outputBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&outputBuf, &outputBuf, attach.Reader); err != nil {
+		return nil, err
+	}
+
+	insp, err := self.client.ContainerExecInspect(ctx, resp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecResult{
+		ExitCode: insp.ExitCode,
+		Output:   outputBuf.Bytes(),
+	}, nil
+}
+
+func (self *dockerBackend) ContainerExecAttach(
+	ctx context.Context,
+	containerID string,
+	options container.ExecOptions,
+) (string, types.HijackedResponse, error) {
+	resp, err := self.client.ContainerExecCreate(ctx, containerID, options)
+	if err != nil {
+		return "", types.HijackedResponse{}, err
+	}
+
+	attach, err := self.client.ContainerExecAttach(ctx, resp.ID, container.ExecAttachOptions{
+		Tty:         options.Tty,
+		ConsoleSize: options.ConsoleSize,
+	})
+	if err != nil {
+		return "", types.HijackedResponse{}, err
+	}
+
+	return resp.ID, attach, nil
+}
+
+func (self *dockerBackend) ContainerExecResize(
+	ctx context.Context,
+	execID string,
+	options container.ResizeOptions,
+) error {
+	return self.client.ContainerExecResize(ctx, execID, options)
+}
+
+func (self *dockerBackend) ContainerExecInspect(
+	ctx context.Context,
+	execID string,
+) (container.ExecInspect, error) {
+	return self.client.ContainerExecInspect(ctx, execID)
+}
+
+func (self *dockerBackend) CopyToContainer(
+	ctx context.Context,
+	containerID string,
+	dstPath string,
+	content io.Reader,
+) error {
+	return self.client.CopyToContainer(ctx, containerID, dstPath, content, container.CopyToContainerOptions{})
+}
+
+func (self *dockerBackend) CopyFromContainer(
+	ctx context.Context,
+	containerID string,
+	srcPath string,
+) (io.ReadCloser, error) {
+	content, _, err := self.client.CopyFromContainer(ctx, containerID, srcPath)
+	return content, err
+}
+
+func (self *dockerBackend) ContainerStatPath(
+	ctx context.Context,
+	containerID string,
+	p string,
+) (*container.PathStat, error) {
+	stat, err := self.client.ContainerStatPath(ctx, containerID, p)
+	if err == nil {
+		return &stat, nil
+	}
+	if client.IsErrNotFound(err) {
+		return nil, nil
+	}
+
+	return nil, err
+}
+
+func (self *dockerBackend) ContainerInspect(
+	ctx context.Context,
+	containerID string,
+) (container.InspectResponse, error) {
+	return self.client.ContainerInspect(ctx, containerID)
+}
+
+func (self *dockerBackend) ContainerStats(ctx context.Context, containerID string) (container.StatsResponse, error) {
+	// without streaming the engine samples twice, which is needed for the CPU usage
+	stats, err := self.client.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return container.StatsResponse{}, err
+	}
+	defer stats.Body.Close()
+
+	var response container.StatsResponse
+	if err := json.NewDecoder(stats.Body).Decode(&response); err != nil {
+		return container.StatsResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (self *dockerBackend) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	return self.client.DiskUsage(ctx, types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.ImageObject, types.VolumeObject},
+	})
+}
+
+func (self *dockerBackend) ContainerList(
+	ctx context.Context,
+	options container.ListOptions,
+) ([]container.Summary, error) {
+	return self.client.ContainerList(ctx, options)
+}
+
+func (self *dockerBackend) NetworkCreate(
+	ctx context.Context,
+	name string,
+	labels map[string]string,
+	ipam *network.IPAM,
+) (string, error) {
+	options := network.CreateOptions{Labels: labels, IPAM: ipam}
+	// the network is dual stack if the address management has an IPv6 subnet
+	if ipam != nil {
+		for _, config := range ipam.Config {
+			if subnet, err := netip.ParsePrefix(config.Subnet); err == nil && subnet.Addr().Is6() {
+				enableIPv6 := true
+				options.EnableIPv6 = &enableIPv6
+			}
+		}
+	}
+
+	created, err := self.client.NetworkCreate(ctx, name, options)
+	if err != nil {
+		return "", err
+	}
+
+	return created.ID, nil
+}
+
+func (self *dockerBackend) NetworkRemove(ctx context.Context, networkID string) error {
+	return self.client.NetworkRemove(ctx, networkID)
+}
+
+func (self *dockerBackend) NetworkConnect(
+	ctx context.Context,
+	networkID string,
+	containerID string,
+	endpoint *network.EndpointSettings,
+) error {
+	return self.client.NetworkConnect(ctx, networkID, containerID, endpoint)
+}
+
+func (self *dockerBackend) NetworkDisconnect(ctx context.Context, networkID string, containerID string) error {
+	return self.client.NetworkDisconnect(ctx, networkID, containerID, false)
+}
+
+func (self *dockerBackend) NetworkList(
+	ctx context.Context,
+	options network.ListOptions,
+) ([]network.Summary, error) {
+	return self.client.NetworkList(ctx, options)
+}
+
+func (self *dockerBackend) VolumeCreate(
+	ctx context.Context,
+	name string,
+	labels map[string]string,
+) error {
+	_, err := self.client.VolumeCreate(ctx, volume.CreateOptions{Name: name, Labels: labels})
+	return err
+}
+
+func (self *dockerBackend) VolumeRemove(ctx context.Context, name string) error {
+	return self.client.VolumeRemove(ctx, name, false)
+}
+
+func (self *dockerBackend) VolumeList(
+	ctx context.Context,
+	options volume.ListOptions,
+) ([]*volume.Volume, error) {
+	resp, err := self.client.VolumeList(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Volumes, nil
+}
+
+func (self *dockerBackend) Events(
+	ctx context.Context,
+	options events.ListOptions,
+) (<-chan events.Message, <-chan error) {
+	return self.client.Events(ctx, options)
+}
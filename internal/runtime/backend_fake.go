@@ -0,0 +1,1619 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+)
+
+var (
+	ErrFakeNotFound = errors.New("fake backend: no such object")
+	ErrFakeConflict = errors.New("fake backend: conflict")
+)
+
+// FakeBackend is an in-memory backend which allows testing without a container engine.
+//
+// Containers do not run any process, they run until they are stopped or exit by Exit or ExitOnStart.
+// Files copied into a container are kept in memory and the commands 'cat', 'test -e' and 'mkdir -p'
+// are emulated on them. Files below the target of a mounted volume are kept in the volume and shared
+// by all containers which mount it. Events are emitted like the docker daemon does for the supported
+// operations.
+type FakeBackend struct {
+	mu       sync.Mutex
+	sequence int
+	// Images by the reference they were added or pulled with
+	images      map[string]*fakeImage
+	containers  map[string]*fakeContainer
+	networks    map[string]*fakeNetwork
+	subscribers []*fakeSubscriber
+	failures    map[string]error
+	execs       map[string]*fakeExec
+	volumes     map[string]*fakeVolume
+	// Encoded registry credential of every pulled image
+	pullAuth map[string]string
+	pulls    int
+}
+
+type fakeContainer struct {
+	id         string
+	name       string
+	running    bool
+	config     *container.Config
+	hostConfig *container.HostConfig
+	networks   map[string]*network.EndpointSettings
+	files      map[string][]byte
+	dirs       map[string]struct{}
+	// Mode and owner of the files and directories which were copied into the container
+	modes map[string]fakeFileMode
+	// Health of containers with a health check, empty otherwise
+	health     string
+	logs       []fakeLogLine
+	exitCode   int
+	startedAt  time.Time
+	finishedAt time.Time
+	// Resource usage reported by the stats, see SetStats
+	stats container.StatsResponse
+	// Closed once the container stops, replaced on every start
+	stopped chan struct{}
+}
+
+type fakeFileMode struct {
+	mode int64
+	uid  int
+	gid  int
+}
+
+type fakeLogLine struct {
+	time   time.Time
+	stream stdcopy.StdType
+	line   string
+}
+
+// Exec session of a fake container. The command 'cat' without arguments echos stdin until it is closed,
+// all other commands are emulated like ContainerExec.
+type fakeExec struct {
+	running  bool
+	exitCode int
+	height   uint
+	width    uint
+}
+
+type fakeImage struct {
+	id string
+	// Digests in the repository, empty for images which were never pulled
+	repoDigests []string
+	size        int64
+	// Containers of the image exit right after they started if set, see ExitOnStart
+	exit *fakeExit
+}
+
+type fakeExit struct {
+	code int
+	// Lines which the container writes to stdout before it exits
+	output []string
+}
+
+type fakeVolume struct {
+	name      string
+	labels    map[string]string
+	createdAt time.Time
+	// Content of the volume by the path relative to its root
+	files map[string][]byte
+}
+
+type fakeNetwork struct {
+	id     string
+	name   string
+	labels map[string]string
+	ipam   *network.IPAM
+}
+
+type fakeSubscriber struct {
+	filters  filters.Args
+	messages chan events.Message
+}
+
+var _ Backend = (*FakeBackend)(nil)
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		images:     make(map[string]*fakeImage),
+		containers: make(map[string]*fakeContainer),
+		networks:   make(map[string]*fakeNetwork),
+		failures:   make(map[string]error),
+		execs:      make(map[string]*fakeExec),
+		volumes:    make(map[string]*fakeVolume),
+		pullAuth:   make(map[string]string),
+	}
+}
+
+// Makes the image available without pulling it, like an image which was built on the machine.
+func (self *FakeBackend) AddImage(ref string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.images[ref] = &fakeImage{id: fakeDigest(ref)}
+}
+
+func fakeDigest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Must be called while holding the lock.
+func (self *FakeBackend) lookupImage(ref string) *fakeImage {
+	if img, ok := self.images[ref]; ok {
+		return img
+	}
+	for _, img := range self.images {
+		if img.id == ref || slices.Contains(img.repoDigests, ref) {
+			return img
+		}
+	}
+
+	return nil
+}
+
+// Makes every following call of the operation fail with the error, e.g. Fail("ContainerStart", err).
+// A nil error removes the failure.
+func (self *FakeBackend) Fail(operation string, err error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if err == nil {
+		delete(self.failures, operation)
+		return
+	}
+	self.failures[operation] = err
+}
+
+// Appends the line to the logs of the container as if its process wrote it to stdout or stderr.
+func (self *FakeBackend) Log(containerID string, stderr bool, line string) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+
+	stream := stdcopy.Stdout
+	if stderr {
+		stream = stdcopy.Stderr
+	}
+	cont.logs = append(cont.logs, fakeLogLine{time: time.Now(), stream: stream, line: line})
+
+	return nil
+}
+
+// Stops the container as if its process was killed outside of zeus.
+func (self *FakeBackend) Kill(containerID string) error {
+	return self.Exit(containerID, 137)
+}
+
+// Stops the container as if its process exited with the exit code.
+func (self *FakeBackend) Exit(containerID string, exitCode int) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	if cont.running {
+		cont.exitCode = exitCode
+	}
+	self.stop(cont)
+
+	return nil
+}
+
+// Lets the containers of the image exit with the exit code right after they started, like a command
+// which runs to completion. The output is written to stdout before the container exits.
+func (self *FakeBackend) ExitOnStart(ref string, exitCode int, output ...string) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	img := self.lookupImage(ref)
+	if img == nil {
+		return ErrFakeNotFound
+	}
+	img.exit = &fakeExit{code: exitCode, output: output}
+
+	return nil
+}
+
+// Sets the resource usage the stats of the container report.
+func (self *FakeBackend) SetStats(containerID string, stats container.StatsResponse) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	cont.stats = stats
+
+	return nil
+}
+
+// Sets the size on disk of the image.
+func (self *FakeBackend) SetImageSize(ref string, size int64) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	img := self.lookupImage(ref)
+	if img == nil {
+		return ErrFakeNotFound
+	}
+	img.size = size
+
+	return nil
+}
+
+// Sets the health of the container, as if its health check passed or failed.
+func (self *FakeBackend) SetHealth(containerID string, health string) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	if cont.health == "" {
+		return fmt.Errorf("%w: container has no health check", ErrFakeConflict)
+	}
+
+	cont.health = health
+	self.emit(
+		events.ContainerEventType,
+		events.Action(string(events.ActionHealthStatus)+": "+health),
+		cont.id,
+		self.containerAttributes(cont),
+	)
+
+	return nil
+}
+
+// Returns the IDs of all running containers.
+func (self *FakeBackend) RunningContainers() []string {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var ids []string = nil
+	for _, cont := range self.containers {
+		if cont.running {
+			ids = append(ids, cont.id)
+		}
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// Returns the names of all networks, e.g. to assert which networks exist.
+func (self *FakeBackend) Networks() []string {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var names []string = nil
+	for _, nw := range self.networks {
+		names = append(names, nw.name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Returns the content of the file inside the container, even if the container is not running.
+func (self *FakeBackend) File(containerID string, filePath string) ([]byte, bool) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return nil, false
+	}
+	files, key := self.filesOf(cont, path.Clean("/"+strings.TrimPrefix(filePath, "/")))
+	content, ok := files[key]
+	return content, ok
+}
+
+// Returns the files which hold the path of the container and the key of the path inside them.
+// Paths below the target of a mounted volume are kept in the volume. Must be called while holding the lock.
+func (self *FakeBackend) filesOf(cont *fakeContainer, p string) (map[string][]byte, string) {
+	if cont.hostConfig == nil {
+		return cont.files, p
+	}
+
+	for _, m := range cont.hostConfig.Mounts {
+		if m.Type != mount.TypeVolume {
+			continue
+		}
+		v, ok := self.volumes[m.Source]
+		if !ok {
+			continue
+		}
+		if p == m.Target || strings.HasPrefix(p, m.Target+"/") {
+			return v.files, path.Join("/", strings.TrimPrefix(p, m.Target))
+		}
+	}
+
+	return cont.files, p
+}
+
+func (self *FakeBackend) nextID() string {
+	self.sequence++
+	return fmt.Sprintf("%064x", self.sequence)
+}
+
+func (self *FakeBackend) failure(operation string) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.failures[operation]
+}
+
+func (self *FakeBackend) emit(typ events.Type, action events.Action, id string, attributes map[string]string) {
+	msg := events.Message{
+		Type:     typ,
+		Action:   action,
+		Actor:    events.Actor{ID: id, Attributes: attributes},
+		Scope:    "local",
+		Time:     time.Now().Unix(),
+		TimeNano: time.Now().UnixNano(),
+	}
+
+	// actions with details, e.g. 'health_status: healthy', are filtered by their prefix
+	name, _, _ := strings.Cut(string(action), ":")
+	for _, sub := range self.subscribers {
+		if !sub.filters.ExactMatch("type", string(typ)) ||
+			!sub.filters.ExactMatch("event", name) ||
+			!sub.filters.MatchKVList("label", attributes) {
+			continue
+		}
+
+		select {
+		case sub.messages <- msg:
+		default:
+			// slow subscribers lose events, same as with a real daemon
+		}
+	}
+}
+
+func (self *FakeBackend) containerAttributes(cont *fakeContainer) map[string]string {
+	attributes := maps.Clone(cont.config.Labels)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	attributes["name"] = cont.name
+	attributes["image"] = cont.config.Image
+
+	return attributes
+}
+
+// Must be called while holding the lock.
+func (self *FakeBackend) stop(cont *fakeContainer) {
+	if !cont.running {
+		return
+	}
+
+	cont.running = false
+	cont.finishedAt = time.Now()
+	close(cont.stopped)
+	self.emit(events.ContainerEventType, events.ActionDie, cont.id, self.containerAttributes(cont))
+	if cont.hostConfig != nil && cont.hostConfig.AutoRemove {
+		delete(self.containers, cont.id)
+		self.emit(events.ContainerEventType, events.ActionDestroy, cont.id, self.containerAttributes(cont))
+	}
+}
+
+// Must be called while holding the lock.
+func (self *FakeBackend) lookupNetwork(nameOrID string) *fakeNetwork {
+	for _, nw := range self.networks {
+		if nw.id == nameOrID || nw.name == nameOrID {
+			return nw
+		}
+	}
+
+	return nil
+}
+
+// Connects the container to the network with the endpoint. A static address must be inside the subnet
+// of the network and must not be used by another container. Must be called while holding the lock.
+func (self *FakeBackend) connect(cont *fakeContainer, nw *fakeNetwork, endpoint *network.EndpointSettings) error {
+	if endpoint == nil {
+		endpoint = &network.EndpointSettings{}
+	}
+	if endpoint.IPAMConfig != nil {
+		static := []*string{&endpoint.IPAMConfig.IPv4Address, &endpoint.IPAMConfig.IPv6Address}
+		assigned := []*string{&endpoint.IPAddress, &endpoint.GlobalIPv6Address}
+		for idx, s := range static {
+			if *s == "" {
+				continue
+			}
+			address, err := self.staticAddress(cont, nw, *s)
+			if err != nil {
+				return err
+			}
+			*assigned[idx] = address.String()
+		}
+	}
+
+	cont.networks[nw.name] = endpoint
+	return nil
+}
+
+// Returns the static address if it is inside a subnet of the network and no other container uses it.
+// Must be called while holding the lock.
+func (self *FakeBackend) staticAddress(cont *fakeContainer, nw *fakeNetwork, s string) (netip.Addr, error) {
+	address, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%w: invalid address '%s'", ErrFakeConflict, s)
+	}
+
+	inside := false
+	for _, subnet := range nw.subnets() {
+		inside = inside || subnet.Contains(address)
+	}
+	if !inside {
+		return netip.Addr{}, fmt.Errorf("%w: address '%s' is outside of network '%s'", ErrFakeConflict, address, nw.name)
+	}
+	for _, other := range self.containers {
+		e, ok := other.networks[nw.name]
+		if ok && other != cont && (e.IPAddress == address.String() || e.GlobalIPv6Address == address.String()) {
+			return netip.Addr{}, fmt.Errorf("%w: address '%s' is already in use", ErrFakeConflict, address)
+		}
+	}
+
+	return address, nil
+}
+
+// Returns the user configured subnets of the network.
+func (self *fakeNetwork) subnets() []netip.Prefix {
+	if self.ipam == nil {
+		return nil
+	}
+
+	var result []netip.Prefix = nil
+	for _, config := range self.ipam.Config {
+		if subnet, err := netip.ParsePrefix(config.Subnet); err == nil {
+			result = append(result, subnet)
+		}
+	}
+
+	return result
+}
+
+func (self *FakeBackend) ImageExists(ctx context.Context, ref string) (bool, error) {
+	if err := self.failure("ImageExists"); err != nil {
+		return false, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.lookupImage(ref) != nil, nil
+}
+
+func (self *FakeBackend) ImagePull(ctx context.Context, ref string, options PullOptions) error {
+	if err := self.failure("ImagePull"); err != nil {
+		return err
+	}
+
+	options.progress(PullProgress{Status: "Pulling from " + ref})
+	options.progress(PullProgress{Layer: "fake", Status: "Downloading", Current: 512, Total: 1024})
+	options.progress(PullProgress{Layer: "fake", Status: "Pull complete"})
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.pullAuth[ref] = options.RegistryAuth
+	if strings.Contains(ref, "@") {
+		if self.lookupImage(ref) == nil {
+			return fmt.Errorf("%w: image '%s'", ErrFakeNotFound, ref)
+		}
+		return nil
+	}
+
+	// every pull of a tag yields a new digest, as if the tag moved in the registry, the image
+	// of the old digest stays on the machine
+	if old, ok := self.images[ref]; ok && len(old.repoDigests) > 0 {
+		self.images[old.repoDigests[0]] = old
+	}
+	self.pulls++
+	digest := fakeDigest(fmt.Sprintf("%s-%d", ref, self.pulls))
+	self.images[ref] = &fakeImage{
+		id:          fakeDigest(digest),
+		repoDigests: []string{imageRepository(ref) + "@" + digest},
+	}
+	return nil
+}
+
+func (self *FakeBackend) ImageInspect(ctx context.Context, ref string) (image.InspectResponse, error) {
+	if err := self.failure("ImageInspect"); err != nil {
+		return image.InspectResponse{}, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	img := self.lookupImage(ref)
+	if img == nil {
+		return image.InspectResponse{}, fmt.Errorf("%w: image '%s'", ErrFakeNotFound, ref)
+	}
+
+	return image.InspectResponse{ID: img.id, RepoDigests: slices.Clone(img.repoDigests)}, nil
+}
+
+// Returns the encoded registry credential the image was pulled with, false if it was never pulled.
+func (self *FakeBackend) PullAuth(ref string) (string, bool) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	auth, ok := self.pullAuth[ref]
+	return auth, ok
+}
+
+func (self *FakeBackend) ContainerCreate(
+	ctx context.Context,
+	config *container.Config,
+	hostConfig *container.HostConfig,
+	networkConfig *network.NetworkingConfig,
+) (string, error) {
+	if err := self.failure("ContainerCreate"); err != nil {
+		return "", err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.lookupImage(config.Image) == nil {
+		return "", fmt.Errorf("%w: image '%s'", ErrFakeNotFound, config.Image)
+	}
+
+	id := self.nextID()
+	cont := &fakeContainer{
+		id:         id,
+		name:       "fake-" + id[len(id)-8:],
+		config:     config,
+		hostConfig: hostConfig,
+		networks:   make(map[string]*network.EndpointSettings),
+		files:      make(map[string][]byte),
+		dirs:       map[string]struct{}{"/": {}},
+		modes:      make(map[string]fakeFileMode),
+	}
+	if networkConfig != nil {
+		for name, endpoint := range networkConfig.EndpointsConfig {
+			nw := self.lookupNetwork(name)
+			if nw == nil {
+				return "", fmt.Errorf("%w: network '%s'", ErrFakeNotFound, name)
+			}
+			if err := self.connect(cont, nw, endpoint); err != nil {
+				return "", err
+			}
+		}
+	}
+	self.containers[id] = cont
+	self.emit(events.ContainerEventType, events.ActionCreate, id, self.containerAttributes(cont))
+
+	return id, nil
+}
+
+func (self *FakeBackend) ContainerStart(ctx context.Context, containerID string) error {
+	if err := self.failure("ContainerStart"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	if mode := cont.hostConfig.NetworkMode; mode.IsContainer() {
+		if owner, ok := self.containers[mode.ConnectedContainer()]; !ok || !owner.running {
+			return fmt.Errorf("%w: cannot join the network of container '%s'", ErrFakeConflict, mode.ConnectedContainer())
+		}
+	}
+	// like the docker daemon, a port of the host is published by one running container at a time
+	for _, other := range self.containers {
+		if other.running && other.id != cont.id && publishesSameHostPort(cont, other) {
+			return fmt.Errorf("%w: port is already allocated by container '%s'", ErrFakeConflict, other.id)
+		}
+	}
+	cont.running = true
+	cont.exitCode = 0
+	cont.startedAt = time.Now()
+	cont.stopped = make(chan struct{})
+	// health checks of fake containers pass immediately, use SetHealth to change it
+	if check := cont.config.Healthcheck; check != nil && len(check.Test) != 0 && check.Test[0] != "NONE" {
+		cont.health = container.Healthy
+	}
+	self.emit(events.ContainerEventType, events.ActionStart, cont.id, self.containerAttributes(cont))
+
+	if img := self.lookupImage(cont.config.Image); img != nil && img.exit != nil {
+		for _, line := range img.exit.output {
+			cont.logs = append(cont.logs, fakeLogLine{time: time.Now(), stream: stdcopy.Stdout, line: line})
+		}
+		cont.exitCode = img.exit.code
+		self.stop(cont)
+	}
+
+	return nil
+}
+
+// Returns true if both containers publish a port on the same port of the host.
+func publishesSameHostPort(a *fakeContainer, b *fakeContainer) bool {
+	for _, bindings := range a.hostConfig.PortBindings {
+		for _, binding := range bindings {
+			for _, others := range b.hostConfig.PortBindings {
+				if slices.ContainsFunc(others, func(o nat.PortBinding) bool { return o.HostPort == binding.HostPort }) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (self *FakeBackend) ContainerWait(ctx context.Context, containerID string) (int, error) {
+	if err := self.failure("ContainerWait"); err != nil {
+		return 0, err
+	}
+
+	self.mu.Lock()
+	cont, ok := self.containers[containerID]
+	if !ok {
+		self.mu.Unlock()
+		return 0, ErrFakeNotFound
+	}
+	if !cont.running {
+		exitCode := cont.exitCode
+		self.mu.Unlock()
+		return exitCode, nil
+	}
+	stopped := cont.stopped
+	self.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-stopped:
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return cont.exitCode, nil
+}
+
+func (self *FakeBackend) ContainerStop(ctx context.Context, containerID string) error {
+	if err := self.failure("ContainerStop"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	self.stop(cont)
+
+	return nil
+}
+
+func (self *FakeBackend) ContainerRemove(ctx context.Context, containerID string) error {
+	if err := self.failure("ContainerRemove"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	self.stop(cont)
+	if _, ok := self.containers[containerID]; ok {
+		delete(self.containers, containerID)
+		self.emit(events.ContainerEventType, events.ActionDestroy, cont.id, self.containerAttributes(cont))
+	}
+
+	return nil
+}
+
+func (self *FakeBackend) ContainerExec(
+	ctx context.Context,
+	containerID string,
+	cmd []string,
+) (*ExecResult, error) {
+	if err := self.failure("ContainerExec"); err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return nil, ErrFakeNotFound
+	}
+	if !cont.running {
+		return nil, fmt.Errorf("%w: container '%s' is not running", ErrFakeConflict, containerID)
+	}
+
+	return cont.run(cmd), nil
+}
+
+func (self *FakeBackend) ContainerExecAttach(
+	ctx context.Context,
+	containerID string,
+	options container.ExecOptions,
+) (string, types.HijackedResponse, error) {
+	if err := self.failure("ContainerExecAttach"); err != nil {
+		return "", types.HijackedResponse{}, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return "", types.HijackedResponse{}, ErrFakeNotFound
+	}
+	if !cont.running {
+		return "", types.HijackedResponse{}, fmt.Errorf("%w: container '%s' is not running", ErrFakeConflict, containerID)
+	}
+
+	id := self.nextID()
+	exec := &fakeExec{running: true}
+	if options.ConsoleSize != nil {
+		exec.height, exec.width = options.ConsoleSize[0], options.ConsoleSize[1]
+	}
+	self.execs[id] = exec
+
+	stdinReader, stdinWriter := io.Pipe()
+	outputReader, outputWriter := io.Pipe()
+	output := io.Writer(outputWriter)
+	if !options.Tty {
+		output = stdcopy.NewStdWriter(outputWriter, stdcopy.Stdout)
+	}
+
+	finish := func(exitCode int) {
+		self.mu.Lock()
+		exec.running = false
+		exec.exitCode = exitCode
+		self.mu.Unlock()
+		outputWriter.Close()
+	}
+
+	if len(options.Cmd) == 1 && options.Cmd[0] == "cat" {
+		go func() {
+			io.Copy(output, stdinReader)
+			finish(0)
+		}()
+	} else {
+		result := cont.run(options.Cmd)
+		go func() {
+			output.Write(result.Output)
+			finish(result.ExitCode)
+		}()
+	}
+
+	conn := &fakeExecConn{output: outputReader, input: stdinWriter}
+	return id, types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(conn)}, nil
+}
+
+func (self *FakeBackend) ContainerExecResize(
+	ctx context.Context,
+	execID string,
+	options container.ResizeOptions,
+) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	exec, ok := self.execs[execID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+	exec.height, exec.width = options.Height, options.Width
+
+	return nil
+}
+
+func (self *FakeBackend) ContainerExecInspect(
+	ctx context.Context,
+	execID string,
+) (container.ExecInspect, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	exec, ok := self.execs[execID]
+	if !ok {
+		return container.ExecInspect{}, ErrFakeNotFound
+	}
+
+	return container.ExecInspect{ExecID: execID, Running: exec.running, ExitCode: exec.exitCode}, nil
+}
+
+// Returns the terminal size of the exec session.
+func (self *FakeBackend) ExecSize(execID string) (height uint, width uint, err error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	exec, ok := self.execs[execID]
+	if !ok {
+		return 0, 0, ErrFakeNotFound
+	}
+
+	return exec.height, exec.width, nil
+}
+
+// Connection of a fake exec session, which reads the output and writes stdin of the command.
+type fakeExecConn struct {
+	output *io.PipeReader
+	input  *io.PipeWriter
+}
+
+var _ net.Conn = (*fakeExecConn)(nil)
+
+func (self *fakeExecConn) Read(p []byte) (int, error)  { return self.output.Read(p) }
+func (self *fakeExecConn) Write(p []byte) (int, error) { return self.input.Write(p) }
+
+// Closes stdin of the command, like the hijacked connection of the docker engine.
+func (self *fakeExecConn) CloseWrite() error { return self.input.Close() }
+
+func (self *fakeExecConn) Close() error {
+	self.input.Close()
+	return self.output.Close()
+}
+
+func (self *fakeExecConn) LocalAddr() net.Addr                { return fakeAddr{} }
+func (self *fakeExecConn) RemoteAddr() net.Addr               { return fakeAddr{} }
+func (self *fakeExecConn) SetDeadline(t time.Time) error      { return nil }
+func (self *fakeExecConn) SetReadDeadline(t time.Time) error  { return nil }
+func (self *fakeExecConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type fakeAddr struct{}
+
+func (fakeAddr) Network() string { return "fake" }
+func (fakeAddr) String() string  { return "fake" }
+
+// Emulates the command on the files of the container. Must be called while holding the lock.
+func (self *fakeContainer) run(cmd []string) *ExecResult {
+	switch {
+	case len(cmd) == 2 && cmd[0] == "cat":
+		content, ok := self.files[path.Clean(cmd[1])]
+		if !ok {
+			return &ExecResult{ExitCode: 1, Output: []byte("No such file or directory")}
+		}
+		return &ExecResult{ExitCode: 0, Output: slices.Clone(content)}
+
+	case len(cmd) == 3 && cmd[0] == "test" && cmd[1] == "-e":
+		p := path.Clean(cmd[2])
+		_, isFile := self.files[p]
+		_, isDir := self.dirs[p]
+		if isFile || isDir {
+			return &ExecResult{ExitCode: 0}
+		}
+		return &ExecResult{ExitCode: 1}
+
+	case len(cmd) == 3 && cmd[0] == "mkdir" && cmd[1] == "-p":
+		self.mkdirAll(path.Clean(cmd[2]))
+		return &ExecResult{ExitCode: 0}
+
+	default:
+		return &ExecResult{ExitCode: 127, Output: []byte("command not found")}
+	}
+}
+
+// Interval in which followed logs are checked for new lines
+const fakeLogPollInterval = time.Millisecond * 10
+
+func (self *FakeBackend) ContainerLogs(
+	ctx context.Context,
+	containerID string,
+	options container.LogsOptions,
+) (io.ReadCloser, error) {
+	if err := self.failure("ContainerLogs"); err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if options.Since != "" {
+		if d, err := time.ParseDuration(options.Since); err == nil {
+			since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339Nano, options.Since); err == nil {
+			since = t
+		} else {
+			return nil, fmt.Errorf("%w: invalid since '%s'", ErrFakeConflict, options.Since)
+		}
+	}
+
+	self.mu.Lock()
+	cont, ok := self.containers[containerID]
+	if !ok {
+		self.mu.Unlock()
+		return nil, ErrFakeNotFound
+	}
+	next := 0
+	if tail, err := strconv.Atoi(options.Tail); err == nil {
+		next = max(0, len(cont.logs)-tail)
+	}
+	self.mu.Unlock()
+
+	// returns the lines which were written since the last call and if the container still exists
+	pending := func() ([]fakeLogLine, bool) {
+		self.mu.Lock()
+		defer self.mu.Unlock()
+		lines := slices.Clone(cont.logs[next:])
+		next = len(cont.logs)
+		_, exists := self.containers[containerID]
+		return lines, exists
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		for {
+			lines, exists := pending()
+			for _, l := range lines {
+				if l.time.Before(since) {
+					continue
+				}
+				if (l.stream == stdcopy.Stdout && !options.ShowStdout) ||
+					(l.stream == stdcopy.Stderr && !options.ShowStderr) {
+					continue
+				}
+
+				line := l.line + "\n"
+				if options.Timestamps {
+					line = l.time.UTC().Format(time.RFC3339Nano) + " " + line
+				}
+				if _, err := stdcopy.NewStdWriter(writer, l.stream).Write([]byte(line)); err != nil {
+					return
+				}
+			}
+
+			if !options.Follow || !exists {
+				writer.Close()
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				writer.CloseWithError(ctx.Err())
+				return
+			case <-time.After(fakeLogPollInterval):
+			}
+		}
+	}()
+
+	return reader, nil
+}
+
+func (self *fakeContainer) state() string {
+	if self.running {
+		return "running"
+	}
+	return "exited"
+}
+
+// Formats the time like the docker engine, which reports unset times as the zero time.
+func formatFakeTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func (self *fakeContainer) mkdirAll(dir string) {
+	for dir != "/" && dir != "." {
+		self.dirs[dir] = struct{}{}
+		dir = path.Dir(dir)
+	}
+}
+
+func (self *FakeBackend) CopyToContainer(
+	ctx context.Context,
+	containerID string,
+	dstPath string,
+	content io.Reader,
+) error {
+	if err := self.failure("CopyToContainer"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return ErrFakeNotFound
+	}
+
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Join("/", dstPath, hdr.Name)
+		cont.modes[name] = fakeFileMode{mode: hdr.Mode, uid: hdr.Uid, gid: hdr.Gid}
+		if hdr.Typeflag == tar.TypeDir {
+			cont.mkdirAll(name)
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		cont.mkdirAll(path.Dir(name))
+		files, key := self.filesOf(cont, name)
+		files[key] = data
+	}
+}
+
+func (self *FakeBackend) CopyFromContainer(
+	ctx context.Context,
+	containerID string,
+	srcPath string,
+) (io.ReadCloser, error) {
+	if err := self.failure("CopyFromContainer"); err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return nil, ErrFakeNotFound
+	}
+
+	src := path.Clean("/" + srcPath)
+	files, key := self.filesOf(cont, src)
+	base := path.Base(src)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	write := func(name string, content []byte) error {
+		mode := cont.fileMode(path.Join(path.Dir(src), name), 0644)
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    mode.mode,
+			Uid:     mode.uid,
+			Gid:     mode.gid,
+			Size:    int64(len(content)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+
+	if content, ok := files[key]; ok {
+		if err := write(base, content); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(&buf), tw.Close()
+	}
+
+	prefix := strings.TrimSuffix(key, "/") + "/"
+	var names []string = nil
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	_, isDir := cont.dirs[src]
+	if len(names) == 0 && !isDir && key == src {
+		return nil, fmt.Errorf("%w: path '%s'", ErrFakeNotFound, src)
+	}
+
+	err := tw.WriteHeader(&tar.Header{Name: base + "/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := write(base+"/"+strings.TrimPrefix(name, prefix), files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return io.NopCloser(&buf), tw.Close()
+}
+
+func (self *FakeBackend) ContainerStatPath(
+	ctx context.Context,
+	containerID string,
+	p string,
+) (*container.PathStat, error) {
+	if err := self.failure("ContainerStatPath"); err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return nil, ErrFakeNotFound
+	}
+
+	name := path.Clean("/" + p)
+	files, key := self.filesOf(cont, name)
+	if content, ok := files[key]; ok {
+		mode := cont.fileMode(name, 0644)
+		return &container.PathStat{Name: path.Base(name), Size: int64(len(content)), Mode: os.FileMode(mode.mode)}, nil
+	}
+
+	_, isDir := cont.dirs[name]
+	// the target of a mounted volume is always a directory
+	isDir = isDir || key != name
+	prefix := strings.TrimSuffix(key, "/") + "/"
+	for file := range files {
+		isDir = isDir || strings.HasPrefix(file, prefix)
+	}
+	if !isDir {
+		return nil, nil
+	}
+
+	mode := cont.fileMode(name, 0755)
+	return &container.PathStat{Name: path.Base(name), Mode: os.ModeDir | os.FileMode(mode.mode)}, nil
+}
+
+// Returns the mode and owner of the path, files which were never copied into the container
+// get the default mode and are owned by root.
+func (self *fakeContainer) fileMode(p string, defaultMode int64) fakeFileMode {
+	if mode, ok := self.modes[p]; ok {
+		return mode
+	}
+
+	return fakeFileMode{mode: defaultMode}
+}
+
+func (self *FakeBackend) ContainerInspect(
+	ctx context.Context,
+	containerID string,
+) (container.InspectResponse, error) {
+	if err := self.failure("ContainerInspect"); err != nil {
+		return container.InspectResponse{}, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return container.InspectResponse{}, ErrFakeNotFound
+	}
+
+	status := cont.state()
+	var health *container.Health = nil
+	if cont.health != "" {
+		health = &container.Health{Status: cont.health}
+	}
+
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:    cont.id,
+			Name:  "/" + cont.name,
+			Image: cont.config.Image,
+			State: &container.State{
+				Status:     status,
+				Running:    cont.running,
+				Health:     health,
+				ExitCode:   cont.exitCode,
+				StartedAt:  formatFakeTime(cont.startedAt),
+				FinishedAt: formatFakeTime(cont.finishedAt),
+			},
+			HostConfig: cont.hostConfig,
+		},
+		Config: cont.config,
+		NetworkSettings: &container.NetworkSettings{
+			Networks: maps.Clone(cont.networks),
+		},
+	}, nil
+}
+
+func (self *FakeBackend) ContainerList(
+	ctx context.Context,
+	options container.ListOptions,
+) ([]container.Summary, error) {
+	if err := self.failure("ContainerList"); err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var result []container.Summary = nil
+	for _, id := range slices.Sorted(maps.Keys(self.containers)) {
+		cont := self.containers[id]
+		if !options.All && !cont.running {
+			continue
+		}
+		if !options.Filters.MatchKVList("label", cont.config.Labels) {
+			continue
+		}
+
+		summary := container.Summary{
+			ID:     cont.id,
+			Names:  []string{"/" + cont.name},
+			Image:  cont.config.Image,
+			Labels: maps.Clone(cont.config.Labels),
+			State:  cont.state(),
+		}
+		if img := self.lookupImage(cont.config.Image); img != nil {
+			summary.ImageID = img.id
+		}
+		result = append(result, summary)
+	}
+
+	return result, nil
+}
+
+func (self *FakeBackend) ContainerStats(ctx context.Context, containerID string) (container.StatsResponse, error) {
+	if err := self.failure("ContainerStats"); err != nil {
+		return container.StatsResponse{}, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	cont, ok := self.containers[containerID]
+	if !ok {
+		return container.StatsResponse{}, ErrFakeNotFound
+	}
+	if !cont.running {
+		return container.StatsResponse{}, fmt.Errorf("%w: container is not running", ErrFakeConflict)
+	}
+
+	stats := cont.stats
+	stats.ID = cont.id
+	stats.Name = "/" + cont.name
+	return stats, nil
+}
+
+func (self *FakeBackend) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	if err := self.failure("DiskUsage"); err != nil {
+		return types.DiskUsage{}, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	usage := types.DiskUsage{}
+	seen := make(map[string]bool)
+	for _, ref := range slices.Sorted(maps.Keys(self.images)) {
+		img := self.images[ref]
+		if seen[img.id] {
+			continue
+		}
+		seen[img.id] = true
+		usage.Images = append(usage.Images, &image.Summary{ID: img.id, RepoTags: []string{ref}, Size: img.size})
+		usage.LayersSize += img.size
+	}
+	for _, name := range slices.Sorted(maps.Keys(self.volumes)) {
+		v := self.volumes[name]
+		var size int64 = 0
+		for _, content := range v.files {
+			size += int64(len(content))
+		}
+		usage.Volumes = append(usage.Volumes, &volume.Volume{
+			Name:      v.name,
+			Labels:    maps.Clone(v.labels),
+			UsageData: &volume.UsageData{RefCount: -1, Size: size},
+		})
+	}
+
+	return usage, nil
+}
+
+func (self *FakeBackend) NetworkCreate(
+	ctx context.Context,
+	name string,
+	labels map[string]string,
+	ipam *network.IPAM,
+) (string, error) {
+	if err := self.failure("NetworkCreate"); err != nil {
+		return "", err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.lookupNetwork(name) != nil {
+		return "", fmt.Errorf("%w: network '%s' already exists", ErrFakeConflict, name)
+	}
+
+	created := &fakeNetwork{name: name, labels: maps.Clone(labels), ipam: ipam}
+	for _, subnet := range created.subnets() {
+		for _, nw := range self.networks {
+			for _, other := range nw.subnets() {
+				if other.Overlaps(subnet) {
+					return "", fmt.Errorf("%w: subnet '%s' overlaps with network '%s'", ErrFakeConflict, subnet, nw.name)
+				}
+			}
+		}
+	}
+
+	id := self.nextID()
+	created.id = id
+	self.networks[id] = created
+	self.emit(events.NetworkEventType, events.ActionCreate, id, map[string]string{"name": name, "type": "bridge"})
+
+	return id, nil
+}
+
+func (self *FakeBackend) NetworkRemove(ctx context.Context, networkID string) error {
+	if err := self.failure("NetworkRemove"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	nw := self.lookupNetwork(networkID)
+	if nw == nil {
+		return ErrFakeNotFound
+	}
+	for _, cont := range self.containers {
+		if _, ok := cont.networks[nw.name]; ok && cont.running {
+			return fmt.Errorf("%w: network '%s' has active endpoints", ErrFakeConflict, nw.name)
+		}
+	}
+
+	delete(self.networks, nw.id)
+	self.emit(events.NetworkEventType, events.ActionDestroy, nw.id, map[string]string{"name": nw.name, "type": "bridge"})
+
+	return nil
+}
+
+func (self *FakeBackend) NetworkConnect(
+	ctx context.Context,
+	networkID string,
+	containerID string,
+	endpoint *network.EndpointSettings,
+) error {
+	if err := self.failure("NetworkConnect"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	nw := self.lookupNetwork(networkID)
+	cont, ok := self.containers[containerID]
+	if nw == nil || !ok {
+		return ErrFakeNotFound
+	}
+	if _, ok := cont.networks[nw.name]; ok {
+		return fmt.Errorf("%w: container is already connected to network '%s'", ErrFakeConflict, nw.name)
+	}
+
+	if err := self.connect(cont, nw, endpoint); err != nil {
+		return err
+	}
+	self.emit(events.NetworkEventType, events.ActionConnect, nw.id, map[string]string{
+		"container": cont.id,
+		"name":      nw.name,
+		"type":      "bridge",
+	})
+
+	return nil
+}
+
+func (self *FakeBackend) NetworkDisconnect(ctx context.Context, networkID string, containerID string) error {
+	if err := self.failure("NetworkDisconnect"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	nw := self.lookupNetwork(networkID)
+	cont, ok := self.containers[containerID]
+	if nw == nil || !ok {
+		return ErrFakeNotFound
+	}
+	if _, ok := cont.networks[nw.name]; !ok {
+		return fmt.Errorf("%w: container is not connected to network '%s'", ErrFakeConflict, nw.name)
+	}
+
+	delete(cont.networks, nw.name)
+	self.emit(events.NetworkEventType, events.ActionDisconnect, nw.id, map[string]string{
+		"container": cont.id,
+		"name":      nw.name,
+		"type":      "bridge",
+	})
+
+	return nil
+}
+
+func (self *FakeBackend) VolumeCreate(
+	ctx context.Context,
+	name string,
+	labels map[string]string,
+) error {
+	if err := self.failure("VolumeCreate"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if _, ok := self.volumes[name]; ok {
+		return nil
+	}
+	self.volumes[name] = &fakeVolume{
+		name:      name,
+		labels:    maps.Clone(labels),
+		createdAt: time.Now(),
+		files:     make(map[string][]byte),
+	}
+
+	return nil
+}
+
+func (self *FakeBackend) VolumeRemove(ctx context.Context, name string) error {
+	if err := self.failure("VolumeRemove"); err != nil {
+		return err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if _, ok := self.volumes[name]; !ok {
+		return ErrFakeNotFound
+	}
+	for _, cont := range self.containers {
+		if cont.hostConfig == nil {
+			continue
+		}
+		for _, m := range cont.hostConfig.Mounts {
+			if m.Type == mount.TypeVolume && m.Source == name {
+				return fmt.Errorf("%w: volume '%s' is in use by container '%s'", ErrFakeConflict, name, cont.id)
+			}
+		}
+	}
+	delete(self.volumes, name)
+
+	return nil
+}
+
+func (self *FakeBackend) VolumeList(
+	ctx context.Context,
+	options volume.ListOptions,
+) ([]*volume.Volume, error) {
+	if err := self.failure("VolumeList"); err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var result []*volume.Volume = nil
+	for _, name := range slices.Sorted(maps.Keys(self.volumes)) {
+		v := self.volumes[name]
+		if !options.Filters.MatchKVList("label", v.labels) {
+			continue
+		}
+
+		result = append(result, &volume.Volume{
+			Name:      v.name,
+			Driver:    "local",
+			Labels:    maps.Clone(v.labels),
+			CreatedAt: v.createdAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return result, nil
+}
+
+func (self *FakeBackend) NetworkList(
+	ctx context.Context,
+	options network.ListOptions,
+) ([]network.Summary, error) {
+	if err := self.failure("NetworkList"); err != nil {
+		return nil, err
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var result []network.Summary = nil
+	for _, id := range slices.Sorted(maps.Keys(self.networks)) {
+		nw := self.networks[id]
+		if !options.Filters.MatchKVList("label", nw.labels) {
+			continue
+		}
+		if !options.Filters.ExactMatch("name", nw.name) {
+			continue
+		}
+
+		summary := network.Summary{
+			ID:     nw.id,
+			Name:   nw.name,
+			Driver: "bridge",
+			Labels: maps.Clone(nw.labels),
+		}
+		if nw.ipam != nil {
+			summary.IPAM = *nw.ipam
+		}
+		for _, subnet := range nw.subnets() {
+			summary.EnableIPv6 = summary.EnableIPv6 || subnet.Addr().Is6()
+		}
+		result = append(result, summary)
+	}
+
+	return result, nil
+}
+
+func (self *FakeBackend) Events(
+	ctx context.Context,
+	options events.ListOptions,
+) (<-chan events.Message, <-chan error) {
+	errs := make(chan error, 1)
+	if err := self.failure("Events"); err != nil {
+		errs <- err
+		return make(chan events.Message), errs
+	}
+
+	sub := &fakeSubscriber{
+		filters:  options.Filters.Clone(),
+		messages: make(chan events.Message, 128),
+	}
+
+	self.mu.Lock()
+	self.subscribers = append(self.subscribers, sub)
+	self.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		self.mu.Lock()
+		defer self.mu.Unlock()
+		self.subscribers = slices.DeleteFunc(self.subscribers, func(s *fakeSubscriber) bool {
+			return s == sub
+		})
+		errs <- ctx.Err()
+	}()
+
+	return sub.messages, errs
+}
@@ -0,0 +1,264 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	podmanRootfulSocket = "/run/podman/podman.sock"
+	// Path of the socket relative to the runtime directory of the user
+	podmanRootlessSocket = "podman/podman.sock"
+	// Path which zeus uses on the host to share sockets with its containers
+	zeusHostRoot = "/run/zeus"
+)
+
+type PodmanOptions struct {
+	// Path of the podman API socket. Defaults to the rootful or rootless socket of podman.
+	Socket string
+	// Podman runs as an unprivileged user. Privileged ports and /run/zeus are not available,
+	// unless they are mapped explicitly, 80 and 443 are mapped to 8080 and 8443 and /run/zeus
+	// is mapped into the runtime directory of the user.
+	Rootless bool
+	// Host ports which are bound instead of the requested ones, e.g. 80 -> 8080
+	PortMapping map[string]string
+	// Host paths which replace the prefix of bind mount sources, e.g. /run/zeus -> /run/user/1000/zeus
+	PathMapping map[string]string
+}
+
+// Backend which talks to the docker compatible API of podman.
+//
+// The compatible API differs from docker in a few places which are handled here: not all versions
+// apply label filters without a value, network removals are reported as 'remove' instead of 'destroy',
+// and rootless setups cannot bind privileged ports or mount paths below /run.
+type podmanBackend struct {
+	*dockerBackend
+	ports map[string]string
+	paths map[string]string
+}
+
+var _ Backend = (*podmanBackend)(nil)
+
+func NewPodmanBackend(opts PodmanOptions) (Backend, error) {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+
+	socket := opts.Socket
+	if socket == "" {
+		socket = podmanRootfulSocket
+		if opts.Rootless {
+			socket = filepath.Join(runtimeDir, podmanRootlessSocket)
+		}
+	}
+
+	ports := opts.PortMapping
+	paths := opts.PathMapping
+	if opts.Rootless {
+		if ports == nil {
+			ports = map[string]string{"80": "8080", "443": "8443"}
+		}
+		if paths == nil {
+			paths = map[string]string{zeusHostRoot: filepath.Join(runtimeDir, "zeus")}
+		}
+	}
+
+	docker, err := NewDockerBackend(client.WithHost("unix://" + socket))
+	if err != nil {
+		return nil, err
+	}
+
+	return &podmanBackend{
+		dockerBackend: docker.(*dockerBackend),
+		ports:         ports,
+		paths:         paths,
+	}, nil
+}
+
+func (self *podmanBackend) ContainerCreate(
+	ctx context.Context,
+	config *container.Config,
+	hostConfig *container.HostConfig,
+	networkConfig *network.NetworkingConfig,
+) (string, error) {
+	return self.dockerBackend.ContainerCreate(
+		ctx, config, translateHostConfig(hostConfig, self.ports, self.paths), networkConfig,
+	)
+}
+
+func (self *podmanBackend) hostPath(path string) string {
+	return translatePath(path, self.paths)
+}
+
+// Podman runs aardvark-dns on the gateway of every network, it has no embedded DNS in the containers.
+func (self *podmanBackend) resolvesAtGateway() bool {
+	return true
+}
+
+func (self *podmanBackend) ContainerList(
+	ctx context.Context,
+	options container.ListOptions,
+) ([]container.Summary, error) {
+	summary, err := self.dockerBackend.ContainerList(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(summary, func(s container.Summary) bool {
+		return !options.Filters.MatchKVList("label", s.Labels)
+	}), nil
+}
+
+func (self *podmanBackend) NetworkList(
+	ctx context.Context,
+	options network.ListOptions,
+) ([]network.Summary, error) {
+	summary, err := self.dockerBackend.NetworkList(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(summary, func(s network.Summary) bool {
+		return !options.Filters.MatchKVList("label", s.Labels)
+	}), nil
+}
+
+func (self *podmanBackend) VolumeList(
+	ctx context.Context,
+	options volume.ListOptions,
+) ([]*volume.Volume, error) {
+	volumes, err := self.dockerBackend.VolumeList(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(volumes, func(v *volume.Volume) bool {
+		return !options.Filters.MatchKVList("label", v.Labels)
+	}), nil
+}
+
+func (self *podmanBackend) Events(
+	ctx context.Context,
+	options events.ListOptions,
+) (<-chan events.Message, <-chan error) {
+	// the actions are filtered after they are normalized to the names docker uses
+	actions := options.Filters.Clone()
+	serverFilters := options.Filters.Clone()
+	for _, action := range serverFilters.Get("event") {
+		serverFilters.Del("event", action)
+	}
+
+	messages, errs := self.dockerBackend.Events(ctx, events.ListOptions{
+		Since:   options.Since,
+		Until:   options.Until,
+		Filters: serverFilters,
+	})
+
+	out := make(chan events.Message)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				msg = normalizePodmanEvent(msg)
+				if !actions.ExactMatch("event", string(msg.Action)) {
+					continue
+				}
+
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+func normalizePodmanEvent(msg events.Message) events.Message {
+	if msg.Type == events.NetworkEventType && msg.Action == events.ActionRemove {
+		msg.Action = events.ActionDestroy
+	}
+
+	return msg
+}
+
+// Returns a copy of the host config, where the host ports and the sources of bind mounts
+// are replaced according to the mappings.
+func translateHostConfig(
+	hostConfig *container.HostConfig,
+	ports map[string]string,
+	paths map[string]string,
+) *container.HostConfig {
+	if hostConfig == nil {
+		return nil
+	}
+	translated := *hostConfig
+
+	if hostConfig.PortBindings != nil {
+		translated.PortBindings = make(nat.PortMap, len(hostConfig.PortBindings))
+		for port, bindings := range hostConfig.PortBindings {
+			mapped := slices.Clone(bindings)
+			for idx := range mapped {
+				if hostPort, ok := ports[mapped[idx].HostPort]; ok {
+					mapped[idx].HostPort = hostPort
+				}
+			}
+			translated.PortBindings[port] = mapped
+		}
+	}
+
+	translated.Mounts = slices.Clone(hostConfig.Mounts)
+	for idx := range translated.Mounts {
+		m := &translated.Mounts[idx]
+		if m.Type != mount.TypeBind {
+			continue
+		}
+		m.Source = translatePath(m.Source, paths)
+	}
+
+	return &translated
+}
+
+// Replaces the longest matching prefix of the path.
+func translatePath(path string, paths map[string]string) string {
+	longest, target := "", ""
+	for prefix, replacement := range paths {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		if len(prefix) > len(longest) {
+			longest, target = prefix, replacement
+		}
+	}
+	if longest == "" {
+		return path
+	}
+
+	return strings.TrimSuffix(target, "/") + strings.TrimPrefix(path, longest)
+}
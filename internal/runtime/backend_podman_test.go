@@ -0,0 +1,90 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestPodmanTranslateHostConfig(t *testing.T) {
+	hostConfig := &container.HostConfig{
+		AutoRemove: true,
+		PortBindings: nat.PortMap{
+			"80/tcp":   {{HostPort: "80"}},
+			"443/tcp":  {{HostPort: "443"}},
+			"9000/tcp": {{HostPort: "9000"}},
+		},
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: "/run/zeus/", Target: "/run/zeus/"},
+			{Type: mount.TypeBind, Source: "/run/zeus/ingress", Target: "/run/zeus"},
+			{Type: mount.TypeBind, Source: "/run/zeusd", Target: "/run/zeusd"},
+			{Type: mount.TypeVolume, Source: "/run/zeus", Target: "/data"},
+		},
+	}
+
+	translated := translateHostConfig(
+		hostConfig,
+		map[string]string{"80": "8080", "443": "8443"},
+		map[string]string{"/run/zeus": "/run/user/1000/zeus"},
+	)
+
+	if !translated.AutoRemove {
+		t.Errorf("expected untouched fields to be kept")
+	}
+	for port, expected := range map[nat.Port]string{"80/tcp": "8080", "443/tcp": "8443", "9000/tcp": "9000"} {
+		if got := translated.PortBindings[port][0].HostPort; got != expected {
+			t.Errorf("expected host port of %s to be '%s', got '%s'", port, expected, got)
+		}
+	}
+	for idx, expected := range []string{"/run/user/1000/zeus/", "/run/user/1000/zeus/ingress", "/run/zeusd", "/run/zeus"} {
+		if got := translated.Mounts[idx].Source; got != expected {
+			t.Errorf("expected mount source '%s', got '%s'", expected, got)
+		}
+	}
+
+	if hostConfig.PortBindings["80/tcp"][0].HostPort != "80" || hostConfig.Mounts[0].Source != "/run/zeus/" {
+		t.Errorf("expected original host config to stay unchanged")
+	}
+}
+
+func TestPodmanNormalizeEvent(t *testing.T) {
+	msg := normalizePodmanEvent(events.Message{Type: events.NetworkEventType, Action: events.ActionRemove})
+	if msg.Action != events.ActionDestroy {
+		t.Errorf("expected network removal to be reported as destroy, got '%s'", msg.Action)
+	}
+
+	msg = normalizePodmanEvent(events.Message{Type: events.ContainerEventType, Action: events.ActionRemove})
+	if msg.Action != events.ActionRemove {
+		t.Errorf("expected container events to stay unchanged, got '%s'", msg.Action)
+	}
+}
+
+func TestRootlessPodmanHostPath(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	podman, err := NewPodmanBackend(PodmanOptions{Rootless: true})
+	if err != nil {
+		t.Fatalf("expected podman backend to be created, got %q", err)
+	}
+	previous := SetBackend(podman)
+	t.Cleanup(func() { SetBackend(previous) })
+
+	if got, expected := dnsSocketPath(), filepath.Join(runtimeDir, "zeus", "dns", "dns.sock"); got != expected {
+		t.Errorf("expected DNS socket '%s', got '%s'", expected, got)
+	}
+	if got := HostPath("/var/lib/zeus"); got != "/var/lib/zeus" {
+		t.Errorf("expected unmapped path to be kept, got '%s'", got)
+	}
+
+	SetBackend(NewFakeBackend())
+	if got := HostPath("/run/zeus/zeusd.sock"); got != "/run/zeus/zeusd.sock" {
+		t.Errorf("expected backend without mapping to keep the path, got '%s'", got)
+	}
+}
@@ -8,21 +8,27 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
-	"github.com/docker/docker/client"
-	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 	log "github.com/raphaeldichler/zeus/internal/util/logger"
 )
 
 var (
-	ErrContainterCannotStart  = errors.New("cannot start the container")
-	ErrCommandFailedToExecute = errors.New("command existed with error code")
+	ErrContainterCannotStart = errors.New("cannot start the container")
+	ErrNotRegularFile        = errors.New("path is not a regular file")
+	ErrNotDirectory          = errors.New("path is not a directory")
 )
 
 const (
@@ -44,9 +50,10 @@ func (self *ContainerOptions) Add(options ...ContainerOption) {
 }
 
 func (self *ContainerOptions) Build(
+	ctx context.Context,
 	application string,
 ) (*Container, error) {
-	return CreateNewContainer(application, self.options...)
+	return CreateNewContainer(ctx, application, self.options...)
 }
 
 type ContainerConfig struct {
@@ -58,25 +65,44 @@ type ContainerConfig struct {
 	img string
 	// Pulls images if its does not exists on the machine
 	doPull bool
-	// Times to try to start a container before giving up. default 3
+	// Credentials of the registry of the image, empty for public images
+	registryAuth string
+	// Times to try to start a container before giving up, the attempts are delayed by a backoff. default 3
 	retryStart int
 	// Files which are copied into the container before it will be started
 	filesToCopyInto []FileContent
 	network         *Network
+	// Additional names under which the container is reachable inside the network
+	networkAliases []string
+	// Static addresses of the container inside the network, empty to let the container engine pick them
+	networkAddress   string
+	networkAddressV6 string
 
 	log *log.Logger
 }
 
 // Pulls, creates, and starts the container according to the config
-func (self *ContainerConfig) startContainer(applicaiton string) (*Container, error) {
+func (self *ContainerConfig) startContainer(ctx context.Context, applicaiton string) (*Container, error) {
 	if self.doPull {
-		if err := pull(self.img); err != nil {
+		if err := pull(ctx, self.img, PullOptions{RegistryAuth: self.registryAuth}); err != nil {
 			return nil, err
 		}
 	}
 
 	self.config.Image = self.img
+	if self.network != nil {
+		endpoint := self.networkConfig.EndpointsConfig[self.network.name]
+		endpoint.Aliases = self.networkAliases
+		if self.networkAddress != "" || self.networkAddressV6 != "" {
+			endpoint.IPAMConfig = &network.EndpointIPAMConfig{
+				IPv4Address: self.networkAddress,
+				IPv6Address: self.networkAddressV6,
+			}
+		}
+	}
+
 	containerID, err := create(
+		ctx,
 		self.config,
 		self.hostConfig,
 		self.networkConfig,
@@ -85,12 +111,12 @@ func (self *ContainerConfig) startContainer(applicaiton string) (*Container, err
 		return nil, err
 	}
 
-	container := toContainer(applicaiton, containerID, self.network)
-	if err := container.CopyInto(self.filesToCopyInto...); err != nil {
+	container := toContainer(applicaiton, containerID, self.network, self.config.Labels)
+	if err := container.CopyInto(ctx, self.filesToCopyInto...); err != nil {
 		return nil, err
 	}
 
-	if err := start(containerID, self.retryStart); err != nil {
+	if err := start(ctx, containerID, self.retryStart); err != nil {
 		return nil, err
 	}
 
@@ -101,15 +127,18 @@ func toContainer(
 	application string,
 	containerID string,
 	network *Network,
+	labels map[string]string,
 ) *Container {
 	name := application + "-" + containerID[:10]
 	logger := log.New(application, name)
 	return &Container{
 		id:      containerID,
-		client:  c,
+		backend: backend,
 		log:     logger,
 		network: network,
 		name:    name,
+		image:   labels[labelObjectImage],
+		labels:  labels,
 	}
 }
 
@@ -141,12 +170,35 @@ func WithPulling() ContainerOption {
 	}
 }
 
+// Authenticates the pull of the image at its registry, see RegistryAuth.
+func WithRegistryAuth(auth string) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.registryAuth = auth
+	}
+}
+
 func WithCmd(cmd ...string) ContainerOption {
 	return func(cfg *ContainerConfig) {
 		cfg.config.Cmd = cmd
 	}
 }
 
+// Replaces the entrypoint of the image, the container runs the entrypoint instead.
+func WithEntrypoint(entrypoint ...string) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.config.Entrypoint = entrypoint
+	}
+}
+
+// Sets the environment variable key=value inside the container.
+func WithEnv(key string, value string) ContainerOption {
+	assert.NotEmptyString(key, "environment key must not be empty")
+
+	return func(cfg *ContainerConfig) {
+		cfg.config.Env = append(cfg.config.Env, key+"="+value)
+	}
+}
+
 func WithMount(hostMount string, containerMount string) ContainerOption {
 	return func(cfg *ContainerConfig) {
 		cfg.hostConfig.Mounts = append(
@@ -160,6 +212,85 @@ func WithMount(hostMount string, containerMount string) ContainerOption {
 	}
 }
 
+// Limits the memory of the container in bytes, the container is killed if it exceeds the limit.
+func WithMemoryLimit(bytes int64) ContainerOption {
+	assert.True(bytes > 0, "memory limit must be positive")
+
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.Memory = bytes
+		// disallows swapping, otherwise the container could use twice the memory
+		cfg.hostConfig.MemorySwap = bytes
+	}
+}
+
+// Limits the container to the CPU quota, in units of 1e-9 CPUs.
+func WithCPULimit(nanoCPUs int64) ContainerOption {
+	assert.True(nanoCPUs > 0, "cpu limit must be positive")
+
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.NanoCPUs = nanoCPUs
+	}
+}
+
+// Limits the number of processes and threads inside the container.
+func WithPidsLimit(pids int64) ContainerOption {
+	assert.True(pids > 0, "pids limit must be positive")
+
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.PidsLimit = &pids
+	}
+}
+
+func WithReadOnlyRootFilesystem() ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.ReadonlyRootfs = true
+	}
+}
+
+// Drops the linux capabilities, e.g. NET_RAW or ALL, from the container.
+func WithDroppedCapabilities(capabilities ...string) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.CapDrop = append(cfg.hostConfig.CapDrop, capabilities...)
+	}
+}
+
+// Prevents processes inside the container from gaining privileges, e.g. via setuid binaries.
+func WithNoNewPrivileges() ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.SecurityOpt = append(cfg.hostConfig.SecurityOpt, "no-new-privileges:true")
+	}
+}
+
+// Runs the processes of the container as the user and group, which are either names or ids.
+// The group is optional.
+func WithUser(user string, group string) ContainerOption {
+	assert.NotEmptyString(user, "user must not be empty")
+
+	return func(cfg *ContainerConfig) {
+		cfg.config.User = user
+		if group != "" {
+			cfg.config.User = user + ":" + group
+		}
+	}
+}
+
+// Mounts an in-memory filesystem at the path. A size of zero uses the default of the container engine.
+func WithTmpfs(path string, size int64) ContainerOption {
+	assert.StartsWithString(path, "/", "tmpfs path must be absolute")
+	assert.True(size >= 0, "tmpfs size must not be negative")
+
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.Mounts = append(
+			cfg.hostConfig.Mounts,
+			mount.Mount{
+				Type:         mount.TypeTmpfs,
+				Target:       path,
+				TmpfsOptions: &mount.TmpfsOptions{SizeBytes: size},
+			},
+		)
+	}
+}
+
 func WithExposeTcpPort(hostPort string, containerPort string) ContainerOption {
 	assert.StartsNotWithString(hostPort, "tcp/", "we will append tcp/ if needed")
 	assert.StartsNotWithString(containerPort, "tcp/", "we will append tcp/ if needed")
@@ -182,6 +313,19 @@ func WithExposeTcpPort(hostPort string, containerPort string) ContainerOption {
 	}
 }
 
+// Exposes the port only inside the network the container is connected to, no port on the host is bound.
+func WithInternalTcpPort(containerPort string) ContainerOption {
+	assert.StartsNotWithString(containerPort, "tcp/", "we will append tcp/ if needed")
+
+	return func(cfg *ContainerConfig) {
+		if cfg.config.ExposedPorts == nil {
+			cfg.config.ExposedPorts = make(nat.PortSet)
+		}
+
+		cfg.config.ExposedPorts[nat.Port(containerPort+"/tcp")] = struct{}{}
+	}
+}
+
 func WithConnectedToNetwork(nt *Network) ContainerOption {
 	return func(cfg *ContainerConfig) {
 		cfg.network = nt
@@ -191,6 +335,38 @@ func WithConnectedToNetwork(nt *Network) ContainerOption {
 	}
 }
 
+// Registers additional names under which the container can be resolved inside the network.
+// Only has an effect if the container is connected to a network.
+func WithNetworkAliases(aliases ...string) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.networkAliases = append(cfg.networkAliases, aliases...)
+	}
+}
+
+// Assigns the static IPv4 and IPv6 addresses to the container inside the network, which must be part
+// of the subnets of the network. Only has an effect if the container is connected to a network.
+func WithNetworkAddresses(addresses ...string) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		for _, address := range addresses {
+			addr, err := netip.ParseAddr(address)
+			assert.ErrNil(err)
+			if addr.Is4() {
+				cfg.networkAddress = address
+			} else {
+				cfg.networkAddressV6 = address
+			}
+		}
+	}
+}
+
+// Lets the container share the network of the other container instead of connecting it to a network.
+// Both containers reach each other on localhost and the container is reachable by the addresses of the other.
+func WithNetworkOf(other *Container) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.NetworkMode = container.NetworkMode("container:" + other.id)
+	}
+}
+
 func WithLabels(labels ...Label) ContainerOption {
 	return func(cfg *ContainerConfig) {
 		if cfg.config.Labels == nil {
@@ -204,6 +380,17 @@ func WithLabels(labels ...Label) ContainerOption {
 	}
 }
 
+// Keeps the container after it exited, which preserves its exit code and output. Zeus restarts the
+// container according to the policy, the container engine does not.
+//
+// The container gets labeled with zeus.restart.policy={policy}.
+func WithRestartPolicy(policy string) ContainerOption {
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.AutoRemove = false
+		WithLabels(Label{key: labelRestartPolicy, value: policy})(cfg)
+	}
+}
+
 func WithCopyIntoBeforeStart(file FileContent) ContainerOption {
 	return func(cfg *ContainerConfig) {
 		cfg.filesToCopyInto = append(cfg.filesToCopyInto, file)
@@ -212,26 +399,28 @@ func WithCopyIntoBeforeStart(file FileContent) ContainerOption {
 
 type Container struct {
 	id      string
-	client  *client.Client
+	backend Backend
 	network *Network
 	name    string
 	image   string
+	labels  map[string]string
 
 	log *log.Logger
 }
 
 func CreateNewContainer(
+	ctx context.Context,
 	application string,
 	options ...ContainerOption,
 ) (*Container, error) {
-	assert.NotNil(c, "init of docker-client failed")
+	assert.True(backend != nil, "init of runtime backend failed")
 	cfg := defaultContainerConfig()
 
 	for _, opt := range options {
 		opt(cfg)
 	}
 
-	return cfg.startContainer(application)
+	return cfg.startContainer(ctx, application)
 }
 
 func (self *Container) String() string {
@@ -243,21 +432,80 @@ func (self *Container) Image() string {
 	return self.image
 }
 
-func (self *Container) Shutdown() error {
-	ctx := context.Background()
+// Returns the value of the label or an empty string if the container has no such label
+func (self *Container) label(key string) string {
+	return self.labels[key]
+}
+
+func (self *Container) Shutdown(ctx context.Context) error {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	intended.add(self.id)
 	if self.network != nil {
-		if err := self.client.NetworkDisconnect(ctx, self.network.name, self.id, false); err != nil {
+		if err := self.backend.NetworkDisconnect(ctx, self.network.name, self.id); err != nil {
 			return err
 		}
 	}
 
-	return self.client.ContainerStop(
-		ctx,
-		self.id,
-		container.StopOptions{
-			Timeout: nil,
-		},
-	)
+	if err := self.backend.ContainerStop(ctx, self.id); err != nil {
+		return err
+	}
+
+	// containers with a restart policy are kept after they exit and must be removed explicitly
+	if _, kept := self.labels[labelRestartPolicy]; kept {
+		return self.backend.ContainerRemove(ctx, self.id)
+	}
+
+	return nil
+}
+
+// Waits until the container exited and returns its exit code.
+func (self *Container) Wait(ctx context.Context) (int, error) {
+	return self.backend.ContainerWait(ctx, self.id)
+}
+
+// Waits until the container exited and returns its exit code. A container which runs longer than the
+// timeout is stopped and reported as timed out, its exit code is never zero. A timeout of zero waits
+// without limit.
+func (self *Container) WaitOrStop(ctx context.Context, timeout time.Duration) (int, bool, error) {
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	exitCode, err := self.Wait(waitCtx)
+	if err == nil || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+		return exitCode, false, err
+	}
+
+	if err := self.Stop(ctx); err != nil {
+		return 0, true, err
+	}
+	if exitCode, err = self.Wait(ctx); err != nil {
+		return 0, true, err
+	}
+	if exitCode == 0 {
+		exitCode = 137
+	}
+
+	return exitCode, true, nil
+}
+
+// Stops the container without removing it, a container with a restart policy keeps its exit code and output.
+func (self *Container) Stop(ctx context.Context) error {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	intended.add(self.id)
+	return self.backend.ContainerStop(ctx, self.id)
+}
+
+// Removes the container which already exited.
+func (self *Container) Remove(ctx context.Context) error {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	intended.add(self.id)
+	return self.backend.ContainerRemove(ctx, self.id)
 }
 
 func (self *Container) Equal(other *Container) bool {
@@ -283,8 +531,8 @@ func (self *Container) Equal(other *Container) bool {
 	return false
 }
 
-func (self *Container) IsRunning() (bool, error) {
-	resp, err := self.Inspect()
+func (self *Container) IsRunning(ctx context.Context) (bool, error) {
+	resp, err := self.Inspect(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -292,85 +540,104 @@ func (self *Container) IsRunning() (bool, error) {
 	return resp.State.Running, nil
 }
 
-type CmdResult struct {
-	exitCode int
-	stdout   string
-}
-
-func (self *Container) runCommand(cmd ...string) (*CmdResult, error) {
-	ctx := context.Background()
-	resp, err := self.client.ContainerExecCreate(
-		ctx,
-		self.id,
-		container.ExecOptions{
-			Cmd:          cmd,
-			AttachStdin:  true,
-			AttachStdout: true,
-		},
-	)
-	if err != nil {
+// Returns the tar header of the file or directory in the container, nil if the path does not exist.
+func (self *Container) statPath(ctx context.Context, p string) (*tar.Header, error) {
+	stat, err := self.backend.ContainerStatPath(ctx, self.id, p)
+	if err != nil || stat == nil {
 		return nil, err
 	}
 
-	attach, err := self.client.ContainerExecAttach(
-		ctx,
-		resp.ID,
-		container.ExecAttachOptions{},
-	)
+	content, err := self.backend.CopyFromContainer(ctx, self.id, p)
 	if err != nil {
 		return nil, err
 	}
-	defer attach.Close()
+	defer content.Close()
+
+	return tar.NewReader(content).Next()
+}
+
+// Reads the file from the container through the archive API of the container engine, which works on
+// images without a shell and on containers which are not running.
+func (self *Container) ReadFile(ctx context.Context, p string) (string, error) {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	p = path.Join("/", p)
 
-	var outputBuf bytes.Buffer
-	_, err = stdcopy.StdCopy(&outputBuf, &outputBuf, attach.Reader)
+	content, err := self.backend.CopyFromContainer(ctx, self.id, p)
 	if err != nil {
-		self.log.Error("Failed to copy stdout for (%s)", self)
-		return nil, err
+		self.log.Error("Failed to read file '%s' in (%s): %v", p, self, err)
+		return "", err
 	}
+	defer content.Close()
 
-	insp, err := self.client.ContainerExecInspect(ctx, resp.ID)
+	tr := tar.NewReader(content)
+	hdr, err := tr.Next()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		return "", fmt.Errorf("%w: '%s'", ErrNotRegularFile, p)
 	}
-	self.log.Debug("run command \t\t'%s'; exitCode %d", cmd, insp.ExitCode)
-
-	// todo: add debug logging; see what command are executed
-	return &CmdResult{
-		exitCode: insp.ExitCode,
-		stdout:   outputBuf.String(),
-	}, nil
-}
 
-func (self *Container) ReadFile(path string) (string, error) {
-	result, err := self.runCommand("cat", path)
+	data, err := io.ReadAll(tr)
 	if err != nil {
 		return "", err
 	}
 
-	return result.stdout, nil
+	return string(data), nil
 }
 
-func (self *Container) ExitsPath(path string) (bool, error) {
-	result, err := self.runCommand("test", "-e", path)
+func (self *Container) ExistsPath(ctx context.Context, p string) (bool, error) {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	stat, err := self.backend.ContainerStatPath(ctx, self.id, path.Join("/", p))
 	if err != nil {
 		return false, err
 	}
 
-	return result.exitCode == 0, nil
+	return stat != nil, nil
 }
 
-func (self *Container) EnsurePathExists(path string) error {
-	result, err := self.runCommand("mkdir", "-p", path)
-	if err != nil {
-		return err
+// Creates the directory and all of its missing parents with mode 0755, existing directories keep
+// their mode and owner.
+func (self *Container) EnsurePathExists(ctx context.Context, p string) error {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+
+	var missing []string = nil
+	for dir := path.Join("/", p); dir != "/"; dir = path.Dir(dir) {
+		stat, err := self.backend.ContainerStatPath(ctx, self.id, dir)
+		if err != nil {
+			return err
+		}
+		if stat == nil {
+			missing = append(missing, dir)
+			continue
+		}
+		if !stat.Mode.IsDir() {
+			return fmt.Errorf("%w: '%s'", ErrNotDirectory, dir)
+		}
+		break
+	}
+	if len(missing) == 0 {
+		return nil
 	}
 
-	if result.exitCode != 0 {
-		return errors.New("failed to execute command")
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, dir := range slices.Backward(missing) {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     strings.TrimPrefix(dir, "/") + "/",
+			Typeflag: tar.TypeDir,
+			Mode:     0755,
+			ModTime:  time.Now(),
+		})
+		assert.ErrNil(err)
 	}
+	err := tw.Close()
+	assert.ErrNil(err)
 
-	return nil
+	return self.backend.CopyToContainer(ctx, self.id, "/", &buf)
 }
 
 type FileContent interface {
@@ -378,9 +645,21 @@ type FileContent interface {
 	FileContent() []byte
 }
 
+// FileContent which defines the mode and owner of the file in the container.
+type FileContentWithMode interface {
+	FileContent
+	FileMode() os.FileMode
+	FileOwner() (uid int, gid int)
+}
+
+// File which is copied into a container. Without a mode the file keeps the mode and owner of the
+// file it replaces, a new file gets mode 0644 and is owned by root.
 type BasicFileContent struct {
 	Path    string
 	Content []byte
+	Mode    os.FileMode
+	UID     int
+	GID     int
 }
 
 func (self *BasicFileContent) FilePath() string {
@@ -391,40 +670,69 @@ func (self *BasicFileContent) FileContent() []byte {
 	return self.Content
 }
 
-func (self *Container) CopyInto(files ...FileContent) error {
+func (self *BasicFileContent) FileMode() os.FileMode {
+	return self.Mode
+}
+
+func (self *BasicFileContent) FileOwner() (int, int) {
+	return self.UID, self.GID
+}
+
+// Returns the header of the file in the archive which is copied into the container.
+func (self *Container) fileHeader(ctx context.Context, fc FileContent) (*tar.Header, error) {
+	name := path.Join("/", fc.FilePath())
+	hdr := &tar.Header{
+		Name:    strings.TrimPrefix(name, "/"),
+		Mode:    0644,
+		Size:    int64(len(fc.FileContent())),
+		ModTime: time.Now(),
+	}
+
+	if fm, ok := fc.(FileContentWithMode); ok && fm.FileMode() != 0 {
+		hdr.Mode = int64(fm.FileMode().Perm())
+		hdr.Uid, hdr.Gid = fm.FileOwner()
+		return hdr, nil
+	}
+
+	existing, err := self.statPath(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Typeflag == tar.TypeReg {
+		hdr.Mode = existing.Mode
+		hdr.Uid, hdr.Gid = existing.Uid, existing.Gid
+	}
+
+	return hdr, nil
+}
+
+func (self *Container) CopyInto(ctx context.Context, files ...FileContent) error {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+
 	var buf bytes.Buffer
 	tw := tar.NewWriter(&buf)
 
 	for _, fc := range files {
-		content := fc.FileContent()
-		hdr := &tar.Header{
-			Name:    fc.FilePath(),
-			Mode:    0644,
-			Size:    int64(len(content)),
-			ModTime: time.Now(),
+		hdr, err := self.fileHeader(ctx, fc)
+		if err != nil {
+			return err
 		}
 
-		err := tw.WriteHeader(hdr)
+		err = tw.WriteHeader(hdr)
 		assert.ErrNil(err)
-		_, err = tw.Write(content)
+		_, err = tw.Write(fc.FileContent())
 		assert.ErrNil(err)
-
 	}
 
 	err := tw.Close()
 	assert.ErrNil(err)
 
-	tarReader := bytes.NewReader(buf.Bytes())
-	return self.client.CopyToContainer(
-		context.Background(),
-		self.id,
-		"/",
-		tarReader,
-		container.CopyToContainerOptions{},
-	)
+	return self.backend.CopyToContainer(ctx, self.id, "/", &buf)
 }
 
-func (self *Container) Inspect() (container.InspectResponse, error) {
-	ctx := context.Background()
-	return self.client.ContainerInspect(ctx, self.id)
+func (self *Container) Inspect(ctx context.Context) (container.InspectResponse, error) {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	return self.backend.ContainerInspect(ctx, self.id)
 }
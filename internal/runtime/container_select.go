@@ -14,13 +14,17 @@ import (
 )
 
 type SelectedContainer struct {
-	id string
+	id      string
+	labels  map[string]string
+	running bool
 }
 
 func (self *SelectedContainer) NewContainer(
+	ctx context.Context,
 	application string,
 ) (*Container, error) {
 	networks, err := SelectNetworks(
+		ctx,
 		ObjectTypeLabel(NetworkObject),
 		ApplicationNameLabel(application),
 	)
@@ -37,24 +41,50 @@ func (self *SelectedContainer) NewContainer(
 		assert.Unreachable("Network must exists, is created on application start")
 	}
 
-	return toContainer(application, self.id, network), nil
+	// sidecars share the network of the container of their service and are not connected themselves,
+	// like the ingress containers share the one of the container which publishes the ports of the ingress
+	if self.labels[labelServiceRole] == serviceRoleMapping[SidecarRole] ||
+		self.labels[labelObjectType] == objectLabelMapping[IngressObject] {
+		network = nil
+	}
+
+	return toContainer(application, self.id, network, self.labels), nil
 }
 
 // Selects a container by the labels if it exists. No promise about the container is made,
 // it can be in any state.
 func SelectContainer(
+	ctx context.Context,
+	labels ...Label,
+) ([]SelectedContainer, error) {
+	return selectContainer(ctx, false, labels...)
+}
+
+// Selects the containers by the labels, including containers which are not running.
+func SelectContainerInAnyState(
+	ctx context.Context,
+	labels ...Label,
+) ([]SelectedContainer, error) {
+	return selectContainer(ctx, true, labels...)
+}
+
+func selectContainer(
+	ctx context.Context,
+	all bool,
 	labels ...Label,
 ) ([]SelectedContainer, error) {
-	assert.NotNil(c, "init of docker-client failed")
+	assert.True(backend != nil, "init of runtime backend failed")
 
 	args := filters.NewArgs()
 	for _, l := range labels {
 		args.Add("label", fmt.Sprintf("%s=%s", l.key, l.value))
 	}
 
-	ctx := context.Background()
-	summary, err := c.ContainerList(
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	summary, err := backend.ContainerList(
 		ctx, container.ListOptions{
+			All:     all,
 			Filters: args,
 		},
 	)
@@ -64,17 +94,22 @@ func SelectContainer(
 
 	var result []SelectedContainer = nil
 	for _, e := range summary {
-		result = append(result, SelectedContainer{id: e.ID})
+		result = append(result, SelectedContainer{
+			id:      e.ID,
+			labels:  e.Labels,
+			running: e.State == "running",
+		})
 	}
 
 	return result, nil
 }
 
 func TrySelectOneContainer(
+	ctx context.Context,
 	application string,
 	labels ...Label,
 ) (optional.Optional[Container], error) {
-	selectedContainers, err := SelectContainer(labels...)
+	selectedContainers, err := SelectContainer(ctx, labels...)
 	if err != nil {
 		return optional.Empty[Container](), err
 	}
@@ -84,9 +119,9 @@ func TrySelectOneContainer(
 		return optional.Empty[Container](), nil
 
 	case 1:
-		c, err := selectedContainers[0].NewContainer(application)
+		c, err := selectedContainers[0].NewContainer(ctx, application)
 		if err != nil {
-		  return optional.Empty[Container](), err
+			return optional.Empty[Container](), err
 		}
 		return optional.Of(c), nil
 
@@ -100,11 +135,15 @@ func TrySelectOneContainer(
 }
 
 func SelectAllNonApplicationContainers(
+	ctx context.Context,
 	application string,
 ) ([]*Container, error) {
-	ctx := context.Background()
 	args := filters.NewArgs(filters.Arg("label", labelApplicationName))
-	containers, err := c.ContainerList(ctx, container.ListOptions{
+	// exited containers kept by their restart policy are disabled as well
+	listCtx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	containers, err := backend.ContainerList(listCtx, container.ListOptions{
+		All:     true,
 		Filters: args,
 	})
 	if err != nil {
@@ -118,8 +157,8 @@ func SelectAllNonApplicationContainers(
 			continue
 		}
 
-		selected := &SelectedContainer{id: cont.ID}
-		c, err := selected.NewContainer(applicationLabel)
+		selected := &SelectedContainer{id: cont.ID, labels: cont.Labels}
+		c, err := selected.NewContainer(ctx, applicationLabel)
 		if err != nil {
 			return nil, err
 		}
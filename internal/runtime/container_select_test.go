@@ -4,6 +4,7 @@
 package runtime
 
 import (
+	"context"
 	"fmt"
 	"math/rand/v2"
 	"testing"
@@ -21,15 +22,16 @@ func TestRuntimeSelectContainer(t *testing.T) {
 	}
 
 	container, err := CreateNewContainer(
+		context.Background(),
 		application,
 		WithImage("traefik/whoami:latest"),
 		WithPulling(),
 		WithLabels(labels...),
 	)
 	assert.ErrNil(err)
-	defer container.Shutdown()
+	defer container.Shutdown(context.Background())
 
-	selected, err := SelectContainer(labels...)
+	selected, err := SelectContainer(context.Background(), labels...)
 	assert.ErrNil(err)
 
 	if len(selected) != 1 {
@@ -48,7 +50,7 @@ func TestRuntimeSelectContainer(t *testing.T) {
 func TestRuntimeSelectContainerWithNetwork(t *testing.T) {
 	testID := rand.IntN(1000000)
 	application := fmt.Sprintf("testing-%d", testID)
-	network, err := CreateNewNetwork(application)
+	network, err := CreateNewNetwork(context.Background(), application)
 	assert.ErrNil(err)
 	fmt.Println("created network ", network)
 
@@ -58,6 +60,7 @@ func TestRuntimeSelectContainerWithNetwork(t *testing.T) {
 	}
 
 	container, err := CreateNewContainer(
+		context.Background(),
 		application,
 		WithImage("traefik/whoami:latest"),
 		WithPulling(),
@@ -65,10 +68,10 @@ func TestRuntimeSelectContainerWithNetwork(t *testing.T) {
 		WithConnectedToNetwork(network),
 	)
 	assert.ErrNil(err)
-	defer container.Shutdown()
+	defer container.Shutdown(context.Background())
 	fmt.Println("created container ", container)
 
-	selected, err := SelectContainer(labels...)
+	selected, err := SelectContainer(context.Background(), labels...)
 	assert.ErrNil(err)
 
 	if len(selected) != 1 {
@@ -77,7 +80,7 @@ func TestRuntimeSelectContainerWithNetwork(t *testing.T) {
 		)
 	}
 
-	selectedContainer, err := selected[0].NewContainer(application)
+	selectedContainer, err := selected[0].NewContainer(context.Background(), application)
 	assert.ErrNil(err)
 
 	if !selectedContainer.Equal(container) {
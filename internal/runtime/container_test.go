@@ -4,6 +4,9 @@
 package runtime
 
 import (
+	"context"
+	"errors"
+	"os"
 	"testing"
 )
 
@@ -13,6 +16,7 @@ const (
 
 func pullStartAndRunAlping(cmd string) (*Container, error) {
 	return CreateNewContainer(
+		context.Background(),
 		"testing",
 		WithImage("alpine:3.14"),
 		WithPulling(),
@@ -25,7 +29,7 @@ func assertPathNotExist(
 	container *Container,
 	path string,
 ) {
-	exists, err := container.ExitsPath(path)
+	exists, err := container.ExistsPath(context.Background(), path)
 	if err != nil {
 		t.Errorf("checking path exists failed, got %q", err)
 	}
@@ -38,7 +42,7 @@ func assertContainerNotRuns(
 	t *testing.T,
 	container *Container,
 ) {
-	runs, err := container.IsRunning()
+	runs, err := container.IsRunning(context.Background())
 	if err != nil {
 		t.Errorf("checking container runs failed, got %q", err)
 	}
@@ -51,7 +55,7 @@ func assertContainerRuns(
 	t *testing.T,
 	container *Container,
 ) {
-	runs, err := container.IsRunning()
+	runs, err := container.IsRunning(context.Background())
 	if err != nil {
 		t.Errorf("checking container runs failed, got %q", err)
 	}
@@ -65,7 +69,7 @@ func assertPathExist(
 	container *Container,
 	path string,
 ) {
-	exists, err := container.ExitsPath(path)
+	exists, err := container.ExistsPath(context.Background(), path)
 	if err != nil {
 		t.Errorf("checking path exists failed, got %q", err)
 	}
@@ -80,7 +84,7 @@ func assertFileRead(
 	path string,
 	content string,
 ) {
-	read, err := container.ReadFile(path)
+	read, err := container.ReadFile(context.Background(), path)
 	if err != nil {
 		t.Errorf("reading file failed, got %q", err)
 	}
@@ -94,7 +98,7 @@ func TestCheckCopyReadSubsequently(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed starting container, got %q", err)
 	}
-	defer cont.Shutdown()
+	defer cont.Shutdown(context.Background())
 
 	path, data := "file.txt", "foobra"
 	assertPathNotExist(t, cont, path)
@@ -103,7 +107,7 @@ func TestCheckCopyReadSubsequently(t *testing.T) {
 		Path:    path,
 		Content: []byte(data),
 	}
-	err = cont.CopyInto(&f)
+	err = cont.CopyInto(context.Background(), &f)
 	if err != nil {
 		t.Fatalf("coping data failed, got %q", err)
 	}
@@ -119,7 +123,7 @@ func TestReadFileInContainer(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed starting container, got %q", err)
 	}
-	defer cont.Shutdown()
+	defer cont.Shutdown(context.Background())
 
 	assertFileRead(t, cont, "/tmp/file.txt", "foobar")
 }
@@ -129,12 +133,12 @@ func TestEnsurePathExists(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed starting container, got %q", err)
 	}
-	defer cont.Shutdown()
+	defer cont.Shutdown(context.Background())
 
 	path := "/tmp/this-should-really-not-exists"
 	assertPathNotExist(t, cont, path)
 
-	if err := cont.EnsurePathExists(path); err != nil {
+	if err := cont.EnsurePathExists(context.Background(), path); err != nil {
 		t.Errorf("failed to ensure path, got %q", err)
 	}
 
@@ -146,7 +150,7 @@ func TestCopyInto(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed starting container, got %q", err)
 	}
-	defer cont.Shutdown()
+	defer cont.Shutdown(context.Background())
 
 	data := "foobra"
 	path := "/tmp/this-should-really-not-exists.txt"
@@ -156,7 +160,7 @@ func TestCopyInto(t *testing.T) {
 		Path:    path,
 		Content: []byte(data),
 	}
-	if err := cont.CopyInto(&f); err != nil {
+	if err := cont.CopyInto(context.Background(), &f); err != nil {
 		t.Errorf("failed to copy data into container, got %q", err)
 	}
 
@@ -169,7 +173,7 @@ func TestCopyIntoOverwrite(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed starting container, got %q", err)
 	}
-	defer cont.Shutdown()
+	defer cont.Shutdown(context.Background())
 
 	data1 := "foobra"
 	path := "/tmp/this-should-really-not-exists.txt"
@@ -179,7 +183,7 @@ func TestCopyIntoOverwrite(t *testing.T) {
 		Path:    path,
 		Content: []byte(data1),
 	}
-	if err := cont.CopyInto(&f1); err != nil {
+	if err := cont.CopyInto(context.Background(), &f1); err != nil {
 		t.Errorf("failed to copy data into container, got %q", err)
 	}
 
@@ -191,7 +195,7 @@ func TestCopyIntoOverwrite(t *testing.T) {
 		Path:    path,
 		Content: []byte(data2),
 	}
-	if err := cont.CopyInto(&f2); err != nil {
+	if err := cont.CopyInto(context.Background(), &f2); err != nil {
 		t.Errorf("failed to copy data into container, got %q", err)
 	}
 
@@ -204,13 +208,91 @@ func TestIsRunning(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed starting container, got %q", err)
 	}
-	defer cont.Shutdown()
+	defer cont.Shutdown(context.Background())
 
 	assertContainerRuns(t, cont)
-	err = cont.Shutdown()
+	err = cont.Shutdown(context.Background())
 	if err != nil {
 		t.Errorf("failed shutdown container, got %q", err)
 	}
 
 	assertContainerNotRuns(t, cont)
 }
+
+// Images without a shell, e.g. distroless, can not run commands, all file operations must work
+// through the archive API of the container engine.
+func newContainerWithoutShell(t *testing.T) (*FakeBackend, *Container) {
+	fake := useFakeBackend(t)
+	fake.Fail("ContainerExec", errors.New("exec: \"sh\": executable file not found"))
+	fake.AddImage("distroless:v1")
+
+	cont, err := CreateNewContainer(context.Background(), "testing", WithImage("distroless:v1"))
+	if err != nil {
+		t.Fatalf("failed starting container, got %q", err)
+	}
+
+	return fake, cont
+}
+
+func TestFileOperationsWithoutShell(t *testing.T) {
+	_, cont := newContainerWithoutShell(t)
+
+	path := "/etc/zeus/conf.d/rickroll.conf"
+	assertPathNotExist(t, cont, path)
+	if err := cont.EnsurePathExists(context.Background(), "/etc/zeus/conf.d"); err != nil {
+		t.Fatalf("failed to ensure path, got %q", err)
+	}
+	assertPathExist(t, cont, "/etc/zeus/conf.d")
+
+	if err := cont.CopyInto(context.Background(), &BasicFileContent{Path: path, Content: []byte("listen 80;")}); err != nil {
+		t.Fatalf("failed to copy data into container, got %q", err)
+	}
+	assertPathExist(t, cont, path)
+	assertFileRead(t, cont, path, "listen 80;")
+
+	if _, err := cont.ReadFile(context.Background(), "/etc/zeus/conf.d"); !errors.Is(err, ErrNotRegularFile) {
+		t.Errorf("expected reading a directory to fail, got %v", err)
+	}
+	if err := cont.EnsurePathExists(context.Background(), path+"/nested"); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("expected directory below a file to fail, got %v", err)
+	}
+}
+
+func TestCopyIntoPreservesMode(t *testing.T) {
+	_, cont := newContainerWithoutShell(t)
+	ctx := t.Context()
+
+	script := &BasicFileContent{Path: "/usr/bin/entrypoint", Content: []byte("#!/bin/sh"), Mode: 0750, UID: 101, GID: 101}
+	if err := cont.CopyInto(context.Background(), script); err != nil {
+		t.Fatalf("failed to copy data into container, got %q", err)
+	}
+
+	hdr, err := cont.statPath(ctx, "/usr/bin/entrypoint")
+	if err != nil || hdr == nil {
+		t.Fatalf("expected file to exist, got %v", err)
+	}
+	if os.FileMode(hdr.Mode) != 0750 || hdr.Uid != 101 || hdr.Gid != 101 {
+		t.Errorf("expected mode 0750 owned by 101:101, got %o owned by %d:%d", hdr.Mode, hdr.Uid, hdr.Gid)
+	}
+
+	// a replaced file keeps the mode and owner of the file it replaces
+	if err := cont.CopyInto(context.Background(), &BasicFileContent{Path: "/usr/bin/entrypoint", Content: []byte("#!/bin/sh -e")}); err != nil {
+		t.Fatalf("failed to copy data into container, got %q", err)
+	}
+	hdr, err = cont.statPath(ctx, "/usr/bin/entrypoint")
+	if err != nil || hdr == nil {
+		t.Fatalf("expected file to exist, got %v", err)
+	}
+	if os.FileMode(hdr.Mode) != 0750 || hdr.Uid != 101 || hdr.Gid != 101 {
+		t.Errorf("expected mode 0750 owned by 101:101 to be kept, got %o owned by %d:%d", hdr.Mode, hdr.Uid, hdr.Gid)
+	}
+	assertFileRead(t, cont, "/usr/bin/entrypoint", "#!/bin/sh -e")
+
+	if err := cont.CopyInto(context.Background(), &BasicFileContent{Path: "/etc/motd", Content: []byte("zeus")}); err != nil {
+		t.Fatalf("failed to copy data into container, got %q", err)
+	}
+	hdr, err = cont.statPath(ctx, "/etc/motd")
+	if err != nil || hdr == nil || os.FileMode(hdr.Mode) != 0644 || hdr.Uid != 0 {
+		t.Errorf("expected new file with mode 0644 owned by root, got %v", hdr)
+	}
+}
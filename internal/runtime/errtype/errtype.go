@@ -4,6 +4,9 @@
 package errtype
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/raphaeldichler/zeus/internal/record"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
@@ -16,6 +19,7 @@ const (
 	DockerStopContainer
 	DockerInspectContainer
 	DockerCreateNetwork
+	DockerCreateVolume
 )
 
 var dockerDaemomnInteractionMapping map[DockerDaemonInteraction]string = map[DockerDaemonInteraction]string{
@@ -24,6 +28,7 @@ var dockerDaemomnInteractionMapping map[DockerDaemonInteraction]string = map[Doc
 	DockerStopContainer:    "stop",
 	DockerInspectContainer: "inspect",
 	DockerCreateNetwork:    "network",
+	DockerCreateVolume:     "volume",
 }
 
 func FailedInteractionWithDockerDaemon(
@@ -40,3 +45,63 @@ func FailedInteractionWithDockerDaemon(
 		Message:    err.Error(),
 	}
 }
+
+func FailedServiceInteractionWithDockerDaemon(
+	service record.RecordKey,
+	identifier DockerDaemonInteraction,
+	err error,
+) record.ServiceErrorEntryRecord {
+	entry := FailedInteractionWithDockerDaemon(identifier, err)
+
+	return record.ServiceErrorEntryRecord{
+		Service:    service,
+		Type:       entry.Type,
+		Identifier: entry.Identifier,
+		Message:    entry.Message,
+	}
+}
+
+func UnhealthyService(service record.RecordKey, health string) record.ServiceErrorEntryRecord {
+	return record.ServiceErrorEntryRecord{
+		Service:    service,
+		Type:       "UnhealthyService",
+		Identifier: health,
+		Message:    "container did not pass its health check, it receives no traffic",
+	}
+}
+
+func CrashLoopingService(service record.RecordKey, exitCode int, restarts int) record.ServiceErrorEntryRecord {
+	return record.ServiceErrorEntryRecord{
+		Service:    service,
+		Type:       "CrashLoopBackOff",
+		Identifier: strconv.Itoa(exitCode),
+		Message:    fmt.Sprintf("container exited %d time(s) in a row, it is restarted after a backoff", restarts),
+	}
+}
+
+func FailedInitContainer(service record.RecordKey, container string, exitCode int) record.ServiceErrorEntryRecord {
+	return record.ServiceErrorEntryRecord{
+		Service:    service,
+		Type:       "FailedInitContainer",
+		Identifier: container,
+		Message:    fmt.Sprintf("init container exited with code %d, the service is not started", exitCode),
+	}
+}
+
+func RolledBackRollout(service record.RecordKey, reason string) record.ServiceErrorEntryRecord {
+	return record.ServiceErrorEntryRecord{
+		Service:    service,
+		Type:       "RolledBackRollout",
+		Identifier: "rollout",
+		Message:    fmt.Sprintf("%s, the previous container keeps serving", reason),
+	}
+}
+
+func FailedInteractionWithDNS(service record.RecordKey, err error) record.ServiceErrorEntryRecord {
+	return record.ServiceErrorEntryRecord{
+		Service:    service,
+		Type:       "FailedInteractionWithDNS",
+		Identifier: "dns",
+		Message:    err.Error(),
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+// Delay until the events stream is subscribed again after it failed
+const resubscribeDelay = time.Second * 5
+
+// Drift describes a managed object which changed outside of zeus.
+type Drift struct {
+	Application string
+	// Type of the object, e.g. ingress, dns, service or network
+	Object string
+	// Name of the service or the docker name of the object
+	Name   string
+	Action string
+	Time   time.Time
+}
+
+// Time after which an intended removal is forgotten, even if the object was never destroyed, e.g. a
+// container which is stopped and kept or whose destroy event was missed between two subscriptions.
+const intendedRemovalTimeout = time.Minute
+
+// Objects which zeus itself stops or removes. Their events are expected and are not reported as drift.
+// The objects belong to the current backend, once it is replaced they are forgotten, see SetBackend.
+var intended = &intendedRemovals{ids: make(map[string]time.Time)}
+
+type intendedRemovals struct {
+	mu sync.Mutex
+	// maps the object to the time its removal was requested
+	ids map[string]time.Time
+}
+
+func (self *intendedRemovals) add(id string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.ids[id] = time.Now()
+}
+
+// Reports if the removal of the object was intended. If done is true, the object is gone and it is forgotten.
+func (self *intendedRemovals) contains(id string, done bool) bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	for other, added := range self.ids {
+		if time.Since(added) > intendedRemovalTimeout {
+			delete(self.ids, other)
+		}
+	}
+
+	_, ok := self.ids[id]
+	if ok && done {
+		delete(self.ids, id)
+	}
+
+	return ok
+}
+
+// Forgets all intended removals.
+func (self *intendedRemovals) reset() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	clear(self.ids)
+}
+
+// Subscribes to the docker events of all objects managed by zeus and calls notify for every object
+// which dies, disappears or is modified outside of zeus. Blocks until the context is cancelled.
+//
+// If the stream fails, it is subscribed again after a delay. Events which occur in between are lost,
+// the periodic resync of the orchestrator covers them.
+func WatchDrift(ctx context.Context, notify func(Drift)) {
+	assert.True(backend != nil, "init of runtime backend failed")
+	logger := log.New("runtime", "events")
+
+	for {
+		err := watchDrift(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error("Docker events stream failed, subscribe again in %s: %v", resubscribeDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func watchDrift(ctx context.Context, notify func(Drift)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// network events carry no labels, therefore they are subscribed separately and filtered by name
+	containerArgs := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("label", labelObjectType),
+		filters.Arg("label", labelApplicationName),
+		filters.Arg("event", string(events.ActionDie)),
+		filters.Arg("event", string(events.ActionOOM)),
+		filters.Arg("event", string(events.ActionDestroy)),
+		filters.Arg("event", string(events.ActionUpdate)),
+		filters.Arg("event", string(events.ActionRename)),
+		filters.Arg("event", string(events.ActionHealthStatus)),
+	)
+	networkArgs := filters.NewArgs(
+		filters.Arg("type", string(events.NetworkEventType)),
+		filters.Arg("event", string(events.ActionDestroy)),
+	)
+
+	containerMessages, containerErrs := backend.Events(ctx, events.ListOptions{Filters: containerArgs})
+	networkMessages, networkErrs := backend.Events(ctx, events.ListOptions{Filters: networkArgs})
+
+	for {
+		var msg events.Message
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-containerErrs:
+			return err
+		case err := <-networkErrs:
+			return err
+		case msg = <-containerMessages:
+		case msg = <-networkMessages:
+		}
+
+		if drift, ok := toDrift(msg); ok {
+			notify(drift)
+		}
+	}
+}
+
+// Converts the event into a drift. Returns false if the event does not describe a drift
+// of an object managed by zeus.
+func toDrift(msg events.Message) (Drift, bool) {
+	attributes := msg.Actor.Attributes
+	drift := Drift{
+		Action: string(msg.Action),
+		Time:   time.Unix(0, msg.TimeNano),
+	}
+
+	switch msg.Type {
+	case events.ContainerEventType:
+		// containers which become healthy are routed by the next resync, only failing ones need a repair
+		if strings.HasPrefix(string(msg.Action), string(events.ActionHealthStatus)) &&
+			msg.Action != events.ActionHealthStatusUnhealthy {
+			return Drift{}, false
+		}
+
+		done := msg.Action == events.ActionDestroy
+		if intended.contains(msg.Actor.ID, done) {
+			return Drift{}, false
+		}
+
+		drift.Application = attributes[labelApplicationName]
+		drift.Object = attributes[labelObjectType]
+		drift.Name = attributes["name"]
+		if service, ok := attributes[labelServiceName]; ok {
+			drift.Name = service
+		}
+
+	case events.NetworkEventType:
+		application, ok := strings.CutPrefix(attributes["name"], networkNamePrefix)
+		if !ok {
+			return Drift{}, false
+		}
+		if intended.contains(msg.Actor.ID, true) {
+			return Drift{}, false
+		}
+
+		drift.Application = application
+		drift.Object = objectLabelMapping[NetworkObject]
+		drift.Name = attributes["name"]
+
+	default:
+		return Drift{}, false
+	}
+
+	if drift.Application == "" || drift.Object == "" {
+		return Drift{}, false
+	}
+
+	return drift, true
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+func containerEvent(id string, action events.Action, attributes map[string]string) events.Message {
+	return events.Message{
+		Type:   events.ContainerEventType,
+		Action: action,
+		Actor:  events.Actor{ID: id, Attributes: attributes},
+	}
+}
+
+func TestToDriftContainer(t *testing.T) {
+	msg := containerEvent("c1", events.ActionDie, map[string]string{
+		"name":               "brave_turing",
+		labelObjectType:      "service",
+		labelApplicationName: "poseidon",
+		labelServiceName:     "rickroll",
+	})
+
+	drift, ok := toDrift(msg)
+	if !ok {
+		t.Fatalf("expected drift for died service container")
+	}
+	if drift.Application != "poseidon" || drift.Object != "service" || drift.Name != "rickroll" || drift.Action != "die" {
+		t.Errorf("drift not converted correctly, got '%v'", drift)
+	}
+
+	msg = containerEvent("c2", events.ActionDestroy, map[string]string{
+		"name":               "zeus-ingress",
+		labelObjectType:      "ingress",
+		labelApplicationName: "poseidon",
+	})
+	drift, ok = toDrift(msg)
+	if !ok || drift.Name != "zeus-ingress" {
+		t.Errorf("expected drift named by the container, got '%v'", drift)
+	}
+
+	msg = containerEvent("c3", events.ActionDie, map[string]string{"name": "unrelated"})
+	if _, ok := toDrift(msg); ok {
+		t.Errorf("expected no drift for unmanaged container")
+	}
+}
+
+func TestToDriftIgnoresIntendedRemovals(t *testing.T) {
+	attributes := map[string]string{
+		labelObjectType:      "dns",
+		labelApplicationName: "poseidon",
+	}
+	intended.add("c4")
+
+	if _, ok := toDrift(containerEvent("c4", events.ActionDie, attributes)); ok {
+		t.Errorf("expected no drift for container stopped by zeus")
+	}
+	if _, ok := toDrift(containerEvent("c4", events.ActionDestroy, attributes)); ok {
+		t.Errorf("expected no drift for container removed by zeus")
+	}
+	if _, ok := toDrift(containerEvent("c4", events.ActionDie, attributes)); !ok {
+		t.Errorf("expected removed container to be forgotten after destroy")
+	}
+}
+
+func TestToDriftForgetsExpiredIntendedRemovals(t *testing.T) {
+	attributes := map[string]string{
+		labelObjectType:      "service",
+		labelApplicationName: "poseidon",
+	}
+	// a stopped container with a restart policy is kept, no destroy event forgets it
+	intended.add("c5")
+	intended.mu.Lock()
+	intended.ids["c5"] = time.Now().Add(-intendedRemovalTimeout - time.Second)
+	intended.mu.Unlock()
+
+	if _, ok := toDrift(containerEvent("c5", events.ActionDie, attributes)); !ok {
+		t.Errorf("expected drift for container whose intended removal expired")
+	}
+}
+
+func TestSetBackendForgetsIntendedRemovals(t *testing.T) {
+	intended.add("c6")
+	useFakeBackend(t)
+
+	if intended.contains("c6", false) {
+		t.Errorf("expected intended removals of the previous backend to be forgotten")
+	}
+}
+
+func TestToDriftNetwork(t *testing.T) {
+	msg := events.Message{
+		Type:   events.NetworkEventType,
+		Action: events.ActionDestroy,
+		Actor:  events.Actor{ID: "n1", Attributes: map[string]string{"name": networkName("poseidon")}},
+	}
+	drift, ok := toDrift(msg)
+	if !ok {
+		t.Fatalf("expected drift for removed application network")
+	}
+	if drift.Application != "poseidon" || drift.Object != "network" {
+		t.Errorf("drift not converted correctly, got '%v'", drift)
+	}
+
+	msg.Actor.Attributes["name"] = "bridge"
+	if _, ok := toDrift(msg); ok {
+		t.Errorf("expected no drift for unmanaged network")
+	}
+
+	msg.Actor = events.Actor{ID: "n2", Attributes: map[string]string{"name": networkName("poseidon")}}
+	intended.add("n2")
+	if _, ok := toDrift(msg); ok {
+		t.Errorf("expected no drift for network removed by zeus")
+	}
+}
+
+func TestWatchDriftReportsKilledContainer(t *testing.T) {
+	fake := useFakeBackend(t)
+	application := "poseidon"
+	network, err := CreateNewNetwork(context.Background(), application)
+	if err != nil {
+		t.Fatalf("failed to create network, got %q", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	drifts := make(chan Drift, 8)
+	go WatchDrift(ctx, func(d Drift) { drifts <- d })
+
+	for subscribed := false; !subscribed; time.Sleep(time.Millisecond) {
+		fake.mu.Lock()
+		subscribed = len(fake.subscribers) == 2
+		fake.mu.Unlock()
+	}
+	if err := fake.Kill(network.dns.id); err != nil {
+		t.Fatalf("failed to kill container, got %q", err)
+	}
+
+	select {
+	case drift := <-drifts:
+		if drift.Object != "dns" || drift.Application != application || drift.Action != "die" {
+			t.Errorf("unexpected drift, got '%v'", drift)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("expected drift for killed container")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+// Interval in which an exec session is inspected while waiting for its exit code
+const execExitPollInterval = time.Millisecond * 50
+
+type ExecOptions struct {
+	Cmd []string
+	// Allocates a TTY, stdout and stderr of the command are combined
+	Tty bool
+	// Attaches stdin of the command
+	Stdin bool
+	// Initial size of the TTY
+	Height uint
+	Width  uint
+}
+
+// Interactive command which runs inside a container.
+type ExecSession struct {
+	id      string
+	tty     bool
+	backend Backend
+	conn    types.HijackedResponse
+}
+
+// Starts the command inside the container and attaches to it. The session must be closed.
+func (self *Container) Exec(ctx context.Context, options ExecOptions) (*ExecSession, error) {
+	assert.True(len(options.Cmd) > 0, "exec requires a command")
+
+	config := container.ExecOptions{
+		Cmd:          options.Cmd,
+		Tty:          options.Tty,
+		AttachStdin:  options.Stdin,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	if options.Tty && options.Height > 0 && options.Width > 0 {
+		config.ConsoleSize = &[2]uint{options.Height, options.Width}
+	}
+
+	id, conn, err := self.backend.ContainerExecAttach(ctx, self.id, config)
+	if err != nil {
+		self.log.Error("Failed to exec '%s' in (%s): %v", options.Cmd, self, err)
+		return nil, err
+	}
+
+	return &ExecSession{
+		id:      id,
+		tty:     options.Tty,
+		backend: self.backend,
+		conn:    conn,
+	}, nil
+}
+
+// Writes to stdin of the command.
+func (self *ExecSession) Write(p []byte) (int, error) {
+	return self.conn.Conn.Write(p)
+}
+
+// Closes stdin of the command, the output can still be read.
+func (self *ExecSession) CloseStdin() error {
+	return self.conn.CloseWrite()
+}
+
+// Copies the output of the command to the writers until the command exits. With a TTY, all output
+// is written to stdout.
+func (self *ExecSession) CopyOutput(stdout io.Writer, stderr io.Writer) error {
+	if self.tty {
+		_, err := io.Copy(stdout, self.conn.Reader)
+		return err
+	}
+
+	_, err := stdcopy.StdCopy(stdout, stderr, self.conn.Reader)
+	return err
+}
+
+func (self *ExecSession) Resize(ctx context.Context, height uint, width uint) error {
+	return self.backend.ContainerExecResize(ctx, self.id, container.ResizeOptions{Height: height, Width: width})
+}
+
+// Returns the exit code of the command once it exited. The engine may report the command as running
+// shortly after its output ended, therefore it is polled.
+func (self *ExecSession) ExitCode(ctx context.Context) (int, error) {
+	ticker := time.NewTicker(execExitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		inspect, err := self.backend.ContainerExecInspect(ctx, self.id)
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (self *ExecSession) Close() {
+	self.conn.Close()
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestExecSessionEchosStdin(t *testing.T) {
+	fake := useFakeBackend(t)
+	container := startLoggingService(t, fake, "poseidon")
+	ctx := context.Background()
+
+	session, err := container.Exec(ctx, ExecOptions{Cmd: []string{"cat"}, Stdin: true, Tty: true, Height: 24, Width: 80})
+	assert.ErrNil(err)
+	defer session.Close()
+
+	if height, width, err := fake.ExecSize(session.id); err != nil || height != 24 || width != 80 {
+		t.Errorf("expected initial size 24x80, got %dx%d", height, width)
+	}
+	assert.ErrNil(session.Resize(ctx, 40, 120))
+	if height, width, _ := fake.ExecSize(session.id); height != 40 || width != 120 {
+		t.Errorf("expected resized 40x120, got %dx%d", height, width)
+	}
+
+	var stdout bytes.Buffer
+	done := make(chan error)
+	go func() { done <- session.CopyOutput(&stdout, &stdout) }()
+
+	_, err = session.Write([]byte("never gonna give you up\n"))
+	assert.ErrNil(err)
+	assert.ErrNil(session.CloseStdin())
+	assert.ErrNil(<-done)
+
+	if stdout.String() != "never gonna give you up\n" {
+		t.Errorf("expected stdin to be echoed, got %q", stdout.String())
+	}
+	exitCode, err := session.ExitCode(ctx)
+	assert.ErrNil(err)
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+}
+
+func TestExecSessionExitCode(t *testing.T) {
+	fake := useFakeBackend(t)
+	container := startLoggingService(t, fake, "poseidon")
+	ctx := context.Background()
+
+	session, err := container.Exec(ctx, ExecOptions{Cmd: []string{"cat", "/missing"}})
+	assert.ErrNil(err)
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	assert.ErrNil(session.CopyOutput(&stdout, &stderr))
+	exitCode, err := session.ExitCode(ctx)
+	assert.ErrNil(err)
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+	if stdout.Len() == 0 {
+		t.Errorf("expected error output of the command")
+	}
+}
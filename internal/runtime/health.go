@@ -0,0 +1,188 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+const (
+	// Interval in which the health of a container is polled while waiting for it
+	healthPollInterval = time.Millisecond * 250
+	// Upper limit of the time to wait for a container to become healthy
+	maxReadinessTimeout = time.Minute * 2
+)
+
+// Health of a container as reported by the container engine
+const (
+	HealthNone      = container.NoHealthcheck
+	HealthStarting  = container.Starting
+	HealthHealthy   = container.Healthy
+	HealthUnhealthy = container.Unhealthy
+)
+
+// HealthCheck is executed by the container engine inside the container.
+type HealthCheck struct {
+	// Test in the format of the container engine, e.g. ["CMD", "pg_isready"]
+	Test        []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	// Interval during the start period, defaults to the interval
+	StartInterval time.Duration
+	// Consecutive failures until the container is unhealthy
+	Retries int
+}
+
+// Runs the command inside the container, exit code 0 means healthy.
+func ExecProbe(cmd ...string) []string {
+	assert.True(len(cmd) > 0, "exec probe requires a command")
+	return append([]string{"CMD"}, cmd...)
+}
+
+// Requests the path on the port inside the container, a 2xx response means healthy.
+// The image must provide wget or curl.
+func HTTPProbe(port string, path string, timeout time.Duration) []string {
+	assert.StartsWithString(path, "/", "path must be absolute")
+	url := fmt.Sprintf("http://127.0.0.1:%s%s", port, path)
+	seconds := timeoutSeconds(timeout)
+
+	return []string{
+		"CMD-SHELL",
+		fmt.Sprintf(
+			"wget -q -T %d -O /dev/null '%s' || curl -fsS -m %d -o /dev/null '%s'",
+			seconds, url, seconds, url,
+		),
+	}
+}
+
+// Opens a connection to the port inside the container, an accepted connection means healthy.
+// The image must provide nc.
+func TCPProbe(port string, timeout time.Duration) []string {
+	return []string{
+		"CMD-SHELL",
+		fmt.Sprintf("nc -z -w %d 127.0.0.1 %s", timeoutSeconds(timeout), port),
+	}
+}
+
+func timeoutSeconds(timeout time.Duration) int {
+	return max(1, int(math.Ceil(timeout.Seconds())))
+}
+
+// Returns the time after which a container, which did not become healthy, is considered unhealthy.
+func (self *HealthCheck) readinessTimeout() time.Duration {
+	timeout := self.StartPeriod + (self.Interval+self.Timeout)*time.Duration(self.Retries+1)
+	return min(timeout, maxReadinessTimeout)
+}
+
+func WithHealthCheck(check HealthCheck) ContainerOption {
+	assert.True(len(check.Test) > 0, "health check requires a test")
+	assert.True(check.Interval > 0, "health check interval must be positive")
+	assert.True(check.Timeout > 0, "health check timeout must be positive")
+
+	return func(cfg *ContainerConfig) {
+		cfg.config.Healthcheck = &container.HealthConfig{
+			Test:          check.Test,
+			Interval:      check.Interval,
+			Timeout:       check.Timeout,
+			StartPeriod:   check.StartPeriod,
+			StartInterval: check.StartInterval,
+			Retries:       check.Retries,
+		}
+	}
+}
+
+// Returns the health of the container, 'none' if the container has no health check.
+func (self *Container) Health(ctx context.Context) (string, error) {
+	inspect, err := self.Inspect(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return healthOf(inspect), nil
+}
+
+func healthOf(inspect container.InspectResponse) string {
+	if inspect.State == nil || inspect.State.Health == nil {
+		return HealthNone
+	}
+
+	return inspect.State.Health.Status
+}
+
+// Waits until the container is healthy, unhealthy or the timeout passed and returns its last health.
+// Containers without a health check are returned immediately with 'none'.
+func (self *Container) WaitHealthy(ctx context.Context, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		health, err := self.Health(ctx)
+		if err != nil {
+			return "", err
+		}
+		if health != HealthStarting {
+			return health, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return health, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ensures the container is reachable by the alias inside its network or not. Changing the aliases
+// reconnects the container, which interrupts its connections.
+func (self *Container) ensureNetworkAlias(ctx context.Context, alias string, reachable bool) error {
+	assert.NotNil(self.network, "container must be connected to a network")
+
+	inspect, err := self.Inspect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		current []string                    = nil
+		ipam    *network.EndpointIPAMConfig = nil
+	)
+	if inspect.NetworkSettings != nil {
+		if endpoint, ok := inspect.NetworkSettings.Networks[self.network.name]; ok && endpoint != nil {
+			current = endpoint.Aliases
+			ipam = endpoint.IPAMConfig
+		}
+	}
+	if slices.Contains(current, alias) == reachable {
+		return nil
+	}
+
+	aliases := slices.DeleteFunc(slices.Clone(current), func(a string) bool { return a == alias })
+	if reachable {
+		aliases = append(aliases, alias)
+	}
+
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	if err := self.backend.NetworkDisconnect(ctx, self.network.name, self.id); err != nil {
+		return err
+	}
+
+	// the container is reconnected with its static address, otherwise the address would change
+	return self.backend.NetworkConnect(ctx, self.network.name, self.id, &network.EndpointSettings{
+		Aliases:    aliases,
+		IPAMConfig: ipam,
+	})
+}
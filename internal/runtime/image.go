@@ -0,0 +1,135 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+// Registry of images whose reference names none, e.g. 'nginx:stable'
+const DefaultRegistry = "docker.io"
+
+// PullProgress is an update of a pull as reported by the container engine.
+type PullProgress struct {
+	// Layer of the image the update refers to, empty for updates of the whole image
+	Layer  string
+	Status string
+	// Bytes of the layer which are downloaded or extracted, zero if unknown
+	Current int64
+	Total   int64
+}
+
+type PullOptions struct {
+	// Credentials of the registry encoded for the container engine, see RegistryAuth
+	RegistryAuth string
+	// Receives the updates of the pull, may be nil
+	Progress func(PullProgress)
+	// Pulls the image even if it exists, which updates a tag that moved in the registry
+	Always bool
+}
+
+func (self *PullOptions) progress(p PullProgress) {
+	if self.Progress != nil {
+		self.Progress(p)
+	}
+}
+
+// Returns the registry which hosts the image, e.g. 'ghcr.io' for 'ghcr.io/zeus/rickroll:v1'.
+// Like the container engine, the first part of the reference is only a registry if it contains a '.'
+// or ':' or is 'localhost'.
+func RegistryOfImage(ref string) string {
+	host, _, found := strings.Cut(ref, "/")
+	if !found {
+		return DefaultRegistry
+	}
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return DefaultRegistry
+	}
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		return DefaultRegistry
+	}
+
+	return host
+}
+
+// Returns the repository of the image without tag or digest in the short form of the container engine,
+// e.g. 'nginx' for 'docker.io/library/nginx:stable'.
+func imageRepository(ref string) string {
+	repository, _, _ := strings.Cut(ref, "@")
+	if idx := strings.LastIndex(repository, ":"); idx > strings.LastIndex(repository, "/") {
+		repository = repository[:idx]
+	}
+	for _, prefix := range []string{"docker.io/", "index.docker.io/", "registry-1.docker.io/"} {
+		repository = strings.TrimPrefix(repository, prefix)
+	}
+
+	return strings.TrimPrefix(repository, "library/")
+}
+
+// Encodes the credential for the pull of an image.
+func RegistryAuth(credential record.RegistryCredential) string {
+	auth, err := registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      credential.Username,
+		Password:      credential.Password,
+		ServerAddress: credential.Registry,
+	})
+	assert.ErrNil(err)
+
+	return auth
+}
+
+// Pulls the image unless it already exists on the machine. The pull is aborted after the pull timeout.
+func PullImage(ctx context.Context, ref string, options PullOptions) error {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	ctx, cancel := context.WithTimeout(ctx, pullTimeout)
+	defer cancel()
+	exists, err := backend.ImageExists(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if exists && !options.Always {
+		options.progress(PullProgress{Status: "Image is up to date"})
+		return nil
+	}
+
+	return backend.ImagePull(ctx, ref, options)
+}
+
+// Returns an immutable reference of the image which exists on the machine. This is the digest of the
+// image in its repository, e.g. 'ghcr.io/zeus/rickroll@sha256:...', or the ID of an image which was
+// never pulled, e.g. one which was built on the machine.
+func ImageDigest(ctx context.Context, ref string) (string, error) {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	inspect, err := backend.ImageInspect(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	repository := imageRepository(ref)
+	for _, digest := range inspect.RepoDigests {
+		if imageRepository(digest) == repository {
+			return digest, nil
+		}
+	}
+
+	return inspect.ID, nil
+}
+
+// Pulls the image unless it exists and returns its immutable reference, see ImageDigest.
+func ResolveImage(ctx context.Context, ref string, options PullOptions) (string, error) {
+	if err := PullImage(ctx, ref, options); err != nil {
+		return "", err
+	}
+
+	return ImageDigest(ctx, ref)
+}
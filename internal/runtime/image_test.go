@@ -0,0 +1,134 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestRegistryOfImage(t *testing.T) {
+	tests := map[string]string{
+		"nginx:stable":                     DefaultRegistry,
+		"library/nginx:stable":             DefaultRegistry,
+		"rickroll/rickroll:v1":             DefaultRegistry,
+		"index.docker.io/rickroll/app:v1":  DefaultRegistry,
+		"ghcr.io/zeus/rickroll:v1":         "ghcr.io",
+		"registry.local:5000/rickroll":     "registry.local:5000",
+		"localhost/rickroll:v1":            "localhost",
+		"ghcr.io/zeus/rickroll@sha256:abc": "ghcr.io",
+	}
+	for ref, expected := range tests {
+		if got := RegistryOfImage(ref); got != expected {
+			t.Errorf("expected registry '%s' of image '%s', got '%s'", expected, ref, got)
+		}
+	}
+}
+
+func TestSyncPullsWithRegistryCredential(t *testing.T) {
+	fake := useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	credential := record.RegistryCredential{Registry: "ghcr.io", Username: "rick", Password: "astley"}
+	state := record.New("poseidon", record.Development)
+	state.SetRegistry(credential)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "private",
+			Network:     &record.ServiceNetwork{},
+			Container:   &record.ServiceContainer{Image: "ghcr.io/zeus/rickroll:v1"},
+		},
+		{
+			ServiceName: "public",
+			Network:     &record.ServiceNetwork{},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1"},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	if auth, _ := fake.PullAuth("ghcr.io/zeus/rickroll:v1"); auth != RegistryAuth(credential) {
+		t.Errorf("expected private image to be pulled with the credential, got '%s'", auth)
+	}
+	if auth, ok := fake.PullAuth("rickroll:v1"); !ok || auth != "" {
+		t.Errorf("expected public image to be pulled without credential, got '%s'", auth)
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	fake := useFakeBackend(t)
+	ctx := context.Background()
+
+	fake.AddImage("rickroll:local")
+	local, err := ResolveImage(ctx, "rickroll:local", PullOptions{})
+	assert.ErrNil(err)
+	if local != fakeDigest("rickroll:local") {
+		t.Errorf("expected image which was never pulled to resolve to its ID, got '%s'", local)
+	}
+
+	pinned, err := ResolveImage(ctx, "docker.io/library/rickroll:v1", PullOptions{})
+	assert.ErrNil(err)
+	if !strings.HasPrefix(pinned, "rickroll@sha256:") {
+		t.Fatalf("expected pulled image to resolve to its repository digest, got '%s'", pinned)
+	}
+
+	again, err := ResolveImage(ctx, "docker.io/library/rickroll:v1", PullOptions{})
+	assert.ErrNil(err)
+	if again != pinned {
+		t.Errorf("expected existing image not to be pulled again, got '%s'", again)
+	}
+
+	updated, err := ResolveImage(ctx, "docker.io/library/rickroll:v1", PullOptions{Always: true})
+	assert.ErrNil(err)
+	if updated == pinned {
+		t.Errorf("expected image which is always pulled to resolve to the moved tag")
+	}
+	if digest, err := ImageDigest(ctx, pinned); err != nil || digest != pinned {
+		t.Errorf("expected image of the old digest to stay on the machine, got '%s'", digest)
+	}
+}
+
+func TestSyncRunsDigestOfService(t *testing.T) {
+	fake := useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	pinned, err := ResolveImage(context.Background(), "rickroll:v1", PullOptions{})
+	assert.ErrNil(err)
+	// the tag moves after the service was applied
+	_, err = ResolveImage(context.Background(), "rickroll:v1", PullOptions{Always: true})
+	assert.ErrNil(err)
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1", Digest: pinned},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	selected := selectServiceContainers(t, "poseidon")
+	if len(selected) != 1 {
+		t.Fatalf("expected one service container, got %d", len(selected))
+	}
+	inspect, err := fake.ContainerInspect(context.Background(), selected[0].id)
+	assert.ErrNil(err)
+	if inspect.Image != pinned {
+		t.Errorf("expected container to run the digest '%s', got '%s'", pinned, inspect.Image)
+	}
+}
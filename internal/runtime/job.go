@@ -0,0 +1,119 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+)
+
+var ErrNetworkNotFound = errors.New("application network does not exist")
+
+// Starts a run of the job in a one-off container on the application network, the image is pulled if it
+// does not exist. The container is kept after it exited, which preserves its exit code and output until
+// the run is removed.
+//
+// The container gets labeled with:
+//   - zeus.object.type=job
+//   - zeus.object.image={image}
+//   - zeus.job.name={job}
+//   - zeus.job.run={run}
+//   - zeus.application.name={application}
+//   - zeus.restart.policy=never
+func StartJob(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	job *record.JobSpec,
+	run string,
+) (*Container, error) {
+	application := state.Metadata.Application
+	network, err := TrySelectApplicationNetwork(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+	if network == nil {
+		return nil, ErrNetworkNotFound
+	}
+
+	opts := NewContainerOptions()
+	opts.Add(
+		WithImage(job.ImageReference()),
+		WithPulling(),
+		WithLabels(
+			ObjectTypeLabel(JobObject),
+			ObjectImageLabel(job.Image),
+			JobNameLabel(job.Name),
+			JobRunLabel(run),
+			ApplicationNameLabel(application),
+		),
+		WithEnv(envDeploymentType, strings.ToUpper(state.Metadata.Deployment.String())),
+		WithConnectedToNetwork(network),
+		WithRestartPolicy(record.RestartNever),
+	)
+	if len(job.Command) != 0 {
+		opts.Add(WithCmd(job.Command...))
+	}
+
+	if credential := state.Registry(RegistryOfImage(job.Image)); credential != nil {
+		opts.Add(WithRegistryAuth(RegistryAuth(*credential)))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(job.Env)) {
+		opts.Add(WithEnv(key, job.Env[key]))
+	}
+
+	for _, v := range job.Volumes {
+		if err := EnsureVolume(ctx, application, v.Name); err != nil {
+			return nil, err
+		}
+		opts.Add(WithVolume(application, v.Name, v.Path, v.ReadOnly))
+	}
+
+	container, err := opts.Build(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+	// the run is expected to exit, which is no drift of the application
+	intended.add(container.id)
+
+	return container, nil
+}
+
+// Returns the container of the run of the job in any state.
+// Returns ErrContainerNotFound if the container does not exist.
+func FindJobRun(ctx context.Context, application string, job string, run string) (*Container, error) {
+	selected, err := SelectContainerInAnyState(
+		ctx,
+		ObjectTypeLabel(JobObject),
+		JobNameLabel(job),
+		JobRunLabel(run),
+		ApplicationNameLabel(application),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, ErrContainerNotFound
+	}
+
+	return toContainer(application, selected[0].id, nil, selected[0].labels), nil
+}
+
+// Removes the container of the run of the job, a running container is stopped first.
+func RemoveJobRun(ctx context.Context, application string, job string, run string) error {
+	container, err := FindJobRun(ctx, application, job, run)
+	if errors.Is(err, ErrContainerNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return container.Shutdown(ctx)
+}
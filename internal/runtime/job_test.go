@@ -0,0 +1,74 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestStartJobKeepsContainerOfRun(t *testing.T) {
+	fake := useFakeBackend(t)
+	ctx := context.Background()
+	state := record.New("poseidon", record.Development)
+	job := &record.JobSpec{
+		Name:    "cleanup",
+		Image:   "cleanup:v1",
+		Command: []string{"cleanup", "--all"},
+		Env:     map[string]string{"DRY_RUN": "false"},
+	}
+
+	if _, err := StartJob(ctx, state, job, "20250601T030000Z"); !errors.Is(err, ErrNetworkNotFound) {
+		t.Fatalf("expected job to require the application network, got %v", err)
+	}
+
+	_, err := CreateNewNetwork(ctx, "poseidon")
+	assert.ErrNil(err)
+	fake.AddImage("cleanup:v1")
+	assert.ErrNil(fake.ExitOnStart("cleanup:v1", 3, "nothing to clean"))
+
+	container, err := StartJob(ctx, state, job, "20250601T030000Z")
+	assert.ErrNil(err)
+	exitCode, timedOut, err := container.WaitOrStop(ctx, time.Minute)
+	assert.ErrNil(err)
+	if exitCode != 3 || timedOut {
+		t.Errorf("expected exit code of the command, got %d (timed out %v)", exitCode, timedOut)
+	}
+
+	found, err := FindJobRun(ctx, "poseidon", "cleanup", "20250601T030000Z")
+	assert.ErrNil(err)
+	inspect, err := fake.ContainerInspect(ctx, found.id)
+	assert.ErrNil(err)
+	if !slices.Equal(inspect.Config.Cmd, job.Command) || !slices.Contains(inspect.Config.Env, "DRY_RUN=false") {
+		t.Errorf("expected command and env of the job, got %v and %v", inspect.Config.Cmd, inspect.Config.Env)
+	}
+
+	assert.ErrNil(RemoveJobRun(ctx, "poseidon", "cleanup", "20250601T030000Z"))
+	if _, err := FindJobRun(ctx, "poseidon", "cleanup", "20250601T030000Z"); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("expected container of the run to be removed, got %v", err)
+	}
+	assert.ErrNil(RemoveJobRun(ctx, "poseidon", "cleanup", "20250601T030000Z"))
+}
+
+func TestWaitOrStopTimesOut(t *testing.T) {
+	fake := useFakeBackend(t)
+	ctx := context.Background()
+	_, err := CreateNewNetwork(ctx, "poseidon")
+	assert.ErrNil(err)
+	fake.AddImage("report:v1")
+
+	container, err := StartJob(ctx, record.New("poseidon", record.Development), &record.JobSpec{Name: "report", Image: "report:v1"}, "1")
+	assert.ErrNil(err)
+	exitCode, timedOut, err := container.WaitOrStop(ctx, 20*time.Millisecond)
+	assert.ErrNil(err)
+	if !timedOut || exitCode == 0 {
+		t.Errorf("expected container to be stopped after the timeout, got %d (timed out %v)", exitCode, timedOut)
+	}
+}
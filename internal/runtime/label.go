@@ -3,7 +3,11 @@
 
 package runtime
 
-import "github.com/raphaeldichler/zeus/internal/util/assert"
+import (
+	"strconv"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
 
 type Label struct {
 	key   string
@@ -16,18 +20,53 @@ const (
 	IngressObject ObjectLabel = iota + 1
 	NetworkObject
 	DNSObject
+	ServiceObject
+	VolumeObject
+	JobObject
+	// The container which publishes the ports of the ingress, the ingress containers share its network
+	IngressPortsObject
 )
 
 const (
-	labelObjectType      = "zeus.object.type"
-	labelObjectImage     = "zeus.object.image"
-	labelApplicationName = "zeus.application.name"
+	labelObjectType       = "zeus.object.type"
+	labelObjectImage      = "zeus.object.image"
+	labelApplicationName  = "zeus.application.name"
+	labelObjectHash       = "zeus.object.hash"
+	labelServiceName      = "zeus.service.name"
+	labelVolumeName       = "zeus.volume.name"
+	labelRestartPolicy    = "zeus.restart.policy"
+	labelServiceAddress   = "zeus.service.address"
+	labelServiceRole      = "zeus.service.role"
+	labelServiceContainer = "zeus.service.container"
+	labelServiceReplica   = "zeus.service.replica"
+	labelJobName          = "zeus.job.name"
+	labelJobRun           = "zeus.job.run"
 )
 
 var objectLabelMapping map[ObjectLabel]string = map[ObjectLabel]string{
-	IngressObject: "ingress",
-	NetworkObject: "network",
-	DNSObject:     "dns",
+	IngressObject:      "ingress",
+	NetworkObject:      "network",
+	DNSObject:          "dns",
+	ServiceObject:      "service",
+	VolumeObject:       "volume",
+	JobObject:          "job",
+	IngressPortsObject: "ingress-ports",
+}
+
+// ServiceRole is the part a container plays inside its service.
+type ServiceRole int
+
+const (
+	// The container of the service, which receives the traffic
+	MainContainerRole ServiceRole = iota + 1
+	InitContainerRole
+	SidecarRole
+)
+
+var serviceRoleMapping map[ServiceRole]string = map[ServiceRole]string{
+	MainContainerRole: "main",
+	InitContainerRole: "init",
+	SidecarRole:       "sidecar",
 }
 
 // zeus.object.type={object}
@@ -47,3 +86,51 @@ func ObjectImageLabel(image string) Label {
 func ApplicationNameLabel(name string) Label {
 	return Label{key: labelApplicationName, value: name}
 }
+
+// zeus.object.hash={hash}
+func ObjectHashLabel(hash string) Label {
+	return Label{key: labelObjectHash, value: hash}
+}
+
+// zeus.service.name={name}
+func ServiceNameLabel(name string) Label {
+	return Label{key: labelServiceName, value: name}
+}
+
+// zeus.volume.name={name}
+func VolumeNameLabel(name string) Label {
+	return Label{key: labelVolumeName, value: name}
+}
+
+// zeus.service.address={address}
+func ServiceAddressLabel(address string) Label {
+	return Label{key: labelServiceAddress, value: address}
+}
+
+// zeus.service.role={role}
+func ServiceRoleLabel(role ServiceRole) Label {
+	e, ok := serviceRoleMapping[role]
+	assert.True(ok, "service role must exists")
+
+	return Label{key: labelServiceRole, value: e}
+}
+
+// zeus.service.container={name}
+func ServiceContainerLabel(name string) Label {
+	return Label{key: labelServiceContainer, value: name}
+}
+
+// zeus.service.replica={replica}
+func ServiceReplicaLabel(replica int) Label {
+	return Label{key: labelServiceReplica, value: strconv.Itoa(replica)}
+}
+
+// zeus.job.name={name}
+func JobNameLabel(name string) Label {
+	return Label{key: labelJobName, value: name}
+}
+
+// zeus.job.run={run}
+func JobRunLabel(run string) Label {
+	return Label{key: labelJobRun, value: run}
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+var ErrContainerNotFound = errors.New("container not found")
+
+type LogOptions struct {
+	// Keeps streaming new output until the context is cancelled
+	Follow bool
+	// Only output since the timestamp (RFC 3339) or relative duration (e.g. 10m)
+	Since string
+	// Number of lines from the end of the logs, 'all' or empty for all lines
+	Tail string
+	// Prefixes every line with its RFC 3339 timestamp
+	Timestamps bool
+}
+
+// Streams the logs of the container and writes its stdout and stderr to the writers.
+func (self *Container) Logs(
+	ctx context.Context,
+	options LogOptions,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	stream, err := self.backend.ContainerLogs(ctx, self.id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     options.Follow,
+		Since:      options.Since,
+		Tail:       options.Tail,
+		Timestamps: options.Timestamps,
+	})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = stdcopy.StdCopy(stdout, stderr, stream)
+	if ctx.Err() != nil {
+		// the stream ends with an error if it is cancelled while following
+		return nil
+	}
+
+	return err
+}
+
+// Returns the running container of the application which carries the labels.
+// Returns ErrContainerNotFound if no such container runs.
+func FindContainer(ctx context.Context, application string, labels ...Label) (*Container, error) {
+	labels = append(labels, ApplicationNameLabel(application))
+	selected, err := SelectContainer(ctx, labels...)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, ErrContainerNotFound
+	}
+
+	return toContainer(application, selected[0].id, nil, selected[0].labels), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func startLoggingService(t *testing.T, fake *FakeBackend, application string) *Container {
+	network, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	fake.AddImage("rickroll:v1")
+	container, err := CreateNewContainer(
+		context.Background(),
+		application,
+		WithImage("rickroll:v1"),
+		WithConnectedToNetwork(network),
+		WithLabels(
+			ObjectTypeLabel(ServiceObject),
+			ServiceNameLabel("rickroll"),
+			ApplicationNameLabel(application),
+		),
+	)
+	assert.ErrNil(err)
+
+	return container
+}
+
+func TestContainerLogsDemultiplexesStreams(t *testing.T) {
+	fake := useFakeBackend(t)
+	application := "poseidon"
+	startLoggingService(t, fake, application)
+
+	container, err := FindContainer(context.Background(), application, ObjectTypeLabel(ServiceObject), ServiceNameLabel("rickroll"))
+	assert.ErrNil(err)
+	assert.ErrNil(fake.Log(container.id, false, "never gonna"))
+	assert.ErrNil(fake.Log(container.id, true, "give you up"))
+	assert.ErrNil(fake.Log(container.id, false, "let you down"))
+
+	var stdout, stderr bytes.Buffer
+	assert.ErrNil(container.Logs(context.Background(), LogOptions{}, &stdout, &stderr))
+	if stdout.String() != "never gonna\nlet you down\n" {
+		t.Errorf("expected stdout lines, got %q", stdout.String())
+	}
+	if stderr.String() != "give you up\n" {
+		t.Errorf("expected stderr lines, got %q", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	assert.ErrNil(container.Logs(context.Background(), LogOptions{Tail: "1", Timestamps: true}, &stdout, &stderr))
+	line := stdout.String()
+	if !strings.HasSuffix(line, " let you down\n") || stderr.Len() != 0 {
+		t.Errorf("expected the last line with timestamp, got %q and %q", line, stderr.String())
+	}
+	if _, err := time.Parse(time.RFC3339Nano, strings.Fields(line)[0]); err != nil {
+		t.Errorf("expected line to start with timestamp, got %q", line)
+	}
+}
+
+func TestContainerLogsFollow(t *testing.T) {
+	fake := useFakeBackend(t)
+	container := startLoggingService(t, fake, "poseidon")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stdout bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- container.Logs(ctx, LogOptions{Follow: true}, &stdout, &stdout)
+	}()
+
+	assert.ErrNil(fake.Log(container.id, false, "never gonna"))
+	time.Sleep(fakeLogPollInterval * 5)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrNil(err)
+	case <-time.After(time.Second):
+		t.Fatalf("expected followed logs to end with the context")
+	}
+	if stdout.String() != "never gonna\n" {
+		t.Errorf("expected followed line, got %q", stdout.String())
+	}
+}
+
+func TestFindContainerNotRunning(t *testing.T) {
+	useFakeBackend(t)
+	_, err := FindContainer(context.Background(), "poseidon", ObjectTypeLabel(IngressObject))
+	if !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("expected ErrContainerNotFound, got %v", err)
+	}
+}
@@ -5,85 +5,143 @@ package runtime
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"net/netip"
 
-	"github.com/docker/docker/client"
 	"github.com/raphaeldichler/zeus/internal/dnscontroller"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
 
-const NetworkDaemonName = "network"
+const (
+	NetworkDaemonName = "network"
+	networkNamePrefix = "zeus/network/"
+)
 
 func networkName(applicaiton string) string {
 	assert.StartsNotWith(applicaiton, '/', "applications cannot start with '/'")
 	assert.IsAsciiString(applicaiton, "application can only contain ascii chars")
-	return "zeus/network/" + applicaiton
+	return networkNamePrefix + applicaiton
 }
 
 type Network struct {
-	id     string
-	client *client.Client
-	name   string
+	id      string
+	backend Backend
+	name    string
+	// Subnet allocated by zeus, invalid if the container engine picked the subnet
+	subnet netip.Prefix
+	// IPv6 subnet of a dual stack network, invalid if the network is IPv4 only
+	subnetV6 netip.Prefix
+
+	dns *Container
+}
+
+type networkConfig struct {
+	// Subnet which was allocated to the application before, it is kept unless it collides
+	previousSubnet string
+	// Creates a dual stack network with an additional IPv6 subnet
+	ipv6             bool
+	previousSubnetV6 string
+}
+
+type NetworkOption func(cfg *networkConfig)
+
+// Prefers the subnet which was allocated to the application before, e.g. '10.42.0.0/16'.
+func WithPreviousSubnet(subnet string) NetworkOption {
+	return func(cfg *networkConfig) {
+		cfg.previousSubnet = subnet
+	}
+}
 
-  dns *Container
-  dnsClient dnscontroller.Client
+// Creates a dual stack network, which prefers the IPv6 subnet which was allocated to the application
+// before, e.g. 'fd12:3456:789a:2a::/64'. The subnet may be empty.
+func WithIPv6(previousSubnet string) NetworkOption {
+	return func(cfg *networkConfig) {
+		cfg.ipv6 = true
+		cfg.previousSubnetV6 = previousSubnet
+	}
 }
 
-// Interact with the docker daemon and initialises a new network. Additionally start a DNS sever inside the network.
+// Interact with the docker daemon and initialises a new network in a free subnet 10.{part}.0.0/16.
+// Additionally start a DNS sever inside the network, which answers with the static addresses of the
+// services, see syncDNSEntries.
 //
 // The network gets labeled with:
 //   - zeus.object.type=network
 //   - zeus.application.name={application}
+//
 // The DNS gets labeled with:
 //   - zeus.object.type=dns
 //   - zeus.application.name={applicaiton}
 func CreateNewNetwork(
+	ctx context.Context,
 	application string,
+	opts ...NetworkOption,
 ) (*Network, error) {
-	assert.NotNil(c, "init of docker-client failed")
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	cfg := &networkConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	subnet, err := allocateSubnet(ctx, application, cfg.previousSubnet)
+	if err != nil {
+		return nil, err
+	}
+
+	subnetV6 := netip.Prefix{}
+	if cfg.ipv6 {
+		subnetV6, err = allocateSubnetV6(ctx, application, subnet, cfg.previousSubnetV6)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	networkName := networkName(application)
-	networkId, err := createBridgedNetwork(application, networkName)
+	networkId, err := createBridgedNetwork(ctx, application, networkName, subnet, subnetV6)
+	if err != nil {
+		return nil, err
+	}
+
+	network := newNetwork(networkId, networkName, subnet, subnetV6)
+
+	dnsContainer, err := CreateNewContainer(
+		ctx,
+		application,
+		WithImage("coredns:v1"),
+		WithConnectedToNetwork(network),
+		WithLabels(
+			ObjectTypeLabel(DNSObject),
+			ApplicationNameLabel(application),
+		),
+		WithMount("/run/zeus/", "/run/zeus/"),
+		WithEnv("ZEUS_NETWORK_HASH", dnscontroller.NetworkHash(application)),
+	)
 	if err != nil {
+		// the network is left behind if its cleanup fails as well, the caller must know about both
+		if cleanupErr := network.Cleanup(ctx); cleanupErr != nil {
+			return nil, errors.Join(err, cleanupErr)
+		}
+
 		return nil, err
 	}
+	network.dns = dnsContainer
 
-  network := newNetwork(networkId, networkName)
-
-  dnsContainer, err := CreateNewContainer(
-    application,
-    WithImage("coredns:v1"),
-    WithConnectedToNetwork(network),
-    WithLabels(
-      ObjectTypeLabel(DNSObject),
-      ApplicationNameLabel(application),
-    ),
-    WithMount("/run/zeus/", "/run/zeus/"),
-  )
-  fmt.Println(dnsContainer.id)
-  if err != nil {
-    if err := network.Cleanup(); err != nil {
-      // also the network cleanup can fail, for recovery
-    }
-
-    return nil, err
-  }
-  dnsClient := dnscontroller.NewClient()
-
-  network.dns = dnsContainer
-  network.dnsClient = *dnsClient
-
-  return network, nil
+	return network, nil
 }
 
 func newNetwork(
 	id string,
 	name string,
+	subnet netip.Prefix,
+	subnetV6 netip.Prefix,
 ) *Network {
 	return &Network{
-		id:     id,
-		client: c,
-		name:   name,
+		id:       id,
+		backend:  backend,
+		name:     name,
+		subnet:   subnet,
+		subnetV6: subnetV6,
 	}
 }
 
@@ -91,7 +149,28 @@ func (self *Network) String() string {
 	return self.name
 }
 
-func (self *Network) Cleanup() error {
-	ctx := context.Background()
-	return self.client.NetworkRemove(ctx, self.id)
+// Returns the subnet of the network, e.g. '10.42.0.0/16', or an empty string if zeus did not allocate it.
+func (self *Network) Subnet() string {
+	if !self.subnet.IsValid() {
+		return ""
+	}
+
+	return self.subnet.String()
+}
+
+// Returns the IPv6 subnet of a dual stack network, e.g. 'fd12:3456:789a:2a::/64', or an empty string
+// if the network is IPv4 only.
+func (self *Network) SubnetV6() string {
+	if !isApplicationSubnetV6(self.subnetV6) {
+		return ""
+	}
+
+	return self.subnetV6.String()
+}
+
+func (self *Network) Cleanup(ctx context.Context) error {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	intended.add(self.id)
+	return self.backend.NetworkRemove(ctx, self.id)
 }
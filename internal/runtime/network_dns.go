@@ -0,0 +1,94 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"slices"
+
+	"github.com/raphaeldichler/zeus/internal/dnscontroller"
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime/errtype"
+	"google.golang.org/grpc"
+)
+
+type dnsClient interface {
+	SetDNSEntry(
+		ctx context.Context,
+		in *dnscontroller.DNSSetRequest,
+		opts ...grpc.CallOption,
+	) (*dnscontroller.DNSSetResponse, error)
+	Close() error
+}
+
+// Connects to the DNS controller of the network of the enabled application, the caller must close the client.
+var dialDNS = func() dnsClient {
+	return dnscontroller.NewClient(dnsSocketPath())
+}
+
+// Returns the socket of the DNS controller on the host, its directory is mounted into the DNS container.
+func dnsSocketPath() string {
+	return dnscontroller.SocketFileEnvironmentManager.Translate(HostPath).SocketPath()
+}
+
+// Sets the static addresses of the ready replicas of the services as the entries of the DNS of the network.
+// The hostname of a service is answered with an A record, and an AAAA record on a dual stack network, for
+// every ready replica, a service without ready replicas is not answered. Without services all entries are
+// replaced, otherwise only the ones of the services, e.g. once a rollout switched to its new containers.
+// On a network whose addresses are assigned by the container engine no service has a static address.
+func syncDNSEntries(ctx context.Context, state *record.ApplicationRecord, services ...record.RecordKey) {
+	partial := len(services) != 0
+	if !partial {
+		for _, spec := range state.Service.Services {
+			services = append(services, spec.ServiceName)
+		}
+	}
+
+	request := &dnscontroller.DNSSetRequest{
+		NetworkHash: dnscontroller.NetworkHash(state.Metadata.Application),
+		Partial:     partial,
+	}
+	for _, service := range services {
+		spec := state.Service.Get(service)
+		if spec == nil {
+			continue
+		}
+
+		// services which share their hostname are answered with the addresses of all of them
+		idx := slices.IndexFunc(request.Entries, func(e *dnscontroller.DNSSetEntryRequest) bool {
+			return e.Domain == spec.Hostname()
+		})
+		if idx == -1 {
+			idx = len(request.Entries)
+			request.Entries = append(request.Entries, &dnscontroller.DNSSetEntryRequest{
+				Domain: spec.Hostname(),
+			})
+		}
+		entry := request.Entries[idx]
+
+		for replica := range spec.ReplicaCount() {
+			if !state.Service.ReplicaReady(spec.ServiceName, replica) {
+				continue
+			}
+			if address := state.Network.ReplicaAddress(spec.ServiceName, replica); address != "" {
+				entry.IPv4 = append(entry.IPv4, address)
+			}
+			if address := state.Network.ReplicaAddressV6(spec.ServiceName, replica); address != "" {
+				entry.IPv6 = append(entry.IPv6, address)
+			}
+		}
+	}
+
+	client := dialDNS()
+	defer client.Close()
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	if _, err := client.SetDNSEntry(ctx, request); err != nil {
+		service := record.RecordKey("*")
+		if partial {
+			service = services[0]
+		}
+		state.Service.SetError(errtype.FailedInteractionWithDNS(service, err))
+	}
+}
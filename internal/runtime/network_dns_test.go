@@ -0,0 +1,277 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+	"github.com/miekg/dns"
+	"github.com/raphaeldichler/zeus/internal/dnscontroller"
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"google.golang.org/grpc"
+)
+
+// Accepts all entries, for tests which do not resolve the services.
+type discardDNS struct{}
+
+func (discardDNS) SetDNSEntry(
+	ctx context.Context,
+	in *dnscontroller.DNSSetRequest,
+	opts ...grpc.CallOption,
+) (*dnscontroller.DNSSetResponse, error) {
+	return &dnscontroller.DNSSetResponse{}, nil
+}
+
+func (discardDNS) Close() error { return nil }
+
+func useDiscardDNS(t *testing.T) {
+	previous := dialDNS
+	dialDNS = func() dnsClient { return discardDNS{} }
+	t.Cleanup(func() { dialDNS = previous })
+}
+
+// Records the requests of the runtime, for tests which check when the entries are set.
+type recordDNS struct {
+	requests *[]*dnscontroller.DNSSetRequest
+}
+
+func (self recordDNS) SetDNSEntry(
+	ctx context.Context,
+	in *dnscontroller.DNSSetRequest,
+	opts ...grpc.CallOption,
+) (*dnscontroller.DNSSetResponse, error) {
+	*self.requests = append(*self.requests, in)
+	return &dnscontroller.DNSSetResponse{}, nil
+}
+
+func (recordDNS) Close() error { return nil }
+
+func useRecordDNS(t *testing.T) *[]*dnscontroller.DNSSetRequest {
+	requests := new([]*dnscontroller.DNSSetRequest)
+	previous := dialDNS
+	dialDNS = func() dnsClient { return recordDNS{requests: requests} }
+	t.Cleanup(func() { dialDNS = previous })
+
+	return requests
+}
+
+// Returns the partial requests which set the entry of the domain.
+func partialDNSEntries(requests []*dnscontroller.DNSSetRequest, domain string) []*dnscontroller.DNSSetEntryRequest {
+	var entries []*dnscontroller.DNSSetEntryRequest
+	for _, r := range requests {
+		if !r.Partial {
+			continue
+		}
+		for _, e := range r.Entries {
+			if e.Domain == domain {
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	return entries
+}
+
+// Runs the DNS controller of the application, which the runtime syncs its entries to, on a socket of
+// the test. Returns the plugin which answers the queries.
+func useDNS(t *testing.T, application string) *dnscontroller.ZeusDns {
+	socketPath := filepath.Join(t.TempDir(), "dns.sock")
+	listener, err := net.Listen("unix", socketPath)
+	assert.ErrNil(err)
+
+	plugin := &dnscontroller.ZeusDns{}
+	controller := dnscontroller.New(plugin, dnscontroller.NetworkHash(application), listener)
+	go controller.Run()
+	t.Cleanup(func() { listener.Close() })
+
+	previous := dialDNS
+	dialDNS = func() dnsClient { return dnscontroller.NewClient(socketPath) }
+	t.Cleanup(func() { dialDNS = previous })
+
+	return plugin
+}
+
+// Returns the addresses the plugin answers the query of the name with, nil if it does not answer it.
+func resolve(t *testing.T, plugin *dnscontroller.ZeusDns, name string, qtype uint16) []string {
+	t.Helper()
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	rec := dnstest.NewRecorder(&test.ResponseWriter{})
+	if _, err := plugin.ServeDNS(context.Background(), rec, m); err != nil {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(rec.Msg.Answer))
+	for _, rr := range rec.Msg.Answer {
+		switch rr := rr.(type) {
+		case *dns.A:
+			addresses = append(addresses, rr.A.String())
+		case *dns.AAAA:
+			addresses = append(addresses, rr.AAAA.String())
+		}
+	}
+	slices.Sort(addresses)
+	return addresses
+}
+
+func TestSyncSetsDNSEntries(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	plugin := useDNS(t, application)
+	_, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+		{
+			ServiceName: "grafana",
+			Network:     &record.ServiceNetwork{Name: "dashboard"},
+			Container:   &record.ServiceContainer{Image: "grafana:v1"},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	for _, s := range selectServiceContainers(t, application) {
+		service := record.RecordKey(s.labels[labelServiceName])
+		hostname := state.Service.Get(service).Hostname()
+		address := serviceContainerAddress(t, application, s.id)
+		if got := resolve(t, plugin, hostname, dns.TypeA); !slices.Equal(got, []string{address}) {
+			t.Errorf("expected '%s' to resolve to its container address '%s', got %v", hostname, address, got)
+		}
+	}
+
+	state.Service.Services = state.Service.Services[1:]
+	Sync(context.Background(), state)
+	if got := resolve(t, plugin, "rickroll", dns.TypeA); got != nil {
+		t.Errorf("expected deleted service not to be answered, got %v", got)
+	}
+	if got := resolve(t, plugin, "dashboard", dns.TypeA); len(got) != 1 {
+		t.Errorf("expected remaining service to be answered, got %v", got)
+	}
+}
+
+func TestSyncSetsIPv6DNSEntries(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	plugin := useDNS(t, application)
+	_, err := CreateNewNetwork(context.Background(), application, WithIPv6(""))
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Network.IPv6 = true
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	selected := selectServiceContainers(t, application)
+	if len(selected) != 1 {
+		t.Fatalf("expected one service container, got %d", len(selected))
+	}
+	inspect, err := backend.ContainerInspect(context.Background(), selected[0].id)
+	assert.ErrNil(err)
+	endpoint := inspect.NetworkSettings.Networks[networkName(application)]
+
+	if got := resolve(t, plugin, "rickroll", dns.TypeAAAA); !slices.Equal(got, []string{endpoint.GlobalIPv6Address}) {
+		t.Errorf("expected AAAA answer with the container address '%s', got %v", endpoint.GlobalIPv6Address, got)
+	}
+	if got := resolve(t, plugin, "rickroll", dns.TypeA); !slices.Equal(got, []string{endpoint.IPAddress}) {
+		t.Errorf("expected A answer with the container address '%s', got %v", endpoint.IPAddress, got)
+	}
+}
+
+func TestIPv4OnlyServiceHasNoAAAAAnswer(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	plugin := useDNS(t, application)
+	_, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+	}
+
+	Sync(context.Background(), state)
+	if got := resolve(t, plugin, "rickroll", dns.TypeAAAA); got == nil || len(got) != 0 {
+		t.Errorf("expected AAAA query of an IPv4 only service to be answered without records, got %v", got)
+	}
+}
+
+func TestSyncSetsDNSEntriesOfReplicas(t *testing.T) {
+	_, state := newReplicaTestState(t, 3)
+	plugin := useDNS(t, "poseidon")
+	ctx := context.Background()
+
+	Sync(ctx, state)
+	var addresses []string = nil
+	for replica, id := range selectServiceReplicas(t) {
+		address := state.Network.ReplicaAddress("rickroll", replica)
+		if serviceContainerAddress(t, "poseidon", id) != address {
+			t.Fatalf("expected replica %d to run with address '%s'", replica, address)
+		}
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+	if got := resolve(t, plugin, "rickroll", dns.TypeA); !slices.Equal(got, addresses) {
+		t.Errorf("expected an A record for every replica %v, got %v", addresses, got)
+	}
+
+	state.Service.Services[0].Replicas = 1
+	Sync(ctx, state)
+	address := state.Network.Address("rickroll")
+	if got := resolve(t, plugin, "rickroll", dns.TypeA); !slices.Equal(got, []string{address}) {
+		t.Errorf("expected the remaining replica '%s' to be answered, got %v", address, got)
+	}
+}
+
+func TestRolloutSwitchesDNSEntry(t *testing.T) {
+	_, state := newRolloutTestState(t, "")
+	requests := useRecordDNS(t)
+
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected rollout without errors, got %v", state.Service.Errors[0])
+	}
+
+	address := state.Network.Address("rickroll")
+	entries := partialDNSEntries(*requests, "rickroll")
+	if len(entries) != 1 || !slices.Equal(entries[0].IPv4, []string{address}) {
+		t.Errorf("expected the switch to set the entry to the new address '%s', got %v", address, entries)
+	}
+}
+
+func TestRollbackRestoresDNSEntry(t *testing.T) {
+	fake, state := newRolloutTestState(t, "")
+	requests := useRecordDNS(t)
+	address := state.Network.Address("rickroll")
+
+	fake.AddImage("rickroll:v2")
+	assert.ErrNil(fake.ExitOnStart("rickroll:v2", 1, "missing config"))
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(context.Background(), state)
+	if rollout := state.Service.Rollout("rickroll"); rollout.State != record.RolloutRolledBack {
+		t.Fatalf("expected rollout to be rolled back, got %v", rollout)
+	}
+
+	entries := partialDNSEntries(*requests, "rickroll")
+	if len(entries) != 1 || !slices.Equal(entries[0].IPv4, []string{address}) {
+		t.Errorf("expected the rollback to set the entry to the origin address '%s', got %v", address, entries)
+	}
+}
@@ -6,6 +6,7 @@ package runtime
 import (
 	"context"
 	"fmt"
+	"net/netip"
 
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
@@ -13,8 +14,12 @@ import (
 )
 
 type SelectedNetwork struct {
-	name string
-	id   string
+	name     string
+	id       string
+	subnet   netip.Prefix
+	subnetV6 netip.Prefix
+	// IPv4 gateway of the network, invalid if the container engine does not report it
+	gateway netip.Addr
 }
 
 func (self *SelectedNetwork) NewNetwork(
@@ -23,7 +28,7 @@ func (self *SelectedNetwork) NewNetwork(
 	networkName := networkName(application)
 	assert.True(networkName == self.name, "selecting should use the correct labels")
 
-	return newNetwork(self.id, networkName)
+	return newNetwork(self.id, networkName, self.subnet, self.subnetV6)
 }
 
 // Selects a container by the labels if it exists. No promise about the container is made,
@@ -31,17 +36,19 @@ func (self *SelectedNetwork) NewNetwork(
 //
 // If not container exists nil is returned.
 func SelectNetworks(
+	ctx context.Context,
 	labels ...Label,
 ) ([]SelectedNetwork, error) {
-	assert.NotNil(c, "init of docker-client failed")
+	assert.True(backend != nil, "init of runtime backend failed")
 
 	args := filters.NewArgs()
 	for _, l := range labels {
 		args.Add("label", fmt.Sprintf("%s=%s", l.key, l.value))
 	}
 
-	ctx := context.Background()
-	summary, err := c.NetworkList(
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	summary, err := backend.NetworkList(
 		ctx, network.ListOptions{
 			Filters: args,
 		},
@@ -52,17 +59,26 @@ func SelectNetworks(
 
 	var result []SelectedNetwork = nil
 	for _, e := range summary {
-		result = append(result, SelectedNetwork{name: e.Name, id: e.ID})
+		subnet, subnetV6 := summarySubnets(e)
+		result = append(result, SelectedNetwork{
+			name:     e.Name,
+			id:       e.ID,
+			subnet:   subnet,
+			subnetV6: subnetV6,
+			gateway:  summaryGateway(e),
+		})
 	}
 
 	return result, nil
 }
 
 func TrySelectApplicationNetwork(
+	ctx context.Context,
 	application string,
 ) (*Network, error) {
 
 	networks, err := SelectNetworks(
+		ctx,
 		ObjectTypeLabel(NetworkObject),
 		ApplicationNameLabel(application),
 	)
@@ -84,12 +100,45 @@ func TrySelectApplicationNetwork(
 	return nil, nil
 }
 
+// Backend whose containers resolve the names of their network with a DNS which listens on the gateway of
+// the network, e.g. aardvark-dns of podman, instead of the DNS which docker embeds into the containers.
+type gatewayResolverBackend interface {
+	resolvesAtGateway() bool
+}
+
+// Address of the DNS which docker embeds into the containers of user defined networks
+const embeddedResolver = "127.0.0.11"
+
+// Returns the address of the DNS of the container engine, which resolves the names and aliases of the
+// containers inside the network of the application, e.g. to configure the resolver of nginx.
+func NetworkResolver(ctx context.Context, application string) (string, error) {
+	if b, ok := backend.(gatewayResolverBackend); !ok || !b.resolvesAtGateway() {
+		return embeddedResolver, nil
+	}
+
+	networks, err := SelectNetworks(
+		ctx,
+		ObjectTypeLabel(NetworkObject),
+		ApplicationNameLabel(application),
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(networks) != 1 || !networks[0].gateway.IsValid() {
+		return "", fmt.Errorf("the gateway of the network of application '%s' is unknown", application)
+	}
+
+	return networks[0].gateway.String(), nil
+}
+
 func SelectAllNonApplicationNetworks(
+	ctx context.Context,
 	application string,
 ) ([]*Network, error) {
-	ctx := context.Background()
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
 	args := filters.NewArgs(filters.Arg("label", labelApplicationName))
-	networks, err := c.NetworkList(ctx, network.ListOptions{
+	networks, err := backend.NetworkList(ctx, network.ListOptions{
 		Filters: args,
 	})
 	if err != nil {
@@ -103,7 +152,8 @@ func SelectAllNonApplicationNetworks(
 			continue
 		}
 
-		selected := &SelectedNetwork{name: nw.Name, id: nw.ID}
+		subnet, subnetV6 := summarySubnets(nw)
+		selected := &SelectedNetwork{name: nw.Name, id: nw.ID, subnet: subnet, subnetV6: subnetV6}
 		network := selected.NewNetwork(applicationLabel)
 
 		result = append(result, network)
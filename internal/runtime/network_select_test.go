@@ -4,6 +4,7 @@
 package runtime
 
 import (
+	"context"
 	"fmt"
 	"math/rand/v2"
 	"testing"
@@ -15,11 +16,12 @@ func TestRuntimeSelectNetwork(t *testing.T) {
 	testID := rand.IntN(1000000)
 
 	application := fmt.Sprintf("testing-%d", testID)
-	network, err := CreateNewNetwork(application)
+	network, err := CreateNewNetwork(context.Background(), application)
 	assert.ErrNil(err)
-	defer network.Cleanup()
+	defer network.Cleanup(context.Background())
 
 	selected, err := SelectNetworks(
+		context.Background(),
 		ApplicationNameLabel(application),
 		ObjectTypeLabel(NetworkObject),
 	)
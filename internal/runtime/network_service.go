@@ -5,7 +5,12 @@ package runtime
 
 import (
 	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
 
+	"github.com/raphaeldichler/zeus/internal/dnscontroller"
+	"github.com/raphaeldichler/zeus/internal/record"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
 
@@ -42,6 +47,30 @@ func (s *serviceAddressRing) next() string {
 	)
 }
 
+// Hands out the given part, e.g. an address which was assigned before. Returns false if the part
+// was already handed out.
+func (s *serviceAddressRing) take(part uint8) bool {
+	if !s.available() {
+		return false
+	}
+
+	for idx := s.index; idx < len(s.ring); idx++ {
+		if s.ring[idx] == part {
+			s.ring[idx] = s.ring[s.index]
+			s.ring[s.index] = part
+			s.index += 1
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns true if the ring can hand out another address.
+func (s *serviceAddressRing) available() bool {
+	return s.index < len(s.ring)-1
+}
+
 func (s *serviceAddressRing) returnIP(part uint8) {
 	assert.True(s.index > 0, "index ring underflow")
 
@@ -59,3 +88,184 @@ func (s *serviceAddressRing) returnIP(part uint8) {
 	s.ring[s.index] = part
 
 }
+
+// Assigns every replica of every service a static address inside the subnet of the network and stores
+// the address plan in the record. Replicas keep their address as long as they exist, the addresses of
+// deleted services and removed replicas are released. Networks whose subnet was not allocated by zeus
+// leave the addressing to the container engine.
+func assignServiceAddresses(state *record.ApplicationRecord, network *Network) {
+	state.Network.AddressesV6 = nil
+	state.Network.ReplicaAddressesV6 = nil
+	part, ok := applicationSubnetPart(network.subnet)
+	if !ok {
+		state.Network.Addresses = nil
+		state.Network.ReplicaAddresses = nil
+		return
+	}
+	if network.Subnet() != state.Network.Subnet {
+		// the addresses of another subnet are meaningless
+		state.Network.Subnet = network.Subnet()
+		state.Network.Addresses = nil
+		state.Network.ReplicaAddresses = nil
+	}
+	state.Network.SubnetV6 = network.SubnetV6()
+
+	ring := newServiceAddressRing([3]uint8{10, part, serviceAddressIdentifier})
+	// the address of the subnet itself cannot be assigned
+	ring.take(0)
+	// the replaced containers of rollouts keep their address until they are drained
+	for _, address := range drainingServiceAddresses(state) {
+		if octets := address.As4(); octets[2] == serviceAddressIdentifier {
+			ring.take(octets[3])
+		}
+	}
+
+	// the replicas are indexed, an address which is not kept leaves a gap which is filled afterwards
+	assigned := make(map[record.RecordKey][]string)
+	for _, spec := range state.Service.Services {
+		replicas := make([]string, spec.ReplicaCount())
+		for replica := range replicas {
+			previous, err := netip.ParseAddr(state.Network.ReplicaAddress(spec.ServiceName, replica))
+			if err != nil || !network.subnet.Contains(previous) {
+				continue
+			}
+			if octets := previous.As4(); octets[2] == serviceAddressIdentifier && ring.take(octets[3]) {
+				replicas[replica] = previous.String()
+			}
+		}
+		assigned[spec.ServiceName] = replicas
+	}
+	for _, spec := range state.Service.Services {
+		replicas := assigned[spec.ServiceName]
+		for replica := range replicas {
+			if replicas[replica] == "" && ring.available() {
+				replicas[replica] = ring.next()
+			}
+		}
+	}
+
+	state.Network.Addresses = make(map[record.RecordKey]string)
+	state.Network.ReplicaAddresses = make(map[record.RecordKey][]string)
+	for service, replicas := range assigned {
+		if replicas[0] != "" {
+			state.Network.Addresses[service] = replicas[0]
+		}
+		if len(replicas) > 1 {
+			state.Network.ReplicaAddresses[service] = replicas[1:]
+		}
+	}
+
+	// the IPv6 address mirrors the IPv4 address, like the AAAA answers of the DNS
+	if network.SubnetV6() != "" {
+		state.Network.AddressesV6 = make(map[record.RecordKey]string)
+		state.Network.ReplicaAddressesV6 = make(map[record.RecordKey][]string)
+		for service, replicas := range assigned {
+			for replica, address := range replicas {
+				ipv6 := ""
+				if address != "" {
+					ipv6 = dnscontroller.IPv6Of(network.subnetV6, netip.MustParseAddr(address)).String()
+				}
+				if replica == 0 {
+					if ipv6 != "" {
+						state.Network.AddressesV6[service] = ipv6
+					}
+					continue
+				}
+				state.Network.ReplicaAddressesV6[service] = append(state.Network.ReplicaAddressesV6[service], ipv6)
+			}
+		}
+	}
+}
+
+// Returns the static addresses which are held by the replaced containers of rollouts.
+func drainingServiceAddresses(state *record.ApplicationRecord) []netip.Addr {
+	var addresses []netip.Addr = nil
+	for _, rollout := range state.Service.Rollouts {
+		if len(rollout.Draining) == 0 {
+			continue
+		}
+		for _, draining := range rollout.DrainingAddresses {
+			if address, err := netip.ParseAddr(draining); err == nil {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	return addresses
+}
+
+// Returns free static addresses for the new containers of a rolling update, which run next to the
+// containers of the service until the traffic switched, one entry for each of the n containers. The
+// IPv4 address is followed by the IPv6 address of a dual stack network. Returns nil if the network
+// leaves the addressing to the container engine and false if not enough addresses are free.
+func spareServiceAddresses(state *record.ApplicationRecord, network *Network, n int) ([][]string, bool) {
+	part, ok := applicationSubnetPart(network.subnet)
+	if !ok || state.Network.Addresses == nil {
+		return nil, true
+	}
+
+	ring := newServiceAddressRing([3]uint8{10, part, serviceAddressIdentifier})
+	ring.take(0)
+	taken := drainingServiceAddresses(state)
+	for _, address := range slices.Concat(
+		slices.Collect(maps.Values(state.Network.Addresses)),
+		slices.Concat(slices.Collect(maps.Values(state.Network.ReplicaAddresses))...),
+	) {
+		if parsed, err := netip.ParseAddr(address); err == nil {
+			taken = append(taken, parsed)
+		}
+	}
+	for _, address := range taken {
+		if octets := address.As4(); octets[2] == serviceAddressIdentifier {
+			ring.take(octets[3])
+		}
+	}
+
+	spare := make([][]string, 0, n)
+	for range n {
+		if !ring.available() {
+			return nil, false
+		}
+
+		address := ring.next()
+		if network.SubnetV6() == "" {
+			spare = append(spare, []string{address})
+			continue
+		}
+		ipv6 := dnscontroller.IPv6Of(network.subnetV6, netip.MustParseAddr(address))
+		spare = append(spare, []string{address, ipv6.String()})
+	}
+
+	return spare, true
+}
+
+// Returns the static addresses of the replica of the service, the IPv4 address is followed by the IPv6
+// address of a dual stack network. Returns nil if the replica has no static address.
+func serviceAddresses(state *record.ApplicationRecord, service record.RecordKey, replica int) []string {
+	address := state.Network.ReplicaAddress(service, replica)
+	if address == "" {
+		return nil
+	}
+	if ipv6 := state.Network.ReplicaAddressV6(service, replica); ipv6 != "" {
+		return []string{address, ipv6}
+	}
+
+	return []string{address}
+}
+
+// Moves the replica of the service to the static addresses, which were returned by spareServiceAddresses.
+func moveServiceAddresses(state *record.ApplicationRecord, service record.RecordKey, replica int, addresses []string) {
+	if replica == 0 {
+		state.Network.Addresses[service] = addresses[0]
+		if len(addresses) > 1 {
+			state.Network.AddressesV6[service] = addresses[1]
+		}
+		return
+	}
+
+	// the address plan holds an entry for every replica of the service
+	state.Network.ReplicaAddresses[service][replica-1] = addresses[0]
+	if len(addresses) > 1 {
+		state.Network.ReplicaAddressesV6[service][replica-1] = addresses[1]
+	}
+}
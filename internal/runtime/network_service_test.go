@@ -3,7 +3,14 @@
 
 package runtime
 
-import "testing"
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
 
 func TestServiceAddressRing(t *testing.T) {
 	ring := newServiceAddressRing([3]uint8{10, 10, 10})
@@ -21,3 +28,132 @@ func TestServiceAddressRing(t *testing.T) {
 		t.Error("first address is not correct")
 	}
 }
+
+func TestServiceAddressRingTake(t *testing.T) {
+	ring := newServiceAddressRing([3]uint8{10, 10, 10})
+
+	if !ring.take(0) || !ring.take(2) {
+		t.Fatal("expected free parts to be taken")
+	}
+	if ring.take(2) {
+		t.Error("expected taken part not to be taken twice")
+	}
+	if next := ring.next(); next == "10.10.10.0" || next == "10.10.10.2" {
+		t.Errorf("expected taken part not to be handed out, got '%s'", next)
+	}
+
+	for ring.available() {
+		ring.next()
+	}
+	if ring.take(255) {
+		t.Error("expected exhausted ring to hand out nothing")
+	}
+}
+
+// Returns the address of the container inside the application network.
+func serviceContainerAddress(t *testing.T, application string, containerID string) string {
+	inspect, err := backend.ContainerInspect(context.Background(), containerID)
+	assert.ErrNil(err)
+	endpoint, ok := inspect.NetworkSettings.Networks[networkName(application)]
+	if !ok {
+		t.Fatalf("expected container to be connected to the application network")
+	}
+
+	return endpoint.IPAddress
+}
+
+func TestSyncAssignsStaticServiceAddresses(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	nw, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+	subnet := netip.MustParsePrefix(nw.Subnet())
+
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+		{ServiceName: "grafana", Container: &record.ServiceContainer{Image: "grafana:v1"}},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	if state.Network.Subnet != nw.Subnet() {
+		t.Errorf("expected subnet '%s' to be stored, got '%s'", nw.Subnet(), state.Network.Subnet)
+	}
+
+	selected := selectServiceContainers(t, application)
+	if len(selected) != 2 {
+		t.Fatalf("expected two service containers, got %d", len(selected))
+	}
+	for _, s := range selected {
+		service := record.RecordKey(s.labels[labelServiceName])
+		address := state.Network.Address(service)
+		if !subnet.Contains(netip.MustParseAddr(address)) || netip.MustParseAddr(address).As4()[2] != serviceAddressIdentifier {
+			t.Errorf("expected address of '%s' inside the service range of '%s', got '%s'", service, subnet, address)
+		}
+		if got := serviceContainerAddress(t, application, s.id); got != address {
+			t.Errorf("expected container of '%s' to use address '%s', got '%s'", service, address, got)
+		}
+	}
+	if state.Network.Address("rickroll") == state.Network.Address("grafana") {
+		t.Errorf("expected services to get distinct addresses")
+	}
+
+	grafana := state.Network.Address("grafana")
+	state.Service.Services = state.Service.Services[1:]
+	state.Service.Services[0].Container.Image = "grafana:v2"
+	Sync(context.Background(), state)
+	if got := state.Network.Address("grafana"); got != grafana {
+		t.Errorf("expected replaced service to keep address '%s', got '%s'", grafana, got)
+	}
+	if _, ok := state.Network.Addresses["rickroll"]; ok {
+		t.Errorf("expected address of deleted service to be released")
+	}
+}
+
+func TestSyncAssignsIPv6ServiceAddresses(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	nw, err := CreateNewNetwork(context.Background(), application, WithIPv6(""))
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Network.IPv6 = true
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	if state.Network.SubnetV6 != nw.SubnetV6() {
+		t.Errorf("expected IPv6 subnet '%s' to be stored, got '%s'", nw.SubnetV6(), state.Network.SubnetV6)
+	}
+
+	address := netip.MustParseAddr(state.Network.Address("rickroll"))
+	ipv6 := state.Network.AddressV6("rickroll")
+	expected := netip.MustParsePrefix(nw.SubnetV6()).Addr().As16()
+	expected[15] = address.As4()[3]
+	if ipv6 != netip.AddrFrom16(expected).String() {
+		t.Errorf("expected IPv6 address to mirror '%s', got '%s'", address, ipv6)
+	}
+
+	selected := selectServiceContainers(t, application)
+	if len(selected) != 1 {
+		t.Fatalf("expected one service container, got %d", len(selected))
+	}
+	inspect, err := backend.ContainerInspect(context.Background(), selected[0].id)
+	assert.ErrNil(err)
+	if got := inspect.NetworkSettings.Networks[networkName(application)].GlobalIPv6Address; got != ipv6 {
+		t.Errorf("expected container to use IPv6 address '%s', got '%s'", ipv6, got)
+	}
+
+	first := selected[0].id
+	Sync(context.Background(), state)
+	if selected := selectServiceContainers(t, application); len(selected) != 1 || selected[0].id != first {
+		t.Errorf("expected unchanged service to keep its container")
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/netip"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/raphaeldichler/zeus/internal/dnscontroller"
+)
+
+// The application network uses the subnet 10.{part}.0.0/16, the DNS of the network answers with the
+// static addresses of the services. The third octet separates the addresses:
+//   - 10.{part}.0.x static addresses of the services
+//   - 10.{part}.255.x addresses the container engine assigns to the gateway, the DNS, and the ingress
+//
+// Dual stack networks additionally use a unique local IPv6 subnet fd{global id}:{subnet id}::/64, whose
+// global id is derived from the application. The IPv6 addresses mirror the IPv4 addresses, the last two
+// octets of the IPv4 address form the last 16 bits, e.g. 10.{part}.0.7 and fd{global id}:{subnet id}::7.
+const (
+	serviceAddressIdentifier uint8 = 0
+	dynamicAddressIdentifier uint8 = 255
+)
+
+var (
+	ErrNoFreeSubnet = errors.New("no free subnet for the application network")
+
+	// Returns the subnets of the interfaces of the host, replaced by tests
+	hostSubnets = func() ([]netip.Prefix, error) {
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			return nil, err
+		}
+
+		var result []netip.Prefix = nil
+		for _, addr := range addrs {
+			if prefix, err := netip.ParsePrefix(addr.String()); err == nil {
+				result = append(result, prefix.Masked())
+			}
+		}
+
+		return result, nil
+	}
+)
+
+// Returns the subnet 10.{part}.0.0/16.
+func applicationSubnet(part uint8) netip.Prefix {
+	return netip.PrefixFrom(netip.AddrFrom4([4]byte{10, part, 0, 0}), 16)
+}
+
+// Returns the unique local subnet fd{global id}:{id}::/64 of the application.
+func applicationSubnetV6(application string, id uint16) netip.Prefix {
+	sum := sha256.Sum256([]byte(application))
+
+	var octets [16]byte
+	octets[0] = 0xfd
+	copy(octets[1:6], sum[:5])
+	binary.BigEndian.PutUint16(octets[6:8], id)
+
+	return netip.PrefixFrom(netip.AddrFrom16(octets), 64)
+}
+
+// Reports if the subnet is a unique local subnet as allocated by zeus.
+func isApplicationSubnetV6(subnet netip.Prefix) bool {
+	return subnet.IsValid() && subnet.Addr().Is6() && subnet.Bits() == 64 && subnet.Addr().As16()[0] == 0xfd
+}
+
+// Returns the second octet of the subnet if it was allocated by zeus, otherwise false.
+func applicationSubnetPart(subnet netip.Prefix) (uint8, bool) {
+	if !subnet.IsValid() || subnet.Bits() != 16 || !subnet.Addr().Is4() {
+		return 0, false
+	}
+	octets := subnet.Addr().As4()
+	if octets[0] != 10 || octets[1] < 1 || octets[1] > 254 {
+		return 0, false
+	}
+
+	return octets[1], true
+}
+
+// Returns the address management of the subnets, the container engine only assigns addresses of the
+// dynamic range and the services get their addresses assigned explicitly. The IPv6 subnet is only
+// used if it is valid.
+func subnetIPAM(subnet netip.Prefix, subnetV6 netip.Prefix) *network.IPAM {
+	part, ok := applicationSubnetPart(subnet)
+	if !ok {
+		return nil
+	}
+
+	gateway := netip.AddrFrom4([4]byte{10, part, dynamicAddressIdentifier, 254})
+	ipam := &network.IPAM{
+		Driver: "default",
+		Config: []network.IPAMConfig{
+			{
+				Subnet:  subnet.String(),
+				IPRange: netip.PrefixFrom(netip.AddrFrom4([4]byte{10, part, dynamicAddressIdentifier, 0}), 24).String(),
+				Gateway: gateway.String(),
+			},
+		},
+	}
+	if isApplicationSubnetV6(subnetV6) {
+		dynamic := dnscontroller.IPv6Of(subnetV6, netip.AddrFrom4([4]byte{10, part, dynamicAddressIdentifier, 0}))
+		ipam.Config = append(ipam.Config, network.IPAMConfig{
+			Subnet:  subnetV6.String(),
+			IPRange: netip.PrefixFrom(dynamic, 120).String(),
+			Gateway: dnscontroller.IPv6Of(subnetV6, gateway).String(),
+		})
+	}
+
+	return ipam
+}
+
+// Returns the IPv4 and the IPv6 subnet of the network, which are invalid if the container engine
+// picked them or the network is IPv4 only.
+func summarySubnets(summary network.Summary) (netip.Prefix, netip.Prefix) {
+	var subnet, subnetV6 netip.Prefix
+	for _, config := range summary.IPAM.Config {
+		prefix, err := netip.ParsePrefix(config.Subnet)
+		if err != nil {
+			continue
+		}
+		if prefix.Addr().Is4() {
+			subnet = prefix
+		} else {
+			subnetV6 = prefix
+		}
+	}
+
+	return subnet, subnetV6
+}
+
+// Returns the IPv4 gateway of the network, which is invalid if the container engine does not report it.
+func summaryGateway(summary network.Summary) netip.Addr {
+	for _, config := range summary.IPAM.Config {
+		gateway, err := netip.ParseAddr(config.Gateway)
+		if err == nil && gateway.Is4() {
+			return gateway
+		}
+	}
+
+	return netip.Addr{}
+}
+
+// Returns the subnets which are already used by networks of the container engine or interfaces of the host.
+func usedSubnets(ctx context.Context) ([]netip.Prefix, error) {
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	networks, err := backend.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := hostSubnets()
+	if err != nil {
+		return nil, err
+	}
+	for _, nw := range networks {
+		for _, config := range nw.IPAM.Config {
+			if subnet, err := netip.ParsePrefix(config.Subnet); err == nil {
+				used = append(used, subnet.Masked())
+			}
+		}
+	}
+
+	return used, nil
+}
+
+// Returns the previous subnet if it is valid and free, otherwise the first free candidate.
+func firstFreeSubnet(
+	used []netip.Prefix,
+	previous string,
+	valid func(netip.Prefix) bool,
+	candidates int,
+	candidate func(idx int) netip.Prefix,
+) (netip.Prefix, error) {
+	free := func(subnet netip.Prefix) bool {
+		for _, u := range used {
+			if u.Overlaps(subnet) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if subnet, err := netip.ParsePrefix(previous); err == nil && valid(subnet) && free(subnet) {
+		return subnet, nil
+	}
+	for idx := range candidates {
+		if subnet := candidate(idx); free(subnet) {
+			return subnet, nil
+		}
+	}
+
+	return netip.Prefix{}, ErrNoFreeSubnet
+}
+
+// Allocates the subnet of the application network. The previous subnet of the application is kept,
+// otherwise the subnet is derived from the network hash the DNS uses. A subnet which collides with
+// another network or an interface of the host is skipped in favour of the next one.
+func allocateSubnet(ctx context.Context, application string, previous string) (netip.Prefix, error) {
+	used, err := usedSubnets(ctx)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	preferred := dnscontroller.NetworkHashToIpPart(dnscontroller.NetworkHash(application))
+	valid := func(subnet netip.Prefix) bool {
+		_, ok := applicationSubnetPart(subnet)
+		return ok
+	}
+
+	return firstFreeSubnet(used, previous, valid, 254, func(offset int) netip.Prefix {
+		return applicationSubnet(uint8((int(preferred)-1+offset)%254 + 1))
+	})
+}
+
+// Allocates the IPv6 subnet of a dual stack application network like its IPv4 subnet, the subnet id
+// starts at the second octet of the IPv4 subnet.
+func allocateSubnetV6(
+	ctx context.Context,
+	application string,
+	subnet netip.Prefix,
+	previous string,
+) (netip.Prefix, error) {
+	used, err := usedSubnets(ctx)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	part, _ := applicationSubnetPart(subnet)
+	return firstFreeSubnet(used, previous, isApplicationSubnetV6, 1<<16, func(offset int) netip.Prefix {
+		return applicationSubnetV6(application, uint16(int(part)+offset))
+	})
+}
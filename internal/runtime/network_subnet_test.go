@@ -0,0 +1,161 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/raphaeldichler/zeus/internal/dnscontroller"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestCreateNewNetworkAllocatesSubnetOfNetworkHash(t *testing.T) {
+	fake := useFakeBackend(t)
+
+	nw, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	preferred := applicationSubnet(dnscontroller.NetworkHashToIpPart(dnscontroller.NetworkHash("poseidon")))
+	if nw.Subnet() != preferred.String() {
+		t.Errorf("expected subnet '%s' derived from the network hash, got '%s'", preferred, nw.Subnet())
+	}
+
+	selected, err := TrySelectApplicationNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	if selected.Subnet() != nw.Subnet() {
+		t.Errorf("expected selected network to report subnet '%s', got '%s'", nw.Subnet(), selected.Subnet())
+	}
+
+	dns, err := SelectContainer(context.Background(), ObjectTypeLabel(DNSObject))
+	assert.ErrNil(err)
+	inspect, err := fake.ContainerInspect(context.Background(), dns[0].id)
+	assert.ErrNil(err)
+	if !slices.Contains(inspect.Config.Env, "ZEUS_NETWORK_HASH="+dnscontroller.NetworkHash("poseidon")) {
+		t.Errorf("expected DNS to serve the network of the application, got env %v", inspect.Config.Env)
+	}
+}
+
+func TestCreateNewNetworkSkipsUsedSubnets(t *testing.T) {
+	fake := useFakeBackend(t)
+	preferred := applicationSubnet(dnscontroller.NetworkHashToIpPart(dnscontroller.NetworkHash("poseidon")))
+
+	// a network of another tool and an interface of the host use the preferred and the next subnet
+	_, err := fake.NetworkCreate(context.Background(), "compose_default", nil, &network.IPAM{
+		Config: []network.IPAMConfig{{Subnet: preferred.String()}},
+	})
+	assert.ErrNil(err)
+	part, _ := applicationSubnetPart(preferred)
+	next := applicationSubnet(part%254 + 1)
+	hostSubnets = func() ([]netip.Prefix, error) {
+		return []netip.Prefix{netip.PrefixFrom(next.Addr().Next(), 24).Masked()}, nil
+	}
+
+	nw, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	expected := applicationSubnet((part+1)%254 + 1)
+	if nw.Subnet() != expected.String() {
+		t.Errorf("expected first free subnet '%s', got '%s'", expected, nw.Subnet())
+	}
+}
+
+func TestCreateNewNetworkKeepsPreviousSubnet(t *testing.T) {
+	useFakeBackend(t)
+
+	nw, err := CreateNewNetwork(context.Background(), "poseidon", WithPreviousSubnet("10.42.0.0/16"))
+	assert.ErrNil(err)
+	if nw.Subnet() != "10.42.0.0/16" {
+		t.Errorf("expected previous subnet to be kept, got '%s'", nw.Subnet())
+	}
+
+	hostSubnets = func() ([]netip.Prefix, error) {
+		return []netip.Prefix{netip.MustParsePrefix("10.43.0.0/16")}, nil
+	}
+	nw, err = CreateNewNetwork(context.Background(), "hermes", WithPreviousSubnet("10.43.0.0/16"))
+	assert.ErrNil(err)
+	if nw.Subnet() == "10.43.0.0/16" {
+		t.Errorf("expected colliding previous subnet to be replaced")
+	}
+}
+
+func TestCreateNewNetworkReportsFailedCleanup(t *testing.T) {
+	fake := useFakeBackend(t)
+	createErr := errors.New("no space left on device")
+	removeErr := errors.New("network has active endpoints")
+	fake.Fail("ContainerCreate", createErr)
+	fake.Fail("NetworkRemove", removeErr)
+
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	if !errors.Is(err, createErr) || !errors.Is(err, removeErr) {
+		t.Errorf("expected the failed DNS container and the failed cleanup to be reported, got %q", err)
+	}
+}
+
+func TestCreateNewNetworkWithIPv6(t *testing.T) {
+	useFakeBackend(t)
+
+	nw, err := CreateNewNetwork(context.Background(), "poseidon", WithIPv6(""))
+	assert.ErrNil(err)
+	subnetV6 := netip.MustParsePrefix(nw.SubnetV6())
+	if !isApplicationSubnetV6(subnetV6) {
+		t.Fatalf("expected unique local subnet, got '%s'", subnetV6)
+	}
+
+	selected, err := TrySelectApplicationNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	if selected.SubnetV6() != nw.SubnetV6() {
+		t.Errorf("expected selected network to report IPv6 subnet '%s', got '%s'", nw.SubnetV6(), selected.SubnetV6())
+	}
+
+	// a recreated network keeps its subnet, another application gets a different one
+	assert.ErrNil(nw.dns.Shutdown(context.Background()))
+	assert.ErrNil(nw.Cleanup(context.Background()))
+	recreated, err := CreateNewNetwork(context.Background(), "poseidon", WithIPv6(nw.SubnetV6()))
+	assert.ErrNil(err)
+	if recreated.SubnetV6() != nw.SubnetV6() {
+		t.Errorf("expected recreated network to keep IPv6 subnet '%s', got '%s'", nw.SubnetV6(), recreated.SubnetV6())
+	}
+	other, err := CreateNewNetwork(context.Background(), "hermes", WithIPv6(""))
+	assert.ErrNil(err)
+	if netip.MustParsePrefix(other.SubnetV6()).Overlaps(subnetV6) {
+		t.Errorf("expected applications to get distinct IPv6 subnets, got '%s' twice", subnetV6)
+	}
+
+	ipv4only, err := CreateNewNetwork(context.Background(), "zeus")
+	assert.ErrNil(err)
+	if ipv4only.SubnetV6() != "" {
+		t.Errorf("expected IPv4 only network without IPv6 subnet, got '%s'", ipv4only.SubnetV6())
+	}
+}
+
+// Fake of a backend whose DNS listens on the gateway of the network, like aardvark-dns of podman.
+type gatewayResolverFake struct {
+	*FakeBackend
+}
+
+func (gatewayResolverFake) resolvesAtGateway() bool { return true }
+
+func TestNetworkResolver(t *testing.T) {
+	useFakeBackend(t)
+	nw, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	if resolver, err := NetworkResolver(context.Background(), "poseidon"); err != nil || resolver != "127.0.0.11" {
+		t.Errorf("expected the embedded DNS of docker, got '%s' (%v)", resolver, err)
+	}
+
+	SetBackend(gatewayResolverFake{backend.(*FakeBackend)})
+	part, _ := applicationSubnetPart(netip.MustParsePrefix(nw.Subnet()))
+	expected := netip.AddrFrom4([4]byte{10, part, 255, 254}).String()
+	if resolver, err := NetworkResolver(context.Background(), "poseidon"); err != nil || resolver != expected {
+		t.Errorf("expected the DNS on the gateway '%s', got '%s' (%v)", expected, resolver, err)
+	}
+	if _, err := NetworkResolver(context.Background(), "hermes"); err == nil {
+		t.Errorf("expected the resolver of an application without network to be unknown")
+	}
+}
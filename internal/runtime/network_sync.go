@@ -3,15 +3,363 @@
 
 package runtime
 
-import "github.com/raphaeldichler/zeus/internal/record"
+import (
+	"context"
+	"slices"
+	"time"
 
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime/errtype"
+)
 
 // Syncs the network and ensures that all required containers are running to maintain the application state.
-func Sync(state *record.ApplicationRecord) {
+//
+// For every replica of a service specification exactly one container and one container for every sidecar
+// is running. The init containers of a service run to completion before the containers of its replicas are
+// started. Containers whose specification changed are replaced, by a rollout if the service uses the rolling
+// strategy, and containers of services which no longer exist or of replicas beyond the count of the service
+// are shut down. Containers which are drained after a rollout are left to Drain.
+// Exited containers are removed and restarted according to the restart policy of their service, see
+// serviceMayStart. A sidecar shares the lifecycle of its service, if it exits the service is restarted.
+// Afterwards the DNS of the network answers the hostnames of the services with their static addresses.
+// Once the context is done no further service is synced.
+func Sync(ctx context.Context, state *record.ApplicationRecord) {
 	log := state.Logger("runtime-daemon")
 	log.Info("Starting syncing runtime daemon")
 	defer log.Info("Completed syncing runtime daemon")
 
+	application := state.Metadata.Application
+	state.Service.Errors = nil
+	state.Service.Status = nil
 
+	network, err := TrySelectApplicationNetwork(ctx, application)
+	if err != nil {
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon("*", errtype.DockerSelectContainer, err),
+		)
+		return
+	}
+	if network == nil {
+		log.Error("No application network exists, cannot sync services")
+		return
+	}
+	assignServiceAddresses(state, network)
 
+	selected, err := SelectContainerInAnyState(
+		ctx,
+		ObjectTypeLabel(ServiceObject),
+		ApplicationNameLabel(application),
+	)
+	if err != nil {
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon("*", errtype.DockerSelectContainer, err),
+		)
+		return
+	}
+
+	// containers of deleted services are shut down with the others, even if they are drained
+	draining := make(map[string]bool)
+	for service, rollout := range state.Service.Rollouts {
+		if state.Service.Get(service) == nil {
+			continue
+		}
+		for _, id := range rollout.Draining {
+			draining[id] = true
+		}
+	}
+
+	running := make(map[record.RecordKey][]*Container)
+	exited := make(map[record.RecordKey][]*Container)
+	for _, s := range selected {
+		if draining[s.id] {
+			continue
+		}
+		container, err := s.NewContainer(ctx, application)
+		if err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon("*", errtype.DockerSelectContainer, err),
+			)
+			return
+		}
+
+		// init containers only run while a service is started, the ones left over are stale
+		if container.label(labelServiceRole) == serviceRoleMapping[InitContainerRole] {
+			removeStaleInitContainer(ctx, state, container, s.running)
+			continue
+		}
+
+		service := record.RecordKey(container.label(labelServiceName))
+		if s.running {
+			running[service] = append(running[service], container)
+		} else {
+			exited[service] = append(exited[service], container)
+		}
+	}
+
+	now := time.Now()
+	for idx := range state.Service.Services {
+		if ctx.Err() != nil {
+			log.Info("Syncing runtime daemon was cancelled")
+			return
+		}
+
+		spec := &state.Service.Services[idx]
+		containers := running[spec.ServiceName]
+		delete(running, spec.ServiceName)
+
+		if !syncServiceExits(ctx, state, spec, exited[spec.ServiceName]) {
+			continue
+		}
+		delete(exited, spec.ServiceName)
+
+		replicas, surplus := groupServiceReplicas(spec, containers)
+		if len(surplus) != 0 {
+			log.Info("Scale service '%s' down to %d replicas", spec.ServiceName, spec.ReplicaCount())
+			if !shutdownServiceContainers(ctx, state, spec.ServiceName, surplus) {
+				continue
+			}
+		}
+
+		var stale []int = nil
+		for replica := range replicas {
+			if main, ok := serviceContainersCurrent(state, spec, replica, replicas[replica]); ok {
+				syncServiceHealth(ctx, state, spec, replica, main, false)
+			} else {
+				stale = append(stale, replica)
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+
+		if origins := rolloutOrigins(ctx, spec, replicas, stale); len(origins) != 0 {
+			switch {
+			case state.Service.RolledBack(spec):
+				// the previous containers keep serving until the specification changes again
+				syncOriginStatus(ctx, state, spec.ServiceName, origins)
+			case !rollOutService(ctx, state, network, spec, origins, replicas):
+				origins = nil
+			}
+			stale = slices.DeleteFunc(stale, func(replica int) bool { return origins[replica] != nil })
+			if len(stale) == 0 {
+				continue
+			}
+		}
+
+		// the specification or the address changed, the replica is started the first time or it has no
+		// container which keeps serving, in all cases the stale containers are replaced by new ones
+		var replaced []*Container = nil
+		for _, replica := range stale {
+			replaced = append(replaced, replicas[replica]...)
+		}
+		if !shutdownServiceContainers(ctx, state, spec.ServiceName, replaced) {
+			continue
+		}
+
+		if !serviceMayStart(state, spec, stale, now) {
+			continue
+		}
+
+		// volumes are created before the container and outlive it
+		if err := ensureServiceVolumes(ctx, state.Metadata.Application, spec); err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateVolume, err),
+			)
+			continue
+		}
+
+		if failure, err := runServiceInitContainers(ctx, state, network, spec); err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateContainer, err),
+			)
+			continue
+		} else if failure != nil {
+			// the failed init container is recorded like an exit of the service
+			state.Service.SetError(errtype.FailedInitContainer(spec.ServiceName, failure.name, failure.exitCode))
+			if err := recordServiceExit(ctx, state, spec, failure.container); err != nil {
+				state.Service.SetError(
+					errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateContainer, err),
+				)
+				continue
+			}
+			// records the state of the replicas whose init container failed
+			serviceMayStart(state, spec, stale, time.Now())
+			continue
+		}
+
+		// all replicas are started before they are awaited, which lets them become ready side by side
+		created := make(map[int]*Container)
+		for _, replica := range stale {
+			log.Info(
+				"Create container for replica %d of service '%s' with image '%s'",
+				replica, spec.ServiceName, spec.Container.Image,
+			)
+			container, err := createServiceContainer(
+				ctx, state, network, spec, replica, serviceAddresses(state, spec.ServiceName, replica),
+			)
+			if err == nil {
+				err = createServiceSidecars(ctx, state, spec, replica, container)
+			}
+			if err != nil {
+				state.Service.SetError(
+					errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateContainer, err),
+				)
+				break
+			}
+			log.Info("Replica %d of service '%s' runs in container '%s'", replica, spec.ServiceName, container)
+			created[replica] = container
+		}
+		for _, replica := range stale {
+			if container, ok := created[replica]; ok {
+				syncServiceHealth(ctx, state, spec, replica, container, true)
+			}
+		}
+	}
+
+	for service, containers := range running {
+		log.Info("Remove containers of deleted service '%s'", service)
+		shutdownServiceContainers(ctx, state, service, containers)
+	}
+	for service, containers := range exited {
+		if state.Service.Get(service) == nil {
+			syncServiceExits(ctx, state, nil, containers)
+		}
+	}
+	for service := range state.Service.Crashes {
+		if spec := state.Service.Get(service); spec == nil || state.Service.Crash(spec) == nil {
+			delete(state.Service.Crashes, service)
+		}
+	}
+
+	syncDNSEntries(ctx, state)
+}
+
+// Records the exit of the container which runs the current specification of the service and removes
+// all exited containers. Exited containers of older specifications or deleted services are only removed.
+// Returns false if at least one container could not be removed.
+func syncServiceExits(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	containers []*Container,
+) bool {
+	ok := true
+	for _, container := range containers {
+		var err error
+		if spec != nil && container.label(labelObjectHash) == spec.Hash() {
+			err = recordServiceExit(ctx, state, spec, container)
+		} else {
+			err = container.Remove(ctx)
+		}
+		if err != nil {
+			service := record.RecordKey(container.label(labelServiceName))
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(service, errtype.DockerStopContainer, err),
+			)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// Records the health of the container of the replica and ensures that only healthy containers are reachable
+// by the hostname of the service. A newly created container is awaited until it is ready.
+func syncServiceHealth(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	replica int,
+	container *Container,
+	created bool,
+) {
+	status := record.ServiceStatus{
+		ContainerID: container.id,
+		Health:      record.ServiceHealthNone,
+		State:       record.ServiceStateRunning,
+	}
+	defer func() { state.Service.SetReplicaStatus(spec.ServiceName, replica, status) }()
+
+	check := serviceHealthCheck(spec)
+	if check == nil {
+		return
+	}
+
+	var (
+		health string
+		err    error
+	)
+	if created {
+		health, err = container.WaitHealthy(ctx, check.readinessTimeout())
+	} else {
+		health, err = container.Health(ctx)
+	}
+	if err != nil {
+		status.Health = record.ServiceHealthUnhealthy
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerInspectContainer, err),
+		)
+		return
+	}
+
+	status.Health = health
+	if health == record.ServiceHealthUnhealthy {
+		state.Service.SetError(errtype.UnhealthyService(spec.ServiceName, health))
+	}
+
+	healthy := health == record.ServiceHealthHealthy
+	if err := container.ensureNetworkAlias(ctx, spec.Hostname(), healthy); err != nil {
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateNetwork, err),
+		)
+	}
+}
+
+// Shuts all containers down, the sidecars before the container whose network they share. Returns false
+// if at least one container could not be stopped.
+func shutdownServiceContainers(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	service record.RecordKey,
+	containers []*Container,
+) bool {
+	containers = slices.Clone(containers)
+	slices.SortStableFunc(containers, func(a, b *Container) int {
+		sidecar := serviceRoleMapping[SidecarRole]
+		switch {
+		case a.label(labelServiceRole) == sidecar && b.label(labelServiceRole) != sidecar:
+			return -1
+		case a.label(labelServiceRole) != sidecar && b.label(labelServiceRole) == sidecar:
+			return 1
+		}
+		return 0
+	})
+
+	ok := true
+	for _, container := range containers {
+		if err := container.Shutdown(ctx); err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(service, errtype.DockerStopContainer, err),
+			)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// Removes the init container which was left over by an interrupted start of its service.
+func removeStaleInitContainer(ctx context.Context, state *record.ApplicationRecord, container *Container, running bool) {
+	var err error
+	if running {
+		err = container.Shutdown(ctx)
+	} else {
+		err = container.Remove(ctx)
+	}
+	if err != nil {
+		service := record.RecordKey(container.label(labelServiceName))
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon(service, errtype.DockerStopContainer, err),
+		)
+	}
 }
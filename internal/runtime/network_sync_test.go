@@ -0,0 +1,172 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func useFakeBackend(t *testing.T) *FakeBackend {
+	fake := NewFakeBackend()
+	fake.AddImage("coredns:v1")
+	previous := SetBackend(fake)
+	t.Cleanup(func() { SetBackend(previous) })
+	// the allocated subnets must not depend on the interfaces of the machine running the tests
+	previousHostSubnets := hostSubnets
+	hostSubnets = func() ([]netip.Prefix, error) { return nil, nil }
+	t.Cleanup(func() { hostSubnets = previousHostSubnets })
+	useDiscardDNS(t)
+
+	return fake
+}
+
+func selectServiceContainers(t *testing.T, application string) []SelectedContainer {
+	selected, err := SelectContainer(
+		context.Background(),
+		ObjectTypeLabel(ServiceObject),
+		ApplicationNameLabel(application),
+	)
+	assert.ErrNil(err)
+
+	return selected
+}
+
+func TestSyncReconcilesServices(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	_, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{PortMapping: map[string]string{"application": "8000"}},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1"},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	selected := selectServiceContainers(t, application)
+	if len(selected) != 1 {
+		t.Fatalf("expected one service container, got %d", len(selected))
+	}
+	first := selected[0].id
+
+	Sync(context.Background(), state)
+	selected = selectServiceContainers(t, application)
+	if len(selected) != 1 || selected[0].id != first {
+		t.Errorf("expected unchanged service to keep its container")
+	}
+
+	// the changed service is rolled out, the previous container is stopped once it is drained
+	useDrainPeriod(t, 0)
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(context.Background(), state)
+	Drain(context.Background(), state)
+	selected = selectServiceContainers(t, application)
+	if len(selected) != 1 || selected[0].id == first {
+		t.Fatalf("expected changed service to be replaced by a new container")
+	}
+	if image := selected[0].labels[labelObjectImage]; image != "rickroll:v2" {
+		t.Errorf("expected container to run image 'rickroll:v2', got '%s'", image)
+	}
+
+	state.Service.Services = nil
+	Sync(context.Background(), state)
+	if selected := selectServiceContainers(t, application); len(selected) != 0 {
+		t.Errorf("expected containers of deleted service to be removed, got %d", len(selected))
+	}
+}
+
+func TestSyncRecordsBackendErrors(t *testing.T) {
+	fake := useFakeBackend(t)
+	application := "poseidon"
+	_, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	fake.Fail("ImagePull", ErrFakeNotFound)
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+	}
+
+	Sync(context.Background(), state)
+	if state.Service.NoErrors() {
+		t.Fatalf("expected failed pull to be recorded")
+	}
+	if state.Service.Errors[0].Service != "rickroll" {
+		t.Errorf("expected error of service 'rickroll', got '%s'", state.Service.Errors[0].Service)
+	}
+}
+
+func TestSyncGatesUnhealthyServices(t *testing.T) {
+	fake := useFakeBackend(t)
+	application := "poseidon"
+	_, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{PortMapping: map[string]string{"application": "8000"}},
+			Container: &record.ServiceContainer{
+				Image: "rickroll:v1",
+				Health: &record.ServiceHealth{
+					Probe:    record.HealthProbeHTTP,
+					Path:     "/healthz",
+					Port:     "8000",
+					Interval: time.Second,
+					Timeout:  time.Second,
+					Retries:  1,
+				},
+			},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	if !state.Service.Ready("rickroll") {
+		t.Fatalf("expected healthy service to be ready")
+	}
+	selected := selectServiceContainers(t, application)
+	if len(selected) != 1 {
+		t.Fatalf("expected one service container, got %d", len(selected))
+	}
+	id := selected[0].id
+
+	aliases := func() []string {
+		inspect, err := fake.ContainerInspect(context.Background(), id)
+		assert.ErrNil(err)
+		return inspect.NetworkSettings.Networks[networkNamePrefix+application].Aliases
+	}
+	if !slices.Contains(aliases(), "rickroll") {
+		t.Errorf("expected healthy service to be reachable by its hostname, got %v", aliases())
+	}
+
+	assert.ErrNil(fake.SetHealth(id, HealthUnhealthy))
+	Sync(context.Background(), state)
+	if state.Service.Ready("rickroll") {
+		t.Errorf("expected unhealthy service not to be ready")
+	}
+	if state.Service.NoErrors() {
+		t.Errorf("expected unhealthy service to be recorded as error")
+	}
+	if slices.Contains(aliases(), "rickroll") {
+		t.Errorf("expected unhealthy service not to be reachable by its hostname, got %v", aliases())
+	}
+}
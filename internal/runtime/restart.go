@@ -0,0 +1,121 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime/errtype"
+)
+
+const (
+	// Backoff after the first exit of a container, it doubles with every consecutive exit
+	crashBackoffBase = time.Second * 10
+	// Upper limit of the backoff between two restarts
+	crashBackoffMax = time.Minute * 5
+	// A container which ran at least this long before it exited is not considered crash looping
+	crashResetAfter = time.Minute * 10
+	// Number of lines of the output which are kept of an exited container
+	crashLogTail = 20
+)
+
+// Returns the backoff before the container is restarted after its n-th consecutive exit.
+func crashBackoff(restarts int) time.Duration {
+	backoff := crashBackoffBase
+	for i := 1; i < restarts && backoff < crashBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, crashBackoffMax)
+}
+
+// Records the exit of the service container and removes it. The consecutive exits of the service
+// are counted as long as the container exits shortly after it was started.
+func recordServiceExit(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	container *Container,
+) error {
+	inspect, err := container.Inspect(ctx)
+	if err != nil {
+		return err
+	}
+
+	startedAt, _ := time.Parse(time.RFC3339Nano, inspect.State.StartedAt)
+	exitedAt, err := time.Parse(time.RFC3339Nano, inspect.State.FinishedAt)
+	if err != nil || exitedAt.IsZero() {
+		exitedAt = time.Now()
+	}
+
+	// the output is best effort, a missing log tail must not keep the container from being restarted
+	var output bytes.Buffer
+	logsCtx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	container.Logs(logsCtx, LogOptions{Tail: strconv.Itoa(crashLogTail)}, &output, &output)
+	var logs []string = nil
+	if text := strings.TrimRight(output.String(), "\n"); text != "" {
+		logs = strings.Split(text, "\n")
+	}
+
+	restarts := 1
+	if crash := state.Service.Crash(spec); crash != nil && exitedAt.Sub(startedAt) < crashResetAfter {
+		restarts = crash.Restarts + 1
+	}
+
+	state.Service.SetCrash(spec.ServiceName, record.ServiceCrash{
+		Hash:         spec.Hash(),
+		Container:    container.label(labelServiceContainer),
+		Restarts:     restarts,
+		ExitCode:     inspect.State.ExitCode,
+		ExitedAt:     exitedAt,
+		Logs:         logs,
+		BackoffUntil: exitedAt.Add(crashBackoff(restarts)),
+	})
+
+	return container.Remove(ctx)
+}
+
+// Reports if new containers may be started for the replicas of the service. The replicas of a service
+// whose container exited are only restarted if its restart policy permits it and its backoff passed,
+// otherwise the state of the replicas is recorded.
+func serviceMayStart(state *record.ApplicationRecord, spec *record.ServiceSpec, replicas []int, now time.Time) bool {
+	crash := state.Service.Crash(spec)
+	if crash == nil {
+		return true
+	}
+
+	status := record.ServiceStatus{Health: record.ServiceHealthNone, State: record.ServiceStateExited}
+	setStatus := func() {
+		for _, replica := range replicas {
+			state.Service.SetReplicaStatus(spec.ServiceName, replica, status)
+		}
+	}
+
+	switch spec.Container.RestartPolicy() {
+	case record.RestartNever:
+		setStatus()
+		return false
+
+	case record.RestartOnFailure:
+		if crash.ExitCode == 0 {
+			setStatus()
+			return false
+		}
+	}
+
+	if now.Before(crash.BackoffUntil) {
+		status.State = record.ServiceStateCrashLoopBackOff
+		setStatus()
+		state.Service.SetError(errtype.CrashLoopingService(spec.ServiceName, crash.ExitCode, crash.Restarts))
+		return false
+	}
+
+	return true
+}
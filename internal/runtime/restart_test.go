@@ -0,0 +1,153 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestCrashBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		3:  40 * time.Second,
+		6:  5 * time.Minute,
+		50: 5 * time.Minute,
+	}
+	for restarts, expected := range tests {
+		if got := crashBackoff(restarts); got != expected {
+			t.Errorf("expected backoff %s after %d restarts, got %s", expected, restarts, got)
+		}
+	}
+}
+
+func newRestartTestState(t *testing.T, policy string) (*FakeBackend, *record.ApplicationRecord) {
+	fake := useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{PortMapping: map[string]string{"application": "8000"}},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1", Restart: policy},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	return fake, state
+}
+
+// Lets the running container of the service exit and returns the ID of the exited container.
+func exitServiceContainer(t *testing.T, fake *FakeBackend, exitCode int) string {
+	selected := selectServiceContainers(t, "poseidon")
+	if len(selected) != 1 {
+		t.Fatalf("expected one running service container, got %d", len(selected))
+	}
+	assert.ErrNil(fake.Log(selected[0].id, true, "panic: database unreachable"))
+	assert.ErrNil(fake.Exit(selected[0].id, exitCode))
+
+	return selected[0].id
+}
+
+func TestSyncBacksOffCrashLoopingService(t *testing.T) {
+	fake, state := newRestartTestState(t, "")
+
+	exitServiceContainer(t, fake, 1)
+	Sync(context.Background(), state)
+
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 0 {
+		t.Fatalf("expected crashed service not to be restarted during its backoff, got %d containers", len(selected))
+	}
+	if exited, _ := SelectContainerInAnyState(context.Background(), ObjectTypeLabel(ServiceObject)); len(exited) != 0 {
+		t.Errorf("expected exited container to be removed, got %d", len(exited))
+	}
+	if state := state.Service.Status["rickroll"].State; state != record.ServiceStateCrashLoopBackOff {
+		t.Errorf("expected state '%s', got '%s'", record.ServiceStateCrashLoopBackOff, state)
+	}
+	if state.Service.NoErrors() || state.Service.Ready("rickroll") {
+		t.Errorf("expected crash looping service to be reported and not ready")
+	}
+	crash := state.Service.Crash(&state.Service.Services[0])
+	if crash == nil || crash.Restarts != 1 || crash.ExitCode != 1 {
+		t.Fatalf("expected crash to be recorded, got %v", crash)
+	}
+	if !slices.Contains(crash.Logs, "panic: database unreachable") {
+		t.Errorf("expected log tail of the exited container, got %v", crash.Logs)
+	}
+	if next, ok := state.Service.NextRestart(time.Now()); !ok || !next.Equal(crash.BackoffUntil) {
+		t.Errorf("expected next restart at %s, got %s", crash.BackoffUntil, next)
+	}
+
+	crash.BackoffUntil = time.Now().Add(-time.Second)
+	Sync(context.Background(), state)
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 1 {
+		t.Fatalf("expected service to be restarted after its backoff")
+	}
+	if state := state.Service.Status["rickroll"].State; state != record.ServiceStateRunning {
+		t.Errorf("expected state '%s', got '%s'", record.ServiceStateRunning, state)
+	}
+
+	exitServiceContainer(t, fake, 2)
+	Sync(context.Background(), state)
+	crash = state.Service.Crash(&state.Service.Services[0])
+	if crash == nil || crash.Restarts != 2 || crash.ExitCode != 2 {
+		t.Fatalf("expected consecutive crash to be counted, got %v", crash)
+	}
+	if backoff := crash.BackoffUntil.Sub(crash.ExitedAt); backoff != 20*time.Second {
+		t.Errorf("expected backoff to double, got %s", backoff)
+	}
+
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(context.Background(), state)
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 1 {
+		t.Fatalf("expected changed specification to be started immediately")
+	}
+	if len(state.Service.Crashes) != 0 {
+		t.Errorf("expected crash of the old specification to be forgotten, got %v", state.Service.Crashes)
+	}
+}
+
+func TestSyncRespectsRestartPolicy(t *testing.T) {
+	tests := []struct {
+		policy   string
+		exitCode int
+		state    string
+	}{
+		{policy: record.RestartOnFailure, exitCode: 0, state: record.ServiceStateExited},
+		{policy: record.RestartOnFailure, exitCode: 1, state: record.ServiceStateCrashLoopBackOff},
+		{policy: record.RestartNever, exitCode: 1, state: record.ServiceStateExited},
+		{policy: record.RestartAlways, exitCode: 0, state: record.ServiceStateCrashLoopBackOff},
+	}
+	for _, test := range tests {
+		fake, state := newRestartTestState(t, test.policy)
+
+		exitServiceContainer(t, fake, test.exitCode)
+		Sync(context.Background(), state)
+		if got := state.Service.Status["rickroll"].State; got != test.state {
+			t.Errorf("expected policy '%s' with exit code %d to result in '%s', got '%s'", test.policy, test.exitCode, test.state, got)
+		}
+
+		state.Service.Crashes["rickroll"].BackoffUntil = time.Time{}
+		Sync(context.Background(), state)
+		restarted := len(selectServiceContainers(t, "poseidon")) == 1
+		if restarted != (test.state == record.ServiceStateCrashLoopBackOff) {
+			t.Errorf("expected policy '%s' with exit code %d to restart the container: %t", test.policy, test.exitCode, !restarted)
+		}
+		if test.state == record.ServiceStateExited && !state.Service.NoErrors() {
+			t.Errorf("expected stopped service not to be reported as error, got %v", state.Service.Errors[0])
+		}
+	}
+}
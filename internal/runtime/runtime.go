@@ -5,95 +5,94 @@ package runtime
 
 import (
 	"context"
-	"io"
+	"fmt"
+	"net/netip"
 
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
-	"github.com/docker/docker/client"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 )
 
 var (
-	c *client.Client = nil
+	backend Backend = nil
 )
 
 func init() {
-	cli, err := client.NewClientWithOpts(
-		client.WithAPIVersionNegotiation(),
-	)
+	b, err := NewDockerBackend()
 	assert.ErrNil(err)
-	c = cli
+	backend = b
 }
 
-func pull(
-	imageRef string,
-) error {
-	ctx := context.Background()
-	_, err := c.ImageInspect(
-		ctx,
-		imageRef,
-	)
-	if err == nil {
-		return nil
-	}
+// Replaces the backend which is used for all following interactions and returns the previous one.
+//
+// Containers and networks which were obtained before keep using the previous backend. The removals
+// which were intended on the previous backend are forgotten, its IDs may be used again by the backend.
+func SetBackend(b Backend) Backend {
+	assert.True(b != nil, "backend must not be nil")
+	previous := backend
+	backend = b
+	intended.reset()
+
+	return previous
+}
 
-	r, err := c.ImagePull(
-		ctx,
-		imageRef,
-		image.PullOptions{},
-	)
-	if err != nil {
-		return err
-	}
+// Backend whose containers mount other paths of the host than the requested ones, see HostPath.
+type hostPathBackend interface {
+	hostPath(path string) string
+}
 
-	_, err = io.Copy(io.Discard, r)
-	assert.ErrNil(err)
+// Returns the path of the host which the backend mounts for the path, e.g. rootless podman mounts
+// $XDG_RUNTIME_DIR/zeus for /run/zeus. The daemon must use it for all paths it shares with containers.
+func HostPath(path string) string {
+	if b, ok := backend.(hostPathBackend); ok {
+		return b.hostPath(path)
+	}
 
-	err = r.Close()
-	assert.ErrNil(err)
+	return path
+}
 
-	return nil
+func pull(
+	ctx context.Context,
+	imageRef string,
+	options PullOptions,
+) error {
+	return PullImage(ctx, imageRef, options)
 }
 
 func create(
+	ctx context.Context,
 	cfg *container.Config,
 	hostCfg *container.HostConfig,
 	networkCfg *network.NetworkingConfig,
 ) (string, error) {
-	ctx := context.Background()
-
-	cont, err := c.ContainerCreate(
-		ctx, cfg, hostCfg, networkCfg, nil, "",
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	return cont.ID, nil
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	return backend.ContainerCreate(ctx, cfg, hostCfg, networkCfg)
 }
 
+// Starts the container, a failed start is retried with a backoff.
 func start(
+	ctx context.Context,
 	containerID string,
-	retry int,
+	attempts int,
 ) error {
-	var err error = nil
-	ctx := context.Background()
-	for range retry {
-		err = c.ContainerStart(ctx, containerID, container.StartOptions{})
-		if err == nil {
-			return nil
-		}
+	err := retry(ctx, attempts, func(ctx context.Context) error {
+		return backend.ContainerStart(ctx, containerID)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrContainterCannotStart, err)
 	}
 
-	return ErrContainterCannotStart
+	return nil
 }
 
 func existsContaienr(
+	ctx context.Context,
 	containerID string,
 ) (bool, error) {
-	ctx := context.Background()
-	summary, err := c.ContainerList(ctx, container.ListOptions{All: true})
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	summary, err := backend.ContainerList(ctx, container.ListOptions{All: true})
 	if err != nil {
 		return false, err
 	}
@@ -108,23 +107,21 @@ func existsContaienr(
 }
 
 func createBridgedNetwork(
+	ctx context.Context,
 	application string,
 	networkName string,
+	subnet netip.Prefix,
+	subnetV6 netip.Prefix,
 ) (string, error) {
-	ctx := context.Background()
-	created, err := c.NetworkCreate(
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	return backend.NetworkCreate(
 		ctx,
 		networkName,
-		network.CreateOptions{
-			Labels: map[string]string{
-				"zeus.object.type":      objectLabelMapping[NetworkObject],
-				"zeus.application.name": application,
-			},
+		map[string]string{
+			labelObjectType:      objectLabelMapping[NetworkObject],
+			labelApplicationName: application,
 		},
+		subnetIPAM(subnet, subnetV6),
 	)
-	if err != nil {
-		return "", err
-	}
-
-	return created.ID, nil
 }
@@ -0,0 +1,195 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+const (
+	envDeploymentType = "ZEUS_DEPLOYMENT_TYPE"
+	envPorts          = "ZEUS_PORTS"
+)
+
+// application@8000:grafana@3000
+func portsEnvValue(portMapping map[string]string) string {
+	var ports []string = nil
+	for _, name := range slices.Sorted(maps.Keys(portMapping)) {
+		ports = append(ports, name+"@"+portMapping[name])
+	}
+
+	return strings.Join(ports, ":")
+}
+
+// Returns the health check of the service or nil if the service defines none.
+func serviceHealthCheck(spec *record.ServiceSpec) *HealthCheck {
+	health := spec.Container.Health
+	if health == nil {
+		return nil
+	}
+
+	check := &HealthCheck{
+		Interval:    health.Interval,
+		Timeout:     health.Timeout,
+		StartPeriod: health.StartPeriod,
+		Retries:     health.Retries,
+	}
+	switch health.Probe {
+	case record.HealthProbeExec:
+		check.Test = ExecProbe(health.Command...)
+	case record.HealthProbeHTTP:
+		check.Test = HTTPProbe(health.Port, health.Path, health.Timeout)
+	case record.HealthProbeTCP:
+		check.Test = TCPProbe(health.Port, health.Timeout)
+	default:
+		assert.Unreachable("cover all health probes")
+	}
+
+	return check
+}
+
+// Creates and starts the container of the replica of the service inside the application network with
+// the static addresses, the engine assigns an address if there are none.
+//
+// The container gets labeled with:
+//   - zeus.object.type=service
+//   - zeus.object.image={image}
+//   - zeus.object.hash={hash of the specification}
+//   - zeus.service.name={service}
+//   - zeus.service.role=main
+//   - zeus.service.replica={replica}
+//   - zeus.application.name={application}
+//   - zeus.restart.policy={policy}
+//   - zeus.service.address={addresses}, if the service has static addresses, comma separated
+func createServiceContainer(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	network *Network,
+	spec *record.ServiceSpec,
+	replica int,
+	addresses []string,
+) (*Container, error) {
+	assert.NotNil(spec.Container, "service must define a container")
+	assert.NotNil(network, "network must exist before services are created")
+
+	application := state.Metadata.Application
+	opts := NewContainerOptions()
+	opts.Add(
+		WithImage(spec.Container.ImageReference()),
+		WithPulling(),
+		WithConnectedToNetwork(network),
+		WithLabels(
+			ObjectTypeLabel(ServiceObject),
+			ObjectImageLabel(spec.Container.Image),
+			ObjectHashLabel(spec.Hash()),
+			ServiceNameLabel(string(spec.ServiceName)),
+			ServiceRoleLabel(MainContainerRole),
+			ServiceReplicaLabel(replica),
+			ApplicationNameLabel(application),
+		),
+		WithEnv(envDeploymentType, strings.ToUpper(state.Metadata.Deployment.String())),
+		WithRestartPolicy(spec.Container.RestartPolicy()),
+	)
+
+	// the addresses match the entry of the service in the DNS of the network
+	if addresses != nil {
+		opts.Add(
+			WithNetworkAddresses(addresses...),
+			WithLabels(ServiceAddressLabel(strings.Join(addresses, ","))),
+		)
+	}
+
+	// private images are pulled with the credential of their registry
+	if credential := state.Registry(RegistryOfImage(spec.Container.Image)); credential != nil {
+		opts.Add(WithRegistryAuth(RegistryAuth(*credential)))
+	}
+
+	// services with a health check become reachable by their hostname once they are healthy, the hostname
+	// resolves to all replicas which are reachable
+	if check := serviceHealthCheck(spec); check != nil {
+		opts.Add(WithHealthCheck(*check))
+	} else {
+		opts.Add(WithNetworkAliases(spec.Hostname()))
+	}
+
+	if spec.Network != nil {
+		opts.Add(WithEnv(envPorts, portsEnvValue(spec.Network.PortMapping)))
+		for _, port := range spec.Network.PortMapping {
+			opts.Add(WithInternalTcpPort(port))
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(spec.Container.Env)) {
+		opts.Add(WithEnv(key, spec.Container.Env[key]))
+	}
+
+	for _, v := range spec.Container.Volumes {
+		opts.Add(WithVolume(application, v.Name, v.Path, v.ReadOnly))
+	}
+
+	opts.Add(serviceResourceOptions(spec.Container.Resources)...)
+	opts.Add(serviceSecurityOptions(spec.Container.Security)...)
+
+	return opts.Build(ctx, application)
+}
+
+func serviceResourceOptions(resources *record.ServiceResources) []ContainerOption {
+	if resources == nil {
+		return nil
+	}
+
+	opts := []ContainerOption{}
+	if resources.Memory > 0 {
+		opts = append(opts, WithMemoryLimit(resources.Memory))
+	}
+	if resources.NanoCPUs > 0 {
+		opts = append(opts, WithCPULimit(resources.NanoCPUs))
+	}
+	if resources.Pids > 0 {
+		opts = append(opts, WithPidsLimit(resources.Pids))
+	}
+
+	return opts
+}
+
+func serviceSecurityOptions(security *record.ServiceSecurity) []ContainerOption {
+	if security == nil {
+		return nil
+	}
+
+	opts := []ContainerOption{}
+	if security.ReadOnlyRootFilesystem {
+		opts = append(opts, WithReadOnlyRootFilesystem())
+	}
+	if len(security.DropCapabilities) != 0 {
+		opts = append(opts, WithDroppedCapabilities(security.DropCapabilities...))
+	}
+	if security.NoNewPrivileges {
+		opts = append(opts, WithNoNewPrivileges())
+	}
+	if security.User != "" {
+		opts = append(opts, WithUser(security.User, security.Group))
+	}
+	for _, tmpfs := range security.Tmpfs {
+		opts = append(opts, WithTmpfs(tmpfs.Path, tmpfs.Size))
+	}
+
+	return opts
+}
+
+func ensureServiceVolumes(ctx context.Context, application string, spec *record.ServiceSpec) error {
+	for _, v := range spec.Volumes() {
+		if err := EnsureVolume(ctx, application, v.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+// Returns the options which init containers and sidecars of the service share.
+//
+// The container gets labeled with:
+//   - zeus.object.type=service
+//   - zeus.object.image={image}
+//   - zeus.object.hash={hash of the specification of the service}
+//   - zeus.service.name={service}
+//   - zeus.service.role={role}
+//   - zeus.service.container={name}
+//   - zeus.application.name={application}
+//   - zeus.restart.policy={policy}
+func auxiliaryContainerOptions(
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	aux *record.ServiceAuxiliaryContainer,
+	role ServiceRole,
+	restartPolicy string,
+) *ContainerOptions {
+	application := state.Metadata.Application
+	opts := NewContainerOptions()
+	opts.Add(
+		WithImage(aux.ImageReference()),
+		WithPulling(),
+		WithLabels(
+			ObjectTypeLabel(ServiceObject),
+			ObjectImageLabel(aux.Image),
+			ObjectHashLabel(spec.Hash()),
+			ServiceNameLabel(string(spec.ServiceName)),
+			ServiceRoleLabel(role),
+			ServiceContainerLabel(aux.Name),
+			ApplicationNameLabel(application),
+		),
+		WithEnv(envDeploymentType, strings.ToUpper(state.Metadata.Deployment.String())),
+		// the container is kept after it exited, which preserves its exit code and output
+		WithRestartPolicy(restartPolicy),
+	)
+	if len(aux.Command) != 0 {
+		opts.Add(WithCmd(aux.Command...))
+	}
+
+	if credential := state.Registry(RegistryOfImage(aux.Image)); credential != nil {
+		opts.Add(WithRegistryAuth(RegistryAuth(*credential)))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(aux.Env)) {
+		opts.Add(WithEnv(key, aux.Env[key]))
+	}
+
+	for _, v := range aux.Volumes {
+		opts.Add(WithVolume(application, v.Name, v.Path, v.ReadOnly))
+	}
+
+	return opts
+}
+
+// Init container which failed or did not exit in time, it is kept with its exit code and output.
+type initContainerFailure struct {
+	container *Container
+	name      string
+	exitCode  int
+}
+
+// Runs the init containers of the service one after another, each until it exited. Returns the init
+// container which failed or did not exit in time, the container of the service must not be started.
+// Returns nil if all of them exited successfully.
+func runServiceInitContainers(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	network *Network,
+	spec *record.ServiceSpec,
+) (*initContainerFailure, error) {
+	log := state.Logger("runtime-daemon")
+	for idx := range spec.InitContainers {
+		aux := &spec.InitContainers[idx]
+		log.Info("Run init container '%s' of service '%s' with image '%s'", aux.Name, spec.ServiceName, aux.Image)
+
+		opts := auxiliaryContainerOptions(state, spec, aux, InitContainerRole, record.RestartNever)
+		// init containers reach the other services, e.g. to check the schema of a database
+		opts.Add(WithConnectedToNetwork(network))
+		container, err := opts.Build(ctx, state.Metadata.Application)
+		if err != nil {
+			return nil, err
+		}
+		// the init container is expected to exit, which is no drift of the application
+		intended.add(container.id)
+
+		exitCode, timedOut, err := container.WaitOrStop(ctx, initContainerTimeout)
+		if err != nil {
+			container.Remove(context.WithoutCancel(ctx))
+			return nil, err
+		}
+		if timedOut {
+			// the init container is recorded like a failed one
+			log.Error("Init container '%s' of service '%s' did not exit in time", aux.Name, spec.ServiceName)
+		}
+
+		if exitCode == 0 {
+			if err := container.Remove(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return &initContainerFailure{container: container, name: aux.Name, exitCode: exitCode}, nil
+	}
+
+	return nil, nil
+}
+
+// Creates and starts the sidecars of the replica of the service, which share the network of the container
+// of the replica. The sidecars are labeled with zeus.service.replica={replica} as well.
+func createServiceSidecars(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	replica int,
+	main *Container,
+) error {
+	assert.NotNil(spec.Container, "service must define a container")
+
+	for idx := range spec.Sidecars {
+		aux := &spec.Sidecars[idx]
+		opts := auxiliaryContainerOptions(state, spec, aux, SidecarRole, spec.Container.RestartPolicy())
+		opts.Add(WithNetworkOf(main), WithLabels(ServiceReplicaLabel(replica)))
+		if _, err := opts.Build(ctx, state.Metadata.Application); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reports if the containers of the replica are the ones of the current specification of the service:
+// exactly one container of the service with the current address of the replica and one container for
+// every sidecar.
+func serviceContainersCurrent(
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	replica int,
+	containers []*Container,
+) (*Container, bool) {
+	if len(containers) != 1+len(spec.Sidecars) {
+		return nil, false
+	}
+
+	var main *Container = nil
+	sidecars := make(map[string]bool)
+	for _, container := range containers {
+		if container.label(labelObjectHash) != spec.Hash() {
+			return nil, false
+		}
+
+		switch container.label(labelServiceRole) {
+		case serviceRoleMapping[MainContainerRole]:
+			if main != nil ||
+				container.label(labelServiceAddress) != strings.Join(serviceAddresses(state, spec.ServiceName, replica), ",") {
+				return nil, false
+			}
+			main = container
+		case serviceRoleMapping[SidecarRole]:
+			sidecars[container.label(labelServiceContainer)] = true
+		default:
+			return nil, false
+		}
+	}
+	for _, aux := range spec.Sidecars {
+		if !sidecars[aux.Name] {
+			return nil, false
+		}
+	}
+
+	return main, main != nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func newAuxiliaryTestState(t *testing.T) (*FakeBackend, *record.ApplicationRecord) {
+	fake := useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	fake.AddImage("migrate:v1")
+	assert.ErrNil(fake.ExitOnStart("migrate:v1", 0, "schema is up to date"))
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{PortMapping: map[string]string{"application": "8000"}},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1"},
+			InitContainers: []record.ServiceAuxiliaryContainer{
+				{
+					Name:    "migrate",
+					Image:   "migrate:v1",
+					Command: []string{"migrate", "--check"},
+					Volumes: []record.ServiceVolume{{Name: "assets", Path: "/assets"}},
+				},
+			},
+			Sidecars: []record.ServiceAuxiliaryContainer{
+				{Name: "shipper", Image: "shipper:v1", Env: map[string]string{"TARGET": "loki"}},
+			},
+		},
+	}
+
+	return fake, state
+}
+
+// Returns the running containers of the service by their role.
+func selectServiceRoles(t *testing.T, role ServiceRole) []SelectedContainer {
+	selected, err := SelectContainer(
+		context.Background(),
+		ObjectTypeLabel(ServiceObject),
+		ApplicationNameLabel("poseidon"),
+		ServiceRoleLabel(role),
+	)
+	assert.ErrNil(err)
+
+	return selected
+}
+
+func TestSyncRunsInitContainersAndSidecars(t *testing.T) {
+	fake, state := newAuxiliaryTestState(t)
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	main := selectServiceRoles(t, MainContainerRole)
+	sidecars := selectServiceRoles(t, SidecarRole)
+	if len(main) != 1 || len(sidecars) != 1 {
+		t.Fatalf("expected container and sidecar of the service, got %d and %d", len(main), len(sidecars))
+	}
+	if init, _ := SelectContainerInAnyState(context.Background(), ServiceRoleLabel(InitContainerRole)); len(init) != 0 {
+		t.Errorf("expected init container to be removed after it completed, got %d", len(init))
+	}
+	if volumes, _ := SelectVolumes(context.Background(), "poseidon"); len(volumes) != 1 {
+		t.Errorf("expected volume of the init container to be created, got %v", volumes)
+	}
+
+	inspect, err := fake.ContainerInspect(context.Background(), sidecars[0].id)
+	assert.ErrNil(err)
+	if mode := inspect.HostConfig.NetworkMode; mode.ConnectedContainer() != main[0].id {
+		t.Errorf("expected sidecar to share the network of the container, got '%s'", mode)
+	}
+	if !slices.Contains(inspect.Config.Env, "TARGET=loki") || sidecars[0].labels[labelServiceContainer] != "shipper" {
+		t.Errorf("expected sidecar to be configured by its specification, got %v", inspect.Config.Env)
+	}
+
+	Sync(context.Background(), state)
+	if again := selectServiceRoles(t, MainContainerRole); len(again) != 1 || again[0].id != main[0].id {
+		t.Errorf("expected unchanged service to be kept")
+	}
+}
+
+func TestSyncBlocksServiceOnFailedInitContainer(t *testing.T) {
+	fake, state := newAuxiliaryTestState(t)
+	assert.ErrNil(fake.ExitOnStart("migrate:v1", 3, "schema is outdated"))
+
+	Sync(context.Background(), state)
+	if len(selectServiceContainers(t, "poseidon")) != 0 {
+		t.Fatalf("expected service not to be started after its init container failed")
+	}
+	if init, _ := SelectContainerInAnyState(context.Background(), ServiceRoleLabel(InitContainerRole)); len(init) != 0 {
+		t.Errorf("expected failed init container to be removed, got %d", len(init))
+	}
+	crash := state.Service.Crash(&state.Service.Services[0])
+	if crash == nil || crash.Container != "migrate" || crash.ExitCode != 3 {
+		t.Fatalf("expected failed init container to be recorded, got %v", crash)
+	}
+	if !slices.Contains(crash.Logs, "schema is outdated") {
+		t.Errorf("expected output of the init container, got %v", crash.Logs)
+	}
+	if state := state.Service.Status["rickroll"].State; state != record.ServiceStateCrashLoopBackOff {
+		t.Errorf("expected state '%s', got '%s'", record.ServiceStateCrashLoopBackOff, state)
+	}
+	types := make([]string, 0)
+	for _, e := range state.Service.Errors {
+		types = append(types, e.Type)
+	}
+	if !slices.Contains(types, "FailedInitContainer") {
+		t.Errorf("expected failed init container to be reported, got %v", types)
+	}
+
+	assert.ErrNil(fake.ExitOnStart("migrate:v1", 0))
+	Sync(context.Background(), state)
+	if len(selectServiceContainers(t, "poseidon")) != 0 {
+		t.Fatalf("expected init container not to be retried during the backoff")
+	}
+
+	crash.BackoffUntil = time.Now().Add(-time.Second)
+	Sync(context.Background(), state)
+	if main := selectServiceRoles(t, MainContainerRole); len(main) != 1 {
+		t.Fatalf("expected service to be started once its init container succeeded")
+	}
+}
+
+func TestSyncRestartsServiceWithExitedSidecar(t *testing.T) {
+	fake, state := newAuxiliaryTestState(t)
+	Sync(context.Background(), state)
+
+	sidecars := selectServiceRoles(t, SidecarRole)
+	assert.ErrNil(fake.Exit(sidecars[0].id, 1))
+	Sync(context.Background(), state)
+
+	if running := selectServiceContainers(t, "poseidon"); len(running) != 0 {
+		t.Fatalf("expected service to be stopped with its sidecar, got %d containers", len(running))
+	}
+	crash := state.Service.Crash(&state.Service.Services[0])
+	if crash == nil || crash.Container != "shipper" || crash.ExitCode != 1 {
+		t.Fatalf("expected exit of the sidecar to be recorded, got %v", crash)
+	}
+
+	crash.BackoffUntil = time.Now().Add(-time.Second)
+	Sync(context.Background(), state)
+	if len(selectServiceRoles(t, MainContainerRole)) != 1 || len(selectServiceRoles(t, SidecarRole)) != 1 {
+		t.Errorf("expected service to be restarted with its sidecar after the backoff")
+	}
+}
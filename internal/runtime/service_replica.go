@@ -0,0 +1,38 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"strconv"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+)
+
+// Returns the replica of the service the container belongs to. Containers which were created before the
+// services had replicas carry no replica label, they belong to the first replica.
+func (self *Container) replica() int {
+	replica, err := strconv.Atoi(self.label(labelServiceReplica))
+	if err != nil || replica < 0 {
+		return 0
+	}
+
+	return replica
+}
+
+// Groups the containers of the service by their replica. Returns the containers of every replica of the
+// specification and the containers of replicas beyond, which are left over after the service was scaled down.
+func groupServiceReplicas(spec *record.ServiceSpec, containers []*Container) ([][]*Container, []*Container) {
+	replicas := make([][]*Container, spec.ReplicaCount())
+	var surplus []*Container = nil
+	for _, container := range containers {
+		replica := container.replica()
+		if replica >= len(replicas) {
+			surplus = append(surplus, container)
+			continue
+		}
+		replicas[replica] = append(replicas[replica], container)
+	}
+
+	return replicas, surplus
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func newReplicaTestState(t *testing.T, replicas int) (*FakeBackend, *record.ApplicationRecord) {
+	fake, state := newRolloutTestState(t, "")
+	state.Service.Services[0].Replicas = replicas
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	return fake, state
+}
+
+// Returns the container id of every replica of the service.
+func selectServiceReplicas(t *testing.T) map[int]string {
+	replicas := make(map[int]string)
+	for _, s := range selectServiceRoles(t, MainContainerRole) {
+		container, err := s.NewContainer(context.Background(), "poseidon")
+		assert.ErrNil(err)
+		if _, ok := replicas[container.replica()]; ok {
+			t.Fatalf("expected a single container of replica %d", container.replica())
+		}
+		replicas[container.replica()] = container.id
+	}
+
+	return replicas
+}
+
+func sameElements(a []string, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+func TestSyncScalesService(t *testing.T) {
+	_, state := newReplicaTestState(t, 3)
+	ctx := context.Background()
+
+	replicas := selectServiceReplicas(t)
+	if len(replicas) != 3 {
+		t.Fatalf("expected a container for every replica, got %v", replicas)
+	}
+	addresses := make(map[string]bool)
+	for replica, id := range replicas {
+		address := state.Network.ReplicaAddress("rickroll", replica)
+		if address == "" || addresses[address] || serviceContainerAddress(t, "poseidon", id) != address {
+			t.Errorf("expected replica %d to run with its own static address, got '%s'", replica, address)
+		}
+		addresses[address] = true
+		if !state.Service.ReplicaReady("rickroll", replica) {
+			t.Errorf("expected replica %d to be ready", replica)
+		}
+	}
+
+	// scaling keeps the containers of the remaining replicas
+	state.Service.Services[0].Replicas = 1
+	Sync(ctx, state)
+	if scaled := selectServiceReplicas(t); len(scaled) != 1 || scaled[0] != replicas[0] {
+		t.Fatalf("expected the first replica to keep running alone, got %v", scaled)
+	}
+	if len(state.Network.ReplicaAddresses["rickroll"]) != 0 {
+		t.Errorf("expected addresses of the removed replicas to be released, got %v", state.Network.ReplicaAddresses)
+	}
+
+	state.Service.Services[0].Replicas = 2
+	Sync(ctx, state)
+	if scaled := selectServiceReplicas(t); len(scaled) != 2 || scaled[0] != replicas[0] {
+		t.Errorf("expected a second replica next to the first one, got %v", scaled)
+	}
+	if rollout := state.Service.Rollout("rickroll"); rollout != nil {
+		t.Errorf("expected scaling not to roll the service out, got %v", rollout)
+	}
+}
+
+func TestSyncRollsOutReplicas(t *testing.T) {
+	_, state := newReplicaTestState(t, 2)
+	useDrainPeriod(t, 0)
+	ctx := context.Background()
+	previous := selectServiceReplicas(t)
+	previousAddresses := []string{
+		state.Network.ReplicaAddress("rickroll", 0),
+		state.Network.ReplicaAddress("rickroll", 1),
+	}
+
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(ctx, state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected rollout without errors, got %v", state.Service.Errors[0])
+	}
+
+	rollout := state.Service.Rollout("rickroll")
+	if rollout.State != record.RolloutProgressing || !sameElements(rollout.Draining, []string{previous[0], previous[1]}) {
+		t.Fatalf("expected the containers of both replicas to be drained, got %v", rollout)
+	}
+	if !sameElements(rollout.DrainingAddresses, previousAddresses) {
+		t.Errorf("expected the previous addresses to be held while draining, got %v", rollout.DrainingAddresses)
+	}
+	for replica := range 2 {
+		address := state.Network.ReplicaAddress("rickroll", replica)
+		id := state.Service.Status["rickroll"].Replicas[replica].ContainerID
+		if slices.Contains(previousAddresses, address) || serviceContainerAddress(t, "poseidon", id) != address {
+			t.Errorf("expected replica %d to switch to a spare address, got '%s'", replica, address)
+		}
+	}
+
+	Drain(ctx, state)
+	current := selectServiceReplicas(t)
+	if len(current) != 2 || current[0] == previous[0] || current[1] == previous[1] {
+		t.Fatalf("expected the new containers of both replicas to remain, got %v", current)
+	}
+	Sync(ctx, state)
+	if replicas := selectServiceReplicas(t); replicas[0] != current[0] || replicas[1] != current[1] {
+		t.Errorf("expected the rolled out replicas to be kept, got %v", replicas)
+	}
+}
+
+func TestSyncRollsBackReplicas(t *testing.T) {
+	fake, state := newReplicaTestState(t, 2)
+	previous := selectServiceReplicas(t)
+
+	fake.AddImage("rickroll:v2")
+	assert.ErrNil(fake.ExitOnStart("rickroll:v2", 1, "missing config"))
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(context.Background(), state)
+
+	if replicas := selectServiceReplicas(t); len(replicas) != 2 || replicas[0] != previous[0] || replicas[1] != previous[1] {
+		t.Fatalf("expected the previous containers to keep serving, got %v", replicas)
+	}
+	if rollout := state.Service.Rollout("rickroll"); rollout.State != record.RolloutRolledBack {
+		t.Errorf("expected rollout to be rolled back, got %v", rollout)
+	}
+	for replica := range 2 {
+		if !state.Service.ReplicaReady("rickroll", replica) {
+			t.Errorf("expected replica %d to stay ready", replica)
+		}
+	}
+}
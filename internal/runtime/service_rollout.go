@@ -0,0 +1,418 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime/errtype"
+)
+
+// Time the replaced containers of a rollout keep running after the traffic switched to the new containers.
+// The requests in flight complete and clients which resolved the hostname before the switch move on.
+var serviceDrainPeriod = time.Second * 10
+
+// Returns the containers of the stale replicas which run a previous specification and keep serving while
+// the service is rolled out, by their replica. Replicas without such a container are recreated, as are
+// all replicas of a service with the recreate strategy.
+func rolloutOrigins(
+	ctx context.Context,
+	spec *record.ServiceSpec,
+	replicas [][]*Container,
+	stale []int,
+) map[int]*Container {
+	if spec.UpdateStrategy() != record.StrategyRolling {
+		return nil
+	}
+
+	origins := make(map[int]*Container)
+	for _, replica := range stale {
+		if origin := rolloutOrigin(ctx, spec, replicas[replica]); origin != nil {
+			origins[replica] = origin
+		}
+	}
+
+	return origins
+}
+
+// Returns the container of the replica which runs a previous specification and keeps serving while the
+// service is rolled out, nil if the containers of the replica must be recreated. Only a running and ready
+// container keeps serving. Containers of the current specification are left over by an interrupted
+// rollout and are replaced.
+func rolloutOrigin(ctx context.Context, spec *record.ServiceSpec, containers []*Container) *Container {
+	var origin *Container = nil
+	for _, container := range containers {
+		if container.label(labelObjectHash) == spec.Hash() ||
+			container.label(labelServiceRole) != serviceRoleMapping[MainContainerRole] {
+			continue
+		}
+		if origin != nil {
+			return nil
+		}
+		origin = container
+	}
+	if origin == nil {
+		return nil
+	}
+	for _, container := range containers {
+		if hash := container.label(labelObjectHash); hash != spec.Hash() && hash != origin.label(labelObjectHash) {
+			return nil
+		}
+	}
+
+	health, err := origin.Health(ctx)
+	if err != nil || (health != HealthNone && health != HealthHealthy) {
+		return nil
+	}
+
+	return origin
+}
+
+// Rolls the replicas of the service out to its current specification while their origin containers keep
+// serving. The new containers are started next to them with spare addresses and once all of them are ready,
+// the hostname, the static addresses and the DNS entry of the replicas switch to them at once. The replaced
+// containers are drained and stopped by Drain, after the ingress switched as well. If a new container does
+// not become ready the rollout is rolled back. Returns false if the service cannot be rolled out and must be
+// recreated.
+func rollOutService(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	network *Network,
+	spec *record.ServiceSpec,
+	origins map[int]*Container,
+	replicas [][]*Container,
+) bool {
+	log := state.Logger("runtime-daemon")
+	rolled := slices.Sorted(maps.Keys(origins))
+	addresses, ok := spareServiceAddresses(state, network, len(rolled))
+	if !ok {
+		log.Error("No addresses are free to roll out service '%s', it is recreated", spec.ServiceName)
+		return false
+	}
+
+	var previous, leftovers []*Container
+	for _, replica := range rolled {
+		for _, container := range replicas[replica] {
+			if container.label(labelObjectHash) == spec.Hash() {
+				leftovers = append(leftovers, container)
+			} else {
+				previous = append(previous, container)
+			}
+		}
+	}
+	if !shutdownServiceContainers(ctx, state, spec.ServiceName, leftovers) {
+		syncOriginStatus(ctx, state, spec.ServiceName, origins)
+		return true
+	}
+
+	rollout := record.ServiceRollout{
+		Hash:         spec.Hash(),
+		PreviousHash: origins[rolled[0]].label(labelObjectHash),
+		State:        record.RolloutProgressing,
+		StartedAt:    time.Now(),
+	}
+	log.Info(
+		"Roll out %d replicas of service '%s' with image '%s'",
+		len(rolled), spec.ServiceName, spec.Container.Image,
+	)
+
+	if err := ensureServiceVolumes(ctx, state.Metadata.Application, spec); err != nil {
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateVolume, err),
+		)
+		syncOriginStatus(ctx, state, spec.ServiceName, origins)
+		return true
+	}
+
+	failure, err := runServiceInitContainers(ctx, state, network, spec)
+	if err != nil {
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateContainer, err),
+		)
+		syncOriginStatus(ctx, state, spec.ServiceName, origins)
+		return true
+	}
+	if failure != nil {
+		removeStaleInitContainer(ctx, state, failure.container, false)
+		reason := fmt.Sprintf("init container %s exited with code %d", failure.name, failure.exitCode)
+		rollBackService(ctx, state, spec, origins, rollout, reason)
+		return true
+	}
+
+	created := make([]*Container, 0, len(rolled))
+	for idx, replica := range rolled {
+		var spare []string = nil
+		if addresses != nil {
+			spare = addresses[idx]
+		}
+		container, err := createServiceContainer(ctx, state, network, spec, replica, spare)
+		if err == nil {
+			err = createServiceSidecars(ctx, state, spec, replica, container)
+		}
+		if err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateContainer, err),
+			)
+			removeRolloutContainers(ctx, state, spec, rolled)
+			syncOriginStatus(ctx, state, spec.ServiceName, origins)
+			return true
+		}
+		created = append(created, container)
+	}
+
+	health := make([]string, len(created))
+	for idx, container := range created {
+		health[idx] = HealthNone
+		var err error = nil
+		if check := serviceHealthCheck(spec); check != nil {
+			health[idx], err = container.WaitHealthy(ctx, check.readinessTimeout())
+			if err == nil && ctx.Err() != nil {
+				// the containers of the interrupted rollout are replaced by the next sync
+				return true
+			}
+		}
+		running := false
+		if err == nil {
+			running, err = container.IsRunning(ctx)
+		}
+		if err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerInspectContainer, err),
+			)
+			removeRolloutContainers(ctx, state, spec, rolled)
+			syncOriginStatus(ctx, state, spec.ServiceName, origins)
+			return true
+		}
+
+		switch {
+		case !running:
+			rollBackService(ctx, state, spec, origins, rollout, "container exited before it became ready")
+			return true
+		case health[idx] != HealthNone && health[idx] != HealthHealthy:
+			reason := fmt.Sprintf("container did not become healthy in time, its health is %s", health[idx])
+			rollBackService(ctx, state, spec, origins, rollout, reason)
+			return true
+		}
+	}
+
+	// the previous containers keep their alias until they are stopped, both serve until then
+	for idx, container := range created {
+		if health[idx] != HealthHealthy {
+			continue
+		}
+		if err := container.ensureNetworkAlias(ctx, spec.Hostname(), true); err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerCreateNetwork, err),
+			)
+			removeRolloutContainers(ctx, state, spec, rolled)
+			syncOriginStatus(ctx, state, spec.ServiceName, origins)
+			return true
+		}
+	}
+
+	// the ingress reaches the replicas by their static addresses and follows with its next config
+	for idx, replica := range rolled {
+		if addresses != nil {
+			if address := state.Network.ReplicaAddress(spec.ServiceName, replica); address != "" {
+				rollout.DrainingAddresses = append(rollout.DrainingAddresses, address)
+			}
+			moveServiceAddresses(state, spec.ServiceName, replica, addresses[idx])
+		}
+		state.Service.SetReplicaStatus(spec.ServiceName, replica, record.ServiceStatus{
+			ContainerID: created[idx].id,
+			Health:      health[idx],
+			State:       record.ServiceStateRunning,
+		})
+	}
+	for _, container := range previous {
+		rollout.Draining = append(rollout.Draining, container.id)
+	}
+	rollout.SwitchedAt = time.Now()
+	state.Service.SetRollout(spec.ServiceName, rollout)
+	syncDNSEntries(ctx, state, spec.ServiceName)
+	log.Info("Service '%s' switched to %d new containers, the previous ones are drained", spec.ServiceName, len(created))
+
+	return true
+}
+
+// Removes the containers of the rollout and keeps the origin containers serving. The rollout is not
+// retried until the specification of the service changes.
+func rollBackService(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	origins map[int]*Container,
+	rollout record.ServiceRollout,
+	reason string,
+) {
+	state.Logger("runtime-daemon").Error("Roll back service '%s': %s", spec.ServiceName, reason)
+	removeRolloutContainers(ctx, state, spec, slices.Sorted(maps.Keys(origins)))
+
+	rollout.State = record.RolloutRolledBack
+	rollout.Reason = reason
+	rollout.FinishedAt = time.Now()
+	state.Service.SetRollout(spec.ServiceName, rollout)
+	state.Service.SetError(errtype.RolledBackRollout(spec.ServiceName, reason))
+	syncOriginStatus(ctx, state, spec.ServiceName, origins)
+	// the DNS keeps answering with the addresses of the origin containers
+	syncDNSEntries(ctx, state, spec.ServiceName)
+}
+
+// Shuts the containers of the current specification of the replicas down, which were started by a rollout.
+// The containers of other replicas run the current specification already and are kept.
+func removeRolloutContainers(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+	replicas []int,
+) {
+	application := state.Metadata.Application
+	selected, err := SelectContainerInAnyState(
+		ctx,
+		ObjectTypeLabel(ServiceObject),
+		ApplicationNameLabel(application),
+		ServiceNameLabel(string(spec.ServiceName)),
+		ObjectHashLabel(spec.Hash()),
+	)
+	if err != nil {
+		state.Service.SetError(
+			errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerSelectContainer, err),
+		)
+		return
+	}
+
+	containers := make([]*Container, 0, len(selected))
+	for _, s := range selected {
+		container, err := s.NewContainer(ctx, application)
+		if err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, errtype.DockerSelectContainer, err),
+			)
+			return
+		}
+		if slices.Contains(replicas, container.replica()) {
+			containers = append(containers, container)
+		}
+	}
+	shutdownServiceContainers(ctx, state, spec.ServiceName, containers)
+}
+
+// Records the status of the containers which keep serving a previous specification of the service, by
+// their replica.
+func syncOriginStatus(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	service record.RecordKey,
+	origins map[int]*Container,
+) {
+	for replica, origin := range origins {
+		status := record.ServiceStatus{
+			ContainerID: origin.id,
+			Health:      record.ServiceHealthNone,
+			State:       record.ServiceStateRunning,
+		}
+		health, err := origin.Health(ctx)
+		if err != nil {
+			status.Health = record.ServiceHealthUnhealthy
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(service, errtype.DockerInspectContainer, err),
+			)
+		} else {
+			status.Health = health
+		}
+
+		state.Service.SetReplicaStatus(service, replica, status)
+	}
+}
+
+// Stops the containers which were replaced by rollouts once they are drained, the drain period starts
+// when the traffic switched to the new containers. Runs after the ingress switched its upstream, the
+// containers whose drain period has not passed yet are left for a later run, see NextDrain. A run which
+// is cancelled leaves the containers for the next one. Rollouts of deleted services are forgotten.
+func Drain(ctx context.Context, state *record.ApplicationRecord) {
+	log := state.Logger("runtime-daemon")
+	application := state.Metadata.Application
+
+	services := slices.SortedFunc(maps.Keys(state.Service.Rollouts), func(a, b record.RecordKey) int {
+		return state.Service.Rollouts[a].SwitchedAt.Compare(state.Service.Rollouts[b].SwitchedAt)
+	})
+	for _, service := range services {
+		rollout := state.Service.Rollouts[service]
+		// the containers of deleted services were shut down by Sync
+		if state.Service.Get(service) == nil {
+			delete(state.Service.Rollouts, service)
+			continue
+		}
+		if len(rollout.Draining) == 0 {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			log.Info("Draining was cancelled")
+			return
+		}
+		if time.Now().Before(rollout.SwitchedAt.Add(serviceDrainPeriod)) {
+			continue
+		}
+
+		selected, err := SelectContainerInAnyState(
+			ctx,
+			ObjectTypeLabel(ServiceObject),
+			ApplicationNameLabel(application),
+			ServiceNameLabel(string(service)),
+		)
+		if err != nil {
+			state.Service.SetError(
+				errtype.FailedServiceInteractionWithDockerDaemon(service, errtype.DockerSelectContainer, err),
+			)
+			continue
+		}
+
+		var draining []*Container = nil
+		for _, s := range selected {
+			if !slices.Contains(rollout.Draining, s.id) {
+				continue
+			}
+			container, err := s.NewContainer(ctx, application)
+			if err != nil {
+				state.Service.SetError(
+					errtype.FailedServiceInteractionWithDockerDaemon(service, errtype.DockerSelectContainer, err),
+				)
+				return
+			}
+			draining = append(draining, container)
+		}
+		if !shutdownServiceContainers(ctx, state, service, draining) {
+			continue
+		}
+
+		log.Info("Drained the previous containers of service '%s'", service)
+		rollout.Draining = nil
+		rollout.DrainingAddresses = nil
+		rollout.State = record.RolloutComplete
+		rollout.FinishedAt = time.Now()
+	}
+}
+
+// Returns the time at which the drain period of the next rollout passed, whose previous containers are
+// stopped by Drain. Returns false if no rollout drains containers.
+func NextDrain(state *record.ApplicationRecord) (time.Time, bool) {
+	var next time.Time
+	for _, rollout := range state.Service.Rollouts {
+		if len(rollout.Draining) == 0 {
+			continue
+		}
+		drainAt := rollout.SwitchedAt.Add(serviceDrainPeriod)
+		if next.IsZero() || drainAt.Before(next) {
+			next = drainAt
+		}
+	}
+
+	return next, !next.IsZero()
+}
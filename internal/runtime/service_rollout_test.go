@@ -0,0 +1,174 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func useDrainPeriod(t *testing.T, period time.Duration) {
+	previous := serviceDrainPeriod
+	serviceDrainPeriod = period
+	t.Cleanup(func() { serviceDrainPeriod = previous })
+}
+
+func newRolloutTestState(t *testing.T, strategy string) (*FakeBackend, *record.ApplicationRecord) {
+	fake := useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{PortMapping: map[string]string{"application": "8000"}},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1"},
+			Strategy:    strategy,
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	return fake, state
+}
+
+func TestSyncRollsOutService(t *testing.T) {
+	_, state := newRolloutTestState(t, "")
+	useDrainPeriod(t, 0)
+	ctx := context.Background()
+	previous := selectServiceContainers(t, "poseidon")[0].id
+	previousAddress := state.Network.Address("rickroll")
+
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(ctx, state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected rollout without errors, got %v", state.Service.Errors[0])
+	}
+
+	selected := selectServiceContainers(t, "poseidon")
+	if len(selected) != 2 {
+		t.Fatalf("expected previous container to serve next to the new one, got %d containers", len(selected))
+	}
+	current := state.Service.Status["rickroll"].ContainerID
+	if current == previous || !slices.ContainsFunc(selected, func(s SelectedContainer) bool { return s.id == current }) {
+		t.Fatalf("expected status to switch to the new container, got '%s'", current)
+	}
+	address := state.Network.Address("rickroll")
+	if address == previousAddress || serviceContainerAddress(t, "poseidon", current) != address {
+		t.Errorf("expected address of the service to switch to the new container, got '%s'", address)
+	}
+	rollout := state.Service.Rollout("rickroll")
+	if rollout.State != record.RolloutProgressing || !slices.Equal(rollout.Draining, []string{previous}) {
+		t.Fatalf("expected previous container to be drained, got %v", rollout)
+	}
+
+	// the drained container is left to Drain
+	Sync(ctx, state)
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 2 {
+		t.Errorf("expected sync to keep the drained container, got %d containers", len(selected))
+	}
+
+	Drain(ctx, state)
+	selected = selectServiceContainers(t, "poseidon")
+	if len(selected) != 1 || selected[0].id != current {
+		t.Fatalf("expected drained container to be stopped, got %v", selected)
+	}
+	rollout = state.Service.Rollout("rickroll")
+	if rollout.State != record.RolloutComplete || len(rollout.Draining) != 0 || len(rollout.DrainingAddresses) != 0 {
+		t.Errorf("expected rollout to be complete, got %v", rollout)
+	}
+}
+
+func TestDrainLeavesContainersUntilDrainPeriodPassed(t *testing.T) {
+	_, state := newRolloutTestState(t, "")
+	useDrainPeriod(t, time.Hour)
+	ctx := context.Background()
+
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(ctx, state)
+	rollout := state.Service.Rollout("rickroll")
+	if drainAt, ok := NextDrain(state); !ok || !drainAt.Equal(rollout.SwitchedAt.Add(time.Hour)) {
+		t.Fatalf("expected the rollout to be drained after the drain period, got '%s'", drainAt)
+	}
+
+	// the run does not wait for the drain period, a later run drains the containers
+	Drain(ctx, state)
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 2 {
+		t.Fatalf("expected the previous container to serve until the drain period passed, got %d containers", len(selected))
+	}
+
+	useDrainPeriod(t, 0)
+	Drain(ctx, state)
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 1 {
+		t.Errorf("expected the previous container to be stopped, got %d containers", len(selected))
+	}
+	if _, ok := NextDrain(state); ok {
+		t.Errorf("expected no rollout to be left to drain")
+	}
+}
+
+func TestSyncRollsBackFailedRollout(t *testing.T) {
+	fake, state := newRolloutTestState(t, "")
+	ctx := context.Background()
+	previous := selectServiceContainers(t, "poseidon")[0].id
+	address := state.Network.Address("rickroll")
+
+	fake.AddImage("rickroll:v2")
+	assert.ErrNil(fake.ExitOnStart("rickroll:v2", 1, "missing config"))
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(ctx, state)
+
+	selected := selectServiceContainers(t, "poseidon")
+	if len(selected) != 1 || selected[0].id != previous {
+		t.Fatalf("expected previous container to keep serving alone, got %v", selected)
+	}
+	if state.Service.Status["rickroll"].ContainerID != previous || state.Network.Address("rickroll") != address {
+		t.Errorf("expected status and address to stay with the previous container")
+	}
+	rollout := state.Service.Rollout("rickroll")
+	if rollout.State != record.RolloutRolledBack || rollout.Reason == "" {
+		t.Errorf("expected rollout to be rolled back, got %v", rollout)
+	}
+	if !slices.ContainsFunc(state.Service.Errors, func(e *record.ServiceErrorEntryRecord) bool { return e.Type == "RolledBackRollout" }) {
+		t.Errorf("expected rollback to be recorded as error, got %v", state.Service.Errors)
+	}
+
+	// the rollout is not retried until the specification changes
+	started := rollout.StartedAt
+	Sync(ctx, state)
+	if rollout := state.Service.Rollout("rickroll"); !rollout.StartedAt.Equal(started) {
+		t.Errorf("expected rolled back rollout not to be retried")
+	}
+
+	state.Service.Services[0].Container.Image = "rickroll:v3"
+	Sync(ctx, state)
+	if rollout := state.Service.Rollout("rickroll"); rollout.State != record.RolloutProgressing {
+		t.Errorf("expected changed specification to be rolled out, got %v", rollout)
+	}
+}
+
+func TestSyncRecreatesServiceWithRecreateStrategy(t *testing.T) {
+	_, state := newRolloutTestState(t, record.StrategyRecreate)
+	previous := selectServiceContainers(t, "poseidon")[0].id
+
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	Sync(context.Background(), state)
+
+	selected := selectServiceContainers(t, "poseidon")
+	if len(selected) != 1 || selected[0].id == previous {
+		t.Fatalf("expected previous container to be replaced before the new one starts, got %v", selected)
+	}
+	if rollout := state.Service.Rollout("rickroll"); rollout != nil {
+		t.Errorf("expected no rollout, got %v", rollout)
+	}
+}
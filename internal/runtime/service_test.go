@@ -0,0 +1,81 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestServicePortsEnvValue(t *testing.T) {
+	value := portsEnvValue(map[string]string{
+		"grafana":     "3000",
+		"application": "8000",
+	})
+
+	if value != "application@8000:grafana@3000" {
+		t.Errorf("ports are not encoded correctly, got '%s'", value)
+	}
+
+	if portsEnvValue(nil) != "" {
+		t.Errorf("no ports must result in an empty value")
+	}
+}
+
+func TestServiceContainerLimits(t *testing.T) {
+	fake := useFakeBackend(t)
+	application := "poseidon"
+	network, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	spec := &record.ServiceSpec{
+		ServiceName: "rickroll",
+		Container: &record.ServiceContainer{
+			Image:     "rickroll:v1",
+			Resources: &record.ServiceResources{Memory: 256 << 20, NanoCPUs: 5e8, Pids: 128},
+			Security: &record.ServiceSecurity{
+				ReadOnlyRootFilesystem: true,
+				DropCapabilities:       []string{"ALL"},
+				NoNewPrivileges:        true,
+				User:                   "1000",
+				Group:                  "1000",
+				Tmpfs:                  []record.ServiceTmpfs{{Path: "/tmp", Size: 64 << 20}},
+			},
+		},
+	}
+
+	container, err := createServiceContainer(context.Background(), state, network, spec, 0, nil)
+	assert.ErrNil(err)
+	inspect, err := fake.ContainerInspect(context.Background(), container.id)
+	assert.ErrNil(err)
+
+	host := inspect.HostConfig
+	if host.Memory != 256<<20 || host.MemorySwap != 256<<20 {
+		t.Errorf("expected memory and swap limit of 256MiB, got %d and %d", host.Memory, host.MemorySwap)
+	}
+	if host.NanoCPUs != 5e8 {
+		t.Errorf("expected half a CPU, got %d", host.NanoCPUs)
+	}
+	if host.PidsLimit == nil || *host.PidsLimit != 128 {
+		t.Errorf("expected pids limit of 128, got %v", host.PidsLimit)
+	}
+	if !host.ReadonlyRootfs || !slices.Equal(host.CapDrop, []string{"ALL"}) {
+		t.Errorf("expected read-only root filesystem without capabilities")
+	}
+	if !slices.Contains(host.SecurityOpt, "no-new-privileges:true") {
+		t.Errorf("expected no-new-privileges, got %v", host.SecurityOpt)
+	}
+	if inspect.Config.User != "1000:1000" {
+		t.Errorf("expected user '1000:1000', got '%s'", inspect.Config.User)
+	}
+	if len(host.Mounts) != 1 || host.Mounts[0].Type != mount.TypeTmpfs || host.Mounts[0].Target != "/tmp" {
+		t.Errorf("expected tmpfs mount at /tmp, got %v", host.Mounts)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+// ContainerStats is the resource usage of a running container of an application.
+type ContainerStats struct {
+	// Object the container belongs to, e.g. 'service', 'ingress' or 'dns'
+	Object string
+	// Name of the service, empty for containers which are no service
+	Service   string
+	Container string
+	// Usage of the CPUs since the previous sample, 100 percent equal one fully used CPU
+	CPUPercent float64
+	// Memory in use without the page cache which the kernel can reclaim, like 'docker stats'
+	MemoryUsage uint64
+	MemoryLimit uint64
+	// Bytes received and sent over all networks of the container
+	NetworkRx uint64
+	NetworkTx uint64
+	// Bytes read from and written to block devices
+	BlockRead  uint64
+	BlockWrite uint64
+	Pids       uint64
+}
+
+// DiskUsage is the space an image or volume of an application uses on the machine.
+type DiskUsage struct {
+	// Either 'image' or 'volume'
+	Type string
+	Name string
+	Size int64
+}
+
+// Returns the CPU usage in percent, computed like 'docker stats' from the difference to the previous sample.
+func cpuPercent(stats container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// Returns the memory in use without the inactive page cache, cgroup v2 reports it as 'inactive_file'
+// and cgroup v1 as 'total_inactive_file'.
+func memoryUsage(stats container.StatsResponse) uint64 {
+	usage := stats.MemoryStats.Usage
+	inactive, ok := stats.MemoryStats.Stats["inactive_file"]
+	if !ok {
+		inactive = stats.MemoryStats.Stats["total_inactive_file"]
+	}
+	if inactive < usage {
+		return usage - inactive
+	}
+
+	return usage
+}
+
+func toContainerStats(name string, labels map[string]string, stats container.StatsResponse) ContainerStats {
+	result := ContainerStats{
+		Object:      labels[labelObjectType],
+		Service:     labels[labelServiceName],
+		Container:   name,
+		CPUPercent:  cpuPercent(stats),
+		MemoryUsage: memoryUsage(stats),
+		MemoryLimit: stats.MemoryStats.Limit,
+		Pids:        stats.PidsStats.Current,
+	}
+	for _, nw := range stats.Networks {
+		result.NetworkRx += nw.RxBytes
+		result.NetworkTx += nw.TxBytes
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			result.BlockRead += entry.Value
+		case "write":
+			result.BlockWrite += entry.Value
+		}
+	}
+
+	return result
+}
+
+// Returns the resource usage of all running containers of the application, which are its services,
+// its ingress, and its DNS. The stats of the containers are requested concurrently, a container which
+// stops in the meantime is left out.
+func ApplicationStats(ctx context.Context, application string) ([]ContainerStats, error) {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	listCtx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	containers, err := backend.ContainerList(listCtx, container.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", labelApplicationName, application)),
+		),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ContainerStats, len(containers))
+	failed := make([]error, len(containers))
+	var wg sync.WaitGroup
+	for idx, cont := range containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			statsCtx, cancel := withOperationTimeout(ctx)
+			defer cancel()
+			stats, err := backend.ContainerStats(statsCtx, cont.ID)
+			if err != nil {
+				failed[idx] = err
+				return
+			}
+
+			name := cont.ID
+			if len(cont.Names) > 0 {
+				name = strings.TrimPrefix(cont.Names[0], "/")
+			}
+			result[idx] = toContainerStats(name, cont.Labels, stats)
+		}()
+	}
+	wg.Wait()
+
+	var collected []ContainerStats = nil
+	for idx := range containers {
+		if failed[idx] != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		collected = append(collected, result[idx])
+	}
+
+	return collected, nil
+}
+
+// Returns the disk usage of the volumes of the application and of the images its containers run.
+func ApplicationDiskUsage(ctx context.Context, application string) ([]DiskUsage, error) {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	listCtx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	containers, err := backend.ContainerList(listCtx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", labelApplicationName, application)),
+		),
+	})
+	if err != nil {
+		return nil, err
+	}
+	images := make(map[string]string)
+	for _, cont := range containers {
+		images[cont.ImageID] = cont.Image
+	}
+
+	usageCtx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	usage, err := backend.DiskUsage(usageCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []DiskUsage = nil
+	for _, img := range usage.Images {
+		ref, ok := images[img.ID]
+		if !ok {
+			continue
+		}
+		result = append(result, DiskUsage{Type: "image", Name: ref, Size: img.Size})
+	}
+	for _, v := range usage.Volumes {
+		if v.Labels[labelApplicationName] != application || v.Labels[labelObjectType] != objectLabelMapping[VolumeObject] {
+			continue
+		}
+		var size int64 = 0
+		if v.UsageData != nil && v.UsageData.Size > 0 {
+			size = v.UsageData.Size
+		}
+		result = append(result, DiskUsage{Type: "volume", Name: v.Labels[labelVolumeName], Size: size})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestCPUPercent(t *testing.T) {
+	stats := container.StatsResponse{}
+	stats.PreCPUStats.CPUUsage.TotalUsage = 1_000
+	stats.PreCPUStats.SystemUsage = 10_000
+	stats.CPUStats.CPUUsage.TotalUsage = 2_000
+	stats.CPUStats.SystemUsage = 20_000
+	stats.CPUStats.OnlineCPUs = 4
+
+	if got := cpuPercent(stats); got != 40 {
+		t.Errorf("expected 40 percent, got %f", got)
+	}
+	if got := cpuPercent(container.StatsResponse{}); got != 0 {
+		t.Errorf("expected no usage without samples, got %f", got)
+	}
+}
+
+func TestApplicationStatsAndDiskUsage(t *testing.T) {
+	fake := useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "postgres",
+			Container: &record.ServiceContainer{
+				Image:   "postgres:v1",
+				Volumes: []record.ServiceVolume{{Name: "data", Path: "/var/lib/postgresql/data"}},
+			},
+		},
+	}
+	assert.ErrNil(EnsureVolume(context.Background(), "poseidon", "data"))
+	assert.ErrNil(RestoreVolume(
+		context.Background(), "poseidon", "data", volumeArchiveOf(t, map[string]string{"volume/PG_VERSION": "17"}),
+	))
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+
+	selected := selectServiceContainers(t, "poseidon")
+	if len(selected) != 1 {
+		t.Fatalf("expected one service container, got %d", len(selected))
+	}
+	stats := container.StatsResponse{}
+	stats.MemoryStats.Usage = 300
+	stats.MemoryStats.Limit = 1_000
+	stats.MemoryStats.Stats = map[string]uint64{"inactive_file": 100}
+	stats.Networks = map[string]container.NetworkStats{
+		"eth0": {RxBytes: 10, TxBytes: 20},
+		"eth1": {RxBytes: 1, TxBytes: 2},
+	}
+	stats.BlkioStats.IoServiceBytesRecursive = []container.BlkioStatEntry{
+		{Op: "read", Value: 7},
+		{Op: "Write", Value: 9},
+	}
+	stats.PidsStats.Current = 3
+	assert.ErrNil(fake.SetStats(selected[0].id, stats))
+
+	result, err := ApplicationStats(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	var service *ContainerStats = nil
+	for idx := range result {
+		if result[idx].Service == "postgres" {
+			service = &result[idx]
+		}
+	}
+	if len(result) != 2 || service == nil {
+		t.Fatalf("expected the stats of the DNS and the service, got %v", result)
+	}
+	expected := ContainerStats{
+		Object:      "service",
+		Service:     "postgres",
+		Container:   service.Container,
+		MemoryUsage: 200,
+		MemoryLimit: 1_000,
+		NetworkRx:   11,
+		NetworkTx:   22,
+		BlockRead:   7,
+		BlockWrite:  9,
+		Pids:        3,
+	}
+	if *service != expected {
+		t.Errorf("expected stats %v, got %v", expected, *service)
+	}
+
+	assert.ErrNil(fake.SetImageSize("postgres:v1", 4_096))
+	assert.ErrNil(EnsureVolume(context.Background(), "hades", "data"))
+
+	usage, err := ApplicationDiskUsage(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	var image, volume *DiskUsage = nil, nil
+	for idx := range usage {
+		switch {
+		case usage[idx].Type == "image" && usage[idx].Name == "postgres:v1":
+			image = &usage[idx]
+		case usage[idx].Type == "volume":
+			volume = &usage[idx]
+		}
+	}
+	if image == nil || image.Size != 4_096 {
+		t.Errorf("expected size of the service image, got %v", usage)
+	}
+	if volume == nil || volume.Name != "data" || volume.Size != 2 {
+		t.Errorf("expected only the volume of the application with its size, got %v", usage)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// Deadline of a single call of the container engine, a hung engine must not block the orchestrator
+	operationTimeout = time.Second * 30
+	// Deadline of a pull, which downloads all layers of the image
+	pullTimeout = time.Minute * 10
+	// Deadline of an init container, an init container which does not exit is treated as failed
+	initContainerTimeout = time.Minute * 5
+	// Delay before the first retry of a failed operation, doubled for every further attempt
+	retryBackoffBase = time.Millisecond * 500
+	// Upper limit of the delay between two attempts
+	retryBackoffMax = time.Second * 5
+)
+
+// Returns the context for a single call of the container engine, which is cancelled with the
+// parent or after the operation timeout.
+func withOperationTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, operationTimeout)
+}
+
+// Returns the delay before the next attempt, given the number of failed attempts.
+func retryBackoff(failures int) time.Duration {
+	backoff := retryBackoffBase
+	for i := 1; i < failures && backoff < retryBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, retryBackoffMax)
+}
+
+// Calls the operation until it succeeds or it failed the given times and returns its last error.
+// Every attempt has its own deadline, the attempts stop once the context is done.
+func retry(ctx context.Context, attempts int, operation func(ctx context.Context) error) error {
+	var err error = nil
+	for attempt := 1; attempt <= attempts; attempt++ {
+		opCtx, cancel := withOperationTimeout(ctx)
+		err = operation(opCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		timer := time.NewTimer(retryBackoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return err
+}
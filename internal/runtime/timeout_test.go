@@ -0,0 +1,82 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  500 * time.Millisecond,
+		2:  time.Second,
+		3:  2 * time.Second,
+		5:  5 * time.Second,
+		40: 5 * time.Second,
+	}
+	for failures, expected := range tests {
+		if got := retryBackoff(failures); got != expected {
+			t.Errorf("expected backoff %s after %d failures, got %s", expected, failures, got)
+		}
+	}
+}
+
+func TestRetryStopsOnSuccess(t *testing.T) {
+	calls := 0
+	err := retry(context.Background(), 3, func(ctx context.Context) error {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("expected every attempt to have a deadline")
+		}
+		if calls < 2 {
+			return errors.New("daemon busy")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected success in the second attempt, got %d attempts and %v", calls, err)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := retry(ctx, 5, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errors.New("daemon busy")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected retry to stop after cancel, got %d attempts and %v", calls, err)
+	}
+}
+
+func TestSyncStopsOnCancel(t *testing.T) {
+	useFakeBackend(t)
+	_, err := CreateNewNetwork(context.Background(), "poseidon")
+	if err != nil {
+		t.Fatalf("failed to create network, got %q", err)
+	}
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "rickroll",
+			Network:     &record.ServiceNetwork{},
+			Container:   &record.ServiceContainer{Image: "rickroll:v1"},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	Sync(ctx, state)
+	if selected := selectServiceContainers(t, "poseidon"); len(selected) != 0 {
+		t.Errorf("expected cancelled sync not to create containers, got %d", len(selected))
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+// Volume which zeus manages for an application. Its data survives the replacement of containers and
+// is only removed explicitly.
+type Volume struct {
+	Application string
+	// Name of the volume inside the application
+	Name string
+	// Name of the volume in the container engine
+	EngineName string
+	CreatedAt  string
+}
+
+// Returns the name of the volume in the container engine, which is unique across applications.
+func volumeEngineName(application string, name string) string {
+	return fmt.Sprintf("zeus-%s-%s", application, name)
+}
+
+// Creates the volume of the application, if it does not exist yet.
+func EnsureVolume(ctx context.Context, application string, name string) error {
+	assert.True(backend != nil, "init of runtime backend failed")
+	assert.NotEmptyString(name, "volume name must not be empty")
+
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	return backend.VolumeCreate(
+		ctx,
+		volumeEngineName(application, name),
+		map[string]string{
+			labelObjectType:      objectLabelMapping[VolumeObject],
+			labelApplicationName: application,
+			labelVolumeName:      name,
+		},
+	)
+}
+
+// Returns all volumes of the application.
+func SelectVolumes(ctx context.Context, application string) ([]Volume, error) {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	args := filters.NewArgs(
+		filters.Arg("label", fmt.Sprintf("%s=%s", labelObjectType, objectLabelMapping[VolumeObject])),
+		filters.Arg("label", fmt.Sprintf("%s=%s", labelApplicationName, application)),
+	)
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	volumes, err := backend.VolumeList(ctx, volume.ListOptions{Filters: args})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Volume = nil
+	for _, v := range volumes {
+		result = append(result, Volume{
+			Application: application,
+			Name:        v.Labels[labelVolumeName],
+			EngineName:  v.Name,
+			CreatedAt:   v.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// Removes the volume of the application and its data. Fails if a container still uses the volume.
+func RemoveVolume(ctx context.Context, application string, name string) error {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	ctx, cancel := withOperationTimeout(ctx)
+	defer cancel()
+	return backend.VolumeRemove(ctx, volumeEngineName(application, name))
+}
+
+// Removes all volumes of the application and their data.
+func RemoveApplicationVolumes(ctx context.Context, application string) error {
+	volumes, err := SelectVolumes(ctx, application)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range volumes {
+		if err := RemoveVolume(ctx, application, v.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Mounts the volume of the application at the path inside the container. The volume must exist.
+func WithVolume(application string, name string, path string, readOnly bool) ContainerOption {
+	assert.StartsWithString(path, "/", "volume path must be absolute")
+
+	return func(cfg *ContainerConfig) {
+		cfg.hostConfig.Mounts = append(
+			cfg.hostConfig.Mounts,
+			mount.Mount{
+				Type:     mount.TypeVolume,
+				Source:   volumeEngineName(application, name),
+				Target:   path,
+				ReadOnly: readOnly,
+			},
+		)
+	}
+}
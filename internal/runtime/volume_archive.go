@@ -0,0 +1,145 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+const (
+	// Image of the helper container which mounts a volume to read or write its content. The helper
+	// is only created, never started, therefore any small image works.
+	VolumeHelperImage = "busybox:stable"
+	// Path at which the helper container mounts the volume, the entries of archives start with its base name
+	volumeHelperPath = "/volume"
+)
+
+var ErrVolumeNotFound = errors.New("volume does not exist")
+
+// Creates a helper container which mounts the volume at the helper path. The caller must remove it.
+func createVolumeHelper(ctx context.Context, application string, name string, readOnly bool) (string, error) {
+	if err := pull(ctx, VolumeHelperImage, PullOptions{}); err != nil {
+		return "", err
+	}
+
+	return create(
+		ctx,
+		&container.Config{
+			Image:  VolumeHelperImage,
+			Cmd:    []string{"true"},
+			Labels: map[string]string{labelVolumeName: volumeEngineName(application, name)},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{{
+				Type:     mount.TypeVolume,
+				Source:   volumeEngineName(application, name),
+				Target:   volumeHelperPath,
+				ReadOnly: readOnly,
+			}},
+		},
+		nil,
+	)
+}
+
+// Removes the helper container, also if the context of the archive was cancelled.
+func removeVolumeHelper(helperID string) error {
+	ctx, cancel := withOperationTimeout(context.Background())
+	defer cancel()
+	return backend.ContainerRemove(ctx, helperID)
+}
+
+func volumeExists(ctx context.Context, application string, name string) (bool, error) {
+	volumes, err := SelectVolumes(ctx, application)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range volumes {
+		if v.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Removes the helper container when the archive is closed.
+type volumeArchive struct {
+	io.ReadCloser
+	helperID string
+}
+
+func (self *volumeArchive) Close() error {
+	err := self.ReadCloser.Close()
+	if rmErr := removeVolumeHelper(self.helperID); rmErr != nil {
+		return rmErr
+	}
+
+	return err
+}
+
+// Streams the content of the volume as tar archive. The archive must be closed by the caller.
+//
+// The volume is read while the services which mount it keep running. Services which need a consistent
+// state on disk, e.g. databases, should be stopped or flushed before.
+func ArchiveVolume(ctx context.Context, application string, name string) (io.ReadCloser, error) {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	exists, err := volumeExists(ctx, application, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrVolumeNotFound
+	}
+
+	helperID, err := createVolumeHelper(ctx, application, name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := backend.CopyFromContainer(ctx, helperID, volumeHelperPath)
+	if err != nil {
+		removeVolumeHelper(helperID)
+		return nil, err
+	}
+
+	return &volumeArchive{ReadCloser: content, helperID: helperID}, nil
+}
+
+// Replaces the content of the volume with the tar archive, which was created by ArchiveVolume.
+// The volume is created if it does not exist. Fails if a container uses the volume.
+func RestoreVolume(ctx context.Context, application string, name string, archive io.Reader) error {
+	assert.True(backend != nil, "init of runtime backend failed")
+
+	exists, err := volumeExists(ctx, application, name)
+	if err != nil {
+		return err
+	}
+	// recreating the volume is the only way to drop its content without running a container
+	if exists {
+		if err := RemoveVolume(ctx, application, name); err != nil {
+			return fmt.Errorf("volume is in use: %w", err)
+		}
+	}
+	if err := EnsureVolume(ctx, application, name); err != nil {
+		return err
+	}
+
+	helperID, err := createVolumeHelper(ctx, application, name, false)
+	if err != nil {
+		return err
+	}
+	defer removeVolumeHelper(helperID)
+
+	// the entries of the archive start with the base name of the helper path
+	return backend.CopyToContainer(ctx, helperID, "/", archive)
+}
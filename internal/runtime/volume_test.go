@@ -0,0 +1,162 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package runtime
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestSyncKeepsVolumesAcrossUpdates(t *testing.T) {
+	useFakeBackend(t)
+	application := "poseidon"
+	_, err := CreateNewNetwork(context.Background(), application)
+	assert.ErrNil(err)
+
+	state := record.New(application, record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{
+			ServiceName: "postgres",
+			Container: &record.ServiceContainer{
+				Image:   "postgres:v1",
+				Volumes: []record.ServiceVolume{{Name: "data", Path: "/var/lib/postgresql/data"}},
+			},
+		},
+	}
+
+	Sync(context.Background(), state)
+	if !state.Service.NoErrors() {
+		t.Fatalf("expected sync without errors, got %v", state.Service.Errors[0])
+	}
+	volumes, err := SelectVolumes(context.Background(), application)
+	assert.ErrNil(err)
+	if len(volumes) != 1 || volumes[0].Name != "data" || volumes[0].EngineName != "zeus-poseidon-data" {
+		t.Fatalf("expected volume 'data' to be created, got %v", volumes)
+	}
+
+	if err := RemoveVolume(context.Background(), application, "data"); err == nil {
+		t.Errorf("expected volume which is in use not to be removed")
+	}
+
+	state.Service.Services[0].Container.Image = "postgres:v2"
+	Sync(context.Background(), state)
+	volumes, err = SelectVolumes(context.Background(), application)
+	assert.ErrNil(err)
+	if len(volumes) != 1 {
+		t.Errorf("expected volume to survive the replacement of the container, got %v", volumes)
+	}
+
+	state.Service.Services = nil
+	Sync(context.Background(), state)
+	volumes, err = SelectVolumes(context.Background(), application)
+	assert.ErrNil(err)
+	if len(volumes) != 1 {
+		t.Errorf("expected volume to survive the deletion of the service, got %v", volumes)
+	}
+
+	assert.ErrNil(RemoveApplicationVolumes(context.Background(), application))
+	volumes, err = SelectVolumes(context.Background(), application)
+	assert.ErrNil(err)
+	if len(volumes) != 0 {
+		t.Errorf("expected volumes of the application to be removed, got %v", volumes)
+	}
+}
+
+func TestSelectVolumesOfApplication(t *testing.T) {
+	useFakeBackend(t)
+	assert.ErrNil(EnsureVolume(context.Background(), "poseidon", "data"))
+	assert.ErrNil(EnsureVolume(context.Background(), "poseidon", "data"))
+	assert.ErrNil(EnsureVolume(context.Background(), "hades", "data"))
+
+	volumes, err := SelectVolumes(context.Background(), "poseidon")
+	assert.ErrNil(err)
+	if len(volumes) != 1 || volumes[0].Application != "poseidon" {
+		t.Errorf("expected only the volume of the application, got %v", volumes)
+	}
+}
+
+func volumeArchiveOf(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+		assert.ErrNil(err)
+		_, err = tw.Write([]byte(content))
+		assert.ErrNil(err)
+	}
+	assert.ErrNil(tw.Close())
+
+	return &buf
+}
+
+func TestArchiveAndRestoreVolume(t *testing.T) {
+	fake := useFakeBackend(t)
+	application := "poseidon"
+	ctx := context.Background()
+
+	if _, err := ArchiveVolume(ctx, application, "data"); !errors.Is(err, ErrVolumeNotFound) {
+		t.Fatalf("expected archive of a missing volume to fail, got %v", err)
+	}
+
+	archive := volumeArchiveOf(t, map[string]string{"volume/db/base": "tables", "volume/version": "16"})
+	assert.ErrNil(RestoreVolume(ctx, application, "data", archive))
+
+	content, err := ArchiveVolume(ctx, application, "data")
+	assert.ErrNil(err)
+	tr := tar.NewReader(content)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.ErrNil(err)
+		data, err := io.ReadAll(tr)
+		assert.ErrNil(err)
+		files[hdr.Name] = string(data)
+	}
+	assert.ErrNil(content.Close())
+	if files["volume/db/base"] != "tables" || files["volume/version"] != "16" {
+		t.Fatalf("expected archive to contain the restored files, got %v", files)
+	}
+
+	// restoring replaces the content of the volume
+	archive = volumeArchiveOf(t, map[string]string{"volume/version": "17"})
+	assert.ErrNil(RestoreVolume(ctx, application, "data", archive))
+
+	fake.AddImage("postgres:v1")
+	cont, err := CreateNewContainer(
+		context.Background(),
+		application,
+		WithImage("postgres:v1"),
+		WithVolume(application, "data", "/var/lib/postgresql", false),
+	)
+	assert.ErrNil(err)
+	if version, _ := fake.File(cont.id, "/var/lib/postgresql/version"); string(version) != "17" {
+		t.Errorf("expected restored file to be visible in the container, got '%s'", version)
+	}
+	if _, ok := fake.File(cont.id, "/var/lib/postgresql/db/base"); ok {
+		t.Errorf("expected restore to drop the previous content of the volume")
+	}
+
+	archive = volumeArchiveOf(t, map[string]string{"volume/version": "18"})
+	if err := RestoreVolume(ctx, application, "data", archive); err == nil {
+		t.Errorf("expected restore of a volume which is in use to fail")
+	}
+
+	summary, err := fake.ContainerList(ctx, container.ListOptions{All: true})
+	assert.ErrNil(err)
+	if len(summary) != 1 {
+		t.Errorf("expected helper containers to be removed, got %d containers", len(summary))
+	}
+}
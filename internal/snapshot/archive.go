@@ -0,0 +1,115 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package snapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Compressed tar archive of a volume, which is spooled to a temporary file. Stores require the size
+// of the archive before the upload, which is unknown while the volume is streamed.
+type Archive struct {
+	file *os.File
+	Size int64
+	// SHA-256 of the compressed archive, hex encoded
+	Checksum string
+}
+
+// Compresses the tar archive into a temporary file. The archive must be closed by the caller.
+func Compress(tarball io.Reader) (*Archive, error) {
+	file, err := os.CreateTemp("", "zeus-snapshot-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{file: file}
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(file, hash)}
+	zw := gzip.NewWriter(counter)
+	if _, err := io.Copy(zw, tarball); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		archive.Close()
+		return nil, err
+	}
+
+	archive.Size = counter.n
+	archive.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return archive, nil
+}
+
+// Downloads the archive into a temporary file and verifies it against the checksum, which was recorded
+// when the snapshot was taken. The archive must be closed by the caller.
+func Download(ctx context.Context, store Store, key string, checksum string) (*Archive, error) {
+	content, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	file, err := os.CreateTemp("", "zeus-snapshot-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{file: file}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), content)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+
+	archive.Size = size
+	archive.Checksum = hex.EncodeToString(hash.Sum(nil))
+	if archive.Checksum != checksum {
+		archive.Close()
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, checksum, archive.Checksum)
+	}
+
+	return archive, nil
+}
+
+// Uploads the archive to the store.
+func (self *Archive) Upload(ctx context.Context, store Store, key string) error {
+	if _, err := self.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return store.Put(ctx, key, self.file, self.Size)
+}
+
+// Returns the uncompressed tar archive.
+func (self *Archive) Tarball() (io.ReadCloser, error) {
+	if _, err := self.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return gzip.NewReader(self.file)
+}
+
+// Removes the temporary file of the archive.
+func (self *Archive) Close() error {
+	self.file.Close()
+	return os.Remove(self.file.Name())
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (self *countingWriter) Write(p []byte) (int, error) {
+	n, err := self.w.Write(p)
+	self.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot does not exist in the store")
+	ErrInvalidKey       = errors.New("invalid snapshot key")
+	ErrChecksumMismatch = errors.New("checksum of the snapshot does not match")
+
+	keyPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+(/[a-zA-Z0-9._-]+)*$`)
+)
+
+// Store keeps the archives of snapshots outside of the container engine.
+type Store interface {
+	// Kind of the store, which is recorded with each snapshot, e.g. "directory" or "s3"
+	Target() string
+	// Stores the archive under the key, an existing archive is replaced
+	Put(ctx context.Context, key string, content io.Reader, size int64) error
+	// Returns the archive which is stored under the key, or ErrSnapshotNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Removes the archive, removing a missing archive is a no-op
+	Delete(ctx context.Context, key string) error
+}
+
+// Keys consist of slash separated segments, which must not reference the parent or current directory.
+func validKey(key string) bool {
+	if !keyPattern.MatchString(key) {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const TargetDirectory = "directory"
+
+// Stores the archives as files below a directory of the host.
+type directoryStore struct {
+	root string
+}
+
+func NewDirectoryStore(root string) (Store, error) {
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("snapshot directory '%s' must be absolute", root)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+
+	return &directoryStore{root: root}, nil
+}
+
+func (self *directoryStore) Target() string {
+	return TargetDirectory
+}
+
+func (self *directoryStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidKey, key)
+	}
+
+	return filepath.Join(self.root, filepath.FromSlash(key)), nil
+}
+
+// Writes the archive to a temporary file first, a failed write never leaves a partial archive behind.
+func (self *directoryStore) Put(ctx context.Context, key string, content io.Reader, size int64) error {
+	path, err := self.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("expected %d bytes of archive, got %d", size, written)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (self *directoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := self.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrSnapshotNotFound
+	}
+
+	return file, err
+}
+
+func (self *directoryStore) Delete(ctx context.Context, key string) error {
+	path, err := self.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package snapshot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	TargetS3 = "s3"
+
+	s3Service         = "s3"
+	s3DefaultRegion   = "us-east-1"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+)
+
+// Bucket of an S3 compatible object storage, e.g. AWS S3 or MinIO.
+type S3Options struct {
+	// URL of the endpoint, e.g. https://s3.eu-central-1.amazonaws.com or http://127.0.0.1:9000
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Prepended to the key of every archive, e.g. "zeus/"
+	Prefix string
+}
+
+// Stores the archives as objects of the bucket. Objects are addressed path style, which is supported
+// by all S3 compatible storages, and requests are signed with AWS signature version 4.
+type s3Store struct {
+	options  S3Options
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3Store(options S3Options) (Store, error) {
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint '%s': %w", options.Endpoint, err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint '%s' must be a http or https url", options.Endpoint)
+	}
+	if options.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket must not be empty")
+	}
+	if options.AccessKey == "" || options.SecretKey == "" {
+		return nil, fmt.Errorf("s3 access key and secret key must not be empty")
+	}
+	if options.Region == "" {
+		options.Region = s3DefaultRegion
+	}
+
+	return &s3Store{
+		options:  options,
+		endpoint: endpoint,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (self *s3Store) Target() string {
+	return TargetS3
+}
+
+func (self *s3Store) request(
+	ctx context.Context,
+	method string,
+	key string,
+	body io.Reader,
+	size int64,
+) (*http.Response, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidKey, key)
+	}
+
+	target := *self.endpoint
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + self.options.Bucket + "/" + self.options.Prefix + key
+	target.RawPath = ""
+
+	r, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		r.ContentLength = size
+	}
+	// the archives are verified by their checksum, therefore the payload is not part of the signature
+	r.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	signV4(r, self.options.AccessKey, self.options.SecretKey, self.options.Region, time.Now())
+
+	return self.client.Do(r)
+}
+
+// Error document of S3 compatible storages
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func s3ResponseError(resp *http.Response) error {
+	var document s3Error
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := xml.Unmarshal(body, &document); err != nil || document.Code == "" {
+		return fmt.Errorf("s3 request failed with status %s", resp.Status)
+	}
+
+	return fmt.Errorf("s3 request failed with status %s: %s: %s", resp.Status, document.Code, document.Message)
+}
+
+func (self *s3Store) Put(ctx context.Context, key string, content io.Reader, size int64) error {
+	resp, err := self.request(ctx, http.MethodPut, key, content, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError(resp)
+	}
+
+	return nil
+}
+
+func (self *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := self.request(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrSnapshotNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s3ResponseError(resp)
+	}
+}
+
+func (self *s3Store) Delete(ctx context.Context, key string) error {
+	resp, err := self.request(ctx, http.MethodDelete, key, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3ResponseError(resp)
+	}
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// Signs the request with AWS signature version 4. All headers of the request are signed, the hash of the
+// payload must be set in the header X-Amz-Content-Sha256 beforehand.
+func signV4(r *http.Request, accessKey string, secretKey string, region string, now time.Time) {
+	now = now.UTC()
+	r.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+
+	headers := map[string]string{"host": r.URL.Host}
+	for name, values := range r.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := slices.Sorted(maps.Keys(headers))
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		path,
+		strings.ReplaceAll(r.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := now.Format(s3DateFormat) + "/" + region + "/" + s3Service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format(s3TimeFormat),
+		scope,
+		sha256Hex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), now.Format(s3DateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	r.Header.Set(
+		"Authorization",
+		fmt.Sprintf(
+			"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+			accessKey, scope, signedHeaders, signature,
+		),
+	)
+}
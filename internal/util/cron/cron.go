@@ -0,0 +1,208 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid cron schedule")
+
+// Schedule is a parsed cron expression of the five fields minute, hour, day of month, month and day of week.
+//
+// Every field is either '*' or a comma separated list of values, ranges 'a-b' and steps '*/n' or 'a-b/n'.
+// Months and days of week may be given by their English three letter names, a day of week of 7 is Sunday.
+// The macros @hourly, @daily, @midnight, @weekly, @monthly, @yearly and @annually are accepted as well.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// Like cron, a day matches either field if both day fields are restricted, otherwise both
+	domAny bool
+	dowAny bool
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{
+		name:  "month",
+		min:   1,
+		max:   12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	}
+	dowField = field{
+		name:  "day of week",
+		min:   0,
+		max:   7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"},
+	}
+
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Schedules which do not match any time within this many years are never due, e.g. '0 0 30 2 *'
+const searchYears = 5
+
+// Parses the cron expression, e.g. '30 2 * * 1-5' for 02:30 on every weekday.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	self := &Schedule{
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	for idx, target := range []struct {
+		field field
+		bits  *uint64
+	}{
+		{minuteField, &self.minute},
+		{hourField, &self.hour},
+		{domField, &self.dom},
+		{monthField, &self.month},
+		{dowField, &self.dow},
+	} {
+		bits, err := target.field.parse(fields[idx])
+		if err != nil {
+			return nil, err
+		}
+		*target.bits = bits
+	}
+	// Sunday is both 0 and 7
+	if self.dow&(1<<7) != 0 {
+		self.dow |= 1
+	}
+
+	return self, nil
+}
+
+// Returns the bits of all values the field matches.
+func (self field) parse(value string) (uint64, error) {
+	var bits uint64 = 0
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%w: step %q of %s must be a positive number", ErrInvalidSchedule, stepPart, self.name)
+			}
+			step = n
+		}
+
+		low, high := self.min, self.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = self.value(from); err != nil {
+				return 0, err
+			}
+			if high, err = self.value(to); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%w: range %q of %s is reversed", ErrInvalidSchedule, rangePart, self.name)
+			}
+		default:
+			var err error
+			if low, err = self.value(rangePart); err != nil {
+				return 0, err
+			}
+			// like cron, 'a/n' starts at a and runs to the end of the field
+			high = low
+			if hasStep {
+				high = self.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func (self field) value(s string) (int, error) {
+	for idx, name := range self.names {
+		if strings.EqualFold(s, name) {
+			return self.min + idx, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < self.min || v > self.max {
+		return 0, fmt.Errorf("%w: %s %q must be between %d and %d", ErrInvalidSchedule, self.name, s, self.min, self.max)
+	}
+
+	return v, nil
+}
+
+func (self *Schedule) matchesDay(t time.Time) bool {
+	dom := self.dom&(1<<t.Day()) != 0
+	dow := self.dow&(1<<int(t.Weekday())) != 0
+	if self.domAny || self.dowAny {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// Returns the first time after the given one which the schedule matches, in the location of the given time.
+// Returns the zero time if the schedule never matches, e.g. for the 30th of February.
+func (self *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Year() + searchYears
+
+	for t.Year() <= limit {
+		switch {
+		case self.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !self.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case self.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case self.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
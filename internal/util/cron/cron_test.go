@@ -0,0 +1,64 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, time.June, 4, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2025, time.June, 4, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expected: time.Date(2025, time.June, 4, 10, 30, 0, 0, time.UTC)},
+		{expr: "30 2 * * *", expected: time.Date(2025, time.June, 5, 2, 30, 0, 0, time.UTC)},
+		{expr: "0 9-17/4 * * *", expected: time.Date(2025, time.June, 4, 13, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * sat,sun", expected: time.Date(2025, time.June, 7, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", expected: time.Date(2025, time.June, 8, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 jan *", expected: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 13 * 5", expected: time.Date(2025, time.June, 6, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expected: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", expected: time.Time{}},
+		{expr: "@hourly", expected: time.Date(2025, time.June, 4, 11, 0, 0, 0, time.UTC)},
+		{expr: "@weekly", expected: time.Date(2025, time.June, 8, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("expected valid schedule, got %v", err)
+			}
+			if next := schedule.Next(from); !next.Equal(tt.expected) {
+				t.Errorf("expected next run at %s, got %s", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * * monday",
+		"@reboot",
+	} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("expected schedule %q to be rejected, got %v", expr, err)
+		}
+	}
+}
@@ -34,6 +34,13 @@ func (f FileEnvironmentManager) Setup() error {
 	return os.MkdirAll(f.base, f.mode)
 }
 
+// Returns the manager of the socket file inside the translated base path, e.g. the path of the host
+// which is mounted at the base path of a container.
+func (f FileEnvironmentManager) Translate(translate func(path string) string) FileEnvironmentManager {
+	f.base = translate(f.base)
+	return f
+}
+
 func (f FileEnvironmentManager) SocketPath() string {
 	return filepath.Join(f.base, f.socketFile)
 }
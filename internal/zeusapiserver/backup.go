@@ -0,0 +1,242 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/snapshot"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+const (
+	// Interval in which the backup schedules are checked
+	backupScheduleInterval = time.Minute
+	snapshotIDFormat       = "20060102T150405Z"
+)
+
+var ErrSnapshotTarget = errors.New("snapshot is kept in another store")
+
+// Takes and restores snapshots of volumes and runs the backup schedules of all applications.
+type backups struct {
+	records *RecordCollection
+	store   snapshot.Store
+	logger  *log.Logger
+	cancel  context.CancelFunc
+
+	// Serializes all snapshots and restores, a volume must not be restored while a snapshot of it is taken
+	mu sync.Mutex
+}
+
+func newBackups(
+	records *RecordCollection,
+	store snapshot.Store,
+	logger *log.Logger,
+) *backups {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	self := &backups{
+		records: records,
+		store:   store,
+		logger:  logger,
+		cancel:  cancel,
+	}
+	go self.worker(ctx)
+
+	return self
+}
+
+func (self *backups) close() {
+	self.cancel()
+}
+
+func snapshotKey(app application, volume string, id string) string {
+	return fmt.Sprintf("%s/%s/%s.tar.gz", app, volume, id)
+}
+
+// Returns an ID for a snapshot taken now, which is unique inside the application.
+func (self *backups) nextSnapshotID(app application, now time.Time) (string, error) {
+	state, err := self.records.get(app)
+	if err != nil {
+		return "", err
+	}
+
+	id := now.UTC().Format(snapshotIDFormat)
+	for n := 2; state.Snapshot(id) != nil; n++ {
+		id = fmt.Sprintf("%s-%d", now.UTC().Format(snapshotIDFormat), n)
+	}
+
+	return id, nil
+}
+
+// Archives the volume into the store and records the snapshot.
+func (self *backups) backup(
+	ctx context.Context,
+	app application,
+	volume string,
+	scheduled bool,
+) (record.SnapshotRecord, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	now := time.Now()
+	id, err := self.nextSnapshotID(app, now)
+	if err != nil {
+		return record.SnapshotRecord{}, err
+	}
+
+	tarball, err := runtime.ArchiveVolume(ctx, string(app), volume)
+	if err != nil {
+		return record.SnapshotRecord{}, err
+	}
+	archive, err := snapshot.Compress(tarball)
+	if closeErr := tarball.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return record.SnapshotRecord{}, err
+	}
+	defer archive.Close()
+
+	snap := record.SnapshotRecord{
+		ID:        id,
+		Volume:    volume,
+		Target:    self.store.Target(),
+		Key:       snapshotKey(app, volume, id),
+		Size:      archive.Size,
+		Checksum:  archive.Checksum,
+		CreatedAt: now,
+		Scheduled: scheduled,
+	}
+	if err := archive.Upload(ctx, self.store, snap.Key); err != nil {
+		return record.SnapshotRecord{}, err
+	}
+
+	err = self.records.tx(app, func(state *record.ApplicationRecord) error {
+		state.AddSnapshot(snap)
+		return nil
+	})
+	if err != nil {
+		// the application was deleted in the meantime, nobody can restore the archive
+		self.store.Delete(context.Background(), snap.Key)
+		return record.SnapshotRecord{}, err
+	}
+
+	return snap, nil
+}
+
+// Replaces the content of the volume with the snapshot. The snapshot may be taken of another volume.
+func (self *backups) restore(
+	ctx context.Context,
+	app application,
+	volume string,
+	snap record.SnapshotRecord,
+) error {
+	if snap.Target != self.store.Target() {
+		return fmt.Errorf("%w: %s", ErrSnapshotTarget, snap.Target)
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	archive, err := snapshot.Download(ctx, self.store, snap.Key, snap.Checksum)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	tarball, err := archive.Tarball()
+	if err != nil {
+		return err
+	}
+	defer tarball.Close()
+
+	return runtime.RestoreVolume(ctx, string(app), volume, tarball)
+}
+
+// Removes the archive of the snapshot from the store and forgets the snapshot.
+func (self *backups) delete(ctx context.Context, app application, snap record.SnapshotRecord) error {
+	if snap.Target == self.store.Target() {
+		if err := self.store.Delete(ctx, snap.Key); err != nil {
+			return err
+		}
+	}
+
+	return self.records.tx(app, func(state *record.ApplicationRecord) error {
+		state.RemoveSnapshot(snap.ID)
+		return nil
+	})
+}
+
+// Deletes the scheduled snapshots of the volume which exceed the retention.
+func (self *backups) prune(ctx context.Context, app application, volume string, retain int) error {
+	state, err := self.records.get(app)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range state.ExpiredSnapshots(volume, retain) {
+		self.logger.Info("Remove snapshot '%s' of volume '%s' of application '%s'", snap.ID, volume, app)
+		if err := self.delete(ctx, app, snap); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checks the schedules of all applications every interval and takes the snapshots which are due.
+func (self *backups) worker(ctx context.Context) {
+	ticker := time.NewTicker(backupScheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		self.runSchedules(ctx, time.Now())
+	}
+}
+
+// Takes the snapshots of all schedules which are due. A failed snapshot is retried on the next check.
+func (self *backups) runSchedules(ctx context.Context, now time.Time) {
+	for _, state := range self.records.all() {
+		app := application(state.Metadata.Application)
+		for _, schedule := range state.Schedules {
+			if !schedule.Due(now) {
+				continue
+			}
+
+			snap, err := self.backup(ctx, app, schedule.Volume, true)
+			if err != nil {
+				self.logger.Error("Failed to take snapshot of volume '%s' of application '%s': %v", schedule.Volume, app, err)
+				continue
+			}
+			self.logger.Info("Took snapshot '%s' of volume '%s' of application '%s'", snap.ID, schedule.Volume, app)
+
+			err = self.records.tx(app, func(state *record.ApplicationRecord) error {
+				if s := state.Schedule(schedule.Volume); s != nil {
+					s.LastRun = now
+				}
+				return nil
+			})
+			if err != nil {
+				continue
+			}
+
+			if err := self.prune(ctx, app, schedule.Volume, schedule.Retain); err != nil {
+				self.logger.Error("Failed to prune snapshots of volume '%s' of application '%s': %v", schedule.Volume, app, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/docker/docker/client"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/snapshot"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultDaemonConfigPath         string = "/etc/zeus/zeusd.yml"
+	enviornmentNameZeusDaemonConfig string = "ZEUSD_CONFIG"
+)
+
+const (
+	runtimeBackendDocker = "docker"
+	runtimeBackendPodman = "podman"
+)
+
+const defaultSnapshotDirectory = "/var/lib/zeus/snapshots"
+
+type DaemonConfigRuntime struct {
+	// Container engine which runs the applications, either docker or podman
+	Backend string `yaml:"backend"`
+	// Path of the API socket of the container engine, the default socket is used if empty
+	Socket string `yaml:"socket"`
+	// Podman runs as an unprivileged user
+	Rootless bool `yaml:"rootless"`
+	// Host ports which are bound instead of the requested ones, e.g. "80": "8080"
+	Ports map[string]string `yaml:"ports"`
+	// Host paths which are mounted instead of the requested ones, e.g. "/run/zeus": "/run/user/1000/zeus"
+	Paths map[string]string `yaml:"paths"`
+}
+
+type DaemonConfigSnapshotsS3 struct {
+	// URL of the endpoint, e.g. http://127.0.0.1:9000 for a local MinIO
+	Endpoint  string `yaml:"endpoint"`
+	Bucket    string `yaml:"bucket"`
+	Region    string `yaml:"region"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	// Prepended to the key of every snapshot
+	Prefix string `yaml:"prefix"`
+}
+
+type DaemonConfigSnapshots struct {
+	// Directory of the host in which the snapshots are stored, unless a bucket is configured
+	Directory string `yaml:"directory"`
+	// Bucket of an S3 compatible storage in which the snapshots are stored
+	S3 *DaemonConfigSnapshotsS3 `yaml:"s3"`
+}
+
+type DaemonConfig struct {
+	Runtime   DaemonConfigRuntime   `yaml:"runtime"`
+	Snapshots DaemonConfigSnapshots `yaml:"snapshots"`
+}
+
+// Loads the daemon config from $ZEUSD_CONFIG or the default path. If no config file exists,
+// the default config is returned.
+func loadDaemonConfig() (*DaemonConfig, error) {
+	path := defaultDaemonConfigPath
+	if zeusConfig := os.Getenv(enviornmentNameZeusDaemonConfig); zeusConfig != "" {
+		path = zeusConfig
+	}
+
+	cfg := &DaemonConfig{
+		Runtime: DaemonConfigRuntime{
+			Backend: runtimeBackendDocker,
+		},
+		Snapshots: DaemonConfigSnapshots{
+			Directory: defaultSnapshotDirectory,
+		},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Creates the runtime backend which is configured.
+func (self *DaemonConfigRuntime) newBackend() (runtime.Backend, error) {
+	switch self.Backend {
+	case runtimeBackendDocker:
+		if self.Rootless || len(self.Ports) != 0 || len(self.Paths) != 0 {
+			return nil, errors.New("rootless, ports and paths are only supported by the podman backend")
+		}
+		if self.Socket == "" {
+			return runtime.NewDockerBackend()
+		}
+		return runtime.NewDockerBackend(client.WithHost("unix://" + self.Socket))
+
+	case runtimeBackendPodman:
+		return runtime.NewPodmanBackend(runtime.PodmanOptions{
+			Socket:      self.Socket,
+			Rootless:    self.Rootless,
+			PortMapping: self.Ports,
+			PathMapping: self.Paths,
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown runtime backend '%s', expected '%s' or '%s'",
+			self.Backend, runtimeBackendDocker, runtimeBackendPodman,
+		)
+	}
+}
+
+// Creates the store of the snapshots which is configured.
+func (self *DaemonConfigSnapshots) newStore() (snapshot.Store, error) {
+	if self.S3 != nil {
+		return snapshot.NewS3Store(snapshot.S3Options{
+			Endpoint:  self.S3.Endpoint,
+			Bucket:    self.S3.Bucket,
+			Region:    self.S3.Region,
+			AccessKey: self.S3.AccessKey,
+			SecretKey: self.S3.SecretKey,
+			Prefix:    self.S3.Prefix,
+		})
+	}
+
+	return snapshot.NewDirectoryStore(self.Directory)
+}
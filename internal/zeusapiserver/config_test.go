@@ -0,0 +1,162 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/nginxcontroller"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/snapshot"
+)
+
+func TestLoadDaemonConfigDefaults(t *testing.T) {
+	t.Setenv(enviornmentNameZeusDaemonConfig, filepath.Join(t.TempDir(), "missing.yml"))
+
+	config, err := loadDaemonConfig()
+	if err != nil {
+		t.Fatalf("expected missing config to fall back to defaults, got %q", err)
+	}
+	if config.Runtime.Backend != runtimeBackendDocker {
+		t.Errorf("expected default backend '%s', got '%s'", runtimeBackendDocker, config.Runtime.Backend)
+	}
+	if config.Snapshots.Directory != defaultSnapshotDirectory || config.Snapshots.S3 != nil {
+		t.Errorf("expected snapshots in the default directory, got '%v'", config.Snapshots)
+	}
+}
+
+func TestLoadDaemonConfigPodman(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zeusd.yml")
+	err := os.WriteFile(path, []byte(`
+runtime:
+  backend: podman
+  rootless: true
+  ports:
+    "80": "8080"
+  paths:
+    /run/zeus: /run/user/1000/zeus
+`), 0600)
+	if err != nil {
+		t.Fatalf("failed to write config, got %q", err)
+	}
+	t.Setenv(enviornmentNameZeusDaemonConfig, path)
+
+	config, err := loadDaemonConfig()
+	if err != nil {
+		t.Fatalf("expected valid config, got %q", err)
+	}
+	if config.Runtime.Backend != runtimeBackendPodman || !config.Runtime.Rootless {
+		t.Errorf("runtime not decoded correctly, got '%v'", config.Runtime)
+	}
+	if config.Runtime.Ports["80"] != "8080" || config.Runtime.Paths["/run/zeus"] != "/run/user/1000/zeus" {
+		t.Errorf("mappings not decoded correctly, got '%v'", config.Runtime)
+	}
+	if _, err := config.Runtime.newBackend(); err != nil {
+		t.Errorf("expected podman backend to be created, got %q", err)
+	}
+}
+
+func TestDaemonConfigRejectsInvalidBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime DaemonConfigRuntime
+	}{
+		{name: "unknown.backend", runtime: DaemonConfigRuntime{Backend: "containerd"}},
+		{name: "rootless.docker", runtime: DaemonConfigRuntime{Backend: runtimeBackendDocker, Rootless: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.runtime.newBackend(); err == nil {
+				t.Errorf("expected backend to be rejected")
+			}
+		})
+	}
+}
+
+func TestLoadDaemonConfigSnapshotsS3(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zeusd.yml")
+	err := os.WriteFile(path, []byte(`
+snapshots:
+  s3:
+    endpoint: http://127.0.0.1:9000
+    bucket: zeus
+    accessKey: minio
+    secretKey: minio123
+`), 0600)
+	if err != nil {
+		t.Fatalf("failed to write config, got %q", err)
+	}
+	t.Setenv(enviornmentNameZeusDaemonConfig, path)
+
+	config, err := loadDaemonConfig()
+	if err != nil {
+		t.Fatalf("expected valid config, got %q", err)
+	}
+	store, err := config.Snapshots.newStore()
+	if err != nil {
+		t.Fatalf("expected s3 store to be created, got %q", err)
+	}
+	if store.Target() != snapshot.TargetS3 {
+		t.Errorf("expected snapshots to be stored in s3, got '%s'", store.Target())
+	}
+}
+
+func TestRootlessPodmanResolvesDaemonPaths(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	path := filepath.Join(t.TempDir(), "zeusd.yml")
+	err := os.WriteFile(path, []byte(`
+runtime:
+  backend: podman
+  rootless: true
+`), 0600)
+	if err != nil {
+		t.Fatalf("failed to write config, got %q", err)
+	}
+	t.Setenv(enviornmentNameZeusDaemonConfig, path)
+
+	config, err := loadDaemonConfig()
+	if err != nil {
+		t.Fatalf("expected valid config, got %q", err)
+	}
+	backend, err := config.Runtime.newBackend()
+	if err != nil {
+		t.Fatalf("expected podman backend to be created, got %q", err)
+	}
+	previous := runtime.SetBackend(backend)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	// the daemon sets up the sockets it shares with the containers and listens inside the runtime directory
+	for _, environmentManager := range environmentManagers {
+		if err := environmentManager.Setup(); err != nil {
+			t.Fatalf("expected environment to be set up, got %q", err)
+		}
+	}
+	for _, setup := range setups {
+		if err := setup(); err != nil {
+			t.Fatalf("expected setup to succeed, got %q", err)
+		}
+	}
+	listen, err := listenSocket()
+	if err != nil {
+		t.Fatalf("expected daemon to listen, got %q", err)
+	}
+	defer listen.Close()
+
+	root := filepath.Join(runtimeDir, "zeus")
+	if listen.Addr().String() != filepath.Join(root, "zeusd.sock") {
+		t.Errorf("expected daemon to listen inside '%s', got '%s'", root, listen.Addr())
+	}
+	if nginxcontroller.HostSocketDirectory() != filepath.Join(root, "ingress") {
+		t.Errorf("expected ingress socket inside '%s', got '%s'", root, nginxcontroller.HostSocketDirectory())
+	}
+	for _, dir := range []string{"dns", "ingress"} {
+		if _, err := os.Stat(filepath.Join(root, dir)); err != nil {
+			t.Errorf("expected directory '%s' to be created inside '%s', got %q", dir, root, err)
+		}
+	}
+}
@@ -7,13 +7,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 	log "github.com/raphaeldichler/zeus/internal/util/logger"
 	"go.etcd.io/bbolt"
@@ -38,8 +39,14 @@ func CreateApplicationAPIPath() string {
 	return createApplicationAPIPath
 }
 
-func DeleteApplicationAPIPath(application string) string {
-	return strings.Replace(deleteApplicationAPIPath, "{application}", application, 1)
+// If purge is set, the volumes of the application and their data are removed as well.
+func DeleteApplicationAPIPath(application string, purge bool) string {
+	path := strings.Replace(deleteApplicationAPIPath, "{application}", application, 1)
+	if purge {
+		path += "?purge=true"
+	}
+
+	return path
 }
 
 func InspectApplicationAPIPath(application string) string {
@@ -82,7 +89,7 @@ func decodeApplicationName(application string, w http.ResponseWriter) error {
 	}
 
 	if len(application) >= bbolt.MaxKeySize {
-		replyBadRequest(w, fmt.Sprintf("Application name exceeds maximum allowed length of %d", bbolt.MaxKeySize))
+		replyBadRequest(w, "Application name exceeds maximum allowed length of %d", bbolt.MaxKeySize)
 		return ErrBadRequestApplication
 	}
 
@@ -97,20 +104,25 @@ func decodeApplicationName(application string, w http.ResponseWriter) error {
 type CreateApplicationRequest struct {
 	Application    application
 	DeploymentType record.DeploymentType
+	// The application network is dual stack
+	IPv6 bool
 }
 
 type JsonCreateApplicationRequest struct {
 	Application    string `json:"application"`
 	DeploymentType string `json:"deploymentType"`
+	IPv6           bool   `json:"ipv6,omitempty"`
 }
 
 func NewCreateApplicationRequestAsJsonBody(
 	application string,
 	deploymentType string,
+	ipv6 bool,
 ) io.Reader {
 	data := &JsonCreateApplicationRequest{
 		Application:    application,
 		DeploymentType: deploymentType,
+		IPv6:           ipv6,
 	}
 
 	jsonData, err := json.Marshal(data)
@@ -157,6 +169,7 @@ func (self *ApplicationController) DecoderCreateApplicationRequest(
 	}
 
 	out.Application = application(jsonRequest.Application)
+	out.IPv6 = jsonRequest.IPv6
 	return nil
 }
 
@@ -170,7 +183,7 @@ func (self *ApplicationController) CreateApplication(
 	app := string(command.Application)
 	self.logger.Info("Received request to create application: %q", app)
 
-	err := self.records.add(command.Application, command.DeploymentType)
+	err := self.records.add(command.Application, command.DeploymentType, command.IPv6)
 	if err != nil {
 		self.logger.Error("Application creation failed for %q: already exists", app)
 		replyBadRequest(w, "Application already exists.")
@@ -183,6 +196,8 @@ func (self *ApplicationController) CreateApplication(
 
 type DeleteApplicationRequest struct {
 	Application application
+	// Removes the volumes of the application
+	Purge bool
 }
 
 func (self *ApplicationController) DecoderDeleteApplicationRequest(
@@ -198,6 +213,13 @@ func (self *ApplicationController) DecoderDeleteApplicationRequest(
 	}
 
 	out.Application = application(a)
+
+	purge, err := decodeQueryBool(r.URL.Query().Get("purge"), "purge", w)
+	if err != nil {
+		return err
+	}
+	out.Purge = purge
+
 	return nil
 }
 
@@ -211,6 +233,25 @@ func (self *ApplicationController) DeleteApplication(
 	app := string(command.Application)
 	self.logger.Info("Received request to delete application: %q", app)
 
+	if command.Purge {
+		state, err := self.records.get(command.Application)
+		if err != nil {
+			replyBadRequest(w, "Cannot delete application, because not found.")
+			return
+		}
+		// volumes cannot be removed while the containers of the application use them
+		if state.Metadata.Enabled {
+			replyBadRequest(w, "Cannot purge application, because it is enabled. Disable it first.")
+			return
+		}
+		if err := runtime.RemoveApplicationVolumes(r.Context(), app); err != nil {
+			self.logger.Error("Failed to remove volumes of application %q: %v", app, err)
+			replyBadRequest(w, "Cannot purge application, removing its volumes failed: %v", err)
+			return
+		}
+		self.logger.Info("Volumes of application %q removed", app)
+	}
+
 	err := self.records.delete(command.Application)
 	if err != nil {
 		self.logger.Error("Failed to delete application %q: not found", app)
@@ -233,9 +274,49 @@ type InspectApplicationRequest struct {
 }
 
 type InspectApplicationResponse struct {
-	Application    string `json:"application"`
-	DeploymentType string `json:"deploymentType"`
-	Enabled        bool   `json:"enabled"`
+	Application    string                      `json:"application"`
+	DeploymentType string                      `json:"deploymentType"`
+	Enabled        bool                        `json:"enabled"`
+	Network        InspectApplicationNetwork   `json:"network"`
+	Repairs        []InspectApplicationRepairs `json:"repairs,omitempty"`
+}
+
+type InspectApplicationNetwork struct {
+	IPv6     bool   `json:"ipv6"`
+	Subnet   string `json:"subnet,omitempty"`
+	SubnetV6 string `json:"subnetV6,omitempty"`
+}
+
+type InspectApplicationRepairs struct {
+	Object     string    `json:"object"`
+	Name       string    `json:"name"`
+	Action     string    `json:"action"`
+	DetectedAt time.Time `json:"detectedAt"`
+	RepairedAt time.Time `json:"repairedAt"`
+}
+
+func buildApplicationResponse(app *record.ApplicationRecord) InspectApplicationResponse {
+	response := InspectApplicationResponse{
+		Application:    app.Metadata.Application,
+		DeploymentType: app.Metadata.Deployment.String(),
+		Enabled:        app.Metadata.Enabled,
+		Network: InspectApplicationNetwork{
+			IPv6:     app.Network.IPv6,
+			Subnet:   app.Network.Subnet,
+			SubnetV6: app.Network.SubnetV6,
+		},
+	}
+	for _, repair := range app.Repairs {
+		response.Repairs = append(response.Repairs, InspectApplicationRepairs{
+			Object:     repair.Object,
+			Name:       repair.Name,
+			Action:     repair.Action,
+			DetectedAt: repair.DetectedAt,
+			RepairedAt: repair.RepairedAt,
+		})
+	}
+
+	return response
 }
 
 func (self *ApplicationController) DecoderInspectApplicationRequest(
@@ -275,11 +356,7 @@ func (self *ApplicationController) InspectAllApplication(
 	for _, e := range records {
 		response.Applications = append(
 			response.Applications,
-			InspectApplicationResponse{
-				Application:    e.Metadata.Application,
-				DeploymentType: e.Metadata.Deployment.String(),
-				Enabled:        e.Metadata.Enabled,
-			},
+			buildApplicationResponse(e),
 		)
 	}
 
@@ -306,11 +383,7 @@ func (self *ApplicationController) InspectApplication(
 	self.logger.Info("Inspected application: %q", appName)
 
 	err = json.NewEncoder(w).Encode(
-		InspectApplicationResponse{
-			Application:    app.Metadata.Application,
-			DeploymentType: app.Metadata.Deployment.String(),
-			Enabled:        app.Metadata.Enabled,
-		},
+		buildApplicationResponse(app),
 	)
 	assert.ErrNil(err)
 	w.WriteHeader(http.StatusOK)
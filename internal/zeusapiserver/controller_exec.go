@@ -0,0 +1,228 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+var ErrBadRequestExec = errors.New("bad request: exec")
+
+const serviceExecAPIPath = "/v1.0/applications/{application}/services/{service}/exec"
+
+type ExecQuery struct {
+	Cmd   []string
+	Tty   bool
+	Stdin bool
+	// Initial size of the TTY
+	Height uint
+	Width  uint
+}
+
+func ServiceExecAPIPath(application string, service string, query ExecQuery) string {
+	path := strings.Replace(serviceExecAPIPath, "{application}", application, 1)
+	path = strings.Replace(path, "{service}", service, 1)
+
+	values := url.Values{"cmd": query.Cmd}
+	values.Set("tty", strconv.FormatBool(query.Tty))
+	values.Set("stdin", strconv.FormatBool(query.Stdin))
+	if query.Height > 0 && query.Width > 0 {
+		values.Set("height", strconv.FormatUint(uint64(query.Height), 10))
+		values.Set("width", strconv.FormatUint(uint64(query.Width), 10))
+	}
+
+	return path + "?" + values.Encode()
+}
+
+type ServiceExecRequest struct {
+	Application application
+	Service     record.RecordKey
+	Options     runtime.ExecOptions
+}
+
+func decodeExecSize(value string, name string, w http.ResponseWriter) (uint, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	size, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		replyBadRequest(w, "Query parameter %s must be a terminal size", name)
+		return 0, ErrBadRequestExec
+	}
+
+	return uint(size), nil
+}
+
+func PostServiceExecRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceExecRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	s := r.PathValue("service")
+	if err := decodeServiceName(s, w); err != nil {
+		return err
+	}
+	out.Service = record.RecordKey(s)
+
+	if !strings.EqualFold(r.Header.Get("Upgrade"), ExecUpgradeProtocol) {
+		replyBadRequest(w, "Exec requires an upgrade to %s", ExecUpgradeProtocol)
+		return ErrBadRequestExec
+	}
+
+	query := r.URL.Query()
+	out.Options.Cmd = query["cmd"]
+	if len(out.Options.Cmd) == 0 || out.Options.Cmd[0] == "" {
+		replyBadRequest(w, "Command must not be empty")
+		return ErrBadRequestExec
+	}
+
+	var err error
+	if out.Options.Tty, err = decodeQueryBool(query.Get("tty"), "tty", w); err != nil {
+		return err
+	}
+	if out.Options.Stdin, err = decodeQueryBool(query.Get("stdin"), "stdin", w); err != nil {
+		return err
+	}
+	if out.Options.Height, err = decodeExecSize(query.Get("height"), "height", w); err != nil {
+		return err
+	}
+	if out.Options.Width, err = decodeExecSize(query.Get("width"), "width", w); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Upgrades the connection and proxies the exec session of the command in the service container until
+// the command exits or the client disconnects.
+func (self *ZeusController) PostServiceExec(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceExecRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	if state.Service.Get(command.Service) == nil {
+		replyBadRequest(w, "Service does not exist")
+		return
+	}
+
+	container, err := runtime.FindContainer(
+		r.Context(),
+		string(command.Application),
+		runtime.ObjectTypeLabel(runtime.ServiceObject),
+		runtime.ServiceNameLabel(string(command.Service)),
+		runtime.ServiceRoleLabel(runtime.MainContainerRole),
+	)
+	if errors.Is(err, runtime.ErrContainerNotFound) {
+		replyBadRequest(w, "Service is not running")
+		return
+	}
+	if err != nil {
+		replyBadRequest(w, "Failed to find the container: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	session, err := container.Exec(ctx, command.Options)
+	if err != nil {
+		replyBadRequest(w, "Failed to exec: %v", err)
+		return
+	}
+	defer session.Close()
+
+	hijacker, ok := w.(http.Hijacker)
+	assert.True(ok, "response writer of the http server supports hijacking")
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		replyBadRequest(w, "Failed to upgrade the connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	_, err = io.WriteString(
+		conn,
+		"HTTP/1.1 101 Switching Protocols\r\n"+
+			"Connection: Upgrade\r\n"+
+			"Upgrade: "+ExecUpgradeProtocol+"\r\n\r\n",
+	)
+	if err != nil {
+		return
+	}
+
+	logger := log.New(string(command.Application), "exec")
+	go proxyExecInput(ctx, buffered, session, logger)
+
+	writers := NewExecFrameWriters(conn, ExecFrameStdout, ExecFrameStderr, ExecFrameExit, ExecFrameError)
+	stdout, stderr, exit, failure := writers[0], writers[1], writers[2], writers[3]
+
+	if err := session.CopyOutput(stdout, stderr); err != nil && ctx.Err() == nil {
+		logger.Error("Failed to copy output of exec in %s: %v", container, err)
+		failure.WriteFrame([]byte(err.Error()))
+		return
+	}
+
+	exitCode, err := session.ExitCode(ctx)
+	if err != nil {
+		failure.WriteFrame([]byte(err.Error()))
+		return
+	}
+	exit.WriteFrame(EncodeExecExit(exitCode))
+}
+
+// Forwards the input frames of the client to the session. If the client disconnects, the session is closed.
+func proxyExecInput(ctx context.Context, r io.Reader, session *runtime.ExecSession, logger *log.Logger) {
+	defer session.Close()
+
+	for {
+		typ, payload, err := ReadExecFrame(r)
+		if err != nil {
+			return
+		}
+
+		switch typ {
+		case ExecFrameStdin:
+			if _, err := session.Write(payload); err != nil {
+				return
+			}
+		case ExecFrameCloseStdin:
+			if err := session.CloseStdin(); err != nil {
+				return
+			}
+		case ExecFrameResize:
+			height, width, ok := DecodeExecResize(payload)
+			if !ok {
+				continue
+			}
+			if err := session.Resize(ctx, height, width); err != nil {
+				logger.Error("Failed to resize exec session: %v", err)
+			}
+		default:
+			// unknown frames are ignored, which allows adding frames later
+		}
+	}
+}
@@ -12,8 +12,9 @@ import (
 	"time"
 
 	"github.com/raphaeldichler/zeus/internal/ingress"
-	"github.com/raphaeldichler/zeus/internal/ingress/errtype"
 	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	runtimeErr "github.com/raphaeldichler/zeus/internal/runtime/errtype"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 	bboltErr "go.etcd.io/bbolt/errors"
 )
@@ -79,9 +80,11 @@ type CertificateInspectResponse struct {
 
 type ContainerInspectResponse struct {
 	ContainerID string `json:"containerId"`
-	Image       string `json:"image"`
-	ImageID     string `json:"imageId"`
-	State       string `json:"state"`
+	// Tag of the image and the immutable reference it was resolved to
+	Image   string `json:"image"`
+	Digest  string `json:"digest"`
+	ImageID string `json:"imageId"`
+	State   string `json:"state"`
 }
 
 type IngressApplyRequestBody struct {
@@ -153,6 +156,22 @@ func (self *ZeusController) PostIngressApply(
 
 	fmt.Println(command)
 
+	// the image of the ingress is resolved to its digest once, when the ingress is created
+	digest := ""
+	if state, err := self.records.get(application(command.Application)); err == nil {
+		image := record.DefaultIngressImage
+		if state.Ingress != nil {
+			image = state.Ingress.Metadata.Image
+		}
+		if state.Ingress == nil || state.Ingress.Metadata.Digest == "" {
+			digest, err = runtime.ResolveImage(r.Context(), image, pullOptions(state, image))
+			if err != nil {
+				replyBadRequest(w, "Failed to resolve ingress image %q: %v", image, err)
+				return
+			}
+		}
+	}
+
 	err := self.records.tx(
 		application(command.Application),
 		func(r *record.ApplicationRecord) error {
@@ -161,6 +180,9 @@ func (self *ZeusController) PostIngressApply(
 				ingress = record.NewIngressRecord()
 				r.Ingress = ingress
 			}
+			if ingress.Metadata.Digest == "" {
+				ingress.Metadata.Digest = digest
+			}
 
 			serverFilter := newServerFilter(ingress.Servers)
 			ingress.Servers = nil
@@ -208,6 +230,7 @@ func (self *ZeusController) PostIngressApply(
 						Path:     path.Path,
 						Matching: path.Matching,
 						Service:  record.RecordKey(path.Service.Name),
+						Port:     path.Service.Port,
 					}
 					server.HTTP.Paths = append(server.HTTP.Paths, loc)
 				}
@@ -233,19 +256,19 @@ func buildServerResponse(state *record.ApplicationRecord) []ServerInspectRespons
 				path = "= " + serverPath.Path
 			}
 
-			fmt.Print("host", server.Host)
-			fmt.Println(" | path", path)
+			backend := state.Service.GetEndpoint(serverPath.Service, serverPath.Port)
+			if backend == "" {
+				backend = "-"
+			}
+
 			servers = append(servers, ServerInspectResponse{
 				Host:     server.Host,
 				Path:     path,
-				Backends: state.Service.GetEndpoint(serverPath.Service),
+				Backends: backend,
 			})
 		}
 	}
 
-	fmt.Println("servers")
-	fmt.Println(servers)
-
 	return servers
 }
 
@@ -332,6 +355,7 @@ func (self *ZeusController) GetIngressInspect(
 		Container: ContainerInspectResponse{
 			ContainerID: "-",
 			Image:       i.Metadata.Image,
+			Digest:      i.Metadata.ImageReference(),
 			ImageID:     "-",
 			State:       "Not Created",
 		},
@@ -340,18 +364,18 @@ func (self *ZeusController) GetIngressInspect(
 		Errors:       buildErrorResponse(state),
 	}
 
-	container, ok := ingress.SelectIngressContainer(state)
-	if !ok {
+	optionalContainer := ingress.SelectIngressContainer(r.Context(), state)
+	if optionalContainer.IsEmpty() {
 		w.WriteHeader(http.StatusOK)
 		err = json.NewEncoder(w).Encode(response)
 		assert.ErrNil(err)
 		return
 	}
 
-	inspect, err := container.Inspect()
+	inspect, err := optionalContainer.Get().Inspect(r.Context())
 	if err != nil {
 		state.Ingress.SetError(
-			errtype.FailedInteractionWithDockerDaemon(errtype.DockerInspectContainer, err),
+			runtimeErr.FailedInteractionWithDockerDaemon(runtimeErr.DockerInspectContainer, err),
 		)
 	} else {
 		response.IP = inspect.NetworkSettings.IPAddress
@@ -0,0 +1,528 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/util/cron"
+	bboltErr "go.etcd.io/bbolt/errors"
+)
+
+var (
+	ErrBadRequestJob = errors.New("bad request: job")
+
+	jobRunIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z(-[0-9]+)?$`)
+)
+
+const (
+	// Number of finished runs which are kept per outcome, unless configured otherwise
+	defaultJobSuccessfulHistory = 3
+	defaultJobFailedHistory     = 1
+)
+
+const (
+	jobApplyAPIPath      = "/v1.0/applications/{application}/jobs"
+	jobInspectAllAPIPath = "/v1.0/applications/{application}/jobs"
+	jobInspectAPIPath    = "/v1.0/applications/{application}/jobs/{job}"
+	jobDeleteAPIPath     = "/v1.0/applications/{application}/jobs/{job}"
+	jobRunAPIPath        = "/v1.0/applications/{application}/jobs/{job}/run"
+)
+
+func jobPath(path string, application string, job string) string {
+	path = strings.Replace(path, "{application}", application, 1)
+	return strings.Replace(path, "{job}", job, 1)
+}
+
+func JobApplyAPIPath(apiVersion string, application string) string {
+	switch apiVersion {
+	case "v1.0":
+		return strings.Replace(jobApplyAPIPath, "{application}", application, 1)
+	default:
+		assert.Unreachable("cover all cases of api version")
+	}
+	return ""
+}
+
+func JobInspectAllAPIPath(application string) string {
+	return strings.Replace(jobInspectAllAPIPath, "{application}", application, 1)
+}
+
+func JobInspectAPIPath(application string, job string) string {
+	return jobPath(jobInspectAPIPath, application, job)
+}
+
+func JobDeleteAPIPath(application string, job string) string {
+	return jobPath(jobDeleteAPIPath, application, job)
+}
+
+func JobRunAPIPath(application string, job string) string {
+	return jobPath(jobRunAPIPath, application, job)
+}
+
+type JobApplyRequestBody struct {
+	Metadata struct {
+		Name string `json:"name" yaml:"name"`
+	} `json:"metadata" yaml:"metadata"`
+	Spec struct {
+		// Cron expression, e.g. '0 3 * * *', jobs without one only run when triggered
+		Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+		// One of allow, forbid or replace, defaults to forbid
+		Concurrency string `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+		// Runs which take longer are stopped and failed, e.g. 30m
+		Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+		History struct {
+			// Number of finished runs which are kept per outcome, defaults to 3 and 1
+			Successful *int `json:"successful,omitempty" yaml:"successful,omitempty"`
+			Failed     *int `json:"failed,omitempty" yaml:"failed,omitempty"`
+		} `json:"history" yaml:"history"`
+		Container struct {
+			Image string `json:"image" yaml:"image"`
+			// Replaces the command of the image
+			Command []string                   `json:"command,omitempty" yaml:"command,omitempty"`
+			Env     []ServiceEnvRequestBody    `json:"env,omitempty" yaml:"env,omitempty"`
+			Volumes []ServiceVolumeRequestBody `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+		} `json:"container" yaml:"container"`
+	} `json:"spec" yaml:"spec"`
+}
+
+type JobApplyRequest struct {
+	Application application
+	JobApplyRequestBody
+}
+
+type JobInspectAllRequest struct {
+	Application application
+}
+
+type JobInspectRequest struct {
+	Application application
+	Job         string
+}
+
+type JobDeleteRequest struct {
+	Application application
+	Job         string
+}
+
+type JobRunRequest struct {
+	Application application
+	Job         string
+}
+
+type JobInspectAllResponse struct {
+	Jobs []JobInspectResponse `json:"jobs"`
+}
+
+type JobInspectResponse struct {
+	Name        string   `json:"name"`
+	Image       string   `json:"image"`
+	Digest      string   `json:"digest"`
+	Command     []string `json:"command"`
+	Schedule    string   `json:"schedule"`
+	Concurrency string   `json:"concurrency"`
+	Timeout     string   `json:"timeout"`
+	// Time of the next scheduled run, nil if the job has no schedule
+	NextRun *time.Time              `json:"nextRun,omitempty"`
+	Runs    []JobRunInspectResponse `json:"runs"`
+}
+
+type JobRunInspectResponse struct {
+	ID  string `json:"id"`
+	Job string `json:"job"`
+	// Either schedule or manual
+	Trigger    string     `json:"trigger"`
+	State      string     `json:"state"`
+	ExitCode   *int       `json:"exitCode,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func decodeJobName(job string, w http.ResponseWriter) error {
+	if !serviceNamePattern.MatchString(job) {
+		replyBadRequest(w, "Job name must consist of lowercase letters, digits and '-', at most 63 chars")
+		return ErrBadRequestJob
+	}
+
+	return nil
+}
+
+func decodeJobRunID(id string, w http.ResponseWriter) error {
+	if !jobRunIDPattern.MatchString(id) {
+		replyBadRequest(w, "Run ID must have the format 20060102T150405Z")
+		return ErrBadRequestJob
+	}
+
+	return nil
+}
+
+// Decodes the application and job of the path.
+func decodeJobPath(w http.ResponseWriter, r *http.Request) (application, string, error) {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return "", "", err
+	}
+
+	j := r.PathValue("job")
+	if err := decodeJobName(j, w); err != nil {
+		return "", "", err
+	}
+
+	return application(a), j, nil
+}
+
+func PostJobApplyRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *JobApplyRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	if err := json.NewDecoder(r.Body).Decode(&out.JobApplyRequestBody); err != nil {
+		replyBadRequest(w, "Invalid JSON payload")
+		return err
+	}
+
+	if err := decodeJobName(out.Metadata.Name, w); err != nil {
+		return err
+	}
+
+	spec := out.Spec
+	if spec.Schedule != "" {
+		if _, err := cron.Parse(spec.Schedule); err != nil {
+			replyBadRequest(w, "Schedule %q is not a valid cron expression: %v", spec.Schedule, err)
+			return ErrBadRequestJob
+		}
+	}
+
+	switch spec.Concurrency {
+	case "", record.ConcurrencyAllow, record.ConcurrencyForbid, record.ConcurrencyReplace:
+	default:
+		replyBadRequest(w, "Concurrency policy %q must be one of allow, forbid or replace", spec.Concurrency)
+		return ErrBadRequestJob
+	}
+
+	if spec.Timeout != "" {
+		if d, err := time.ParseDuration(spec.Timeout); err != nil || d <= 0 {
+			replyBadRequest(w, "Timeout %q must be a positive duration, e.g. 30m", spec.Timeout)
+			return ErrBadRequestJob
+		}
+	}
+
+	for _, limit := range []*int{spec.History.Successful, spec.History.Failed} {
+		if limit != nil && *limit < 0 {
+			replyBadRequest(w, "History must not be negative")
+			return ErrBadRequestJob
+		}
+	}
+
+	if strings.TrimSpace(spec.Container.Image) == "" {
+		replyBadRequest(w, "Container image must not be empty")
+		return ErrBadRequestJob
+	}
+	if err := decodeServiceEnv(spec.Container.Env, w); err != nil {
+		return err
+	}
+	if _, err := decodeVolumeMounts(spec.Container.Volumes, w); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (self *JobApplyRequest) toSpec() record.JobSpec {
+	spec := self.Spec
+	timeout, _ := time.ParseDuration(spec.Timeout)
+	successful, failed := defaultJobSuccessfulHistory, defaultJobFailedHistory
+	if spec.History.Successful != nil {
+		successful = *spec.History.Successful
+	}
+	if spec.History.Failed != nil {
+		failed = *spec.History.Failed
+	}
+
+	return record.JobSpec{
+		Name:              self.Metadata.Name,
+		Image:             spec.Container.Image,
+		Command:           spec.Container.Command,
+		Env:               toServiceEnv(spec.Container.Env),
+		Volumes:           toServiceVolumes(spec.Container.Volumes),
+		Schedule:          spec.Schedule,
+		Concurrency:       spec.Concurrency,
+		Timeout:           timeout,
+		SuccessfulHistory: successful,
+		FailedHistory:     failed,
+	}
+}
+
+func GetJobInspectAllRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *JobInspectAllRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	return nil
+}
+
+func GetJobInspectRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *JobInspectRequest,
+) error {
+	var err error
+	out.Application, out.Job, err = decodeJobPath(w, r)
+	return err
+}
+
+func DeleteJobRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *JobDeleteRequest,
+) error {
+	var err error
+	out.Application, out.Job, err = decodeJobPath(w, r)
+	return err
+}
+
+func PostJobRunRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *JobRunRequest,
+) error {
+	var err error
+	out.Application, out.Job, err = decodeJobPath(w, r)
+	return err
+}
+
+func toJobRunResponse(run record.JobRun) JobRunInspectResponse {
+	response := JobRunInspectResponse{
+		ID:        run.ID,
+		Job:       run.Job,
+		Trigger:   "manual",
+		State:     run.State,
+		Reason:    run.Reason,
+		StartedAt: run.StartedAt,
+	}
+	if run.Scheduled {
+		response.Trigger = "schedule"
+	}
+	if run.State != record.JobRunRunning {
+		response.ExitCode = &run.ExitCode
+		response.FinishedAt = &run.FinishedAt
+	}
+
+	return response
+}
+
+func toJobResponse(state *record.ApplicationRecord, job *record.JobSpec) JobInspectResponse {
+	response := JobInspectResponse{
+		Name:        job.Name,
+		Image:       job.Image,
+		Digest:      job.ImageReference(),
+		Command:     job.Command,
+		Schedule:    job.Schedule,
+		Concurrency: job.ConcurrencyPolicy(),
+		Timeout:     "-",
+		Runs:        make([]JobRunInspectResponse, 0),
+	}
+	if response.Command == nil {
+		response.Command = make([]string, 0)
+	}
+	if job.Timeout > 0 {
+		response.Timeout = job.Timeout.String()
+	}
+	if job.Schedule != "" {
+		schedule, err := cron.Parse(job.Schedule)
+		assert.ErrNil(err)
+		if next := schedule.Next(job.LastScheduled); !next.IsZero() {
+			// a run which is due starts with the next check of the schedules
+			if now := time.Now(); next.Before(now) {
+				next = now
+			}
+			response.NextRun = &next
+		}
+	}
+	for _, run := range state.RunsOfJob(job.Name) {
+		response.Runs = append(response.Runs, toJobRunResponse(run))
+	}
+
+	return response
+}
+
+// Creates or replaces the job. The image tag is resolved to its digest like the image of a service, a
+// replaced job keeps the digest as long as its image is unchanged. Running runs are not affected.
+func (self *ZeusController) PostJobApply(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *JobApplyRequest,
+) {
+	assert.True(command.Application.valid(), "decoder must validate the application")
+
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	job := command.toSpec()
+	if existing := state.Job(job.Name); existing != nil && existing.Image == job.Image {
+		job.Digest = existing.Digest
+	}
+	if job.Digest == "" {
+		digest, err := runtime.ResolveImage(r.Context(), job.Image, pullOptions(state, job.Image))
+		if err != nil {
+			replyBadRequest(w, "Failed to resolve image %q: %v", job.Image, err)
+			return
+		}
+		job.Digest = digest
+	}
+
+	var expired []record.JobRun
+	err = self.records.tx(command.Application, func(state *record.ApplicationRecord) error {
+		// the schedule only runs at times after it was applied
+		job.LastScheduled = time.Now()
+		if existing := state.Job(job.Name); existing != nil && existing.Schedule == job.Schedule {
+			job.LastScheduled = existing.LastScheduled
+		}
+		state.SetJob(job)
+
+		// the history may have been lowered
+		expired = state.ExpiredJobRuns(job.Name)
+		return nil
+	})
+	if errors.Is(err, bboltErr.ErrBucketNotFound) {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	assert.ErrNil(err)
+	self.jobs.prune(command.Application, expired)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (self *ZeusController) GetJobInspectAll(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *JobInspectAllRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	response := JobInspectAllResponse{
+		Jobs: make([]JobInspectResponse, 0, len(state.Jobs)),
+	}
+	for idx := range state.Jobs {
+		response.Jobs = append(response.Jobs, toJobResponse(state, &state.Jobs[idx]))
+	}
+	slices.SortFunc(response.Jobs, func(a, b JobInspectResponse) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
+
+func (self *ZeusController) GetJobInspect(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *JobInspectRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	job := state.Job(command.Job)
+	if job == nil {
+		replyBadRequest(w, "Job does not exist")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(toJobResponse(state, job))
+	assert.ErrNil(err)
+}
+
+// Removes the job. Its running runs keep running, afterwards they are removed with their containers.
+func (self *ZeusController) DeleteJob(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *JobDeleteRequest,
+) {
+	var expired []record.JobRun
+	err := self.records.tx(command.Application, func(state *record.ApplicationRecord) error {
+		if state.Job(command.Job) == nil {
+			return ErrJobNotFound
+		}
+		state.RemoveJob(command.Job)
+
+		expired = state.ExpiredJobRuns(command.Job)
+		return nil
+	})
+	if errors.Is(err, ErrJobNotFound) {
+		replyBadRequest(w, "Job does not exist")
+		return
+	}
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	self.jobs.prune(command.Application, expired)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Starts a run of the job now, according to its concurrency policy.
+func (self *ZeusController) PostJobRun(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *JobRunRequest,
+) {
+	run, err := self.jobs.run(r.Context(), command.Application, command.Job, false)
+	switch {
+	case errors.Is(err, bboltErr.ErrBucketNotFound):
+		replyBadRequest(w, "Application does not exist")
+		return
+	case errors.Is(err, ErrApplicationNotEnabled):
+		replyBadRequest(w, "Application must be enabled to run a job")
+		return
+	case errors.Is(err, ErrJobNotFound):
+		replyBadRequest(w, "Job does not exist")
+		return
+	case errors.Is(err, ErrJobRunning):
+		replyBadRequest(w, "Job is already running and its concurrency policy forbids another run")
+		return
+	case err != nil:
+		replyBadRequest(w, "Failed to run job: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(toJobRunResponse(run))
+	assert.ErrNil(err)
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+	"go.etcd.io/bbolt"
+)
+
+func decodeJobApply(body string) (*JobApplyRequest, *httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("POST", JobApplyAPIPath("v1.0", "poseidon"), strings.NewReader(body))
+	r.SetPathValue("application", "poseidon")
+	w := httptest.NewRecorder()
+
+	out := new(JobApplyRequest)
+	err := PostJobApplyRequestDecoder(w, r, out)
+	return out, w, err
+}
+
+func TestJobApplyDecoder(t *testing.T) {
+	out, _, err := decodeJobApply(`{
+		"metadata": {"name": "cleanup"},
+		"spec": {
+			"schedule": "30 2 * * *",
+			"concurrency": "replace",
+			"timeout": "10m",
+			"history": {"failed": 5},
+			"container": {"image": "cleanup:v1", "command": ["cleanup", "--all"], "env": [{"name": "DRY_RUN", "value": "false"}]}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	spec := out.toSpec()
+	if spec.Name != "cleanup" || spec.Schedule != "30 2 * * *" || spec.ConcurrencyPolicy() != record.ConcurrencyReplace {
+		t.Errorf("job not decoded correctly, got '%v'", spec)
+	}
+	if spec.Timeout != 10*time.Minute || len(spec.Command) != 2 || spec.Env["DRY_RUN"] != "false" {
+		t.Errorf("container not decoded correctly, got '%v'", spec)
+	}
+	if spec.SuccessfulHistory != defaultJobSuccessfulHistory || spec.FailedHistory != 5 {
+		t.Errorf("expected default for unset history, got %d and %d", spec.SuccessfulHistory, spec.FailedHistory)
+	}
+}
+
+func TestJobApplyDecoderRejects(t *testing.T) {
+	cases := map[string]string{
+		"invalid.schedule":    `{"metadata": {"name": "cleanup"}, "spec": {"schedule": "61 * * * *", "container": {"image": "cleanup:v1"}}}`,
+		"invalid.concurrency": `{"metadata": {"name": "cleanup"}, "spec": {"concurrency": "queue", "container": {"image": "cleanup:v1"}}}`,
+		"negative.timeout":    `{"metadata": {"name": "cleanup"}, "spec": {"timeout": "-1m", "container": {"image": "cleanup:v1"}}}`,
+		"negative.history":    `{"metadata": {"name": "cleanup"}, "spec": {"history": {"successful": -1}, "container": {"image": "cleanup:v1"}}}`,
+		"missing.image":       `{"metadata": {"name": "cleanup"}, "spec": {"container": {}}}`,
+		"invalid.name":        `{"metadata": {"name": "Clean Up"}, "spec": {"container": {"image": "cleanup:v1"}}}`,
+	}
+
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, w, err := decodeJobApply(body)
+			if err == nil {
+				t.Fatalf("expected request to be rejected")
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func newTestJobs(t *testing.T) (*jobs, *runtime.FakeBackend) {
+	fake := runtime.NewFakeBackend()
+	// the application network starts its dns server
+	fake.AddImage("coredns:v1")
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+	assert.ErrNil(records.enableIfNonElse("poseidon"))
+	_, err = runtime.CreateNewNetwork(context.Background(), "poseidon")
+	assert.ErrNil(err)
+
+	// the worker is not started, schedules are run explicitly
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &jobs{records: records, logger: log.New("zeusapiserver", "jobs"), ctx: ctx, cancel: cancel}
+	t.Cleanup(j.close)
+
+	return j, fake
+}
+
+func setTestJob(t *testing.T, j *jobs, job record.JobSpec) {
+	err := j.records.tx("poseidon", func(state *record.ApplicationRecord) error {
+		state.SetJob(job)
+		return nil
+	})
+	assert.ErrNil(err)
+}
+
+// Waits until no run of the job is running anymore and returns its runs.
+func awaitTestJobRuns(t *testing.T, j *jobs, job string) []record.JobRun {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		state, err := j.records.get("poseidon")
+		assert.ErrNil(err)
+
+		runs := state.RunsOfJob(job)
+		running := false
+		for _, run := range runs {
+			running = running || run.State == record.JobRunRunning
+		}
+		if !running {
+			return runs
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected runs of job '%s' to finish, got %v", job, runs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobRunRecordsOutcomeAndHistory(t *testing.T) {
+	j, fake := newTestJobs(t)
+	ctx := context.Background()
+	fake.AddImage("cleanup:v1")
+	fake.AddImage("report:v1")
+	assert.ErrNil(fake.ExitOnStart("cleanup:v1", 0, "removed 3 rows"))
+	assert.ErrNil(fake.ExitOnStart("report:v1", 2, "no data"))
+	setTestJob(t, j, record.JobSpec{Name: "cleanup", Image: "cleanup:v1", Command: []string{"cleanup"}, SuccessfulHistory: 2, FailedHistory: 1})
+	setTestJob(t, j, record.JobSpec{Name: "report", Image: "report:v1", SuccessfulHistory: 1, FailedHistory: 1})
+
+	for i := 0; i < 3; i++ {
+		_, err := j.run(ctx, "poseidon", "cleanup", false)
+		assert.ErrNil(err)
+		awaitTestJobRuns(t, j, "cleanup")
+	}
+	runs := awaitTestJobRuns(t, j, "cleanup")
+	if len(runs) != 2 {
+		t.Fatalf("expected the history to keep two successful runs, got %v", runs)
+	}
+	for _, run := range runs {
+		if run.State != record.JobRunSucceeded || run.ExitCode != 0 {
+			t.Errorf("expected run to succeed, got %v", run)
+		}
+		if _, err := runtime.FindJobRun(ctx, "poseidon", "cleanup", run.ID); err != nil {
+			t.Errorf("expected container of kept run '%s' to exist, got %v", run.ID, err)
+		}
+	}
+
+	run, err := j.run(ctx, "poseidon", "report", true)
+	assert.ErrNil(err)
+	runs = awaitTestJobRuns(t, j, "report")
+	if len(runs) != 1 || runs[0].ID != run.ID || runs[0].State != record.JobRunFailed || runs[0].ExitCode != 2 || !runs[0].Scheduled {
+		t.Errorf("expected scheduled run to fail with its exit code, got %v", runs)
+	}
+}
+
+func TestJobRunConcurrencyPolicy(t *testing.T) {
+	j, fake := newTestJobs(t)
+	ctx := context.Background()
+	fake.AddImage("cleanup:v1")
+	setTestJob(t, j, record.JobSpec{Name: "cleanup", Image: "cleanup:v1", SuccessfulHistory: 3, FailedHistory: 3})
+
+	first, err := j.run(ctx, "poseidon", "cleanup", false)
+	assert.ErrNil(err)
+	if _, err := j.run(ctx, "poseidon", "cleanup", false); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("expected second run to be forbidden, got %v", err)
+	}
+
+	setTestJob(t, j, record.JobSpec{Name: "cleanup", Image: "cleanup:v1", Concurrency: record.ConcurrencyReplace, SuccessfulHistory: 3, FailedHistory: 3})
+	second, err := j.run(ctx, "poseidon", "cleanup", false)
+	assert.ErrNil(err)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		state, err := j.records.get("poseidon")
+		assert.ErrNil(err)
+		replaced := state.JobRun("cleanup", first.ID)
+		if replaced.State == record.JobRunFailed {
+			if !strings.Contains(replaced.Reason, second.ID) {
+				t.Errorf("expected reason to name the replacing run, got '%s'", replaced.Reason)
+			}
+			if state.JobRun("cleanup", second.ID).State != record.JobRunRunning {
+				t.Errorf("expected replacing run to keep running")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected replaced run to fail, got %v", replaced)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobRunTimeout(t *testing.T) {
+	j, fake := newTestJobs(t)
+	fake.AddImage("cleanup:v1")
+	setTestJob(t, j, record.JobSpec{Name: "cleanup", Image: "cleanup:v1", Timeout: 50 * time.Millisecond, FailedHistory: 1})
+
+	_, err := j.run(context.Background(), "poseidon", "cleanup", false)
+	assert.ErrNil(err)
+	runs := awaitTestJobRuns(t, j, "cleanup")
+	if len(runs) != 1 || runs[0].State != record.JobRunFailed || !strings.HasPrefix(runs[0].Reason, "Timed out") {
+		t.Errorf("expected run to time out, got %v", runs)
+	}
+}
+
+func TestJobSchedules(t *testing.T) {
+	j, fake := newTestJobs(t)
+	fake.AddImage("cleanup:v1")
+	assert.ErrNil(fake.ExitOnStart("cleanup:v1", 0))
+	start := time.Date(2025, 6, 1, 1, 0, 0, 0, time.Local)
+	setTestJob(t, j, record.JobSpec{Name: "cleanup", Image: "cleanup:v1", Schedule: "0 3 * * *", SuccessfulHistory: 5, LastScheduled: start})
+
+	j.runSchedules(start.Add(time.Hour))
+	if runs := awaitTestJobRuns(t, j, "cleanup"); len(runs) != 0 {
+		t.Fatalf("expected no run before the schedule is due, got %v", runs)
+	}
+
+	// the daemon was stopped for two days, the missed runs are caught up once
+	j.runSchedules(start.Add(50 * time.Hour))
+	j.runSchedules(start.Add(50*time.Hour + time.Minute))
+	runs := awaitTestJobRuns(t, j, "cleanup")
+	if len(runs) != 1 || !runs[0].Scheduled || runs[0].State != record.JobRunSucceeded {
+		t.Fatalf("expected a single scheduled run, got %v", runs)
+	}
+
+	state, err := j.records.get("poseidon")
+	assert.ErrNil(err)
+	if !state.Job("cleanup").LastScheduled.Equal(start.Add(50 * time.Hour)) {
+		t.Errorf("expected last schedule to be updated, got %v", state.Job("cleanup").LastScheduled)
+	}
+}
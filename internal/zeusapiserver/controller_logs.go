@@ -0,0 +1,260 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+var (
+	ErrBadRequestLogs  = errors.New("bad request: logs")
+	ErrBadRequestQuery = errors.New("bad request: query parameter")
+)
+
+const (
+	logsAPIPath = "/v1.0/applications/{application}/logs"
+
+	// Logs are streamed in the multiplexed format of the docker engine, which keeps stdout and stderr apart
+	LogsContentType = "application/vnd.docker.multiplexed-stream"
+
+	LogsObjectService = "service"
+	LogsObjectIngress = "ingress"
+	LogsObjectDNS     = "dns"
+	LogsObjectJob     = "job"
+)
+
+type LogsQuery struct {
+	// One of service, ingress, dns or job
+	Object string
+	// Name of the service, only used for the service object
+	Service string
+	// Name of a sidecar of the service, if empty the logs of the container of the service are streamed
+	Container string
+	// Name of the job and ID of its run, only used for the job object. Without a run the latest run is used
+	Job        string
+	Run        string
+	Follow     bool
+	Since      string
+	Tail       string
+	Timestamps bool
+}
+
+func LogsAPIPath(application string, query LogsQuery) string {
+	values := url.Values{}
+	values.Set("object", query.Object)
+	if query.Service != "" {
+		values.Set("service", query.Service)
+	}
+	if query.Container != "" {
+		values.Set("container", query.Container)
+	}
+	if query.Job != "" {
+		values.Set("job", query.Job)
+	}
+	if query.Run != "" {
+		values.Set("run", query.Run)
+	}
+	if query.Follow {
+		values.Set("follow", "true")
+	}
+	if query.Since != "" {
+		values.Set("since", query.Since)
+	}
+	if query.Tail != "" {
+		values.Set("tail", query.Tail)
+	}
+	if query.Timestamps {
+		values.Set("timestamps", "true")
+	}
+
+	return strings.Replace(logsAPIPath, "{application}", application, 1) + "?" + values.Encode()
+}
+
+type LogsRequest struct {
+	Application application
+	Labels      []runtime.Label
+	// Set for the job object, whose runs are selected by their record instead of labels
+	Job     string
+	Run     string
+	Options runtime.LogOptions
+}
+
+func decodeQueryBool(value string, name string, w http.ResponseWriter) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		replyBadRequest(w, "Query parameter %s must be a boolean", name)
+		return false, ErrBadRequestQuery
+	}
+
+	return b, nil
+}
+
+func GetLogsRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *LogsRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	query := r.URL.Query()
+	switch query.Get("object") {
+	case LogsObjectService, "":
+		service := query.Get("service")
+		if err := decodeServiceName(service, w); err != nil {
+			return err
+		}
+		out.Labels = []runtime.Label{
+			runtime.ObjectTypeLabel(runtime.ServiceObject),
+			runtime.ServiceNameLabel(service),
+			runtime.ServiceRoleLabel(runtime.MainContainerRole),
+		}
+		if container := query.Get("container"); container != "" {
+			if err := decodeAuxiliaryContainerName(container, w); err != nil {
+				return err
+			}
+			out.Labels = []runtime.Label{
+				runtime.ObjectTypeLabel(runtime.ServiceObject),
+				runtime.ServiceNameLabel(service),
+				runtime.ServiceRoleLabel(runtime.SidecarRole),
+				runtime.ServiceContainerLabel(container),
+			}
+		}
+	case LogsObjectIngress:
+		out.Labels = []runtime.Label{runtime.ObjectTypeLabel(runtime.IngressObject)}
+	case LogsObjectDNS:
+		out.Labels = []runtime.Label{runtime.ObjectTypeLabel(runtime.DNSObject)}
+	case LogsObjectJob:
+		out.Job = query.Get("job")
+		if err := decodeJobName(out.Job, w); err != nil {
+			return err
+		}
+		if out.Run = query.Get("run"); out.Run != "" {
+			if err := decodeJobRunID(out.Run, w); err != nil {
+				return err
+			}
+		}
+	default:
+		replyBadRequest(w, "Object %q must be one of service, ingress, dns or job", query.Get("object"))
+		return ErrBadRequestLogs
+	}
+
+	var err error
+	if out.Options.Follow, err = decodeQueryBool(query.Get("follow"), "follow", w); err != nil {
+		return err
+	}
+	if out.Options.Timestamps, err = decodeQueryBool(query.Get("timestamps"), "timestamps", w); err != nil {
+		return err
+	}
+
+	if since := query.Get("since"); since != "" {
+		d, durationErr := time.ParseDuration(since)
+		_, timeErr := time.Parse(time.RFC3339Nano, since)
+		if (durationErr != nil || d <= 0) && timeErr != nil {
+			replyBadRequest(w, "Since %q must be a positive duration or an RFC 3339 timestamp", since)
+			return ErrBadRequestLogs
+		}
+		out.Options.Since = since
+	}
+
+	if tail := query.Get("tail"); tail != "" && tail != "all" {
+		if n, err := strconv.Atoi(tail); err != nil || n < 0 {
+			replyBadRequest(w, "Tail %q must be 'all' or a non-negative number", tail)
+			return ErrBadRequestLogs
+		}
+		out.Options.Tail = tail
+	}
+
+	return nil
+}
+
+// Flushes every write, otherwise followed logs are buffered by the server.
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (self *flushWriter) Write(p []byte) (int, error) {
+	n, err := self.w.Write(p)
+	self.flusher.Flush()
+	return n, err
+}
+
+func (self *ZeusController) GetLogs(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *LogsRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	var container *runtime.Container
+	if command.Job != "" {
+		run := command.Run
+		if run == "" {
+			latest := state.LatestJobRun(command.Job)
+			if latest == nil {
+				replyBadRequest(w, "Job has no runs")
+				return
+			}
+			run = latest.ID
+		}
+
+		// finished runs keep their container until they exceed the history of the job
+		container, err = runtime.FindJobRun(r.Context(), string(command.Application), command.Job, run)
+		if errors.Is(err, runtime.ErrContainerNotFound) {
+			replyBadRequest(w, "Container of run %s does not exist", run)
+			return
+		}
+	} else {
+		container, err = runtime.FindContainer(r.Context(), string(command.Application), command.Labels...)
+		if errors.Is(err, runtime.ErrContainerNotFound) {
+			replyBadRequest(w, "Container is not running")
+			return
+		}
+	}
+	if err != nil {
+		replyBadRequest(w, "Failed to find the container: %v", err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	assert.True(ok, "response writer of the http server supports flushing")
+	w.Header().Set("Content-Type", LogsContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	out := &flushWriter{w: w, flusher: flusher}
+	err = container.Logs(
+		r.Context(),
+		command.Options,
+		stdcopy.NewStdWriter(out, stdcopy.Stdout),
+		stdcopy.NewStdWriter(out, stdcopy.Stderr),
+	)
+	if err != nil {
+		// the status is already sent, the client sees the stream end
+		log.New(string(command.Application), "logs").Error("Failed to stream logs of %s: %v", container, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/runtime"
+)
+
+func decodeLogs(application string, query LogsQuery) (*LogsRequest, *httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("GET", LogsAPIPath(application, query), nil)
+	r.SetPathValue("application", application)
+	w := httptest.NewRecorder()
+
+	out := new(LogsRequest)
+	err := GetLogsRequestDecoder(w, r, out)
+	return out, w, err
+}
+
+func TestLogsDecoder(t *testing.T) {
+	out, _, err := decodeLogs("poseidon", LogsQuery{
+		Object:     LogsObjectService,
+		Service:    "rickroll",
+		Follow:     true,
+		Since:      "10m",
+		Tail:       "100",
+		Timestamps: true,
+	})
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	if len(out.Labels) != 3 || out.Labels[2] != runtime.ServiceRoleLabel(runtime.MainContainerRole) {
+		t.Errorf("expected object type, service name, and role label, got %v", out.Labels)
+	}
+	options := out.Options
+	if !options.Follow || !options.Timestamps || options.Since != "10m" || options.Tail != "100" {
+		t.Errorf("options not decoded correctly, got '%v'", options)
+	}
+}
+
+func TestLogsDecoderSidecar(t *testing.T) {
+	out, _, err := decodeLogs("poseidon", LogsQuery{Object: LogsObjectService, Service: "rickroll", Container: "shipper"})
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	if len(out.Labels) != 4 || out.Labels[3] != runtime.ServiceContainerLabel("shipper") {
+		t.Errorf("expected labels of the sidecar, got %v", out.Labels)
+	}
+}
+
+func TestLogsDecoderRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query LogsQuery
+	}{
+		{name: "unknown.object", query: LogsQuery{Object: "network"}},
+		{name: "missing.service", query: LogsQuery{Object: LogsObjectService}},
+		{name: "invalid.since", query: LogsQuery{Object: LogsObjectIngress, Since: "yesterday"}},
+		{name: "negative.tail", query: LogsQuery{Object: LogsObjectDNS, Tail: "-1"}},
+		{name: "invalid.container", query: LogsQuery{Object: LogsObjectService, Service: "rickroll", Container: "Ship_per"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, w, err := decodeLogs("poseidon", tt.query)
+			if err == nil {
+				t.Fatalf("expected request to be rejected")
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
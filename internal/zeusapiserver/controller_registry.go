@@ -0,0 +1,317 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+var (
+	ErrBadRequestRegistry = errors.New("bad request: registry")
+
+	registryPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]{1,5})?$`)
+)
+
+const (
+	registryInspectAllAPIPath = "/v1.0/applications/{application}/registries"
+	registryDeleteAPIPath     = "/v1.0/applications/{application}/registries/{registry}"
+	imagePullAPIPath          = "/v1.0/applications/{application}/images/pull"
+
+	// Progress of a pull is streamed as one JSON object per line
+	ImagePullContentType = "application/x-ndjson"
+)
+
+func RegistryInspectAllAPIPath(application string) string {
+	return strings.Replace(registryInspectAllAPIPath, "{application}", application, 1)
+}
+
+func RegistryLoginAPIPath(application string) string {
+	return RegistryInspectAllAPIPath(application)
+}
+
+func RegistryDeleteAPIPath(application string, registry string) string {
+	path := strings.Replace(registryDeleteAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{registry}", registry, 1)
+}
+
+func ImagePullAPIPath(application string) string {
+	return strings.Replace(imagePullAPIPath, "{application}", application, 1)
+}
+
+func decodeRegistry(registry string, w http.ResponseWriter) error {
+	if !registryPattern.MatchString(registry) {
+		replyBadRequest(w, "Registry %q must be a lowercase host with an optional port, e.g. ghcr.io", registry)
+		return ErrBadRequestRegistry
+	}
+
+	return nil
+}
+
+type RegistryLoginRequestBody struct {
+	Registry string `json:"registry"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RegistryLoginRequest struct {
+	Application application
+	RegistryLoginRequestBody
+}
+
+type RegistryInspectAllRequest struct {
+	Application application
+}
+
+type RegistryDeleteRequest struct {
+	Application application
+	Registry    string
+}
+
+type RegistryInspectAllResponse struct {
+	Registries []RegistryInspectResponse `json:"registries"`
+}
+
+// The password of a registry is never returned.
+type RegistryInspectResponse struct {
+	Registry string `json:"registry"`
+	Username string `json:"username"`
+}
+
+type ImagePullRequestBody struct {
+	Image string `json:"image"`
+	// Pulls the image even if it exists, which updates a tag that moved in the registry
+	Always bool `json:"always,omitempty"`
+}
+
+type ImagePullRequest struct {
+	Application application
+	ImagePullRequestBody
+}
+
+// ImagePullEvent is a line of the progress stream of a pull. The last event of a failed pull carries the error.
+type ImagePullEvent struct {
+	Layer   string `json:"layer,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Current int64  `json:"current,omitempty"`
+	Total   int64  `json:"total,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func PostRegistryLoginRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *RegistryLoginRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	if err := json.NewDecoder(r.Body).Decode(&out.RegistryLoginRequestBody); err != nil {
+		replyBadRequest(w, "Invalid JSON payload")
+		return err
+	}
+
+	if err := decodeRegistry(out.Registry, w); err != nil {
+		return err
+	}
+	if out.Username == "" || out.Password == "" {
+		replyBadRequest(w, "Username and password must not be empty")
+		return ErrBadRequestRegistry
+	}
+
+	return nil
+}
+
+func GetRegistryInspectAllRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *RegistryInspectAllRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	return nil
+}
+
+func DeleteRegistryRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *RegistryDeleteRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	registry := r.PathValue("registry")
+	if err := decodeRegistry(registry, w); err != nil {
+		return err
+	}
+	out.Registry = registry
+
+	return nil
+}
+
+func PostImagePullRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ImagePullRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	if err := json.NewDecoder(r.Body).Decode(&out.ImagePullRequestBody); err != nil {
+		replyBadRequest(w, "Invalid JSON payload")
+		return err
+	}
+	if strings.TrimSpace(out.Image) == "" {
+		replyBadRequest(w, "Image must not be empty")
+		return ErrBadRequestRegistry
+	}
+
+	return nil
+}
+
+// Returns the options to pull the image with the credential of its registry, if the application has one.
+func pullOptions(state *record.ApplicationRecord, image string) runtime.PullOptions {
+	options := runtime.PullOptions{}
+	if credential := state.Registry(runtime.RegistryOfImage(image)); credential != nil {
+		options.RegistryAuth = runtime.RegistryAuth(*credential)
+	}
+
+	return options
+}
+
+// Stores the credential of the registry, the images of the application are pulled with it.
+func (self *ZeusController) PostRegistryLogin(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *RegistryLoginRequest,
+) {
+	err := self.records.tx(command.Application, func(state *record.ApplicationRecord) error {
+		state.SetRegistry(record.RegistryCredential{
+			Registry: command.Registry,
+			Username: command.Username,
+			Password: command.Password,
+		})
+		return nil
+	})
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (self *ZeusController) GetRegistryInspectAll(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *RegistryInspectAllRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	response := RegistryInspectAllResponse{
+		Registries: make([]RegistryInspectResponse, 0),
+	}
+	for _, credential := range state.Registries {
+		response.Registries = append(response.Registries, RegistryInspectResponse{
+			Registry: credential.Registry,
+			Username: credential.Username,
+		})
+	}
+	slices.SortFunc(response.Registries, func(a, b RegistryInspectResponse) int {
+		return strings.Compare(a.Registry, b.Registry)
+	})
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
+
+func (self *ZeusController) DeleteRegistry(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *RegistryDeleteRequest,
+) {
+	err := self.records.tx(command.Application, func(state *record.ApplicationRecord) error {
+		if state.Registry(command.Registry) == nil {
+			return ErrBadRequestRegistry
+		}
+		state.RemoveRegistry(command.Registry)
+		return nil
+	})
+	if errors.Is(err, ErrBadRequestRegistry) {
+		replyBadRequest(w, "Registry has no credential")
+		return
+	}
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Pulls the image with the credential of its registry and streams the progress of the pull.
+// Images which already exist are only pulled again if requested, the pull is cancelled if the client goes away.
+func (self *ZeusController) PostImagePull(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ImagePullRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	options := pullOptions(state, command.Image)
+	options.Always = command.Always
+
+	flusher, ok := w.(http.Flusher)
+	assert.True(ok, "response writer of the http server supports flushing")
+	w.Header().Set("Content-Type", ImagePullContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(&flushWriter{w: w, flusher: flusher})
+	options.Progress = func(p runtime.PullProgress) {
+		encoder.Encode(ImagePullEvent{
+			Layer:   p.Layer,
+			Status:  p.Status,
+			Current: p.Current,
+			Total:   p.Total,
+		})
+	}
+
+	if err := runtime.PullImage(r.Context(), command.Image, options); err != nil {
+		log.New(string(command.Application), "images").Error("Failed to pull image %q: %v", command.Image, err)
+		// the status is already sent, the error is the last event of the stream
+		encoder.Encode(ImagePullEvent{Error: err.Error()})
+	}
+}
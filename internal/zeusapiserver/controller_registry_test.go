@@ -0,0 +1,108 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"go.etcd.io/bbolt"
+)
+
+func decodeRegistryLogin(body string) (*RegistryLoginRequest, *httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("POST", RegistryLoginAPIPath("poseidon"), strings.NewReader(body))
+	r.SetPathValue("application", "poseidon")
+	w := httptest.NewRecorder()
+
+	out := new(RegistryLoginRequest)
+	err := PostRegistryLoginRequestDecoder(w, r, out)
+	return out, w, err
+}
+
+func TestRegistryLoginDecoder(t *testing.T) {
+	out, _, err := decodeRegistryLogin(`{"registry": "registry.local:5000", "username": "rick", "password": "astley"}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+	if out.Registry != "registry.local:5000" || out.Username != "rick" || out.Password != "astley" {
+		t.Errorf("login not decoded correctly, got %v", out)
+	}
+
+	for _, body := range []string{
+		`{"registry": "https://ghcr.io", "username": "rick", "password": "astley"}`,
+		`{"registry": "GHCR.io", "username": "rick", "password": "astley"}`,
+		`{"registry": "ghcr.io", "username": "rick"}`,
+	} {
+		_, w, err := decodeRegistryLogin(body)
+		if err == nil || w.Code != http.StatusBadRequest {
+			t.Errorf("expected login '%s' to be rejected", body)
+		}
+	}
+}
+
+func TestImagePullStreamsProgress(t *testing.T) {
+	fake := runtime.NewFakeBackend()
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+	err = records.tx("poseidon", func(state *record.ApplicationRecord) error {
+		state.SetRegistry(record.RegistryCredential{Registry: "ghcr.io", Username: "rick", Password: "astley"})
+		return nil
+	})
+	assert.ErrNil(err)
+	controller := &ZeusController{records: records}
+
+	pull := func(image string) []ImagePullEvent {
+		r := httptest.NewRequest("POST", ImagePullAPIPath("poseidon"), nil)
+		w := httptest.NewRecorder()
+		controller.PostImagePull(w, r, &ImagePullRequest{
+			Application:          "poseidon",
+			ImagePullRequestBody: ImagePullRequestBody{Image: image},
+		})
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ImagePullContentType {
+			t.Fatalf("expected progress stream, got status %d", w.Code)
+		}
+
+		var events []ImagePullEvent
+		decoder := json.NewDecoder(w.Body)
+		for decoder.More() {
+			var event ImagePullEvent
+			assert.ErrNil(decoder.Decode(&event))
+			events = append(events, event)
+		}
+		return events
+	}
+
+	events := pull("ghcr.io/zeus/rickroll:v1")
+	if len(events) < 2 || events[len(events)-1].Status != "Pull complete" {
+		t.Errorf("expected progress of the pull, got %v", events)
+	}
+	auth, _ := fake.PullAuth("ghcr.io/zeus/rickroll:v1")
+	if auth != runtime.RegistryAuth(record.RegistryCredential{Registry: "ghcr.io", Username: "rick", Password: "astley"}) {
+		t.Errorf("expected image to be pulled with the credential of its registry, got '%s'", auth)
+	}
+
+	if events := pull("ghcr.io/zeus/rickroll:v1"); len(events) != 1 || events[0].Status != "Image is up to date" {
+		t.Errorf("expected existing image not to be pulled again, got %v", events)
+	}
+
+	fake.Fail("ImagePull", errors.New("unauthorized"))
+	events = pull("ghcr.io/zeus/rickroll:v2")
+	if len(events) != 1 || !strings.Contains(events[0].Error, "unauthorized") {
+		t.Errorf("expected failed pull to end the stream with its error, got %v", events)
+	}
+}
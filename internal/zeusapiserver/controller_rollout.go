@@ -0,0 +1,223 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	bboltErr "go.etcd.io/bbolt/errors"
+)
+
+var ErrServiceNoPrevious = errors.New("service has no previous specification")
+
+const (
+	serviceRolloutAPIPath     = "/v1.0/applications/{application}/services/{service}/rollout"
+	serviceRolloutUndoAPIPath = "/v1.0/applications/{application}/services/{service}/rollout/undo"
+)
+
+// The rollout to the current specification has not started yet, or the containers are recreated
+const rolloutPending = "pending"
+
+func ServiceRolloutAPIPath(application string, service string) string {
+	path := strings.Replace(serviceRolloutAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{service}", service, 1)
+}
+
+func ServiceRolloutUndoAPIPath(application string, service string) string {
+	path := strings.Replace(serviceRolloutUndoAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{service}", service, 1)
+}
+
+type ServiceRolloutRequest struct {
+	Application application
+	Service     record.RecordKey
+}
+
+type ServiceRolloutUndoRequest struct {
+	Application application
+	Service     record.RecordKey
+}
+
+type ServiceRolloutResponse struct {
+	Service  string `json:"service"`
+	Strategy string `json:"strategy"`
+	// One of pending, progressing, complete or rolled-back
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+	// Image the service ran before its specification last changed, restored by an undo
+	PreviousImage  string     `json:"previousImage,omitempty"`
+	PreviousDigest string     `json:"previousDigest,omitempty"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	SwitchedAt     *time.Time `json:"switchedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+	// Replaced containers which still serve requests in flight
+	Draining []string `json:"draining"`
+}
+
+type ServiceRolloutUndoResponse struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+func GetServiceRolloutRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceRolloutRequest,
+) error {
+	a, s, err := decodeServicePathValues(w, r)
+	if err != nil {
+		return err
+	}
+
+	out.Application = a
+	out.Service = s
+	return nil
+}
+
+func PostServiceRolloutUndoRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceRolloutUndoRequest,
+) error {
+	a, s, err := decodeServicePathValues(w, r)
+	if err != nil {
+		return err
+	}
+
+	out.Application = a
+	out.Service = s
+	return nil
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func (self *ZeusController) GetServiceRollout(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceRolloutRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	spec := state.Service.Get(command.Service)
+	if spec == nil {
+		replyBadRequest(w, "Service does not exist")
+		return
+	}
+
+	response := ServiceRolloutResponse{
+		Service:  string(spec.ServiceName),
+		Strategy: spec.UpdateStrategy(),
+		State:    rolloutPending,
+		Image:    spec.Container.Image,
+		Digest:   spec.Container.ImageReference(),
+		Draining: make([]string, 0),
+	}
+	if previous := state.Service.Previous[spec.ServiceName]; previous != nil {
+		response.PreviousImage = previous.Container.Image
+		response.PreviousDigest = previous.Container.ImageReference()
+	}
+
+	if rollout := state.Service.Rollout(spec.ServiceName); rollout != nil && rollout.Hash == spec.Hash() {
+		response.State = rollout.State
+		response.Reason = rollout.Reason
+		response.StartedAt = optionalTime(rollout.StartedAt)
+		response.SwitchedAt = optionalTime(rollout.SwitchedAt)
+		response.FinishedAt = optionalTime(rollout.FinishedAt)
+		response.Draining = append(response.Draining, rollout.Draining...)
+	} else {
+		// services which were created or recreated run the current specification without a rollout
+		_, err := runtime.FindContainer(
+			r.Context(),
+			state.Metadata.Application,
+			runtime.ObjectTypeLabel(runtime.ServiceObject),
+			runtime.ObjectHashLabel(spec.Hash()),
+			runtime.ServiceNameLabel(string(spec.ServiceName)),
+			runtime.ServiceRoleLabel(runtime.MainContainerRole),
+		)
+		switch {
+		case err == nil:
+			response.State = record.RolloutComplete
+		case !errors.Is(err, runtime.ErrContainerNotFound):
+			replyBadRequest(w, "Failed to select the containers of the service: %v", err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
+
+// Restores the specification the service had before it was last changed. The restored specification is
+// rolled out like any other change, undoing again returns to the one which was replaced.
+func (self *ZeusController) PostServiceRolloutUndo(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceRolloutUndoRequest,
+) {
+	var response ServiceRolloutUndoResponse
+	err := self.records.tx(
+		command.Application,
+		func(r *record.ApplicationRecord) error {
+			spec := r.Service.Get(command.Service)
+			if spec == nil {
+				return ErrServiceNotFound
+			}
+			previous := r.Service.Previous[command.Service]
+			if previous == nil {
+				return ErrServiceNoPrevious
+			}
+
+			// the replicas and their balancing are not rolled out, the service keeps its current scale
+			current := *spec
+			*spec = *previous
+			spec.Replicas, spec.Balance = current.Replicas, current.Balance
+			r.Service.Previous[command.Service] = &current
+			response.Image = spec.Container.Image
+			response.Digest = spec.Container.ImageReference()
+			return nil
+		},
+	)
+
+	switch {
+	case errors.Is(err, bboltErr.ErrBucketNotFound):
+		replyBadRequest(w, "Application does not exist")
+		return
+
+	case errors.Is(err, ErrServiceNotFound):
+		replyBadRequest(w, "Service does not exist")
+		return
+
+	case errors.Is(err, ErrServiceNoPrevious):
+		replyBadRequest(w, "Service has no previous specification to return to")
+		return
+
+	case err != nil:
+		assert.Unreachable("cover all cases of the undo transaction")
+	}
+
+	self.orchestrator.ping()
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
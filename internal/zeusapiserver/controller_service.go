@@ -0,0 +1,1326 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"path"
+	"regexp"
+	goruntime "runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	runtimeErr "github.com/raphaeldichler/zeus/internal/runtime/errtype"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	bboltErr "go.etcd.io/bbolt/errors"
+)
+
+var (
+	ErrBadRequestService = errors.New("bad request: service")
+	ErrServiceNotFound   = errors.New("service not found")
+	// The image of the service changed while its digest was resolved
+	ErrServiceImageChanged = errors.New("service image changed")
+
+	// services are used as dns names inside the application network
+	serviceNamePattern = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	envNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	capabilityPattern  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	// users and groups are either names or numeric ids
+	userPattern = regexp.MustCompile(`^([a-z_][a-z0-9_-]{0,31}|[0-9]+)$`)
+)
+
+const (
+	serviceApplyAPIPath      = "/v1.0/applications/{application}/services"
+	serviceInspectAllAPIPath = "/v1.0/applications/{application}/services"
+	serviceInspectAPIPath    = "/v1.0/applications/{application}/services/{service}"
+	serviceDeleteAPIPath     = "/v1.0/applications/{application}/services/{service}"
+
+	serviceUpdateImageAPIPath = "/v1.0/applications/{application}/services/{service}/update-image"
+)
+
+func ServiceApplyAPIPath(apiVersion string, application string) string {
+	switch apiVersion {
+	case "v1.0":
+		return strings.Replace(serviceApplyAPIPath, "{application}", application, 1)
+	default:
+		assert.Unreachable("cover all cases of api version")
+	}
+	return ""
+}
+
+func ServiceInspectAllAPIPath(application string) string {
+	return strings.Replace(serviceInspectAllAPIPath, "{application}", application, 1)
+}
+
+func ServiceInspectAPIPath(application string, service string) string {
+	path := strings.Replace(serviceInspectAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{service}", service, 1)
+}
+
+func ServiceDeleteAPIPath(application string, service string) string {
+	path := strings.Replace(serviceDeleteAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{service}", service, 1)
+}
+
+func ServiceUpdateImageAPIPath(application string, service string) string {
+	path := strings.Replace(serviceUpdateImageAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{service}", service, 1)
+}
+
+type ServiceApplyRequestBody struct {
+	Metadata struct {
+		Name string `json:"name" yaml:"name"`
+	} `json:"metadata" yaml:"metadata"`
+	Spec struct {
+		Network struct {
+			Name  string `json:"name" yaml:"name"`
+			Ports []struct {
+				Name string `json:"name" yaml:"name"`
+				Port string `json:"port" yaml:"port"`
+			} `json:"ports" yaml:"ports"`
+		} `json:"network" yaml:"network"`
+		Container struct {
+			Image     string                       `json:"image" yaml:"image"`
+			Env       []ServiceEnvRequestBody      `json:"env" yaml:"env"`
+			Health    *ServiceHealthRequestBody    `json:"health,omitempty" yaml:"health,omitempty"`
+			Resources *ServiceResourcesRequestBody `json:"resources,omitempty" yaml:"resources,omitempty"`
+			Security  *ServiceSecurityRequestBody  `json:"security,omitempty" yaml:"security,omitempty"`
+			// One of always, on-failure or never, defaults to always
+			Restart string                     `json:"restart,omitempty" yaml:"restart,omitempty"`
+			Volumes []ServiceVolumeRequestBody `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+		} `json:"container" yaml:"container"`
+		// Run to completion in order before the container is started
+		InitContainers []ServiceAuxiliaryContainerRequestBody `json:"initContainers,omitempty" yaml:"initContainers,omitempty"`
+		// Run next to the container and share its network
+		Sidecars []ServiceAuxiliaryContainerRequestBody `json:"sidecars,omitempty" yaml:"sidecars,omitempty"`
+		// One of rolling or recreate, defaults to rolling
+		Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+		// Number of containers which run the service, defaults to 1
+		Replicas int `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+		// One of round-robin, least-conn, ip-hash or sticky, defaults to round-robin
+		Balance string `json:"balance,omitempty" yaml:"balance,omitempty"`
+	} `json:"spec" yaml:"spec"`
+}
+
+type ServiceEnvRequestBody struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type ServiceVolumeRequestBody struct {
+	Name     string `json:"name" yaml:"name"`
+	Path     string `json:"path" yaml:"path"`
+	ReadOnly bool   `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+}
+
+// Init container or sidecar, the name must be unique among the init containers and sidecars of the service.
+type ServiceAuxiliaryContainerRequestBody struct {
+	Name  string `json:"name" yaml:"name"`
+	Image string `json:"image" yaml:"image"`
+	// Replaces the command of the image
+	Command []string                   `json:"command,omitempty" yaml:"command,omitempty"`
+	Env     []ServiceEnvRequestBody    `json:"env,omitempty" yaml:"env,omitempty"`
+	Volumes []ServiceVolumeRequestBody `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+}
+
+// Exactly one of exec, http or tcp must be defined. Ports are either the name of a service port or a number.
+type ServiceHealthRequestBody struct {
+	Exec []string `json:"exec,omitempty" yaml:"exec,omitempty"`
+	HTTP *struct {
+		Path string `json:"path" yaml:"path"`
+		Port string `json:"port" yaml:"port"`
+	} `json:"http,omitempty" yaml:"http,omitempty"`
+	TCP *struct {
+		Port string `json:"port" yaml:"port"`
+	} `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+	Interval    string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout     string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	StartPeriod string `json:"startPeriod,omitempty" yaml:"startPeriod,omitempty"`
+	Retries     int    `json:"retries,omitempty" yaml:"retries,omitempty"`
+}
+
+// Sizes are given in bytes or with a binary unit, e.g. 512m or 1g. CPUs are given as a fraction of CPUs, e.g. 0.5.
+type ServiceResourcesRequestBody struct {
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	CPUs   string `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	Pids   int64  `json:"pids,omitempty" yaml:"pids,omitempty"`
+}
+
+type ServiceSecurityRequestBody struct {
+	ReadOnlyRootFilesystem bool     `json:"readOnlyRootFilesystem,omitempty" yaml:"readOnlyRootFilesystem,omitempty"`
+	DropCapabilities       []string `json:"dropCapabilities,omitempty" yaml:"dropCapabilities,omitempty"`
+	NoNewPrivileges        bool     `json:"noNewPrivileges,omitempty" yaml:"noNewPrivileges,omitempty"`
+	User                   string   `json:"user,omitempty" yaml:"user,omitempty"`
+	Group                  string   `json:"group,omitempty" yaml:"group,omitempty"`
+	Tmpfs                  []struct {
+		Path string `json:"path" yaml:"path"`
+		Size string `json:"size,omitempty" yaml:"size,omitempty"`
+	} `json:"tmpfs,omitempty" yaml:"tmpfs,omitempty"`
+}
+
+type ServiceApplyRequest struct {
+	Application application
+	ServiceApplyRequestBody
+}
+
+type ServiceInspectRequest struct {
+	Application application
+	Service     record.RecordKey
+}
+
+type ServiceInspectAllRequest struct {
+	Application application
+}
+
+type ServiceDeleteRequest struct {
+	Application application
+	Service     record.RecordKey
+}
+
+type ServiceInspectAllResponse struct {
+	Services []ServiceInspectResponse `json:"services"`
+}
+
+type ServiceInspectResponse struct {
+	Name      string                          `json:"name"`
+	Hostname  string                          `json:"hostname"`
+	Image     string                          `json:"image"`
+	Health    string                          `json:"health"`
+	Ready     bool                            `json:"ready"`
+	State     string                          `json:"state"`
+	Restart   string                          `json:"restart"`
+	Restarts  int                             `json:"restarts"`
+	LastExit  *ServiceExitInspectResponse     `json:"lastExit,omitempty"`
+	Container ContainerInspectResponse        `json:"container"`
+	Balance   string                          `json:"balance"`
+	Replicas  []ServiceReplicaInspectResponse `json:"replicas"`
+	Ports     []ServicePortInspectResponse    `json:"ports"`
+	Env       []ServiceEnvInspectResponse     `json:"env"`
+	Errors    []ServiceErrorInspectEntry      `json:"errors"`
+}
+
+type ServiceReplicaInspectResponse struct {
+	Replica     int    `json:"replica"`
+	ContainerID string `json:"containerId"`
+	Address     string `json:"address"`
+	Health      string `json:"health"`
+	Ready       bool   `json:"ready"`
+	State       string `json:"state"`
+}
+
+type ServiceExitInspectResponse struct {
+	// Name of the init container or sidecar which exited, empty if the container of the service exited
+	Container string    `json:"container,omitempty"`
+	Code      int       `json:"code"`
+	At        time.Time `json:"at"`
+	Logs      []string  `json:"logs"`
+}
+
+type ServicePortInspectResponse struct {
+	Name string `json:"name"`
+	Port string `json:"port"`
+}
+
+type ServiceEnvInspectResponse struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ServiceErrorInspectEntry struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+	Message    string `json:"message"`
+}
+
+func decodeServiceName(service string, w http.ResponseWriter) error {
+	if !serviceNamePattern.MatchString(service) {
+		replyBadRequest(
+			w,
+			"Service name must be a valid DNS label: lowercase letters, digits and '-', at most 63 chars",
+		)
+		return ErrBadRequestService
+	}
+
+	return nil
+}
+
+func decodeServicePort(port string, w http.ResponseWriter) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		replyBadRequest(w, "Port %q must be a number between 1 and 65535", port)
+		return ErrBadRequestService
+	}
+
+	return nil
+}
+
+const (
+	defaultHealthInterval = time.Second * 10
+	defaultHealthTimeout  = time.Second * 5
+	defaultHealthRetries  = 3
+)
+
+// Resolves the port of the health probe, which is either the name of a service port or a number.
+func (self *ServiceApplyRequest) healthPort(port string) (string, bool) {
+	for _, p := range self.Spec.Network.Ports {
+		if p.Name == port {
+			return p.Port, true
+		}
+	}
+
+	number, err := strconv.Atoi(port)
+	if err != nil || number < 1 || number > 65535 {
+		return "", false
+	}
+
+	return port, true
+}
+
+func parseHealthDuration(value string, fallback time.Duration) (time.Duration, bool) {
+	if value == "" {
+		return fallback, true
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+
+	return d, true
+}
+
+func decodeServiceHealth(out *ServiceApplyRequest, w http.ResponseWriter) error {
+	health := out.Spec.Container.Health
+	if health == nil {
+		return nil
+	}
+
+	probes := 0
+	if len(health.Exec) != 0 {
+		probes++
+	}
+	if health.HTTP != nil {
+		probes++
+		if !strings.HasPrefix(health.HTTP.Path, "/") {
+			replyBadRequest(w, "Health check path %q must start with '/'", health.HTTP.Path)
+			return ErrBadRequestService
+		}
+		if _, ok := out.healthPort(health.HTTP.Port); !ok {
+			replyBadRequest(w, "Health check port %q is neither a service port nor a valid number", health.HTTP.Port)
+			return ErrBadRequestService
+		}
+	}
+	if health.TCP != nil {
+		probes++
+		if _, ok := out.healthPort(health.TCP.Port); !ok {
+			replyBadRequest(w, "Health check port %q is neither a service port nor a valid number", health.TCP.Port)
+			return ErrBadRequestService
+		}
+	}
+	if probes != 1 {
+		replyBadRequest(w, "Health check must define exactly one of exec, http or tcp")
+		return ErrBadRequestService
+	}
+
+	for name, value := range map[string]string{
+		"interval":    health.Interval,
+		"timeout":     health.Timeout,
+		"startPeriod": health.StartPeriod,
+	} {
+		if _, ok := parseHealthDuration(value, 0); !ok {
+			replyBadRequest(w, "Health check %s %q is not a valid duration", name, value)
+			return ErrBadRequestService
+		}
+	}
+	interval, _ := parseHealthDuration(health.Interval, defaultHealthInterval)
+	timeout, _ := parseHealthDuration(health.Timeout, defaultHealthTimeout)
+	if interval == 0 || timeout == 0 {
+		replyBadRequest(w, "Health check interval and timeout must be positive")
+		return ErrBadRequestService
+	}
+	if health.Retries < 0 {
+		replyBadRequest(w, "Health check retries must not be negative")
+		return ErrBadRequestService
+	}
+
+	return nil
+}
+
+// Validates the volumes of a single container and returns the paths they are mounted at.
+func decodeVolumeMounts(volumes []ServiceVolumeRequestBody, w http.ResponseWriter) (map[string]bool, error) {
+	paths := make(map[string]bool)
+	for _, volume := range volumes {
+		if err := decodeVolumeName(volume.Name, w); err != nil {
+			return nil, err
+		}
+		if !path.IsAbs(volume.Path) || path.Clean(volume.Path) == "/" {
+			replyBadRequest(w, "Volume path %q must be an absolute path other than '/'", volume.Path)
+			return nil, ErrBadRequestService
+		}
+		if paths[path.Clean(volume.Path)] {
+			replyBadRequest(w, "Volume path %q is mounted multiple times", volume.Path)
+			return nil, ErrBadRequestService
+		}
+		paths[path.Clean(volume.Path)] = true
+	}
+
+	return paths, nil
+}
+
+func decodeServiceEnv(env []ServiceEnvRequestBody, w http.ResponseWriter) error {
+	names := make(map[string]bool)
+	for _, e := range env {
+		if !envNamePattern.MatchString(e.Name) {
+			replyBadRequest(w, "Environment variable name %q is invalid", e.Name)
+			return ErrBadRequestService
+		}
+		if names[e.Name] {
+			replyBadRequest(w, "Environment variable %q is defined multiple times", e.Name)
+			return ErrBadRequestService
+		}
+		names[e.Name] = true
+	}
+
+	return nil
+}
+
+func decodeServiceVolumes(out *ServiceApplyRequest, w http.ResponseWriter) error {
+	paths, err := decodeVolumeMounts(out.Spec.Container.Volumes, w)
+	if err != nil {
+		return err
+	}
+
+	if security := out.Spec.Container.Security; security != nil {
+		for _, tmpfs := range security.Tmpfs {
+			if paths[path.Clean(tmpfs.Path)] {
+				replyBadRequest(w, "Path %q is used by a volume and a tmpfs", tmpfs.Path)
+				return ErrBadRequestService
+			}
+		}
+	}
+
+	return nil
+}
+
+// The smallest memory limit the container engine accepts
+const minMemoryLimit = 6 * 1024 * 1024
+
+// The replicas of all services share the static addresses of the application network
+const maxServiceReplicas = 32
+
+func parseCPUs(value string) (int64, bool) {
+	cpus, err := strconv.ParseFloat(value, 64)
+	if err != nil || cpus <= 0 {
+		return 0, false
+	}
+
+	return int64(cpus * 1e9), true
+}
+
+func decodeServiceResources(out *ServiceApplyRequest, w http.ResponseWriter) error {
+	resources := out.Spec.Container.Resources
+	if resources == nil {
+		return nil
+	}
+
+	if resources.Memory != "" {
+		memory, err := units.RAMInBytes(resources.Memory)
+		if err != nil || memory < minMemoryLimit {
+			replyBadRequest(w, "Memory limit %q must be a size of at least 6m", resources.Memory)
+			return ErrBadRequestService
+		}
+	}
+
+	if resources.CPUs != "" {
+		nanoCPUs, ok := parseCPUs(resources.CPUs)
+		if !ok {
+			replyBadRequest(w, "CPU limit %q must be a positive number", resources.CPUs)
+			return ErrBadRequestService
+		}
+		if available := int64(goruntime.NumCPU()) * 1e9; nanoCPUs > available {
+			replyBadRequest(w, "CPU limit %q exceeds the %d CPUs of the host", resources.CPUs, goruntime.NumCPU())
+			return ErrBadRequestService
+		}
+	}
+
+	if resources.Pids < 0 {
+		replyBadRequest(w, "Pids limit must not be negative")
+		return ErrBadRequestService
+	}
+
+	return nil
+}
+
+// Capabilities are accepted with or without the CAP_ prefix and in any case.
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+}
+
+func decodeServiceSecurity(out *ServiceApplyRequest, w http.ResponseWriter) error {
+	security := out.Spec.Container.Security
+	if security == nil {
+		return nil
+	}
+
+	for _, capability := range security.DropCapabilities {
+		if !capabilityPattern.MatchString(normalizeCapability(capability)) {
+			replyBadRequest(w, "Capability %q is invalid", capability)
+			return ErrBadRequestService
+		}
+	}
+
+	if security.User != "" && !userPattern.MatchString(security.User) {
+		replyBadRequest(w, "User %q must be a name or an id", security.User)
+		return ErrBadRequestService
+	}
+	if security.Group != "" {
+		if security.User == "" {
+			replyBadRequest(w, "Group requires a user")
+			return ErrBadRequestService
+		}
+		if !userPattern.MatchString(security.Group) {
+			replyBadRequest(w, "Group %q must be a name or an id", security.Group)
+			return ErrBadRequestService
+		}
+	}
+
+	paths := make(map[string]bool)
+	for _, tmpfs := range security.Tmpfs {
+		if !path.IsAbs(tmpfs.Path) || path.Clean(tmpfs.Path) == "/" {
+			replyBadRequest(w, "Tmpfs path %q must be an absolute path other than '/'", tmpfs.Path)
+			return ErrBadRequestService
+		}
+		if paths[path.Clean(tmpfs.Path)] {
+			replyBadRequest(w, "Tmpfs path %q is defined multiple times", tmpfs.Path)
+			return ErrBadRequestService
+		}
+		paths[path.Clean(tmpfs.Path)] = true
+
+		if tmpfs.Size != "" {
+			if size, err := units.RAMInBytes(tmpfs.Size); err != nil || size <= 0 {
+				replyBadRequest(w, "Tmpfs size %q must be a positive size", tmpfs.Size)
+				return ErrBadRequestService
+			}
+		}
+	}
+
+	return nil
+}
+
+func PostServiceApplyRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceApplyRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	if err := json.NewDecoder(r.Body).Decode(&out.ServiceApplyRequestBody); err != nil {
+		replyBadRequest(w, "Invalid JSON payload")
+		return err
+	}
+
+	if err := decodeServiceName(out.Metadata.Name, w); err != nil {
+		return err
+	}
+
+	network := out.Spec.Network
+	if network.Name != "" {
+		if err := decodeServiceName(network.Name, w); err != nil {
+			return err
+		}
+	}
+
+	ports := make(map[string]bool)
+	for _, port := range network.Ports {
+		if port.Name == "" {
+			replyBadRequest(w, "Port name must not be empty")
+			return ErrBadRequestService
+		}
+		if ports[port.Name] {
+			replyBadRequest(w, "Port name %q is defined multiple times", port.Name)
+			return ErrBadRequestService
+		}
+		ports[port.Name] = true
+
+		if err := decodeServicePort(port.Port, w); err != nil {
+			return err
+		}
+	}
+
+	container := out.Spec.Container
+	if strings.TrimSpace(container.Image) == "" {
+		replyBadRequest(w, "Container image must not be empty")
+		return ErrBadRequestService
+	}
+
+	if err := decodeServiceEnv(container.Env, w); err != nil {
+		return err
+	}
+
+	if err := decodeServiceHealth(out, w); err != nil {
+		return err
+	}
+	if err := decodeServiceResources(out, w); err != nil {
+		return err
+	}
+	if err := decodeServiceVolumes(out, w); err != nil {
+		return err
+	}
+
+	if err := decodeServiceSecurity(out, w); err != nil {
+		return err
+	}
+
+	switch container.Restart {
+	case "", record.RestartAlways, record.RestartOnFailure, record.RestartNever:
+	default:
+		replyBadRequest(w, "Restart policy %q must be one of always, on-failure or never", container.Restart)
+		return ErrBadRequestService
+	}
+
+	switch out.Spec.Strategy {
+	case "", record.StrategyRolling, record.StrategyRecreate:
+	default:
+		replyBadRequest(w, "Strategy %q must be one of rolling or recreate", out.Spec.Strategy)
+		return ErrBadRequestService
+	}
+
+	if out.Spec.Replicas < 0 || out.Spec.Replicas > maxServiceReplicas {
+		replyBadRequest(w, "Replicas %d must be between 1 and %d", out.Spec.Replicas, maxServiceReplicas)
+		return ErrBadRequestService
+	}
+	switch out.Spec.Balance {
+	case "", record.BalanceRoundRobin, record.BalanceLeastConn, record.BalanceIPHash, record.BalanceSticky:
+	default:
+		replyBadRequest(w, "Balance %q must be one of round-robin, least-conn, ip-hash or sticky", out.Spec.Balance)
+		return ErrBadRequestService
+	}
+
+	return decodeServiceAuxiliaryContainers(out, w)
+}
+
+func decodeAuxiliaryContainerName(name string, w http.ResponseWriter) error {
+	if !serviceNamePattern.MatchString(name) {
+		replyBadRequest(w, "Container name %q must consist of lowercase letters, digits and '-', at most 63 chars", name)
+		return ErrBadRequestService
+	}
+
+	return nil
+}
+
+func decodeServiceAuxiliaryContainers(out *ServiceApplyRequest, w http.ResponseWriter) error {
+	names := make(map[string]bool)
+	for _, aux := range slices.Concat(out.Spec.InitContainers, out.Spec.Sidecars) {
+		if err := decodeAuxiliaryContainerName(aux.Name, w); err != nil {
+			return err
+		}
+		if names[aux.Name] {
+			replyBadRequest(w, "Container name %q is used by multiple init containers or sidecars", aux.Name)
+			return ErrBadRequestService
+		}
+		names[aux.Name] = true
+
+		if strings.TrimSpace(aux.Image) == "" {
+			replyBadRequest(w, "Image of container %q must not be empty", aux.Name)
+			return ErrBadRequestService
+		}
+		if err := decodeServiceEnv(aux.Env, w); err != nil {
+			return err
+		}
+		if _, err := decodeVolumeMounts(aux.Volumes, w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toServiceEnv(env []ServiceEnvRequestBody) map[string]string {
+	out := make(map[string]string)
+	for _, e := range env {
+		out[e.Name] = e.Value
+	}
+
+	return out
+}
+
+func toServiceVolumes(volumes []ServiceVolumeRequestBody) []record.ServiceVolume {
+	var out []record.ServiceVolume = nil
+	for _, volume := range volumes {
+		out = append(out, record.ServiceVolume{
+			Name:     volume.Name,
+			Path:     path.Clean(volume.Path),
+			ReadOnly: volume.ReadOnly,
+		})
+	}
+
+	return out
+}
+
+func toAuxiliaryContainers(containers []ServiceAuxiliaryContainerRequestBody) []record.ServiceAuxiliaryContainer {
+	var out []record.ServiceAuxiliaryContainer = nil
+	for _, aux := range containers {
+		out = append(out, record.ServiceAuxiliaryContainer{
+			Name:    aux.Name,
+			Image:   aux.Image,
+			Command: aux.Command,
+			Env:     toServiceEnv(aux.Env),
+			Volumes: toServiceVolumes(aux.Volumes),
+		})
+	}
+
+	return out
+}
+
+func (self *ServiceApplyRequest) toResources() *record.ServiceResources {
+	resources := self.Spec.Container.Resources
+	if resources == nil {
+		return nil
+	}
+
+	out := &record.ServiceResources{Pids: resources.Pids}
+	if resources.Memory != "" {
+		out.Memory, _ = units.RAMInBytes(resources.Memory)
+	}
+	if resources.CPUs != "" {
+		out.NanoCPUs, _ = parseCPUs(resources.CPUs)
+	}
+
+	return out
+}
+
+func (self *ServiceApplyRequest) toSecurity() *record.ServiceSecurity {
+	security := self.Spec.Container.Security
+	if security == nil {
+		return nil
+	}
+
+	out := &record.ServiceSecurity{
+		ReadOnlyRootFilesystem: security.ReadOnlyRootFilesystem,
+		NoNewPrivileges:        security.NoNewPrivileges,
+		User:                   security.User,
+		Group:                  security.Group,
+	}
+	for _, capability := range security.DropCapabilities {
+		out.DropCapabilities = append(out.DropCapabilities, normalizeCapability(capability))
+	}
+	for _, tmpfs := range security.Tmpfs {
+		size := int64(0)
+		if tmpfs.Size != "" {
+			size, _ = units.RAMInBytes(tmpfs.Size)
+		}
+		out.Tmpfs = append(out.Tmpfs, record.ServiceTmpfs{Path: path.Clean(tmpfs.Path), Size: size})
+	}
+
+	return out
+}
+
+func (self *ServiceApplyRequest) toHealth() *record.ServiceHealth {
+	health := self.Spec.Container.Health
+	if health == nil {
+		return nil
+	}
+
+	interval, _ := parseHealthDuration(health.Interval, defaultHealthInterval)
+	timeout, _ := parseHealthDuration(health.Timeout, defaultHealthTimeout)
+	startPeriod, _ := parseHealthDuration(health.StartPeriod, 0)
+	retries := health.Retries
+	if retries == 0 {
+		retries = defaultHealthRetries
+	}
+
+	out := &record.ServiceHealth{
+		Interval:    interval,
+		Timeout:     timeout,
+		StartPeriod: startPeriod,
+		Retries:     retries,
+	}
+	switch {
+	case len(health.Exec) != 0:
+		out.Probe = record.HealthProbeExec
+		out.Command = health.Exec
+	case health.HTTP != nil:
+		out.Probe = record.HealthProbeHTTP
+		out.Path = health.HTTP.Path
+		out.Port, _ = self.healthPort(health.HTTP.Port)
+	case health.TCP != nil:
+		out.Probe = record.HealthProbeTCP
+		out.Port, _ = self.healthPort(health.TCP.Port)
+	default:
+		assert.Unreachable("decoder must validate the health check")
+	}
+
+	return out
+}
+
+func (self *ServiceApplyRequest) toSpec() record.ServiceSpec {
+	portMapping := make(map[string]string)
+	for _, port := range self.Spec.Network.Ports {
+		portMapping[port.Name] = port.Port
+	}
+
+	return record.ServiceSpec{
+		ServiceName: record.RecordKey(self.Metadata.Name),
+		Network: &record.ServiceNetwork{
+			Name:        self.Spec.Network.Name,
+			PortMapping: portMapping,
+		},
+		Container: &record.ServiceContainer{
+			Image:     self.Spec.Container.Image,
+			Env:       toServiceEnv(self.Spec.Container.Env),
+			Health:    self.toHealth(),
+			Resources: self.toResources(),
+			Security:  self.toSecurity(),
+			Volumes:   toServiceVolumes(self.Spec.Container.Volumes),
+			Restart:   self.Spec.Container.Restart,
+		},
+		InitContainers: toAuxiliaryContainers(self.Spec.InitContainers),
+		Sidecars:       toAuxiliaryContainers(self.Spec.Sidecars),
+		Strategy:       self.Spec.Strategy,
+		Replicas:       self.Spec.Replicas,
+		Balance:        self.Spec.Balance,
+	}
+}
+
+func (self *ZeusController) PostServiceApply(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceApplyRequest,
+) {
+	assert.True(command.Application.valid(), "decoder must validate the application")
+
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	// the tag stays resolved to the same digest until the image is updated explicitly
+	spec := command.toSpec()
+	existing := state.Service.Get(spec.ServiceName)
+	if existing != nil && existing.Container.Image == spec.Container.Image {
+		spec.Container.Digest = existing.Container.Digest
+	}
+	if spec.Container.Digest == "" {
+		digest, err := runtime.ResolveImage(r.Context(), spec.Container.Image, pullOptions(state, spec.Container.Image))
+		if err != nil {
+			replyBadRequest(w, "Failed to resolve image %q: %v", spec.Container.Image, err)
+			return
+		}
+		spec.Container.Digest = digest
+	}
+	for _, containers := range [][]record.ServiceAuxiliaryContainer{spec.InitContainers, spec.Sidecars} {
+		if err := resolveAuxiliaryImages(r.Context(), state, existing, containers); err != nil {
+			replyBadRequest(w, "Failed to resolve image: %v", err)
+			return
+		}
+	}
+
+	err = self.records.tx(
+		command.Application,
+		func(r *record.ApplicationRecord) error {
+			if existing := r.Service.Get(spec.ServiceName); existing != nil {
+				r.Service.SetPrevious(*existing, &spec)
+				*existing = spec
+				return nil
+			}
+
+			r.Service.Services = append(r.Service.Services, spec)
+			return nil
+		},
+	)
+	if errors.Is(err, bboltErr.ErrBucketNotFound) {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	assert.ErrNil(err)
+
+	self.orchestrator.ping()
+	w.WriteHeader(http.StatusOK)
+}
+
+// Returns the init containers and sidecars of the service, in the order they are resolved.
+func auxiliaryContainers(spec *record.ServiceSpec) []*record.ServiceAuxiliaryContainer {
+	var containers []*record.ServiceAuxiliaryContainer = nil
+	for idx := range spec.InitContainers {
+		containers = append(containers, &spec.InitContainers[idx])
+	}
+	for idx := range spec.Sidecars {
+		containers = append(containers, &spec.Sidecars[idx])
+	}
+
+	return containers
+}
+
+// Reports if the init containers and sidecars still run the images of the updates.
+func sameAuxiliaryImages(containers []*record.ServiceAuxiliaryContainer, updates []ServiceUpdateImageContainerResponse) bool {
+	if len(containers) != len(updates) {
+		return false
+	}
+	for idx, aux := range containers {
+		if aux.Name != updates[idx].Name || aux.Image != updates[idx].Image {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolves the image tags of the init containers or sidecars like the one of the container. A container
+// keeps the digest of the existing specification as long as its image is unchanged.
+func resolveAuxiliaryImages(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	existing *record.ServiceSpec,
+	containers []record.ServiceAuxiliaryContainer,
+) error {
+	for idx := range containers {
+		aux := &containers[idx]
+		if existing != nil {
+			for _, other := range slices.Concat(existing.InitContainers, existing.Sidecars) {
+				if other.Name == aux.Name && other.Image == aux.Image {
+					aux.Digest = other.Digest
+				}
+			}
+		}
+		if aux.Digest != "" {
+			continue
+		}
+
+		digest, err := runtime.ResolveImage(ctx, aux.Image, pullOptions(state, aux.Image))
+		if err != nil {
+			return fmt.Errorf("image %q of container %q: %w", aux.Image, aux.Name, err)
+		}
+		aux.Digest = digest
+	}
+
+	return nil
+}
+
+func decodeServicePathValues(
+	w http.ResponseWriter,
+	r *http.Request,
+) (application, record.RecordKey, error) {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return "", "", err
+	}
+
+	s := r.PathValue("service")
+	if err := decodeServiceName(s, w); err != nil {
+		return "", "", err
+	}
+
+	return application(a), record.RecordKey(s), nil
+}
+
+func GetServiceInspectRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceInspectRequest,
+) error {
+	a, s, err := decodeServicePathValues(w, r)
+	if err != nil {
+		return err
+	}
+
+	out.Application = a
+	out.Service = s
+	return nil
+}
+
+func GetServiceInspectAllRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceInspectAllRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+
+	out.Application = application(a)
+	return nil
+}
+
+func DeleteServiceRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceDeleteRequest,
+) error {
+	a, s, err := decodeServicePathValues(w, r)
+	if err != nil {
+		return err
+	}
+
+	out.Application = a
+	out.Service = s
+	return nil
+}
+
+func buildServiceResponse(
+	ctx context.Context,
+	state *record.ApplicationRecord,
+	spec *record.ServiceSpec,
+) ServiceInspectResponse {
+	response := ServiceInspectResponse{
+		Name:     string(spec.ServiceName),
+		Hostname: spec.Hostname(),
+		Image:    spec.Container.Image,
+		Health:   "-",
+		Ready:    state.Service.Ready(spec.ServiceName),
+		State:    "-",
+		Restart:  spec.Container.RestartPolicy(),
+		Container: ContainerInspectResponse{
+			ContainerID: "-",
+			Image:       spec.Container.Image,
+			Digest:      spec.Container.ImageReference(),
+			ImageID:     "-",
+			State:       "Not Created",
+		},
+		Balance:  spec.BalanceMethod(),
+		Replicas: make([]ServiceReplicaInspectResponse, 0, spec.ReplicaCount()),
+		Ports:    make([]ServicePortInspectResponse, 0),
+		Env:      make([]ServiceEnvInspectResponse, 0),
+		Errors:   make([]ServiceErrorInspectEntry, 0),
+	}
+
+	status, ok := state.Service.Status[spec.ServiceName]
+	if ok {
+		response.Health = status.Health
+		response.State = status.State
+	}
+	for replica := range spec.ReplicaCount() {
+		entry := ServiceReplicaInspectResponse{
+			Replica:     replica,
+			ContainerID: "-",
+			Address:     state.Network.ReplicaAddress(spec.ServiceName, replica),
+			Health:      "-",
+			Ready:       state.Service.ReplicaReady(spec.ServiceName, replica),
+			State:       "-",
+		}
+		if ok && replica < len(status.Replicas) {
+			entry.ContainerID = status.Replicas[replica].ContainerID
+			entry.Health = status.Replicas[replica].Health
+			entry.State = status.Replicas[replica].State
+		}
+		response.Replicas = append(response.Replicas, entry)
+	}
+	if crash := state.Service.Crash(spec); crash != nil {
+		response.Restarts = crash.Restarts
+		response.LastExit = &ServiceExitInspectResponse{
+			Container: crash.Container,
+			Code:      crash.ExitCode,
+			At:        crash.ExitedAt,
+			Logs:      crash.Logs,
+		}
+	}
+
+	if spec.Network != nil {
+		for _, name := range slices.Sorted(maps.Keys(spec.Network.PortMapping)) {
+			response.Ports = append(response.Ports, ServicePortInspectResponse{
+				Name: name,
+				Port: spec.Network.PortMapping[name],
+			})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(spec.Container.Env)) {
+		response.Env = append(response.Env, ServiceEnvInspectResponse{
+			Name:  name,
+			Value: spec.Container.Env[name],
+		})
+	}
+
+	// a rollout runs the containers of the previous specification next to the current ones until they are drained
+	container, err := runtime.FindContainer(
+		ctx,
+		state.Metadata.Application,
+		runtime.ObjectTypeLabel(runtime.ServiceObject),
+		runtime.ObjectHashLabel(spec.Hash()),
+		runtime.ServiceNameLabel(string(spec.ServiceName)),
+		runtime.ServiceRoleLabel(runtime.MainContainerRole),
+	)
+	if errors.Is(err, runtime.ErrContainerNotFound) {
+		container, err = runtime.FindContainer(
+			ctx,
+			state.Metadata.Application,
+			runtime.ObjectTypeLabel(runtime.ServiceObject),
+			runtime.ServiceNameLabel(string(spec.ServiceName)),
+			runtime.ServiceRoleLabel(runtime.MainContainerRole),
+		)
+	}
+	if err != nil && !errors.Is(err, runtime.ErrContainerNotFound) {
+		state.Service.SetError(
+			runtimeErr.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, runtimeErr.DockerSelectContainer, err),
+		)
+	}
+
+	if err == nil {
+		inspect, err := container.Inspect(ctx)
+		if err != nil {
+			state.Service.SetError(
+				runtimeErr.FailedServiceInteractionWithDockerDaemon(spec.ServiceName, runtimeErr.DockerInspectContainer, err),
+			)
+		} else {
+			response.Container.State = inspect.State.Status
+			response.Container.ContainerID = inspect.ID
+			response.Container.ImageID = inspect.Image
+		}
+	}
+
+	for _, e := range state.Service.Errors {
+		if e.Service != spec.ServiceName && e.Service != "*" {
+			continue
+		}
+
+		response.Errors = append(response.Errors, ServiceErrorInspectEntry{
+			Type:       e.Type,
+			Identifier: e.Identifier,
+			Message:    e.Message,
+		})
+	}
+
+	return response
+}
+
+func (self *ZeusController) GetServiceInspect(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceInspectRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	spec := state.Service.Get(command.Service)
+	if spec == nil {
+		replyBadRequest(w, "Service does not exist")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(buildServiceResponse(r.Context(), state, spec))
+	assert.ErrNil(err)
+}
+
+func (self *ZeusController) GetServiceInspectAll(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceInspectAllRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	response := ServiceInspectAllResponse{
+		Services: make([]ServiceInspectResponse, 0),
+	}
+	for idx := range state.Service.Services {
+		response.Services = append(
+			response.Services,
+			buildServiceResponse(r.Context(), state, &state.Service.Services[idx]),
+		)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
+
+func (self *ZeusController) DeleteService(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceDeleteRequest,
+) {
+	err := self.records.tx(
+		command.Application,
+		func(r *record.ApplicationRecord) error {
+			for idx, spec := range r.Service.Services {
+				if spec.ServiceName == command.Service {
+					r.Service.Services = append(r.Service.Services[:idx], r.Service.Services[idx+1:]...)
+					delete(r.Service.Previous, command.Service)
+					return nil
+				}
+			}
+
+			return ErrServiceNotFound
+		},
+	)
+
+	switch {
+	case errors.Is(err, bboltErr.ErrBucketNotFound):
+		replyBadRequest(w, "Application does not exist")
+		return
+
+	case errors.Is(err, ErrServiceNotFound):
+		replyBadRequest(w, "Service does not exist")
+		return
+
+	case err != nil:
+		assert.Unreachable("cover all cases of the delete transaction")
+	}
+
+	self.orchestrator.ping()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type ServiceUpdateImageRequest struct {
+	Application application
+	Service     record.RecordKey
+}
+
+type ServiceUpdateImageResponse struct {
+	Image    string `json:"image"`
+	Digest   string `json:"digest"`
+	Previous string `json:"previous"`
+	// Reports if the digest of any container of the service changed
+	Updated bool `json:"updated"`
+	// Init containers and sidecars of the service
+	Containers []ServiceUpdateImageContainerResponse `json:"containers,omitempty"`
+}
+
+type ServiceUpdateImageContainerResponse struct {
+	Name     string `json:"name"`
+	Image    string `json:"image"`
+	Digest   string `json:"digest"`
+	Previous string `json:"previous"`
+	Updated  bool   `json:"updated"`
+}
+
+func PostServiceUpdateImageRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *ServiceUpdateImageRequest,
+) error {
+	a, s, err := decodeServicePathValues(w, r)
+	if err != nil {
+		return err
+	}
+
+	out.Application = a
+	out.Service = s
+	return nil
+}
+
+// Resolves the image tags of the service, its init containers and sidecars again to the digests of the
+// images in the registry. The images are always pulled, therefore a tag which moved in the registry is
+// picked up. The containers are replaced if a digest changed.
+func (self *ZeusController) PostServiceUpdateImage(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *ServiceUpdateImageRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	spec := state.Service.Get(command.Service)
+	if spec == nil {
+		replyBadRequest(w, "Service does not exist")
+		return
+	}
+
+	image := spec.Container.Image
+	options := pullOptions(state, image)
+	options.Always = true
+	digest, err := runtime.ResolveImage(r.Context(), image, options)
+	if err != nil {
+		replyBadRequest(w, "Failed to resolve image %q: %v", image, err)
+		return
+	}
+
+	response := ServiceUpdateImageResponse{Image: image, Digest: digest}
+	for _, aux := range slices.Concat(spec.InitContainers, spec.Sidecars) {
+		options := pullOptions(state, aux.Image)
+		options.Always = true
+		digest, err := runtime.ResolveImage(r.Context(), aux.Image, options)
+		if err != nil {
+			replyBadRequest(w, "Failed to resolve image %q of container %q: %v", aux.Image, aux.Name, err)
+			return
+		}
+		response.Containers = append(response.Containers, ServiceUpdateImageContainerResponse{
+			Name:   aux.Name,
+			Image:  aux.Image,
+			Digest: digest,
+		})
+	}
+
+	err = self.records.tx(
+		command.Application,
+		func(r *record.ApplicationRecord) error {
+			spec := r.Service.Get(command.Service)
+			if spec == nil {
+				return ErrServiceNotFound
+			}
+			auxiliary := auxiliaryContainers(spec)
+			// the service was applied with other images in the meantime, which were resolved on their own
+			if spec.Container.Image != image || !sameAuxiliaryImages(auxiliary, response.Containers) {
+				return ErrServiceImageChanged
+			}
+
+			previous := *spec
+			container := *spec.Container
+			previous.Container = &container
+			previous.InitContainers = slices.Clone(spec.InitContainers)
+			previous.Sidecars = slices.Clone(spec.Sidecars)
+
+			response.Previous = spec.Container.Digest
+			response.Updated = spec.Container.Digest != digest
+			spec.Container.Digest = digest
+			for idx, aux := range auxiliary {
+				update := &response.Containers[idx]
+				update.Previous = aux.Digest
+				update.Updated = aux.Digest != update.Digest
+				response.Updated = response.Updated || update.Updated
+				aux.Digest = update.Digest
+			}
+			r.Service.SetPrevious(previous, spec)
+			return nil
+		},
+	)
+
+	switch {
+	case errors.Is(err, bboltErr.ErrBucketNotFound):
+		replyBadRequest(w, "Application does not exist")
+		return
+
+	case errors.Is(err, ErrServiceNotFound):
+		replyBadRequest(w, "Service does not exist")
+		return
+
+	case errors.Is(err, ErrServiceImageChanged):
+		replyBadRequest(w, "Service was applied with another image in the meantime")
+		return
+
+	case err != nil:
+		assert.Unreachable("cover all cases of the update transaction")
+	}
+
+	if response.Updated {
+		self.orchestrator.ping()
+	}
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
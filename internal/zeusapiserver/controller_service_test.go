@@ -0,0 +1,548 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"go.etcd.io/bbolt"
+)
+
+func decodeServiceApply(application string, body string) (*ServiceApplyRequest, *httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("POST", ServiceApplyAPIPath("v1.0", application), strings.NewReader(body))
+	r.SetPathValue("application", application)
+	w := httptest.NewRecorder()
+
+	out := new(ServiceApplyRequest)
+	err := PostServiceApplyRequestDecoder(w, r, out)
+	return out, w, err
+}
+
+func TestServiceApplyDecoder(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {
+			"network": {"name": "rroll", "ports": [{"name": "application", "port": "8000"}]},
+			"container": {"image": "rickroll:v1.12", "env": [{"name": "DELAY", "value": "10"}]}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	spec := out.toSpec()
+	if spec.ServiceName != "rickroll" || spec.Hostname() != "rroll" {
+		t.Errorf("service name or hostname not decoded correctly, got '%v'", spec)
+	}
+	if spec.Network.PortMapping["application"] != "8000" {
+		t.Errorf("port mapping not decoded correctly, got '%v'", spec.Network.PortMapping)
+	}
+	if spec.Container.Env["DELAY"] != "10" {
+		t.Errorf("env not decoded correctly, got '%v'", spec.Container.Env)
+	}
+}
+
+func TestServiceApplyDecoderHealth(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {
+			"network": {"ports": [{"name": "application", "port": "8000"}]},
+			"container": {"image": "rickroll:v1.12", "health": {"http": {"path": "/healthz", "port": "application"}, "interval": "3s"}}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	health := out.toSpec().Container.Health
+	if health == nil || health.Probe != record.HealthProbeHTTP {
+		t.Fatalf("expected http health check, got '%v'", health)
+	}
+	if health.Port != "8000" || health.Path != "/healthz" {
+		t.Errorf("expected port name to be resolved, got port '%s' and path '%s'", health.Port, health.Path)
+	}
+	if health.Interval != 3*time.Second || health.Timeout != defaultHealthTimeout || health.Retries != defaultHealthRetries {
+		t.Errorf("expected defaults for unset values, got '%v'", health)
+	}
+}
+
+func TestServiceApplyDecoderLimits(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {
+			"container": {
+				"image": "rickroll:v1.12",
+				"resources": {"memory": "512m", "cpus": "0.5", "pids": 100},
+				"security": {"dropCapabilities": ["cap_net_raw"], "user": "app", "tmpfs": [{"path": "/tmp/", "size": "64m"}]}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	container := out.toSpec().Container
+	if container.Resources.Memory != 512<<20 || container.Resources.NanoCPUs != 5e8 || container.Resources.Pids != 100 {
+		t.Errorf("resources not decoded correctly, got '%v'", container.Resources)
+	}
+	if container.Security.DropCapabilities[0] != "NET_RAW" {
+		t.Errorf("expected capability to be normalized, got '%s'", container.Security.DropCapabilities[0])
+	}
+	if tmpfs := container.Security.Tmpfs[0]; tmpfs.Path != "/tmp" || tmpfs.Size != 64<<20 {
+		t.Errorf("tmpfs not decoded correctly, got '%v'", tmpfs)
+	}
+}
+
+func TestServiceApplyDecoderVolumes(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "postgres"},
+		"spec": {"container": {"image": "postgres:17", "volumes": [{"name": "data", "path": "/var/lib/postgresql/data/", "readOnly": true}]}}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	volumes := out.toSpec().Container.Volumes
+	if len(volumes) != 1 || volumes[0].Name != "data" || volumes[0].Path != "/var/lib/postgresql/data" || !volumes[0].ReadOnly {
+		t.Errorf("volumes not decoded correctly, got '%v'", volumes)
+	}
+}
+
+func TestServiceApplyDecoderRestart(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "migrate"},
+		"spec": {"container": {"image": "migrate:v1", "restart": "on-failure"}}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	if policy := out.toSpec().Container.RestartPolicy(); policy != record.RestartOnFailure {
+		t.Errorf("expected restart policy '%s', got '%s'", record.RestartOnFailure, policy)
+	}
+}
+
+func TestServiceApplyDecoderStrategy(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "postgres"},
+		"spec": {"container": {"image": "postgres:v1"}, "strategy": "recreate"}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	spec := out.toSpec()
+	if strategy := spec.UpdateStrategy(); strategy != record.StrategyRecreate {
+		t.Errorf("expected strategy '%s', got '%s'", record.StrategyRecreate, strategy)
+	}
+}
+
+func TestServiceApplyDecoderReplicas(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {"container": {"image": "rickroll:v1"}, "replicas": 3, "balance": "sticky"}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	spec := out.toSpec()
+	if spec.ReplicaCount() != 3 || spec.BalanceMethod() != record.BalanceSticky {
+		t.Errorf("expected 3 replicas balanced by '%s', got %d by '%s'", record.BalanceSticky, spec.ReplicaCount(), spec.BalanceMethod())
+	}
+
+	out, _, err = decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {"container": {"image": "rickroll:v1"}}
+	}`)
+	assert.ErrNil(err)
+	if spec := out.toSpec(); spec.ReplicaCount() != 1 || spec.BalanceMethod() != record.BalanceRoundRobin {
+		t.Errorf("expected a single replica balanced by '%s', got %d by '%s'", record.BalanceRoundRobin, spec.ReplicaCount(), spec.BalanceMethod())
+	}
+}
+
+func TestServiceApplyDecoderAuxiliaryContainers(t *testing.T) {
+	out, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {
+			"container": {"image": "rickroll:v1"},
+			"initContainers": [{"name": "migrate", "image": "migrate:v1", "command": ["migrate", "up"], "volumes": [{"name": "data", "path": "/data/"}]}],
+			"sidecars": [{"name": "shipper", "image": "shipper:v1", "env": [{"name": "TARGET", "value": "loki"}]}]
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+
+	spec := out.toSpec()
+	if len(spec.InitContainers) != 1 || len(spec.Sidecars) != 1 {
+		t.Fatalf("expected init container and sidecar, got %v and %v", spec.InitContainers, spec.Sidecars)
+	}
+	init := spec.InitContainers[0]
+	if init.Name != "migrate" || init.Image != "migrate:v1" || len(init.Command) != 2 || init.Volumes[0].Path != "/data" {
+		t.Errorf("init container not decoded correctly, got '%v'", init)
+	}
+	if sidecar := spec.Sidecars[0]; sidecar.Name != "shipper" || sidecar.Env["TARGET"] != "loki" {
+		t.Errorf("sidecar not decoded correctly, got '%v'", sidecar)
+	}
+	if volumes := spec.Volumes(); len(volumes) != 1 || volumes[0].Name != "data" {
+		t.Errorf("expected volumes of the init container to belong to the service, got %v", volumes)
+	}
+}
+
+func TestServiceApplyDecoderRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "invalid.name",
+			body: `{"metadata": {"name": "Rick_Roll"}, "spec": {"container": {"image": "a"}}}`,
+		},
+		{
+			name: "missing.image",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": ""}}}`,
+		},
+		{
+			name: "invalid.strategy",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "strategy": "blue-green"}}`,
+		},
+		{
+			name: "negative.replicas",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "replicas": -1}}`,
+		},
+		{
+			name: "too.many.replicas",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "replicas": 1000}}`,
+		},
+		{
+			name: "invalid.balance",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "balance": "random"}}`,
+		},
+		{
+			name: "invalid.port",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"network": {"ports": [{"name": "a", "port": "70000"}]}, "container": {"image": "a"}}}`,
+		},
+		{
+			name: "duplicated.port",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"network": {"ports": [{"name": "a", "port": "80"}, {"name": "a", "port": "81"}]}, "container": {"image": "a"}}}`,
+		},
+		{
+			name: "health.without.probe",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "health": {"interval": "5s"}}}}`,
+		},
+		{
+			name: "health.multiple.probes",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "health": {"exec": ["true"], "tcp": {"port": "80"}}}}}`,
+		},
+		{
+			name: "health.unknown.port",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "health": {"http": {"path": "/", "port": "http"}}}}}`,
+		},
+		{
+			name: "health.invalid.duration",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "health": {"exec": ["true"], "timeout": "soon"}}}}`,
+		},
+		{
+			name: "memory.too.small",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "resources": {"memory": "1k"}}}}`,
+		},
+		{
+			name: "cpus.negative",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "resources": {"cpus": "-1"}}}}`,
+		},
+		{
+			name: "cpus.exceed.host",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "resources": {"cpus": "100000"}}}}`,
+		},
+		{
+			name: "invalid.capability",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "security": {"dropCapabilities": ["net raw"]}}}}`,
+		},
+		{
+			name: "group.without.user",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "security": {"group": "1000"}}}}`,
+		},
+		{
+			name: "unknown.restart.policy",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "restart": "unless-stopped"}}}`,
+		},
+		{
+			name: "relative.tmpfs",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "security": {"tmpfs": [{"path": "tmp"}]}}}}`,
+		},
+		{
+			name: "invalid.volume.name",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "volumes": [{"name": "Data", "path": "/data"}]}}}`,
+		},
+		{
+			name: "duplicated.volume.path",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "volumes": [{"name": "a", "path": "/data"}, {"name": "b", "path": "/data/"}]}}}`,
+		},
+		{
+			name: "sidecar.without.image",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "sidecars": [{"name": "shipper"}]}}`,
+		},
+		{
+			name: "invalid.init.container.name",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "initContainers": [{"name": "Migrate", "image": "b"}]}}`,
+		},
+		{
+			name: "duplicated.auxiliary.container",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a"}, "initContainers": [{"name": "x", "image": "b"}], "sidecars": [{"name": "x", "image": "c"}]}}`,
+		},
+		{
+			name: "invalid.env",
+			body: `{"metadata": {"name": "rickroll"}, "spec": {"container": {"image": "a", "env": [{"name": "1X", "value": ""}]}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, w, err := decodeServiceApply("poseidon", tt.body)
+			if err == nil {
+				t.Fatalf("expected request to be rejected")
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestServiceApplyPinsImageDigest(t *testing.T) {
+	fake := runtime.NewFakeBackend()
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+	controller := &ZeusController{
+		records:      records,
+		orchestrator: &orchestrator{signal: make(chan struct{}, 1)},
+	}
+
+	apply := func(image string) string {
+		command, _, err := decodeServiceApply("poseidon", `{
+			"metadata": {"name": "rickroll"},
+			"spec": {"container": {"image": "`+image+`"}}
+		}`)
+		assert.ErrNil(err)
+
+		w := httptest.NewRecorder()
+		controller.PostServiceApply(w, httptest.NewRequest("POST", "/", nil), command)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected service to be applied, got %d: %s", w.Code, w.Body.String())
+		}
+
+		state, err := records.get("poseidon")
+		assert.ErrNil(err)
+		return state.Service.Get("rickroll").Container.Digest
+	}
+
+	pinned := apply("rickroll:v1")
+	if !strings.HasPrefix(pinned, "rickroll@sha256:") {
+		t.Fatalf("expected tag to be resolved to a digest, got '%s'", pinned)
+	}
+	// the fake serves a new digest on every pull of a tag, i.e. the tag moved in the registry
+	// since the apply, a re-apply must not pick it up
+	if digest := apply("rickroll:v1"); digest != pinned {
+		t.Errorf("expected re-apply to keep digest '%s', got '%s'", pinned, digest)
+	}
+
+	w := httptest.NewRecorder()
+	controller.PostServiceUpdateImage(w, httptest.NewRequest("POST", "/", nil), &ServiceUpdateImageRequest{
+		Application: "poseidon",
+		Service:     "rickroll",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected image to be updated, got %d: %s", w.Code, w.Body.String())
+	}
+	var update ServiceUpdateImageResponse
+	assert.ErrNil(json.NewDecoder(w.Body).Decode(&update))
+	if !update.Updated || update.Previous != pinned || update.Digest == pinned {
+		t.Errorf("expected update from '%s' to the moved tag, got %v", pinned, update)
+	}
+	if digest := apply("rickroll:v1"); digest != update.Digest {
+		t.Errorf("expected re-apply to keep updated digest '%s', got '%s'", update.Digest, digest)
+	}
+
+	if digest := apply("rickroll:v2"); digest == update.Digest || digest == "" {
+		t.Errorf("expected changed image to be resolved again, got '%s'", digest)
+	}
+}
+
+func TestServiceUpdateImageRefreshesAuxiliaryContainers(t *testing.T) {
+	fake := runtime.NewFakeBackend()
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+	controller := &ZeusController{
+		records:      records,
+		orchestrator: &orchestrator{signal: make(chan struct{}, 1)},
+	}
+
+	command, _, err := decodeServiceApply("poseidon", `{
+		"metadata": {"name": "rickroll"},
+		"spec": {
+			"container": {"image": "rickroll:v1"},
+			"initContainers": [{"name": "migrate", "image": "migrate:v1"}],
+			"sidecars": [{"name": "shipper", "image": "shipper:v1"}]
+		}
+	}`)
+	assert.ErrNil(err)
+	w := httptest.NewRecorder()
+	controller.PostServiceApply(w, httptest.NewRequest("POST", "/", nil), command)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected service to be applied, got %d: %s", w.Code, w.Body.String())
+	}
+	state, err := records.get("poseidon")
+	assert.ErrNil(err)
+	pinned := state.Service.Get("rickroll")
+
+	w = httptest.NewRecorder()
+	controller.PostServiceUpdateImage(w, httptest.NewRequest("POST", "/", nil), &ServiceUpdateImageRequest{
+		Application: "poseidon",
+		Service:     "rickroll",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected images to be updated, got %d: %s", w.Code, w.Body.String())
+	}
+	var update ServiceUpdateImageResponse
+	assert.ErrNil(json.NewDecoder(w.Body).Decode(&update))
+	if !update.Updated || len(update.Containers) != 2 {
+		t.Fatalf("expected the init container and the sidecar to be reported, got %v", update)
+	}
+
+	state, err = records.get("poseidon")
+	assert.ErrNil(err)
+	spec := state.Service.Get("rickroll")
+	for idx, aux := range []struct {
+		pinned, current record.ServiceAuxiliaryContainer
+	}{
+		{pinned.InitContainers[0], spec.InitContainers[0]},
+		{pinned.Sidecars[0], spec.Sidecars[0]},
+	} {
+		reported := update.Containers[idx]
+		if reported.Name != aux.current.Name || !reported.Updated || reported.Previous != aux.pinned.Digest {
+			t.Errorf("expected update of container '%s' from '%s', got %v", aux.current.Name, aux.pinned.Digest, reported)
+		}
+		if aux.current.Digest != reported.Digest || aux.current.Digest == aux.pinned.Digest {
+			t.Errorf("expected container '%s' to move to the pulled digest, got '%s'", aux.current.Name, aux.current.Digest)
+		}
+	}
+}
+
+func TestServiceRolloutUndo(t *testing.T) {
+	fake := runtime.NewFakeBackend()
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+	controller := &ZeusController{
+		records:      records,
+		orchestrator: &orchestrator{signal: make(chan struct{}, 1)},
+	}
+
+	apply := func(image string) {
+		command, _, err := decodeServiceApply("poseidon", `{
+			"metadata": {"name": "rickroll"},
+			"spec": {"container": {"image": "`+image+`"}}
+		}`)
+		assert.ErrNil(err)
+
+		w := httptest.NewRecorder()
+		controller.PostServiceApply(w, httptest.NewRequest("POST", "/", nil), command)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected service to be applied, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	undo := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		controller.PostServiceRolloutUndo(w, httptest.NewRequest("POST", "/", nil), &ServiceRolloutUndoRequest{
+			Application: "poseidon",
+			Service:     "rickroll",
+		})
+		return w
+	}
+	image := func() string {
+		state, err := records.get("poseidon")
+		assert.ErrNil(err)
+		return state.Service.Get("rickroll").Container.Image
+	}
+
+	apply("rickroll:v1")
+	if w := undo(); w.Code != http.StatusBadRequest {
+		t.Errorf("expected undo without previous specification to be rejected, got %d", w.Code)
+	}
+
+	apply("rickroll:v2")
+	// an unchanged specification keeps the previous one
+	apply("rickroll:v2")
+	w := httptest.NewRecorder()
+	controller.GetServiceRollout(w, httptest.NewRequest("GET", "/", nil), &ServiceRolloutRequest{
+		Application: "poseidon",
+		Service:     "rickroll",
+	})
+	var status ServiceRolloutResponse
+	assert.ErrNil(json.NewDecoder(w.Body).Decode(&status))
+	if status.State != rolloutPending || status.Strategy != record.StrategyRolling || status.PreviousImage != "rickroll:v1" {
+		t.Errorf("expected pending rollout from 'rickroll:v1', got %v", status)
+	}
+
+	if w := undo(); w.Code != http.StatusOK || image() != "rickroll:v1" {
+		t.Fatalf("expected undo to restore 'rickroll:v1', got %d and '%s'", w.Code, image())
+	}
+	if w := undo(); w.Code != http.StatusOK || image() != "rickroll:v2" {
+		t.Errorf("expected second undo to return to 'rickroll:v2', got %d and '%s'", w.Code, image())
+	}
+}
+
+func TestServiceInspectWhileDraining(t *testing.T) {
+	fake := runtime.NewFakeBackend()
+	fake.AddImage("coredns:v1")
+	previous := runtime.SetBackend(fake)
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+	ctx := context.Background()
+	_, err := runtime.CreateNewNetwork(ctx, "poseidon")
+	assert.ErrNil(err)
+
+	state := record.New("poseidon", record.Development)
+	state.Service.Services = []record.ServiceSpec{
+		{ServiceName: "rickroll", Container: &record.ServiceContainer{Image: "rickroll:v1"}},
+	}
+	runtime.Sync(ctx, state)
+	state.Service.Services[0].Container.Image = "rickroll:v2"
+	runtime.Sync(ctx, state)
+	if rollout := state.Service.Rollout("rickroll"); rollout == nil || len(rollout.Draining) != 1 {
+		t.Fatalf("expected previous container to be drained, got %v", rollout)
+	}
+
+	response := buildServiceResponse(ctx, state, state.Service.Get("rickroll"))
+	if response.Container.ContainerID != state.Service.Status["rickroll"].ContainerID {
+		t.Errorf("expected container of the current specification, got '%s'", response.Container.ContainerID)
+	}
+}
@@ -0,0 +1,484 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+var (
+	ErrBadRequestSnapshot = errors.New("bad request: snapshot")
+
+	snapshotIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z(-[0-9]+)?$`)
+)
+
+const (
+	// Lower limit of the interval of backup schedules
+	minBackupInterval = time.Hour
+	// Number of scheduled snapshots which are kept, unless configured otherwise
+	defaultBackupRetain = 7
+)
+
+const (
+	volumeBackupAPIPath       = "/v1.0/applications/{application}/volumes/{volume}/backup"
+	volumeRestoreAPIPath      = "/v1.0/applications/{application}/volumes/{volume}/restore"
+	volumeScheduleAPIPath     = "/v1.0/applications/{application}/volumes/{volume}/schedule"
+	snapshotInspectAllAPIPath = "/v1.0/applications/{application}/snapshots"
+	snapshotDeleteAPIPath     = "/v1.0/applications/{application}/snapshots/{snapshot}"
+)
+
+func volumePath(path string, application string, volume string) string {
+	path = strings.Replace(path, "{application}", application, 1)
+	return strings.Replace(path, "{volume}", volume, 1)
+}
+
+func VolumeBackupAPIPath(application string, volume string) string {
+	return volumePath(volumeBackupAPIPath, application, volume)
+}
+
+func VolumeRestoreAPIPath(application string, volume string) string {
+	return volumePath(volumeRestoreAPIPath, application, volume)
+}
+
+func VolumeScheduleAPIPath(application string, volume string) string {
+	return volumePath(volumeScheduleAPIPath, application, volume)
+}
+
+func SnapshotInspectAllAPIPath(application string) string {
+	return strings.Replace(snapshotInspectAllAPIPath, "{application}", application, 1)
+}
+
+func SnapshotDeleteAPIPath(application string, snapshot string) string {
+	path := strings.Replace(snapshotDeleteAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{snapshot}", snapshot, 1)
+}
+
+func decodeSnapshotID(id string, w http.ResponseWriter) error {
+	if !snapshotIDPattern.MatchString(id) {
+		replyBadRequest(w, "Snapshot ID must have the format 20060102T150405Z")
+		return ErrBadRequestSnapshot
+	}
+
+	return nil
+}
+
+// Decodes the application and volume of the path.
+func decodeVolumePath(w http.ResponseWriter, r *http.Request) (application, string, error) {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return "", "", err
+	}
+
+	v := r.PathValue("volume")
+	if err := decodeVolumeName(v, w); err != nil {
+		return "", "", err
+	}
+
+	return application(a), v, nil
+}
+
+type VolumeBackupRequest struct {
+	Application application
+	Volume      string
+}
+
+type VolumeRestoreRequestBody struct {
+	// ID of the snapshot, which may be taken of another volume of the application
+	Snapshot string `json:"snapshot"`
+}
+
+type VolumeRestoreRequest struct {
+	Application application
+	Volume      string
+	VolumeRestoreRequestBody
+}
+
+type VolumeScheduleRequestBody struct {
+	// Duration between two snapshots, e.g. 24h
+	Interval string `json:"interval"`
+	// Number of scheduled snapshots which are kept, default 7
+	Retain int `json:"retain,omitempty"`
+}
+
+type VolumeScheduleRequest struct {
+	Application application
+	Volume      string
+	Interval    time.Duration
+	Retain      int
+}
+
+type VolumeScheduleDeleteRequest struct {
+	Application application
+	Volume      string
+}
+
+type SnapshotInspectAllRequest struct {
+	Application application
+}
+
+type SnapshotDeleteRequest struct {
+	Application application
+	Snapshot    string
+}
+
+type SnapshotInspectResponse struct {
+	ID        string `json:"id"`
+	Volume    string `json:"volume"`
+	Target    string `json:"target"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"checksum"`
+	CreatedAt string `json:"createdAt"`
+	Scheduled bool   `json:"scheduled"`
+}
+
+type ScheduleInspectResponse struct {
+	Volume   string `json:"volume"`
+	Interval string `json:"interval"`
+	Retain   int    `json:"retain"`
+	LastRun  string `json:"lastRun"`
+}
+
+type SnapshotInspectAllResponse struct {
+	Snapshots []SnapshotInspectResponse `json:"snapshots"`
+	Schedules []ScheduleInspectResponse `json:"schedules"`
+}
+
+func toSnapshotResponse(snap record.SnapshotRecord) SnapshotInspectResponse {
+	return SnapshotInspectResponse{
+		ID:        snap.ID,
+		Volume:    snap.Volume,
+		Target:    snap.Target,
+		Size:      snap.Size,
+		Checksum:  snap.Checksum,
+		CreatedAt: snap.CreatedAt.UTC().Format(time.RFC3339),
+		Scheduled: snap.Scheduled,
+	}
+}
+
+func toScheduleResponse(schedule record.BackupSchedule) ScheduleInspectResponse {
+	lastRun := "-"
+	if !schedule.LastRun.IsZero() {
+		lastRun = schedule.LastRun.UTC().Format(time.RFC3339)
+	}
+
+	return ScheduleInspectResponse{
+		Volume:   schedule.Volume,
+		Interval: schedule.Interval.String(),
+		Retain:   schedule.Retain,
+		LastRun:  lastRun,
+	}
+}
+
+func PostVolumeBackupRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *VolumeBackupRequest,
+) error {
+	var err error
+	out.Application, out.Volume, err = decodeVolumePath(w, r)
+	return err
+}
+
+func PostVolumeRestoreRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *VolumeRestoreRequest,
+) error {
+	var err error
+	out.Application, out.Volume, err = decodeVolumePath(w, r)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&out.VolumeRestoreRequestBody); err != nil {
+		replyBadRequest(w, "Invalid JSON payload")
+		return err
+	}
+
+	return decodeSnapshotID(out.Snapshot, w)
+}
+
+func PostVolumeScheduleRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *VolumeScheduleRequest,
+) error {
+	var err error
+	out.Application, out.Volume, err = decodeVolumePath(w, r)
+	if err != nil {
+		return err
+	}
+
+	var body VolumeScheduleRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		replyBadRequest(w, "Invalid JSON payload")
+		return err
+	}
+
+	out.Interval, err = time.ParseDuration(body.Interval)
+	if err != nil || out.Interval < minBackupInterval {
+		replyBadRequest(w, "Interval must be a duration of at least %s, e.g. 24h", minBackupInterval)
+		return ErrBadRequestSnapshot
+	}
+
+	out.Retain = body.Retain
+	if out.Retain == 0 {
+		out.Retain = defaultBackupRetain
+	}
+	if out.Retain < 1 {
+		replyBadRequest(w, "Retain must keep at least one snapshot")
+		return ErrBadRequestSnapshot
+	}
+
+	return nil
+}
+
+func DeleteVolumeScheduleRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *VolumeScheduleDeleteRequest,
+) error {
+	var err error
+	out.Application, out.Volume, err = decodeVolumePath(w, r)
+	return err
+}
+
+func GetSnapshotInspectAllRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *SnapshotInspectAllRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	return nil
+}
+
+func DeleteSnapshotRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *SnapshotDeleteRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	s := r.PathValue("snapshot")
+	if err := decodeSnapshotID(s, w); err != nil {
+		return err
+	}
+	out.Snapshot = s
+
+	return nil
+}
+
+// Takes a snapshot of the volume. The services which mount the volume keep running.
+func (self *ZeusController) PostVolumeBackup(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *VolumeBackupRequest,
+) {
+	if _, err := self.records.get(command.Application); err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	snap, err := self.backups.backup(r.Context(), command.Application, command.Volume, false)
+	if errors.Is(err, runtime.ErrVolumeNotFound) {
+		replyBadRequest(w, "Volume does not exist")
+		return
+	}
+	if err != nil {
+		replyBadRequest(w, "Failed to take snapshot: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(toSnapshotResponse(snap))
+	assert.ErrNil(err)
+}
+
+// Replaces the content of the volume with the snapshot. The volume must not be in use, therefore
+// it cannot be restored while services of the enabled application mount it.
+func (self *ZeusController) PostVolumeRestore(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *VolumeRestoreRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	snap := state.Snapshot(command.Snapshot)
+	if snap == nil {
+		replyBadRequest(w, "Snapshot does not exist")
+		return
+	}
+	services := volumeServices(state, command.Volume)
+	if state.Metadata.Enabled && len(services) != 0 {
+		replyBadRequest(
+			w,
+			"Volume is mounted by the services %s. Disable the application first.",
+			strings.Join(services, ", "),
+		)
+		return
+	}
+
+	if err := self.backups.restore(r.Context(), command.Application, command.Volume, *snap); err != nil {
+		replyBadRequest(w, "Failed to restore snapshot: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Takes a snapshot of the volume every interval, the first one after the interval passed.
+// Replaces the existing schedule of the volume.
+func (self *ZeusController) PostVolumeSchedule(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *VolumeScheduleRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	if len(volumeServices(state, command.Volume)) == 0 {
+		volumes, err := runtime.SelectVolumes(r.Context(), string(command.Application))
+		if err != nil {
+			replyBadRequest(w, "Failed to list volumes: %v", err)
+			return
+		}
+		exists := slices.ContainsFunc(volumes, func(v runtime.Volume) bool { return v.Name == command.Volume })
+		if !exists {
+			replyBadRequest(w, "Volume does not exist")
+			return
+		}
+	}
+
+	schedule := record.BackupSchedule{
+		Volume:   command.Volume,
+		Interval: command.Interval,
+		Retain:   command.Retain,
+		LastRun:  time.Now(),
+	}
+	err = self.records.tx(command.Application, func(state *record.ApplicationRecord) error {
+		if existing := state.Schedule(command.Volume); existing != nil {
+			schedule.LastRun = existing.LastRun
+		}
+		state.SetSchedule(schedule)
+		return nil
+	})
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	// the retention may have been lowered
+	if err := self.backups.prune(context.Background(), command.Application, command.Volume, command.Retain); err != nil {
+		replyBadRequest(w, "Failed to remove expired snapshots: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(toScheduleResponse(schedule))
+	assert.ErrNil(err)
+}
+
+// Stops the schedule of the volume, its snapshots are kept.
+func (self *ZeusController) DeleteVolumeSchedule(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *VolumeScheduleDeleteRequest,
+) {
+	err := self.records.tx(command.Application, func(state *record.ApplicationRecord) error {
+		if state.Schedule(command.Volume) == nil {
+			return ErrBadRequestSnapshot
+		}
+		state.RemoveSchedule(command.Volume)
+		return nil
+	})
+	if errors.Is(err, ErrBadRequestSnapshot) {
+		replyBadRequest(w, "Volume has no backup schedule")
+		return
+	}
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (self *ZeusController) GetSnapshotInspectAll(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *SnapshotInspectAllRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	response := SnapshotInspectAllResponse{
+		Snapshots: make([]SnapshotInspectResponse, 0, len(state.Snapshots)),
+		Schedules: make([]ScheduleInspectResponse, 0, len(state.Schedules)),
+	}
+	for _, snap := range state.Snapshots {
+		response.Snapshots = append(response.Snapshots, toSnapshotResponse(snap))
+	}
+	for _, schedule := range state.Schedules {
+		response.Schedules = append(response.Schedules, toScheduleResponse(schedule))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
+
+// Removes the snapshot and its archive from the store.
+func (self *ZeusController) DeleteSnapshot(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *SnapshotDeleteRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	snap := state.Snapshot(command.Snapshot)
+	if snap == nil {
+		replyBadRequest(w, "Snapshot does not exist")
+		return
+	}
+
+	if err := self.backups.delete(r.Context(), command.Application, *snap); err != nil {
+		replyBadRequest(w, "Failed to delete snapshot: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/snapshot"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+	"go.etcd.io/bbolt"
+)
+
+func newTestBackups(t *testing.T) *backups {
+	previous := runtime.SetBackend(runtime.NewFakeBackend())
+	t.Cleanup(func() { runtime.SetBackend(previous) })
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+
+	store, err := snapshot.NewDirectoryStore(t.TempDir())
+	assert.ErrNil(err)
+
+	// the worker is not started, schedules are run explicitly
+	return &backups{records: records, store: store, logger: log.New("zeusapiserver", "backups")}
+}
+
+func restoreTestVolume(t *testing.T, volume string, content string) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.ErrNil(tw.WriteHeader(&tar.Header{Name: "volume/version", Mode: 0644, Size: int64(len(content))}))
+	_, err := tw.Write([]byte(content))
+	assert.ErrNil(err)
+	assert.ErrNil(tw.Close())
+
+	assert.ErrNil(runtime.RestoreVolume(context.Background(), "poseidon", volume, &buf))
+}
+
+func TestBackupAndRestoreSnapshot(t *testing.T) {
+	b := newTestBackups(t)
+	ctx := context.Background()
+	restoreTestVolume(t, "data", "16")
+
+	snap, err := b.backup(ctx, "poseidon", "data", false)
+	assert.ErrNil(err)
+	if snap.Volume != "data" || snap.Target != snapshot.TargetDirectory || snap.Size == 0 {
+		t.Errorf("snapshot not recorded correctly, got %v", snap)
+	}
+	second, err := b.backup(ctx, "poseidon", "data", false)
+	assert.ErrNil(err)
+	if second.ID == snap.ID {
+		t.Errorf("expected snapshots to have unique IDs, got '%s' twice", snap.ID)
+	}
+
+	state, err := b.records.get("poseidon")
+	assert.ErrNil(err)
+	if len(state.Snapshots) != 2 {
+		t.Fatalf("expected snapshots to be recorded, got %v", state.Snapshots)
+	}
+
+	restoreTestVolume(t, "data", "17")
+	assert.ErrNil(b.restore(ctx, "poseidon", "copy", snap))
+	tarball, err := runtime.ArchiveVolume(ctx, "poseidon", "copy")
+	assert.ErrNil(err)
+	defer tarball.Close()
+	tr := tar.NewReader(tarball)
+	_, err = tr.Next()
+	assert.ErrNil(err)
+	hdr, err := tr.Next()
+	assert.ErrNil(err)
+	var version bytes.Buffer
+	version.ReadFrom(tr)
+	if hdr.Name != "volume/version" || version.String() != "16" {
+		t.Errorf("expected snapshot to be restored into the volume, got '%s' = '%s'", hdr.Name, version.String())
+	}
+
+	assert.ErrNil(b.delete(ctx, "poseidon", snap))
+	if err := b.restore(ctx, "poseidon", "data", snap); err == nil {
+		t.Errorf("expected restore of a deleted snapshot to fail")
+	}
+}
+
+func TestBackupSchedulesKeepRetention(t *testing.T) {
+	b := newTestBackups(t)
+	ctx := context.Background()
+	restoreTestVolume(t, "data", "16")
+
+	manual, err := b.backup(ctx, "poseidon", "data", false)
+	assert.ErrNil(err)
+
+	start := time.Now()
+	err = b.records.tx("poseidon", func(state *record.ApplicationRecord) error {
+		state.SetSchedule(record.BackupSchedule{Volume: "data", Interval: time.Hour, Retain: 2, LastRun: start})
+		return nil
+	})
+	assert.ErrNil(err)
+
+	b.runSchedules(ctx, start.Add(time.Minute))
+	state, err := b.records.get("poseidon")
+	assert.ErrNil(err)
+	if len(state.Snapshots) != 1 {
+		t.Fatalf("expected no snapshot before the interval passed, got %v", state.Snapshots)
+	}
+
+	for i := 1; i <= 3; i++ {
+		b.runSchedules(ctx, start.Add(time.Duration(i)*time.Hour))
+	}
+	state, err = b.records.get("poseidon")
+	assert.ErrNil(err)
+	if len(state.Snapshots) != 3 {
+		t.Fatalf("expected manual and two scheduled snapshots to be kept, got %v", state.Snapshots)
+	}
+	if state.Snapshot(manual.ID) == nil {
+		t.Errorf("expected manual snapshot to be kept by the retention")
+	}
+	if !state.Schedule("data").LastRun.Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("expected last run of the schedule to be updated, got %v", state.Schedule("data").LastRun)
+	}
+}
+
+func decodeVolumeSchedule(body string) (*VolumeScheduleRequest, *httptest.ResponseRecorder, error) {
+	r := httptest.NewRequest("POST", VolumeScheduleAPIPath("poseidon", "data"), strings.NewReader(body))
+	r.SetPathValue("application", "poseidon")
+	r.SetPathValue("volume", "data")
+	w := httptest.NewRecorder()
+
+	out := new(VolumeScheduleRequest)
+	err := PostVolumeScheduleRequestDecoder(w, r, out)
+	return out, w, err
+}
+
+func TestVolumeScheduleDecoder(t *testing.T) {
+	out, _, err := decodeVolumeSchedule(`{"interval": "24h"}`)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+	if out.Interval != 24*time.Hour || out.Retain != defaultBackupRetain {
+		t.Errorf("schedule not decoded correctly, got %v", out)
+	}
+
+	for _, body := range []string{`{"interval": "5m"}`, `{"interval": "daily"}`, `{"interval": "24h", "retain": -1}`} {
+		_, w, err := decodeVolumeSchedule(body)
+		if err == nil || w.Code != http.StatusBadRequest {
+			t.Errorf("expected schedule '%s' to be rejected", body)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+const (
+	topAPIPath = "/v1.0/applications/{application}/top"
+)
+
+func TopAPIPath(application string) string {
+	return strings.Replace(topAPIPath, "{application}", application, 1)
+}
+
+type TopRequest struct {
+	Application application
+}
+
+type TopResponse struct {
+	Containers []TopContainerResponse `json:"containers"`
+	Total      TopTotalResponse       `json:"total"`
+	Disk       []TopDiskResponse      `json:"disk"`
+}
+
+type TopContainerResponse struct {
+	// One of service, ingress or dns
+	Object string `json:"object"`
+	// Empty for containers which are no service
+	Service   string `json:"service"`
+	Container string `json:"container"`
+	// 100 percent equal one fully used CPU
+	CPUPercent  float64 `json:"cpuPercent"`
+	MemoryUsage uint64  `json:"memoryUsage"`
+	MemoryLimit uint64  `json:"memoryLimit"`
+	NetworkRx   uint64  `json:"networkRx"`
+	NetworkTx   uint64  `json:"networkTx"`
+	BlockRead   uint64  `json:"blockRead"`
+	BlockWrite  uint64  `json:"blockWrite"`
+	Pids        uint64  `json:"pids"`
+}
+
+// Sum of the resource usage of all containers and the disk usage of the application.
+type TopTotalResponse struct {
+	Containers  int     `json:"containers"`
+	CPUPercent  float64 `json:"cpuPercent"`
+	MemoryUsage uint64  `json:"memoryUsage"`
+	NetworkRx   uint64  `json:"networkRx"`
+	NetworkTx   uint64  `json:"networkTx"`
+	BlockRead   uint64  `json:"blockRead"`
+	BlockWrite  uint64  `json:"blockWrite"`
+	Pids        uint64  `json:"pids"`
+	DiskImages  int64   `json:"diskImages"`
+	DiskVolumes int64   `json:"diskVolumes"`
+}
+
+type TopDiskResponse struct {
+	// Either image or volume
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+func GetTopRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *TopRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	return nil
+}
+
+func toTopResponse(stats []runtime.ContainerStats, disk []runtime.DiskUsage) TopResponse {
+	response := TopResponse{
+		Containers: make([]TopContainerResponse, 0, len(stats)),
+		Disk:       make([]TopDiskResponse, 0, len(disk)),
+	}
+	for _, s := range stats {
+		response.Containers = append(response.Containers, TopContainerResponse{
+			Object:      s.Object,
+			Service:     s.Service,
+			Container:   s.Container,
+			CPUPercent:  s.CPUPercent,
+			MemoryUsage: s.MemoryUsage,
+			MemoryLimit: s.MemoryLimit,
+			NetworkRx:   s.NetworkRx,
+			NetworkTx:   s.NetworkTx,
+			BlockRead:   s.BlockRead,
+			BlockWrite:  s.BlockWrite,
+			Pids:        s.Pids,
+		})
+
+		response.Total.Containers++
+		response.Total.CPUPercent += s.CPUPercent
+		response.Total.MemoryUsage += s.MemoryUsage
+		response.Total.NetworkRx += s.NetworkRx
+		response.Total.NetworkTx += s.NetworkTx
+		response.Total.BlockRead += s.BlockRead
+		response.Total.BlockWrite += s.BlockWrite
+		response.Total.Pids += s.Pids
+	}
+	for _, d := range disk {
+		response.Disk = append(response.Disk, TopDiskResponse{Type: d.Type, Name: d.Name, Size: d.Size})
+
+		switch d.Type {
+		case "image":
+			response.Total.DiskImages += d.Size
+		case "volume":
+			response.Total.DiskVolumes += d.Size
+		}
+	}
+
+	slices.SortFunc(response.Containers, func(a, b TopContainerResponse) int {
+		if c := strings.Compare(a.Object, b.Object); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Service, b.Service); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Container, b.Container)
+	})
+	slices.SortFunc(response.Disk, func(a, b TopDiskResponse) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return response
+}
+
+// Returns the resource usage of all containers of the enabled application, which are its services,
+// ingress and DNS, together with the disk usage of its images and volumes.
+func (self *ZeusController) GetTop(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *TopRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+	if !state.Metadata.Enabled {
+		replyBadRequest(w, "Application is not enabled")
+		return
+	}
+
+	stats, err := runtime.ApplicationStats(r.Context(), string(command.Application))
+	if err != nil {
+		replyBadRequest(w, "Failed to read the stats of the containers: %v", err)
+		return
+	}
+	disk, err := runtime.ApplicationDiskUsage(r.Context(), string(command.Application))
+	if err != nil {
+		replyBadRequest(w, "Failed to read the disk usage: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(toTopResponse(stats, disk))
+	assert.ErrNil(err)
+}
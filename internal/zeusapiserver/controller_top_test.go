@@ -0,0 +1,43 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/runtime"
+)
+
+func TestTopResponseTotals(t *testing.T) {
+	response := toTopResponse(
+		[]runtime.ContainerStats{
+			{Object: "service", Service: "rickroll", Container: "poseidon-2", CPUPercent: 12.5, MemoryUsage: 100, NetworkRx: 1, Pids: 4},
+			{Object: "dns", Container: "poseidon-dns", CPUPercent: 0.5, MemoryUsage: 20, NetworkRx: 2, Pids: 1},
+		},
+		[]runtime.DiskUsage{
+			{Type: "volume", Name: "data", Size: 64},
+			{Type: "image", Name: "rickroll:v1", Size: 1_000},
+			{Type: "image", Name: "coredns:v1", Size: 500},
+		},
+	)
+
+	if len(response.Containers) != 2 || response.Containers[0].Object != "dns" {
+		t.Errorf("expected containers sorted by object, got %v", response.Containers)
+	}
+	expected := TopTotalResponse{
+		Containers:  2,
+		CPUPercent:  13,
+		MemoryUsage: 120,
+		NetworkRx:   3,
+		Pids:        5,
+		DiskImages:  1_500,
+		DiskVolumes: 64,
+	}
+	if response.Total != expected {
+		t.Errorf("expected totals %v, got %v", expected, response.Total)
+	}
+	if response.Disk[0].Name != "coredns:v1" || response.Disk[2].Type != "volume" {
+		t.Errorf("expected disk usage sorted by type and name, got %v", response.Disk)
+	}
+}
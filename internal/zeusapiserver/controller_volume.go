@@ -0,0 +1,204 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+var (
+	ErrBadRequestVolume = errors.New("bad request: volume")
+
+	volumeNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+const (
+	volumeInspectAllAPIPath = "/v1.0/applications/{application}/volumes"
+	volumeDeleteAPIPath     = "/v1.0/applications/{application}/volumes/{volume}"
+)
+
+func VolumeInspectAllAPIPath(application string) string {
+	return strings.Replace(volumeInspectAllAPIPath, "{application}", application, 1)
+}
+
+func VolumeDeleteAPIPath(application string, volume string) string {
+	path := strings.Replace(volumeDeleteAPIPath, "{application}", application, 1)
+	return strings.Replace(path, "{volume}", volume, 1)
+}
+
+func decodeVolumeName(volume string, w http.ResponseWriter) error {
+	if !volumeNamePattern.MatchString(volume) {
+		replyBadRequest(w, "Volume name must consist of lowercase letters, digits and '-', at most 63 chars")
+		return ErrBadRequestVolume
+	}
+
+	return nil
+}
+
+type VolumeInspectAllRequest struct {
+	Application application
+}
+
+type VolumeDeleteRequest struct {
+	Application application
+	Volume      string
+}
+
+type VolumeInspectAllResponse struct {
+	Volumes []VolumeInspectResponse `json:"volumes"`
+}
+
+type VolumeInspectResponse struct {
+	Name string `json:"name"`
+	// Volumes are created when the first service which mounts them starts
+	Created   bool     `json:"created"`
+	CreatedAt string   `json:"createdAt"`
+	Services  []string `json:"services"`
+}
+
+func GetVolumeInspectAllRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *VolumeInspectAllRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	return nil
+}
+
+func DeleteVolumeRequestDecoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	out *VolumeDeleteRequest,
+) error {
+	a := r.PathValue("application")
+	if err := decodeApplicationName(a, w); err != nil {
+		return err
+	}
+	out.Application = application(a)
+
+	v := r.PathValue("volume")
+	if err := decodeVolumeName(v, w); err != nil {
+		return err
+	}
+	out.Volume = v
+
+	return nil
+}
+
+func volumeServices(state *record.ApplicationRecord, volume string) []string {
+	services := make([]string, 0)
+	for _, service := range state.Service.VolumeUsers(volume) {
+		services = append(services, string(service))
+	}
+
+	return services
+}
+
+func (self *ZeusController) GetVolumeInspectAll(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *VolumeInspectAllRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	volumes, err := runtime.SelectVolumes(r.Context(), string(command.Application))
+	if err != nil {
+		replyBadRequest(w, "Failed to list volumes: %v", err)
+		return
+	}
+
+	response := VolumeInspectAllResponse{
+		Volumes: make([]VolumeInspectResponse, 0),
+	}
+	created := make(map[string]bool)
+	for _, v := range volumes {
+		created[v.Name] = true
+		response.Volumes = append(response.Volumes, VolumeInspectResponse{
+			Name:      v.Name,
+			Created:   true,
+			CreatedAt: v.CreatedAt,
+			Services:  volumeServices(state, v.Name),
+		})
+	}
+	for _, spec := range state.Service.Services {
+		for _, v := range spec.Volumes() {
+			if created[v.Name] {
+				continue
+			}
+			created[v.Name] = true
+			response.Volumes = append(response.Volumes, VolumeInspectResponse{
+				Name:      v.Name,
+				CreatedAt: "-",
+				Services:  volumeServices(state, v.Name),
+			})
+		}
+	}
+	slices.SortFunc(response.Volumes, func(a, b VolumeInspectResponse) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	assert.ErrNil(err)
+}
+
+// Removes the volume and its data. Volumes which are mounted by a service or job cannot be deleted.
+func (self *ZeusController) DeleteVolume(
+	w http.ResponseWriter,
+	r *http.Request,
+	command *VolumeDeleteRequest,
+) {
+	state, err := self.records.get(command.Application)
+	if err != nil {
+		replyBadRequest(w, "Application does not exist")
+		return
+	}
+
+	if services := volumeServices(state, command.Volume); len(services) != 0 {
+		replyBadRequest(w, "Volume is mounted by the services %s", strings.Join(services, ", "))
+		return
+	}
+	for _, job := range state.Jobs {
+		if slices.ContainsFunc(job.Volumes, func(v record.ServiceVolume) bool { return v.Name == command.Volume }) {
+			replyBadRequest(w, "Volume is mounted by the job %s", job.Name)
+			return
+		}
+	}
+
+	volumes, err := runtime.SelectVolumes(r.Context(), string(command.Application))
+	if err != nil {
+		replyBadRequest(w, "Failed to list volumes: %v", err)
+		return
+	}
+	exists := slices.ContainsFunc(volumes, func(v runtime.Volume) bool { return v.Name == command.Volume })
+	if !exists {
+		replyBadRequest(w, "Volume does not exist")
+		return
+	}
+
+	if err := runtime.RemoveVolume(r.Context(), string(command.Application), command.Volume); err != nil {
+		replyBadRequest(w, "Failed to remove volume: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Protocol to which the connection of an exec request is upgraded.
+//
+// Both sides exchange frames on the upgraded connection. A frame consists of a header of one byte type
+// and a four byte big endian length, followed by the payload.
+const ExecUpgradeProtocol = "zeus-exec"
+
+const (
+	// client: input of the command
+	ExecFrameStdin byte = iota + 1
+	// client: stdin of the command is closed
+	ExecFrameCloseStdin
+	// client: size of the TTY, height and width as two byte big endian numbers
+	ExecFrameResize
+	// server: stdout of the command, or all output if a TTY is allocated
+	ExecFrameStdout
+	// server: stderr of the command
+	ExecFrameStderr
+	// server: exit code of the command as four byte big endian number, the last frame of the session
+	ExecFrameExit
+	// server: the session failed, the payload is the message, the last frame of the session
+	ExecFrameError
+)
+
+const (
+	execFrameHeaderSize = 5
+	// Upper limit of the payload of a frame, larger writes are split
+	execFrameMaxPayload = 32 * 1024
+)
+
+var ErrExecFrameTooLarge = errors.New("exec frame exceeds the maximum payload")
+
+// Writes frames of one type. Multiple writers may share the underlying writer.
+type ExecFrameWriter struct {
+	mu  *sync.Mutex
+	w   io.Writer
+	typ byte
+}
+
+func NewExecFrameWriters(w io.Writer, types ...byte) []*ExecFrameWriter {
+	mu := new(sync.Mutex)
+	writers := make([]*ExecFrameWriter, 0, len(types))
+	for _, typ := range types {
+		writers = append(writers, &ExecFrameWriter{mu: mu, w: w, typ: typ})
+	}
+
+	return writers
+}
+
+func (self *ExecFrameWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), execFrameMaxPayload)]
+		if err := self.WriteFrame(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+
+	return written, nil
+}
+
+func (self *ExecFrameWriter) WriteFrame(payload []byte) error {
+	if len(payload) > execFrameMaxPayload {
+		return ErrExecFrameTooLarge
+	}
+
+	frame := make([]byte, execFrameHeaderSize+len(payload))
+	frame[0] = self.typ
+	binary.BigEndian.PutUint32(frame[1:execFrameHeaderSize], uint32(len(payload)))
+	copy(frame[execFrameHeaderSize:], payload)
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	_, err := self.w.Write(frame)
+	return err
+}
+
+func ReadExecFrame(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, execFrameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > execFrameMaxPayload {
+		return 0, nil, fmt.Errorf("%w: %d bytes", ErrExecFrameTooLarge, size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], payload, nil
+}
+
+func EncodeExecResize(height uint, width uint) []byte {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint16(payload[0:2], uint16(height))
+	binary.BigEndian.PutUint16(payload[2:4], uint16(width))
+	return payload
+}
+
+func DecodeExecResize(payload []byte) (height uint, width uint, ok bool) {
+	if len(payload) != 4 {
+		return 0, 0, false
+	}
+
+	return uint(binary.BigEndian.Uint16(payload[0:2])), uint(binary.BigEndian.Uint16(payload[2:4])), true
+}
+
+func EncodeExecExit(exitCode int) []byte {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(int32(exitCode)))
+	return payload
+}
+
+func DecodeExecExit(payload []byte) (int, bool) {
+	if len(payload) != 4 {
+		return 0, false
+	}
+
+	return int(int32(binary.BigEndian.Uint32(payload))), true
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+)
+
+func TestExecFramesRoundTrip(t *testing.T) {
+	var conn bytes.Buffer
+	writers := NewExecFrameWriters(&conn, ExecFrameStdout, ExecFrameResize, ExecFrameExit)
+
+	large := bytes.Repeat([]byte("x"), execFrameMaxPayload+1)
+	_, err := writers[0].Write(large)
+	assert.ErrNil(err)
+	assert.ErrNil(writers[1].WriteFrame(EncodeExecResize(24, 80)))
+	assert.ErrNil(writers[2].WriteFrame(EncodeExecExit(-1)))
+
+	var stdout []byte
+	for range 2 {
+		typ, payload, err := ReadExecFrame(&conn)
+		assert.ErrNil(err)
+		if typ != ExecFrameStdout {
+			t.Fatalf("expected stdout frame, got %d", typ)
+		}
+		stdout = append(stdout, payload...)
+	}
+	if !bytes.Equal(stdout, large) {
+		t.Errorf("expected large write to be split into frames")
+	}
+
+	typ, payload, err := ReadExecFrame(&conn)
+	assert.ErrNil(err)
+	if height, width, ok := DecodeExecResize(payload); typ != ExecFrameResize || !ok || height != 24 || width != 80 {
+		t.Errorf("expected resize to 24x80, got %dx%d", height, width)
+	}
+
+	typ, payload, err = ReadExecFrame(&conn)
+	assert.ErrNil(err)
+	if exitCode, ok := DecodeExecExit(payload); typ != ExecFrameExit || !ok || exitCode != -1 {
+		t.Errorf("expected exit code -1, got %d", exitCode)
+	}
+}
+
+func TestServiceExecDecoder(t *testing.T) {
+	decode := func(query ExecQuery, upgrade string) (*ServiceExecRequest, *httptest.ResponseRecorder, error) {
+		r := httptest.NewRequest("POST", ServiceExecAPIPath("poseidon", "rickroll", query), nil)
+		r.SetPathValue("application", "poseidon")
+		r.SetPathValue("service", "rickroll")
+		r.Header.Set("Upgrade", upgrade)
+		w := httptest.NewRecorder()
+
+		out := new(ServiceExecRequest)
+		err := PostServiceExecRequestDecoder(w, r, out)
+		return out, w, err
+	}
+
+	out, _, err := decode(ExecQuery{Cmd: []string{"sh", "-c", "echo 1"}, Tty: true, Stdin: true, Height: 24, Width: 80}, ExecUpgradeProtocol)
+	if err != nil {
+		t.Fatalf("expected valid request, got %q", err)
+	}
+	options := out.Options
+	if len(options.Cmd) != 3 || options.Cmd[2] != "echo 1" || !options.Tty || !options.Stdin || options.Height != 24 {
+		t.Errorf("options not decoded correctly, got '%v'", options)
+	}
+
+	if _, w, err := decode(ExecQuery{}, ExecUpgradeProtocol); err == nil || w.Code != http.StatusBadRequest {
+		t.Errorf("expected request without command to be rejected")
+	}
+	if _, w, err := decode(ExecQuery{Cmd: []string{"sh"}}, ""); err == nil || w.Code != http.StatusBadRequest {
+		t.Errorf("expected request without upgrade to be rejected")
+	}
+}
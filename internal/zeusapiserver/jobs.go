@@ -0,0 +1,318 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/runtime"
+	"github.com/raphaeldichler/zeus/internal/util/cron"
+	log "github.com/raphaeldichler/zeus/internal/util/logger"
+)
+
+const (
+	// Interval in which the schedules of the jobs are checked, a run starts at most this late
+	jobScheduleInterval = time.Second * 10
+	jobRunIDFormat      = "20060102T150405Z"
+)
+
+var (
+	ErrJobNotFound = errors.New("job does not exist")
+	// The concurrency policy of the job forbids a second run
+	ErrJobRunning = errors.New("job is already running")
+	// Jobs run on the application network, which only exists while the application is enabled
+	ErrApplicationNotEnabled = errors.New("application is not enabled")
+)
+
+// Starts the runs of the jobs of the enabled application, either on their schedule or when triggered,
+// and records their outcome.
+type jobs struct {
+	records *RecordCollection
+	logger  *log.Logger
+	// Cancelled once the daemon stops, running containers are left running and resumed on the next start
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Serializes the start of runs, the concurrency policy is checked against the recorded runs
+	mu sync.Mutex
+	// Runs whose containers are awaited
+	wg sync.WaitGroup
+}
+
+func newJobs(
+	records *RecordCollection,
+	logger *log.Logger,
+) *jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	self := &jobs{
+		records: records,
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	self.resume()
+	go self.worker()
+
+	return self
+}
+
+// Stops scheduling and awaiting runs, afterwards the records are no longer accessed.
+func (self *jobs) close() {
+	self.cancel()
+	self.wg.Wait()
+}
+
+// Returns an ID for a run of the job started now, which is unique among the runs of the job.
+func nextJobRunID(state *record.ApplicationRecord, job string, now time.Time) string {
+	id := now.UTC().Format(jobRunIDFormat)
+	for n := 2; state.JobRun(job, id) != nil; n++ {
+		id = fmt.Sprintf("%s-%d", now.UTC().Format(jobRunIDFormat), n)
+	}
+
+	return id
+}
+
+// Starts a run of the job according to its concurrency policy and returns the run. The container is
+// awaited in the background, its outcome is recorded once it exited.
+func (self *jobs) run(ctx context.Context, app application, name string, scheduled bool) (record.JobRun, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var (
+		job      record.JobSpec
+		run      record.JobRun
+		replaced []record.JobRun
+	)
+	now := time.Now()
+	err := self.records.tx(app, func(state *record.ApplicationRecord) error {
+		if !state.Metadata.Enabled {
+			return ErrApplicationNotEnabled
+		}
+		spec := state.Job(name)
+		if spec == nil {
+			return ErrJobNotFound
+		}
+		job = *spec
+
+		run = record.JobRun{
+			ID:        nextJobRunID(state, name, now),
+			Job:       name,
+			Scheduled: scheduled,
+			State:     record.JobRunRunning,
+			StartedAt: now,
+		}
+		for idx := range state.JobRuns {
+			other := &state.JobRuns[idx]
+			if other.Job != name || other.State != record.JobRunRunning {
+				continue
+			}
+
+			switch job.ConcurrencyPolicy() {
+			case record.ConcurrencyForbid:
+				return ErrJobRunning
+			case record.ConcurrencyReplace:
+				other.Reason = fmt.Sprintf("Replaced by run %s", run.ID)
+				replaced = append(replaced, *other)
+			}
+		}
+
+		state.AddJobRun(run)
+		return nil
+	})
+	if err != nil {
+		return record.JobRun{}, err
+	}
+
+	// the replaced runs are recorded as failed once their containers exited
+	for _, other := range replaced {
+		self.logger.Info("Stop run '%s' of job '%s' of application '%s', it is replaced", other.ID, name, app)
+		container, err := runtime.FindJobRun(ctx, string(app), name, other.ID)
+		if err == nil {
+			err = container.Stop(ctx)
+		}
+		if err != nil && !errors.Is(err, runtime.ErrContainerNotFound) {
+			self.logger.Error("Failed to stop run '%s' of job '%s' of application '%s': %v", other.ID, name, app, err)
+		}
+	}
+
+	state, err := self.records.get(app)
+	if err != nil {
+		return record.JobRun{}, err
+	}
+	container, err := runtime.StartJob(ctx, state, &job, run.ID)
+	if err != nil {
+		self.finish(app, name, run.ID, -1, fmt.Sprintf("Failed to start: %v", err))
+		return record.JobRun{}, err
+	}
+	self.logger.Info("Started run '%s' of job '%s' of application '%s'", run.ID, name, app)
+
+	self.await(app, job, run, container)
+	return run, nil
+}
+
+// Awaits the container of the run in the background and records its outcome.
+func (self *jobs) await(app application, job record.JobSpec, run record.JobRun, container *runtime.Container) {
+	timeout := job.Timeout
+	if timeout > 0 {
+		// a resumed run keeps the deadline of its start
+		timeout = max(time.Until(run.StartedAt.Add(timeout)), time.Nanosecond)
+	}
+
+	self.wg.Add(1)
+	go func() {
+		defer self.wg.Done()
+
+		exitCode, timedOut, err := container.WaitOrStop(self.ctx, timeout)
+		if self.ctx.Err() != nil {
+			return
+		}
+
+		reason := ""
+		switch {
+		case err != nil:
+			exitCode = -1
+			reason = fmt.Sprintf("Failed to await the container: %v", err)
+		case timedOut:
+			reason = fmt.Sprintf("Timed out after %s", job.Timeout)
+		}
+		self.finish(app, run.Job, run.ID, exitCode, reason)
+	}()
+}
+
+// Records the outcome of the run and removes the runs which exceed the history of the job. A run
+// succeeded if it exited with 0 and did not fail otherwise, e.g. because it was replaced.
+func (self *jobs) finish(app application, job string, id string, exitCode int, reason string) {
+	var expired []record.JobRun
+	err := self.records.tx(app, func(state *record.ApplicationRecord) error {
+		run := state.JobRun(job, id)
+		if run == nil {
+			return nil
+		}
+
+		run.ExitCode = exitCode
+		run.FinishedAt = time.Now()
+		if reason != "" {
+			run.Reason = reason
+		}
+		run.State = record.JobRunSucceeded
+		if exitCode != 0 || run.Reason != "" {
+			run.State = record.JobRunFailed
+		}
+		self.logger.Info("Run '%s' of job '%s' of application '%s' %s with exit code %d", id, job, app, run.State, exitCode)
+
+		expired = state.ExpiredJobRuns(job)
+		return nil
+	})
+	if err != nil {
+		// the application was deleted in the meantime together with its containers
+		return
+	}
+
+	self.prune(app, expired)
+}
+
+// Removes the runs and their containers.
+func (self *jobs) prune(app application, runs []record.JobRun) {
+	for _, run := range runs {
+		if err := runtime.RemoveJobRun(self.ctx, string(app), run.Job, run.ID); err != nil {
+			self.logger.Error("Failed to remove run '%s' of job '%s' of application '%s': %v", run.ID, run.Job, app, err)
+			continue
+		}
+
+		self.records.tx(app, func(state *record.ApplicationRecord) error {
+			state.RemoveJobRun(run.Job, run.ID)
+			return nil
+		})
+	}
+}
+
+// Awaits the runs which were running when the daemon stopped. A run whose container is gone, e.g.
+// because another application was enabled in the meantime, is recorded as failed.
+func (self *jobs) resume() {
+	for _, state := range self.records.all() {
+		app := application(state.Metadata.Application)
+		for _, run := range state.JobRuns {
+			if run.State != record.JobRunRunning {
+				continue
+			}
+
+			container, err := runtime.FindJobRun(self.ctx, string(app), run.Job, run.ID)
+			if err != nil {
+				self.finish(app, run.Job, run.ID, -1, "Container of the run is gone")
+				continue
+			}
+
+			job := record.JobSpec{Name: run.Job}
+			if spec := state.Job(run.Job); spec != nil {
+				job = *spec
+			}
+			self.await(app, job, run, container)
+		}
+	}
+}
+
+// Checks the schedules of the jobs every interval and starts the runs which are due.
+func (self *jobs) worker() {
+	ticker := time.NewTicker(jobScheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-self.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		self.runSchedules(time.Now())
+	}
+}
+
+// Starts the runs of the jobs of the enabled application which are due. Runs which were missed while
+// the daemon was stopped or the application was disabled are caught up by a single run.
+func (self *jobs) runSchedules(now time.Time) {
+	state := self.records.getEnabledApplication()
+	if state == nil {
+		return
+	}
+
+	app := application(state.Metadata.Application)
+	for _, job := range state.Jobs {
+		if job.Schedule == "" {
+			continue
+		}
+		schedule, err := cron.Parse(job.Schedule)
+		if err != nil {
+			self.logger.Error("Invalid schedule of job '%s' of application '%s': %v", job.Name, app, err)
+			continue
+		}
+		if next := schedule.Next(job.LastScheduled); next.IsZero() || next.After(now) {
+			continue
+		}
+
+		err = self.records.tx(app, func(state *record.ApplicationRecord) error {
+			if spec := state.Job(job.Name); spec != nil {
+				spec.LastScheduled = now
+			}
+			return nil
+		})
+		if err != nil {
+			continue
+		}
+
+		_, err = self.run(self.ctx, app, job.Name, true)
+		if errors.Is(err, ErrJobRunning) {
+			self.logger.Info("Skip run of job '%s' of application '%s', the previous run is still running", job.Name, app)
+			continue
+		}
+		if err != nil {
+			self.logger.Error("Failed to run job '%s' of application '%s': %v", job.Name, app, err)
+		}
+	}
+}
@@ -5,6 +5,10 @@ package zeusapiserver
 
 import (
 	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
 
 	"github.com/raphaeldichler/zeus/internal/dnscontroller"
 	"github.com/raphaeldichler/zeus/internal/ingress"
@@ -12,14 +16,16 @@ import (
 	"github.com/raphaeldichler/zeus/internal/runtime"
 	"github.com/raphaeldichler/zeus/internal/util/assert"
 	log "github.com/raphaeldichler/zeus/internal/util/logger"
+	"github.com/raphaeldichler/zeus/internal/util/socket"
 )
 
 type (
 	// setup is the function which is used to setup the application state before syncronization
 	setup func() error
 
-	// service is the function which is used to syncronize the application state
-	service func(record *record.ApplicationRecord)
+	// service is the function which is used to syncronize the application state, it stops once
+	// the context is done
+	service func(ctx context.Context, record *record.ApplicationRecord)
 )
 
 // EnviromentManager is responsible for setting up the enviroment on the host system.
@@ -29,23 +35,69 @@ type EnviromentManager interface {
 	Setup() error
 }
 
+// Sets the socket file up at the path of the host which is mounted into the containers, see runtime.HostPath.
+type hostEnvironmentManager struct {
+	socket.FileEnvironmentManager
+}
+
+func (self hostEnvironmentManager) Setup() error {
+	return self.Translate(runtime.HostPath).Setup()
+}
+
 var (
 	environmentManagers = []EnviromentManager{
-		dnscontroller.SocketFileEnvironmentManager,
+		hostEnvironmentManager{dnscontroller.SocketFileEnvironmentManager},
 	}
 	services []service = []service{
+		runtime.Sync,
 		ingress.Sync,
 	}
+	// Services which run only if all services before completed without errors
+	finalizers []service = []service{
+		// the replaced containers of rollouts are stopped once the ingress switched to the new ones
+		runtime.Drain,
+	}
 	setups []setup = []setup{
 		ingress.Setup,
 	}
 )
 
+const (
+	// Interval in which the application state is reconciled, even if no change was applied
+	resyncInterval = time.Minute * 5
+	// Fraction of the interval which is randomly added or subtracted from the next resync
+	resyncJitter = 0.1
+	// Delay until the first retry after a failed run, doubled for every consecutive failure
+	backoffBase = time.Second * 5
+	// Upper limit of the delay between retries of failed runs
+	backoffMax = resyncInterval
+)
+
+var errOrchestrationIncomplete = errors.New("orchestration completed with errors")
+
+// Returns the delay until the next run, given the number of consecutive failed runs.
+func nextOrchestration(failures int) time.Duration {
+	delay := resyncInterval
+	if failures > 0 {
+		delay = backoffBase << min(failures-1, 16)
+		delay = min(delay, backoffMax)
+	}
+
+	jitter := time.Duration(float64(delay) * resyncJitter * (2*rand.Float64() - 1))
+	return delay + jitter
+}
+
 type orchestrator struct {
 	records *RecordCollection
 	signal  chan struct{}
 	cancel  context.CancelFunc
-	logger  *log.Logger
+	// Closed once the worker stopped
+	done   chan struct{}
+	logger *log.Logger
+
+	mu sync.Mutex
+	// Drifts which were detected but not yet repaired by an orchestration
+	drifts []runtime.Drift
 }
 
 func newOrchestrator(
@@ -56,17 +108,23 @@ func newOrchestrator(
 
 	o := &orchestrator{
 		records: records,
-		signal:  make(chan struct{}),
-		cancel:  cancel,
-		logger:  logger,
+		// a single buffered slot coalesces all pings which arrive while a run is in progress
+		signal: make(chan struct{}, 1),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		logger: logger,
 	}
 	go o.worker(ctx)
+	go runtime.WatchDrift(ctx, o.drift)
 
 	return o
 }
 
+// Cancels the orchestration which is in progress and waits until it stopped, afterwards the
+// records are no longer accessed by the orchestrator.
 func (o *orchestrator) close() {
 	o.cancel()
+	<-o.done
 }
 
 func (o *orchestrator) ping() {
@@ -76,95 +134,219 @@ func (o *orchestrator) ping() {
 	}
 }
 
+// Remembers the drift and triggers an orchestration to repair it.
+func (o *orchestrator) drift(d runtime.Drift) {
+	o.logger.Info("Detected drift: %s '%s' of application '%s' received '%s'", d.Object, d.Name, d.Application, d.Action)
+
+	o.mu.Lock()
+	o.drifts = append(o.drifts, d)
+	o.mu.Unlock()
+
+	o.ping()
+}
+
+// Takes all drifts which were detected so far.
+func (o *orchestrator) takeDrifts() []runtime.Drift {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	drifts := o.drifts
+	o.drifts = nil
+	return drifts
+}
+
+// Hands drifts back which could not be repaired, they are recorded by a later run.
+func (o *orchestrator) returnDrifts(drifts []runtime.Drift) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.drifts = append(drifts, o.drifts...)
+}
+
+// Records the drifts of the application as repaired. Multiple events of the same object,
+// e.g. a container which died and got removed, are recorded once.
+func recordRepairs(state *record.ApplicationRecord, drifts []runtime.Drift) {
+	now := time.Now()
+	seen := make(map[string]struct{})
+	for _, d := range drifts {
+		if d.Application != state.Metadata.Application {
+			continue
+		}
+
+		key := d.Object + "/" + d.Name
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		state.AddRepair(record.RepairRecord{
+			Object:     d.Object,
+			Name:       d.Name,
+			Action:     d.Action,
+			DetectedAt: d.Time,
+			RepairedAt: now,
+		})
+	}
+}
+
+// Runs the orchestration once on startup, the host may have drifted while the daemon was down.
+// Afterwards it runs on every ping and periodically every resync interval. Failed runs are
+// retried with an exponential backoff.
 func (o *orchestrator) worker(ctx context.Context) {
+	defer close(o.done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	failures := 0
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-o.signal:
-			o.orchestrate()
+		case <-timer.C:
+		}
+
+		wakeAt, err := o.orchestrate(ctx)
+		if ctx.Err() != nil {
+			o.logger.Info("Orchestration was cancelled")
+			return
 		}
+		if err != nil {
+			failures++
+			o.logger.Error("Orchestration failed %d time(s) in a row: %v", failures, err)
+		} else {
+			failures = 0
+		}
+
+		delay := nextOrchestration(failures)
+		// crashed services are restarted as soon as their backoff passed, rollouts are drained as soon as
+		// their drain period passed
+		if !wakeAt.IsZero() {
+			delay = min(delay, max(time.Until(wakeAt), 0))
+		}
+		o.logger.Info("Next orchestration in %s", delay)
+		timer.Reset(delay)
 	}
 }
 
-func (o *orchestrator) orchestrate() {
+// Orchestrates the enabled application. Returns the time at which the next crashed service of the
+// application is restarted or the next rollout is drained, or the zero time if nothing waits for it.
+// A cancelled run stops early and does not store its incomplete outcome.
+func (o *orchestrator) orchestrate(ctx context.Context) (wakeAt time.Time, err error) {
 	o.logger.Info("Orchestration was invoked")
 
-	var failed = false
+	drifts := o.takeDrifts()
+	defer func() {
+		if err != nil {
+			o.returnDrifts(drifts)
+		}
+	}()
+
 	for _, environmentManager := range environmentManagers {
 		if err := environmentManager.Setup(); err != nil {
-			o.logger.Info("")
-			failed = true
+			o.logger.Error("Failed to setup environment: %v", err)
+			return time.Time{}, err
 		}
 	}
-	if failed {
-		o.logger.Info("")
-		return
-	}
 
 	record := o.records.getEnabledApplication()
 	if record == nil {
 		o.logger.Info("Filter enabled applications: no record found")
-		return
+		return time.Time{}, nil
 	}
 
-	err := o.disableNonApplicationContainer(record.Metadata.Application)
+	err = o.disableNonApplicationContainer(ctx, record.Metadata.Application)
 	if err != nil {
-		return
+		o.logger.Error("Failed to disable non application containers: %v", err)
+		return time.Time{}, err
 	}
 
 	for _, setup := range setups {
 		if err := setup(); err != nil {
 			o.logger.Error("Failed to setup application: %v", err)
-			return
+			return time.Time{}, err
 		}
 	}
 
-	nw, err := runtime.TrySelectApplicationNetwork(record.Metadata.Application)
+	nw, err := runtime.TrySelectApplicationNetwork(ctx, record.Metadata.Application)
 	if err != nil {
 		o.logger.Error("Failed to select application network: %v", err)
-		return
+		return time.Time{}, err
 	}
 	if nw == nil {
 		o.logger.Info("Start orchestration: no network found. Create new network")
-		nw, err := runtime.CreateNewNetwork(record.Metadata.Application)
+		// a recreated network keeps its subnets, the services keep their addresses
+		options := []runtime.NetworkOption{runtime.WithPreviousSubnet(record.Network.Subnet)}
+		if record.Network.IPv6 {
+			options = append(options, runtime.WithIPv6(record.Network.SubnetV6))
+		}
+		nw, err := runtime.CreateNewNetwork(ctx, record.Metadata.Application, options...)
 		if err != nil {
 			o.logger.Error("Failed to create new network: %v", err)
-			return
+			return time.Time{}, err
 		}
 		assert.NotNil(nw, "network must not be nil")
 	}
 
 	for _, svc := range services {
-		svc(record)
+		svc(ctx, record)
+	}
+	if record.NoErrors() {
+		for _, svc := range finalizers {
+			svc(ctx, record)
+		}
+	} else {
+		o.logger.Info("Skip finalizing the orchestration, a service completed with errors")
+	}
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
 	}
 
-	o.records.sync(record)
+	wakeAt, _ = record.Service.NextRestart(time.Now())
+	if drainAt, ok := runtime.NextDrain(record); ok && (wakeAt.IsZero() || drainAt.Before(wakeAt)) {
+		wakeAt = drainAt
+	}
+	if !record.NoErrors() {
+		o.store(record)
+		return wakeAt, errOrchestrationIncomplete
+	}
+
+	recordRepairs(record, drifts)
+	o.store(record)
+	return wakeAt, nil
+}
+
+// Stores the outcome of the orchestration. An application which was deleted or disabled during the
+// orchestration keeps its record, the outcome is dropped.
+func (o *orchestrator) store(state *record.ApplicationRecord) {
+	if err := o.records.sync(state); err != nil {
+		o.logger.Info("Skip storing the orchestration of application '%s': %v", state.Metadata.Application, err)
+	}
 }
 
 // Disables all containers and networks that are not part of the application
-func (o *orchestrator) disableNonApplicationContainer(application string) error {
+func (o *orchestrator) disableNonApplicationContainer(ctx context.Context, application string) error {
 	o.logger.Info("Disable non application containers")
-	containers, err := runtime.SelectAllNonApplicationContainers(application)
+	containers, err := runtime.SelectAllNonApplicationContainers(ctx, application)
 	if err != nil {
 		return err
 	}
 
 	for _, cont := range containers {
 		o.logger.Info("Disable container %s", cont)
-		if err := cont.Shutdown(); err != nil {
+		if err := cont.Shutdown(ctx); err != nil {
 			return err
 		}
 	}
 
 	o.logger.Info("Disable non application networks %s", application)
-	networks, err := runtime.SelectAllNonApplicationNetworks(application)
+	networks, err := runtime.SelectAllNonApplicationNetworks(ctx, application)
 	if err != nil {
 		return err
 	}
 	for _, nw := range networks {
 		o.logger.Info("Disable network %s", nw)
-		if err := nw.Cleanup(); err != nil {
+		if err := nw.Cleanup(ctx); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,50 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOrchestration(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		expected time.Duration
+	}{
+		{name: "resync", failures: 0, expected: resyncInterval},
+		{name: "first.failure", failures: 1, expected: backoffBase},
+		{name: "second.failure", failures: 2, expected: backoffBase * 2},
+		{name: "third.failure", failures: 3, expected: backoffBase * 4},
+		{name: "capped", failures: 20, expected: backoffMax},
+		{name: "overflow", failures: 1000, expected: backoffMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower := time.Duration(float64(tt.expected) * (1 - resyncJitter))
+			upper := time.Duration(float64(tt.expected) * (1 + resyncJitter))
+
+			for range 100 {
+				delay := nextOrchestration(tt.failures)
+				if delay < lower || delay > upper {
+					t.Fatalf("expected delay in [%s, %s], got %s", lower, upper, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestratorPingCoalesces(t *testing.T) {
+	o := &orchestrator{signal: make(chan struct{}, 1)}
+
+	o.ping()
+	o.ping()
+	o.ping()
+
+	if len(o.signal) != 1 {
+		t.Errorf("expected a single pending ping, got %d", len(o.signal))
+	}
+}
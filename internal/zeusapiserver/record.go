@@ -5,7 +5,6 @@ package zeusapiserver
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"sync"
 
@@ -15,7 +14,11 @@ import (
 	bboltErr "go.etcd.io/bbolt/errors"
 )
 
-const ZeusDataStorePath = "/var/lib/zeus/store.bbolt"
+const (
+	ZeusDataStorePath = "/var/lib/zeus/store.bbolt"
+	// Key which encrypts the secrets of the records inside the store, e.g. the passwords of registries
+	ZeusSecretKeyPath = "/var/lib/zeus/secret.key"
+)
 
 var (
 	ErrStopIteration      = errors.New("stop bbolt for-each iteration")
@@ -52,6 +55,9 @@ type RecordCollection struct {
 }
 
 func OpenAndCreateRecordCollection() (*RecordCollection, error) {
+	if err := record.LoadSecretKey(ZeusSecretKeyPath); err != nil {
+		return nil, err
+	}
 	db, err := bbolt.Open(ZeusDataStorePath, 0600, nil)
 	if err != nil {
 		return nil, err
@@ -77,7 +83,8 @@ func (self *RecordCollection) delete(app application) error {
 }
 
 // Only returns an error if the application already exists.
-func (self *RecordCollection) add(app application, deploymentType record.DeploymentType) error {
+// Creates the record of the application, its network is dual stack if ipv6 is set.
+func (self *RecordCollection) add(app application, deploymentType record.DeploymentType, ipv6 bool) error {
 	self.mu.Lock()
 	defer self.mu.Unlock()
 
@@ -88,6 +95,7 @@ func (self *RecordCollection) add(app application, deploymentType record.Deploym
 		}
 
 		appRecord := record.New(string(app), deploymentType)
+		appRecord.Network.IPv6 = ipv6
 		blob := appRecord.ToGob()
 		assert.True(len(blob) < bbolt.MaxValueSize, "blob must stay under 2GB")
 		err = b.Put(RecordKey, blob)
@@ -104,7 +112,6 @@ func (self *RecordCollection) add(app application, deploymentType record.Deploym
 
 // Only returns an error if the application does not exists.
 func (self *RecordCollection) get(app application) (*record.ApplicationRecord, error) {
-	fmt.Println(self)
 	self.mu.Lock()
 	defer self.mu.Unlock()
 
@@ -231,20 +238,27 @@ func (self *RecordCollection) all() []*record.ApplicationRecord {
 	return records
 }
 
-// Synchronizes the application state with the other application state. If the application is not enabled it will panic.
+// Synchronizes the application state with the other application state.
+//
+// Returns an ErrBucketNotFound error if the application was deleted and record.ErrEnabledChanged if it
+// was enabled or disabled since the other state was read, the other state is dropped in both cases.
 func (self *RecordCollection) sync(other *record.ApplicationRecord) error {
 	self.mu.Lock()
 	defer self.mu.Unlock()
 
 	return self.db.Update(func(tx *bbolt.Tx) error {
 		b := tx.Bucket([]byte(other.Metadata.Application))
-		assert.NotNil(b, "application must have a record entry")
+		if b == nil {
+			return bboltErr.ErrBucketNotFound
+		}
 
 		recordBytes := b.Get(RecordKey)
 		assert.True(recordBytes != nil, "application must have a record entry")
 
 		appRecord := record.FromGob(recordBytes)
-		appRecord.Sync(other)
+		if err := appRecord.Sync(other); err != nil {
+			return err
+		}
 
 		blob := appRecord.ToGob()
 		assert.True(len(blob) < bbolt.MaxValueSize, "blob must stay under 2GB")
@@ -0,0 +1,39 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusapiserver
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphaeldichler/zeus/internal/record"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"go.etcd.io/bbolt"
+	bboltErr "go.etcd.io/bbolt/errors"
+)
+
+func TestRecordCollectionSyncDropsStaleState(t *testing.T) {
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "store.bbolt"), 0600, nil)
+	assert.ErrNil(err)
+	records := &RecordCollection{db: db}
+	t.Cleanup(func() { records.cleanup() })
+	assert.ErrNil(records.add("poseidon", record.Development, false))
+	assert.ErrNil(records.enableIfNonElse("poseidon"))
+
+	// the orchestration read the enabled application, which is disabled until it completes
+	state := records.getEnabledApplication()
+	assert.ErrNil(records.tx("poseidon", func(r *record.ApplicationRecord) error {
+		r.Metadata.Enabled = false
+		return nil
+	}))
+	if err := records.sync(state); !errors.Is(err, record.ErrEnabledChanged) {
+		t.Errorf("expected the state of the disabled application to be dropped, got %v", err)
+	}
+
+	assert.ErrNil(records.delete("poseidon"))
+	if err := records.sync(state); !errors.Is(err, bboltErr.ErrBucketNotFound) {
+		t.Errorf("expected the state of the deleted application to be dropped, got %v", err)
+	}
+}
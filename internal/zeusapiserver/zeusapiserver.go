@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 
+	"github.com/raphaeldichler/zeus/internal/runtime"
 	"github.com/raphaeldichler/zeus/internal/server"
 	log "github.com/raphaeldichler/zeus/internal/util/logger"
 )
 
+// Socket of the API, rootless setups listen on the path it is mapped to, see runtime.HostPath
 const SocketPath = "/run/zeus/zeusd.sock"
 
 type ZeusController struct {
@@ -20,16 +23,26 @@ type ZeusController struct {
 	records      *RecordCollection
 	application  *ApplicationController
 	orchestrator *orchestrator
+	backups      *backups
+	jobs         *jobs
 }
 
 func New() (*ZeusController, error) {
-	if _, err := os.Stat(SocketPath); err == nil {
-		if err := os.Remove(SocketPath); err != nil {
-			return nil, err
-		}
+	config, err := loadDaemonConfig()
+	if err != nil {
+		return nil, err
+	}
+	backend, err := config.Runtime.newBackend()
+	if err != nil {
+		return nil, err
+	}
+	runtime.SetBackend(backend)
+	store, err := config.Snapshots.newStore()
+	if err != nil {
+		return nil, err
 	}
 
-	listen, err := net.Listen("unix", SocketPath)
+	listen, err := listenSocket()
 	if err != nil {
 		return nil, err
 	}
@@ -41,11 +54,15 @@ func New() (*ZeusController, error) {
 	}
 	applicationController := NewApplication(records)
 	orchestrator := newOrchestrator(records, log.New("zeusapiserver", "orchestrator"))
+	backups := newBackups(records, store, log.New("zeusapiserver", "backups"))
+	jobs := newJobs(records, log.New("zeusapiserver", "jobs"))
 
 	self := &ZeusController{
 		application:  applicationController,
 		orchestrator: orchestrator,
 		records:      records,
+		backups:      backups,
+		jobs:         jobs,
 	}
 	self.server = server.New(
 		server.WithListener(listen),
@@ -91,14 +108,173 @@ func New() (*ZeusController, error) {
 			self.PostIngressApply,
 			server.WithRequestDecoder(PostIngressApplyRequestDecoder),
 		),
+		// Services
+		server.Post(
+			serviceApplyAPIPath,
+			self.PostServiceApply,
+			server.WithRequestDecoder(PostServiceApplyRequestDecoder),
+		),
+		server.Get(
+			serviceInspectAllAPIPath,
+			self.GetServiceInspectAll,
+			server.WithRequestDecoder(GetServiceInspectAllRequestDecoder),
+		),
+		server.Get(
+			serviceInspectAPIPath,
+			self.GetServiceInspect,
+			server.WithRequestDecoder(GetServiceInspectRequestDecoder),
+		),
+		server.Delete(
+			serviceDeleteAPIPath,
+			self.DeleteService,
+			server.WithRequestDecoder(DeleteServiceRequestDecoder),
+		),
+		server.Post(
+			serviceUpdateImageAPIPath,
+			self.PostServiceUpdateImage,
+			server.WithRequestDecoder(PostServiceUpdateImageRequestDecoder),
+		),
+		server.Get(
+			serviceRolloutAPIPath,
+			self.GetServiceRollout,
+			server.WithRequestDecoder(GetServiceRolloutRequestDecoder),
+		),
+		server.Post(
+			serviceRolloutUndoAPIPath,
+			self.PostServiceRolloutUndo,
+			server.WithRequestDecoder(PostServiceRolloutUndoRequestDecoder),
+		),
+		server.Post(
+			serviceExecAPIPath,
+			self.PostServiceExec,
+			server.WithRequestDecoder(PostServiceExecRequestDecoder),
+		),
+		// Volumes
+		server.Get(
+			volumeInspectAllAPIPath,
+			self.GetVolumeInspectAll,
+			server.WithRequestDecoder(GetVolumeInspectAllRequestDecoder),
+		),
+		server.Delete(
+			volumeDeleteAPIPath,
+			self.DeleteVolume,
+			server.WithRequestDecoder(DeleteVolumeRequestDecoder),
+		),
+		// Snapshots
+		server.Post(
+			volumeBackupAPIPath,
+			self.PostVolumeBackup,
+			server.WithRequestDecoder(PostVolumeBackupRequestDecoder),
+		),
+		server.Post(
+			volumeRestoreAPIPath,
+			self.PostVolumeRestore,
+			server.WithRequestDecoder(PostVolumeRestoreRequestDecoder),
+		),
+		server.Post(
+			volumeScheduleAPIPath,
+			self.PostVolumeSchedule,
+			server.WithRequestDecoder(PostVolumeScheduleRequestDecoder),
+		),
+		server.Delete(
+			volumeScheduleAPIPath,
+			self.DeleteVolumeSchedule,
+			server.WithRequestDecoder(DeleteVolumeScheduleRequestDecoder),
+		),
+		server.Get(
+			snapshotInspectAllAPIPath,
+			self.GetSnapshotInspectAll,
+			server.WithRequestDecoder(GetSnapshotInspectAllRequestDecoder),
+		),
+		server.Delete(
+			snapshotDeleteAPIPath,
+			self.DeleteSnapshot,
+			server.WithRequestDecoder(DeleteSnapshotRequestDecoder),
+		),
+		// Registries
+		server.Post(
+			registryInspectAllAPIPath,
+			self.PostRegistryLogin,
+			server.WithRequestDecoder(PostRegistryLoginRequestDecoder),
+		),
+		server.Get(
+			registryInspectAllAPIPath,
+			self.GetRegistryInspectAll,
+			server.WithRequestDecoder(GetRegistryInspectAllRequestDecoder),
+		),
+		server.Delete(
+			registryDeleteAPIPath,
+			self.DeleteRegistry,
+			server.WithRequestDecoder(DeleteRegistryRequestDecoder),
+		),
+		server.Post(
+			imagePullAPIPath,
+			self.PostImagePull,
+			server.WithRequestDecoder(PostImagePullRequestDecoder),
+		),
+		// Logs
+		server.Get(
+			logsAPIPath,
+			self.GetLogs,
+			server.WithRequestDecoder(GetLogsRequestDecoder),
+		),
+		// Jobs
+		server.Post(
+			jobApplyAPIPath,
+			self.PostJobApply,
+			server.WithRequestDecoder(PostJobApplyRequestDecoder),
+		),
+		server.Get(
+			jobInspectAllAPIPath,
+			self.GetJobInspectAll,
+			server.WithRequestDecoder(GetJobInspectAllRequestDecoder),
+		),
+		server.Get(
+			jobInspectAPIPath,
+			self.GetJobInspect,
+			server.WithRequestDecoder(GetJobInspectRequestDecoder),
+		),
+		server.Delete(
+			jobDeleteAPIPath,
+			self.DeleteJob,
+			server.WithRequestDecoder(DeleteJobRequestDecoder),
+		),
+		server.Post(
+			jobRunAPIPath,
+			self.PostJobRun,
+			server.WithRequestDecoder(PostJobRunRequestDecoder),
+		),
+		// Top
+		server.Get(
+			topAPIPath,
+			self.GetTop,
+			server.WithRequestDecoder(GetTopRequestDecoder),
+		),
 	)
 
 	return self, nil
 }
 
+// Listens on the socket of the API, at the path of the host it is mapped to by the backend.
+func listenSocket() (net.Listener, error) {
+	socketPath := runtime.HostPath(SocketPath)
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(socketPath); err == nil {
+		if err := os.Remove(socketPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return net.Listen("unix", socketPath)
+}
+
 func (self *ZeusController) Run() error {
 	defer func() {
 		self.orchestrator.close()
+		self.backups.close()
+		self.jobs.close()
 		self.records.cleanup()
 	}()
 
@@ -34,6 +34,8 @@ type contextProvider struct {
 
 type ConfigLocalhost struct {
 	Enabled bool `yaml:"enabled"`
+	// Socket of the daemon, e.g. $XDG_RUNTIME_DIR/zeus/zeusd.sock if it runs with rootless podman
+	Socket string `yaml:"socket"`
 }
 
 type ConfigRemote struct {
@@ -43,6 +45,8 @@ type ConfigRemote struct {
 		User string `yaml:"user"`
 		Cert string `yaml:"cert"`
 	} `yaml:"ssh"`
+	// Socket of the daemon on the remote host
+	Socket string `yaml:"socket"`
 }
 
 type Config struct {
@@ -74,7 +78,7 @@ func (c *Config) remoteSSHDialer() (*http.Transport, error) {
 
 	return &http.Transport{
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return sshConn.Dial("unix", unixServerSocket)
+			return sshConn.Dial("unix", c.Remote.Socket)
 		},
 	}, nil
 }
@@ -82,7 +86,7 @@ func (c *Config) remoteSSHDialer() (*http.Transport, error) {
 func (c *Config) localUnixDialer() (*http.Transport, error) {
 	return &http.Transport{
 		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
-			return net.Dial("unix", unixServerSocket)
+			return net.Dial("unix", c.Localhost.Socket)
 		},
 	}, nil
 }
@@ -96,9 +100,11 @@ func loadConfig(path string) (*Config, error) {
 	cfg := &Config{
 		Localhost: ConfigLocalhost{
 			Enabled: false,
+			Socket:  unixServerSocket,
 		},
 		Remote: ConfigRemote{
-			Port: "22",
+			Port:   "22",
+			Socket: unixServerSocket,
 		},
 	}
 	if err := yaml.Unmarshal(data, cfg); err != nil {
@@ -131,6 +137,9 @@ func (c *Config) newClient(formatter formatter.Output) (*client, error) {
 			Transport: transporter,
 			Timeout:   httpTimeout,
 		},
+		stream: &http.Client{
+			Transport: transporter,
+		},
 		application: c.Application,
 		formatter:   formatter,
 	}, nil
@@ -140,6 +149,8 @@ type client struct {
 	http        *http.Client
 	application string
 	formatter   formatter.Output
+	// Client without timeout for responses which are streamed, e.g. followed logs
+	stream *http.Client
 }
 
 func unixURL(path string) string {
@@ -221,10 +232,10 @@ func (c *client) applicationDisabled(application string) string {
 	return ""
 }
 
-func (c *client) applicationDeleted(application string) string {
+func (c *client) applicationDeleted(application string, purge bool) string {
 	req, err := http.NewRequest(
 		"DELETE",
-		unixURL(zeusapiserver.DeleteApplicationAPIPath(application)),
+		unixURL(zeusapiserver.DeleteApplicationAPIPath(application, purge)),
 		nil,
 	)
 	assert.ErrNil(err)
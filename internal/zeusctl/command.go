@@ -112,6 +112,13 @@ func NewCommand() *Command {
 	for _, provider := range []CommandProvider{
 		ingressCommands,
 		applicationCommands,
+		serviceCommands,
+		logsCommands,
+		execCommands,
+		volumeCommands,
+		registryCommands,
+		topCommands,
+		jobCommands,
 	} {
 		provider(rootCmd, clientProvider)
 	}
@@ -22,6 +22,7 @@ zeus application inspect
 --all/-a (default )
 zeus application inspect poseidon
 zeus application delete poseiodn
+zeus application delete poseiodn --purge
 zeus application enable|disable poseiodn
 */
 
@@ -30,8 +31,10 @@ var (
 		Use:   "application",
 		Short: "Application management commands",
 	}
-	applicationName string = ""
-	applicationType string = ""
+	applicationName  string = ""
+	applicationType  string = ""
+	applicationPurge bool   = false
+	applicationIPv6  bool   = false
 )
 
 func applicationCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
@@ -70,6 +73,7 @@ func createApplication(clientProvider *contextProvider) {
 				zeusapiserver.NewCreateApplicationRequestAsJsonBody(
 					applicationName,
 					applicationType,
+					applicationIPv6,
 				),
 			)
 			assert.ErrNil(err)
@@ -93,6 +97,9 @@ func createApplication(clientProvider *contextProvider) {
 	createCmd.Flags().StringVarP(
 		&applicationType, "type", "t", "development", "Application type: development or production",
 	)
+	createCmd.Flags().BoolVar(
+		&applicationIPv6, "ipv6", false, "Create a dual stack application network with IPv6 addresses",
+	)
 
 	application.AddCommand(createCmd)
 }
@@ -137,11 +144,15 @@ func deleteApplication(clientProvider *contextProvider) {
 			assert.NotNil(client, "client must not be nil")
 
 			fmt.Println(
-				client.applicationDeleted(applicationName),
+				client.applicationDeleted(applicationName, applicationPurge),
 			)
 		},
 	}
 
+	deleteCmd.Flags().BoolVar(
+		&applicationPurge, "purge", false, "Remove the volumes of the application and their data",
+	)
+
 	application.AddCommand(deleteCmd)
 }
 
@@ -0,0 +1,155 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+/*
+zeus exec rickroll -- sh
+zeus exec rickroll -T -- cat /etc/hostname
+*/
+
+var (
+	execNoTty   bool
+	execNoStdin bool
+)
+
+func execCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	execCmd := &cobra.Command{
+		Use:   "exec [service] -- [command]",
+		Short: "Run a command inside a service container",
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			if cmd.ArgsLenAtDash() != 1 {
+				failCommand(cmd, "Separate the command from the service with '--'")
+			}
+
+			stdinFd := int(os.Stdin.Fd())
+			query := zeusapiserver.ExecQuery{
+				Cmd:   args[1:],
+				Stdin: !execNoStdin,
+				// a TTY is only useful if the input comes from a terminal
+				Tty: !execNoTty && !execNoStdin && term.IsTerminal(stdinFd),
+			}
+			if query.Tty {
+				if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+					query.Height, query.Width = uint(height), uint(width)
+				}
+			}
+
+			os.Exit(client.exec(args[0], query))
+		},
+	}
+
+	execCmd.Flags().BoolVarP(&execNoTty, "no-tty", "T", false, "Do not allocate a TTY")
+	execCmd.Flags().BoolVar(&execNoStdin, "no-stdin", false, "Do not attach stdin")
+
+	rootCmd.AddCommand(execCmd)
+}
+
+// Runs the command and proxies the terminal until it exits. Returns the exit code of the command.
+func (c *client) exec(service string, query zeusapiserver.ExecQuery) int {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.ServiceExecAPIPath(c.application, service, query)),
+		nil,
+	)
+	assert.ErrNil(err)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", zeusapiserver.ExecUpgradeProtocol)
+
+	resp, err := c.stream.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusSwitchingProtocols:
+	case http.StatusBadRequest:
+		fmt.Fprintln(os.Stderr, toError(resp))
+		return 1
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	assert.True(ok, "body of an upgraded connection is writable")
+	defer conn.Close()
+
+	if query.Tty {
+		fd := int(os.Stdin.Fd())
+		state, err := term.MakeRaw(fd)
+		failOnError(err, "Could not configure the terminal: %v", err)
+		defer term.Restore(fd, state)
+	}
+
+	writers := zeusapiserver.NewExecFrameWriters(
+		conn,
+		zeusapiserver.ExecFrameStdin,
+		zeusapiserver.ExecFrameCloseStdin,
+		zeusapiserver.ExecFrameResize,
+	)
+	stdin, closeStdin, resize := writers[0], writers[1], writers[2]
+
+	if query.Stdin {
+		go func() {
+			if _, err := io.Copy(stdin, os.Stdin); err == nil {
+				closeStdin.WriteFrame(nil)
+			}
+		}()
+	}
+
+	if query.Tty {
+		resized := make(chan os.Signal, 1)
+		signal.Notify(resized, syscall.SIGWINCH)
+		defer signal.Stop(resized)
+		go func() {
+			for range resized {
+				if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+					resize.WriteFrame(zeusapiserver.EncodeExecResize(uint(height), uint(width)))
+				}
+			}
+		}()
+	}
+
+	for {
+		typ, payload, err := zeusapiserver.ReadExecFrame(conn)
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(os.Stderr, "Error: connection closed before the command exited")
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: stream failed: %v\n", err)
+			return 1
+		}
+
+		switch typ {
+		case zeusapiserver.ExecFrameStdout:
+			os.Stdout.Write(payload)
+		case zeusapiserver.ExecFrameStderr:
+			os.Stderr.Write(payload)
+		case zeusapiserver.ExecFrameExit:
+			exitCode, ok := zeusapiserver.DecodeExecExit(payload)
+			assert.True(ok, "exit frame carries the exit code")
+			return exitCode
+		case zeusapiserver.ExecFrameError:
+			fmt.Fprintf(os.Stderr, "Error from server: %s\n", payload)
+			return 1
+		}
+	}
+}
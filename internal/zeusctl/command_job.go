@@ -0,0 +1,318 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/spf13/cobra"
+)
+
+/*
+zeus job apply -f cleanup.job.yaml
+zeus job ls
+zeus job ls cleanup
+zeus job run cleanup
+zeus job run cleanup -f
+zeus job logs cleanup
+zeus job logs cleanup 20250601T030000Z
+zeus job delete cleanup
+*/
+
+var (
+	job = &cobra.Command{
+		Use:   "job",
+		Short: "Scheduled and one-off job commands",
+	}
+	jobFilePath   string
+	jobNoPull     bool
+	jobRunFollow  bool
+	jobLogsFollow bool
+	jobLogsTail   string
+)
+
+func jobCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	applyJob(clientProvider)
+	listJobs(clientProvider)
+	deleteJob(clientProvider)
+	runJob(clientProvider)
+	logsJob(clientProvider)
+	rootCmd.AddCommand(job)
+}
+
+type JobApplyRequest struct {
+	Version                           string `json:"version" yaml:"version"`
+	zeusapiserver.JobApplyRequestBody `yaml:",inline"`
+}
+
+func applyJob(clientProvider *contextProvider) {
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply job configuration",
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.True(jobFilePath != "", "file path must not be empty")
+			content, err := os.ReadFile(jobFilePath)
+			failOnError(err, "Could not read file: %v", err)
+
+			apply := yamlToObject[JobApplyRequest](
+				io.NopCloser(bytes.NewReader(content)),
+			)
+			if apply.Version != "v1.0" {
+				failCommand(cmd, "Unsupported version: %q", apply.Version)
+			}
+
+			if !jobNoPull {
+				if msg := client.imagePull(apply.Spec.Container.Image, false); msg != "" {
+					fmt.Println(msg)
+					os.Exit(1)
+				}
+			}
+
+			fmt.Println(client.jobApply(apply))
+		},
+	}
+
+	applyCmd.Flags().StringVarP(&jobFilePath, "file", "f", "", "Path to job file")
+	applyCmd.Flags().BoolVar(&jobNoPull, "no-pull", false, "Do not show the progress of the pull, the daemon pulls a missing image while applying")
+	applyCmd.MarkFlagRequired("file")
+
+	job.AddCommand(applyCmd)
+}
+
+func listJobs(clientProvider *contextProvider) {
+	listCmd := &cobra.Command{
+		Use:   "ls [job]",
+		Short: "List jobs, or the runs of a job",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			switch len(args) {
+			case 0:
+				fmt.Println(client.jobInspectAll())
+			case 1:
+				fmt.Println(client.jobInspect(args[0]))
+			default:
+				assert.Unreachable("cover all cases of number of arguments")
+			}
+		},
+	}
+
+	job.AddCommand(listCmd)
+}
+
+func deleteJob(clientProvider *contextProvider) {
+	deleteCmd := &cobra.Command{
+		Use:   "delete [job]",
+		Short: "Delete job, running runs are finished first",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.jobDelete(args[0]))
+		},
+	}
+
+	job.AddCommand(deleteCmd)
+}
+
+func runJob(clientProvider *contextProvider) {
+	runCmd := &cobra.Command{
+		Use:   "run [job]",
+		Short: "Start a run of the job now",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			run, msg := client.jobRun(args[0])
+			if run == nil {
+				fmt.Println(msg)
+				os.Exit(1)
+			}
+			if !jobRunFollow {
+				fmt.Println(msg)
+				return
+			}
+
+			query := zeusapiserver.LogsQuery{
+				Object: zeusapiserver.LogsObjectJob,
+				Job:    args[0],
+				Run:    run.ID,
+				Follow: true,
+			}
+			if msg := client.logs(query); msg != "" {
+				fmt.Println(msg)
+				os.Exit(1)
+			}
+		},
+	}
+
+	runCmd.Flags().BoolVarP(&jobRunFollow, "follow", "f", false, "Stream the output of the run until it exits")
+
+	job.AddCommand(runCmd)
+}
+
+func logsJob(clientProvider *contextProvider) {
+	logsCmd := &cobra.Command{
+		Use:   "logs [job] [run]",
+		Short: "Print the logs of a run of the job, by default of the latest one",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			query := zeusapiserver.LogsQuery{
+				Object: zeusapiserver.LogsObjectJob,
+				Job:    args[0],
+				Follow: jobLogsFollow,
+				Tail:   jobLogsTail,
+			}
+			if len(args) == 2 {
+				query.Run = args[1]
+			}
+
+			if msg := client.logs(query); msg != "" {
+				fmt.Println(msg)
+				os.Exit(1)
+			}
+		},
+	}
+
+	logsCmd.Flags().BoolVarP(&jobLogsFollow, "follow", "f", false, "Keep streaming new output")
+	logsCmd.Flags().StringVarP(&jobLogsTail, "tail", "n", "all", "Number of lines from the end of the logs")
+
+	job.AddCommand(logsCmd)
+}
+
+func (c *client) jobApply(apply *JobApplyRequest) string {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.JobApplyAPIPath(apply.Version, c.application)),
+		objectToJson(apply.JobApplyRequestBody),
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return "Applied"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) jobInspectAll() string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.JobInspectAllAPIPath(c.application)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.JobInspectAllResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) jobInspect(job string) string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.JobInspectAPIPath(c.application, job)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.JobInspectResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) jobDelete(job string) string {
+	r, err := http.NewRequest(
+		"DELETE",
+		unixURL(zeusapiserver.JobDeleteAPIPath(c.application, job)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Deleted"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+// Starts a run of the job and returns it, or nil and the error of the server. The image is pulled if it
+// is missing, therefore the client without timeout is used.
+func (c *client) jobRun(job string) (*zeusapiserver.JobRunInspectResponse, string) {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.JobRunAPIPath(c.application, job)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.stream.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		run := toObject[zeusapiserver.JobRunInspectResponse](resp.Body)
+		return run, fmt.Sprintf("Started run %s of job %s", run.ID, job)
+	case http.StatusBadRequest:
+		return nil, toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return nil, ""
+}
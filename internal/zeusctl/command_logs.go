@@ -0,0 +1,121 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/spf13/cobra"
+)
+
+/*
+zeus logs rickroll
+zeus logs rickroll -f --since 10m --tail 100
+zeus logs rickroll -c shipper
+zeus logs --ingress
+zeus logs --dns -t
+*/
+
+var (
+	logsFollow     bool
+	logsSince      string
+	logsTail       string
+	logsTimestamps bool
+	logsContainer  string
+	logsIngress    bool
+	logsDNS        bool
+)
+
+func logsCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	logsCmd := &cobra.Command{
+		Use:   "logs [service]",
+		Short: "Print the logs of a service, the ingress or the DNS",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			query := zeusapiserver.LogsQuery{
+				Follow:     logsFollow,
+				Since:      logsSince,
+				Tail:       logsTail,
+				Timestamps: logsTimestamps,
+			}
+			switch {
+			case logsIngress && logsDNS:
+				failCommand(cmd, "Only one of --ingress and --dns can be used")
+			case (logsIngress || logsDNS) && len(args) != 0:
+				failCommand(cmd, "A service cannot be combined with --ingress or --dns")
+			case logsContainer != "" && len(args) == 0:
+				failCommand(cmd, "A container requires a service")
+			case logsIngress:
+				query.Object = zeusapiserver.LogsObjectIngress
+			case logsDNS:
+				query.Object = zeusapiserver.LogsObjectDNS
+			case len(args) == 1:
+				query.Object = zeusapiserver.LogsObjectService
+				query.Service = args[0]
+				query.Container = logsContainer
+			default:
+				failCommand(cmd, "Either a service, --ingress or --dns is required")
+			}
+
+			if msg := client.logs(query); msg != "" {
+				fmt.Println(msg)
+				os.Exit(1)
+			}
+		},
+	}
+
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep streaming new output")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only output since the timestamp (RFC 3339) or relative duration (e.g. 10m)")
+	logsCmd.Flags().StringVarP(&logsTail, "tail", "n", "all", "Number of lines from the end of the logs")
+	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "Show timestamps")
+	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "", "Print the logs of the sidecar of the service")
+	logsCmd.Flags().BoolVar(&logsIngress, "ingress", false, "Print the logs of the ingress")
+	logsCmd.Flags().BoolVar(&logsDNS, "dns", false, "Print the logs of the DNS")
+
+	rootCmd.AddCommand(logsCmd)
+}
+
+// Streams the logs to stdout and stderr until the stream ends or the command is interrupted.
+// Returns the error of the server, empty if the logs were streamed.
+func (c *client) logs(query zeusapiserver.LogsQuery) string {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	r, err := http.NewRequestWithContext(
+		ctx,
+		"GET",
+		unixURL(zeusapiserver.LogsAPIPath(c.application, query)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.stream.Do(r)
+	failOnError(err, "Request failed: %v", err)
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		_, err := stdcopy.StdCopy(os.Stdout, os.Stderr, resp.Body)
+		if ctx.Err() == nil {
+			failOnError(err, "Stream failed: %v", err)
+		}
+		return ""
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
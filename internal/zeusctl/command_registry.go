@@ -0,0 +1,259 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+/*
+zeus registry login ghcr.io -u rick
+echo $TOKEN | zeus registry login ghcr.io -u rick --password-stdin
+zeus registry ls
+zeus registry logout ghcr.io
+*/
+
+var (
+	registry = &cobra.Command{
+		Use:   "registry",
+		Short: "Registry credential management commands",
+	}
+
+	registryUsername      string
+	registryPasswordStdin bool
+)
+
+func registryCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	loginRegistry(clientProvider)
+	listRegistries(clientProvider)
+	logoutRegistry(clientProvider)
+	rootCmd.AddCommand(registry)
+}
+
+// Reads the password from stdin, without echo if stdin is a terminal.
+func readPassword(fromStdin bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !fromStdin && term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimRight(password, "\r\n"), nil
+}
+
+func loginRegistry(clientProvider *contextProvider) {
+	loginCmd := &cobra.Command{
+		Use:   "login [registry]",
+		Short: "Store the credential of a registry, private images are pulled with it",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			password, err := readPassword(registryPasswordStdin)
+			failOnError(err, "Could not read password: %v", err)
+			if password == "" {
+				failCommand(cmd, "Password must not be empty")
+			}
+
+			fmt.Println(client.registryLogin(zeusapiserver.RegistryLoginRequestBody{
+				Registry: args[0],
+				Username: registryUsername,
+				Password: password,
+			}))
+		},
+	}
+
+	loginCmd.Flags().StringVarP(&registryUsername, "username", "u", "", "Username at the registry")
+	loginCmd.Flags().BoolVar(&registryPasswordStdin, "password-stdin", false, "Read the password or token from stdin")
+	loginCmd.MarkFlagRequired("username")
+
+	registry.AddCommand(loginCmd)
+}
+
+func listRegistries(clientProvider *contextProvider) {
+	listCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List registries with a stored credential",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.registryList())
+		},
+	}
+
+	registry.AddCommand(listCmd)
+}
+
+func logoutRegistry(clientProvider *contextProvider) {
+	logoutCmd := &cobra.Command{
+		Use:   "logout [registry]",
+		Short: "Remove the credential of a registry",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.registryLogout(args[0]))
+		},
+	}
+
+	registry.AddCommand(logoutCmd)
+}
+
+func (c *client) registryLogin(body zeusapiserver.RegistryLoginRequestBody) string {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.RegistryLoginAPIPath(c.application)),
+		objectToJson(body),
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Login stored"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) registryList() string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.RegistryInspectAllAPIPath(c.application)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.RegistryInspectAllResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) registryLogout(registry string) string {
+	r, err := http.NewRequest(
+		"DELETE",
+		unixURL(zeusapiserver.RegistryDeleteAPIPath(c.application, registry)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Logged out"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+// Prints a line whenever a layer of the image changes its status. The progress of a download is
+// printed in steps of 10 percent.
+func printPullEvent(out io.Writer, image string, event zeusapiserver.ImagePullEvent, last map[string]string) {
+	if event.Layer == "" {
+		fmt.Fprintf(out, "%s: %s\n", image, event.Status)
+		return
+	}
+
+	status := event.Status
+	if event.Total > 0 {
+		status = fmt.Sprintf("%s %d%%", status, event.Current*10/event.Total*10)
+	}
+	if last[event.Layer] != status {
+		fmt.Fprintf(out, "%s: %s\n", event.Layer, status)
+		last[event.Layer] = status
+	}
+}
+
+// Pulls the image on the machine of the daemon and prints its progress to stderr until the pull completed.
+// An existing image is only pulled again if always is set. Returns the error of the server or the pull,
+// empty if the image was pulled.
+func (c *client) imagePull(image string, always bool) string {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	r, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		unixURL(zeusapiserver.ImagePullAPIPath(c.application)),
+		objectToJson(zeusapiserver.ImagePullRequestBody{Image: image, Always: always}),
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.stream.Do(r)
+	failOnError(err, "Request failed: %v", err)
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		last := make(map[string]string)
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event zeusapiserver.ImagePullEvent
+			if err := decoder.Decode(&event); err == io.EOF {
+				return ""
+			} else if err != nil {
+				return fmt.Sprintf("Pull of image %s was interrupted: %v", image, err)
+			}
+			if event.Error != "" {
+				return fmt.Sprintf("Failed to pull image %s: %s", image, event.Error)
+			}
+
+			printPullEvent(os.Stderr, image, event, last)
+		}
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
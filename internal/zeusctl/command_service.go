@@ -0,0 +1,398 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/spf13/cobra"
+)
+
+/*
+zeus service apply -f rickroll.svc.yaml
+zeus service apply -f rickroll.svc.yaml --no-pull
+zeus service inspect
+zeus service inspect rickroll
+zeus service delete rickroll
+zeus service update-image rickroll
+zeus service rollout status rickroll
+zeus service rollout undo rickroll
+*/
+
+var (
+	service = &cobra.Command{
+		Use:   "service",
+		Short: "Service management commands",
+	}
+	serviceRollout = &cobra.Command{
+		Use:   "rollout",
+		Short: "Service rollout commands",
+	}
+	serviceFilePath string
+	serviceNoPull   bool
+)
+
+func serviceCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	applyService(clientProvider)
+	inspectService(clientProvider)
+	deleteService(clientProvider)
+	updateServiceImage(clientProvider)
+	rolloutServiceStatus(clientProvider)
+	rolloutServiceUndo(clientProvider)
+	service.AddCommand(serviceRollout)
+	rootCmd.AddCommand(service)
+}
+
+type ServiceApplyRequest struct {
+	Version                               string `json:"version" yaml:"version"`
+	zeusapiserver.ServiceApplyRequestBody `yaml:",inline"`
+}
+
+func applyService(clientProvider *contextProvider) {
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply service configuration",
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.True(serviceFilePath != "", "file path must not be empty")
+			content, err := os.ReadFile(serviceFilePath)
+			failOnError(err, "Could not read file: %v", err)
+
+			apply := yamlToObject[ServiceApplyRequest](
+				io.NopCloser(bytes.NewReader(content)),
+			)
+			if apply.Version != "v1.0" {
+				failCommand(cmd, "Unsupported version: %q", apply.Version)
+			}
+
+			// the image is pulled before the service is applied, which shows the progress of large pulls
+			// and reports failed pulls, e.g. of a private image without credential, right away
+			if !serviceNoPull {
+				images := []string{apply.Spec.Container.Image}
+				for _, aux := range slices.Concat(apply.Spec.InitContainers, apply.Spec.Sidecars) {
+					images = append(images, aux.Image)
+				}
+				for _, image := range images {
+					if msg := client.imagePull(image, false); msg != "" {
+						fmt.Println(msg)
+						os.Exit(1)
+					}
+				}
+			}
+
+			fmt.Println(client.serviceApply(apply))
+		},
+	}
+
+	applyCmd.Flags().StringVarP(&serviceFilePath, "file", "f", "", "Path to service file")
+	applyCmd.Flags().BoolVar(&serviceNoPull, "no-pull", false, "Do not show the progress of the pull, the daemon pulls a missing image while applying")
+	applyCmd.MarkFlagRequired("file")
+
+	service.AddCommand(applyCmd)
+}
+
+func inspectService(clientProvider *contextProvider) {
+	inspectCmd := &cobra.Command{
+		Use:   "inspect [service]",
+		Short: "Inspect services",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			switch len(args) {
+			case 0:
+				fmt.Println(client.serviceInspectAll())
+			case 1:
+				fmt.Println(client.serviceInspect(args[0]))
+			default:
+				assert.Unreachable("cover all cases of number of arguments")
+			}
+		},
+	}
+
+	service.AddCommand(inspectCmd)
+}
+
+func deleteService(clientProvider *contextProvider) {
+	deleteCmd := &cobra.Command{
+		Use:   "delete [service]",
+		Short: "Delete service",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.serviceDelete(args[0]))
+		},
+	}
+
+	service.AddCommand(deleteCmd)
+}
+
+func updateServiceImage(clientProvider *contextProvider) {
+	updateCmd := &cobra.Command{
+		Use:   "update-image [service]",
+		Short: "Pull the image tag of the service again and run the image it resolves to now",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			msg, ok := client.serviceUpdateImage(args[0])
+			fmt.Println(msg)
+			if !ok {
+				os.Exit(1)
+			}
+		},
+	}
+
+	service.AddCommand(updateCmd)
+}
+
+func rolloutServiceStatus(clientProvider *contextProvider) {
+	statusCmd := &cobra.Command{
+		Use:   "status [service]",
+		Short: "Show the rollout of the current specification of the service",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.serviceRolloutStatus(args[0]))
+		},
+	}
+
+	serviceRollout.AddCommand(statusCmd)
+}
+
+func rolloutServiceUndo(clientProvider *contextProvider) {
+	undoCmd := &cobra.Command{
+		Use:   "undo [service]",
+		Short: "Return the service to the specification it had before it was last changed",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			msg, ok := client.serviceRolloutUndo(args[0])
+			fmt.Println(msg)
+			if !ok {
+				os.Exit(1)
+			}
+		},
+	}
+
+	serviceRollout.AddCommand(undoCmd)
+}
+
+func (c *client) serviceApply(apply *ServiceApplyRequest) string {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.ServiceApplyAPIPath(apply.Version, c.application)),
+		objectToJson(apply.ServiceApplyRequestBody),
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return "Applied"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) serviceInspectAll() string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.ServiceInspectAllAPIPath(c.application)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.ServiceInspectAllResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) serviceInspect(service string) string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.ServiceInspectAPIPath(c.application, service)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.ServiceInspectResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) serviceDelete(service string) string {
+	r, err := http.NewRequest(
+		"DELETE",
+		unixURL(zeusapiserver.ServiceDeleteAPIPath(c.application, service)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Deleted"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+// Pulls the image tag of the service again and resolves the service to the digest of the pulled image.
+// Returns false if the image could not be updated.
+func (c *client) serviceUpdateImage(service string) (string, bool) {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.ServiceInspectAPIPath(c.application, service)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+	if resp.StatusCode == http.StatusBadRequest {
+		return toError(resp), false
+	}
+	assert.True(resp.StatusCode == http.StatusOK, "cover all cases of status code")
+	image := toObject[zeusapiserver.ServiceInspectResponse](resp.Body).Image
+
+	if msg := c.imagePull(image, true); msg != "" {
+		return msg, false
+	}
+
+	r, err = http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.ServiceUpdateImageAPIPath(c.application, service)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err = c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		update := toObject[zeusapiserver.ServiceUpdateImageResponse](resp.Body)
+		lines := []string{imageUpdateLine(update.Image, update.Digest, update.Previous != update.Digest)}
+		for _, c := range update.Containers {
+			lines = append(lines, fmt.Sprintf("%s: %s", c.Name, imageUpdateLine(c.Image, c.Digest, c.Updated)))
+		}
+		return strings.Join(lines, "\n"), true
+	case http.StatusBadRequest:
+		return toError(resp), false
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return "", false
+}
+
+func imageUpdateLine(image string, digest string, updated bool) string {
+	if !updated {
+		return fmt.Sprintf("Image %s is up to date: %s", image, digest)
+	}
+	return fmt.Sprintf("Updated image %s to %s", image, digest)
+}
+
+func (c *client) serviceRolloutStatus(service string) string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.ServiceRolloutAPIPath(c.application, service)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.ServiceRolloutResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+// Returns false if the service has no previous specification or could not be restored.
+func (c *client) serviceRolloutUndo(service string) (string, bool) {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.ServiceRolloutUndoAPIPath(c.application, service)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		undo := toObject[zeusapiserver.ServiceRolloutUndoResponse](resp.Body)
+		return fmt.Sprintf("Rolling back service %s to image %s: %s", service, undo.Image, undo.Digest), true
+	case http.StatusBadRequest:
+		return toError(resp), false
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return "", false
+}
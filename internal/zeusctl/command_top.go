@@ -0,0 +1,195 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/raphaeldichler/zeus/internal/zeusctl/formatter"
+	"github.com/spf13/cobra"
+)
+
+/*
+zeus top
+zeus top --interval 5s
+zeus top -o json
+*/
+
+var (
+	topInterval time.Duration
+)
+
+func topCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	topCmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show the CPU, memory, network and disk usage of the services, the ingress and the DNS",
+		Long: "Show the resource usage of all containers of the enabled application. The pretty output " +
+			"refreshes until interrupted, json and yaml print the usage once.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			if topInterval <= 0 {
+				failCommand(cmd, "Interval must be positive")
+			}
+
+			if _, ok := client.formatter.(*formatter.Pretty); !ok {
+				top, msg := client.top()
+				if msg != "" {
+					fmt.Println(msg)
+					os.Exit(1)
+				}
+				fmt.Println(client.toOutput(top))
+				return
+			}
+
+			if msg := client.watchTop(topInterval); msg != "" {
+				fmt.Println(msg)
+				os.Exit(1)
+			}
+		},
+	}
+
+	topCmd.Flags().DurationVarP(&topInterval, "interval", "n", 2*time.Second, "Time between two refreshes of the pretty output")
+
+	rootCmd.AddCommand(topCmd)
+}
+
+type topContainerView struct {
+	Object    string
+	Service   string
+	Container string
+	CPU       string
+	Memory    string
+	NetIO     string
+	BlockIO   string
+	Pids      uint64
+}
+
+type topTotalView struct {
+	Containers int
+	CPU        string
+	Memory     string
+	NetIO      string
+	BlockIO    string
+	Images     string
+	Volumes    string
+}
+
+type topDiskView struct {
+	Type string
+	Name string
+	Size string
+}
+
+type topView struct {
+	Containers []topContainerView
+	Total      topTotalView
+	Disk       []topDiskView
+}
+
+func byteSize(size uint64) string {
+	return units.BytesSize(float64(size))
+}
+
+func ioSize(in uint64, out uint64) string {
+	return fmt.Sprintf("%s / %s", byteSize(in), byteSize(out))
+}
+
+// Returns the usage in human readable units, like 'docker stats'.
+func toTopView(top *zeusapiserver.TopResponse) topView {
+	view := topView{
+		Total: topTotalView{
+			Containers: top.Total.Containers,
+			CPU:        fmt.Sprintf("%.2f%%", top.Total.CPUPercent),
+			Memory:     byteSize(top.Total.MemoryUsage),
+			NetIO:      ioSize(top.Total.NetworkRx, top.Total.NetworkTx),
+			BlockIO:    ioSize(top.Total.BlockRead, top.Total.BlockWrite),
+			Images:     byteSize(uint64(top.Total.DiskImages)),
+			Volumes:    byteSize(uint64(top.Total.DiskVolumes)),
+		},
+	}
+	for _, c := range top.Containers {
+		service := c.Service
+		if service == "" {
+			service = "-"
+		}
+		view.Containers = append(view.Containers, topContainerView{
+			Object:    c.Object,
+			Service:   service,
+			Container: c.Container,
+			CPU:       fmt.Sprintf("%.2f%%", c.CPUPercent),
+			Memory:    fmt.Sprintf("%s / %s", byteSize(c.MemoryUsage), byteSize(c.MemoryLimit)),
+			NetIO:     ioSize(c.NetworkRx, c.NetworkTx),
+			BlockIO:   ioSize(c.BlockRead, c.BlockWrite),
+			Pids:      c.Pids,
+		})
+	}
+	for _, d := range top.Disk {
+		view.Disk = append(view.Disk, topDiskView{
+			Type: d.Type,
+			Name: d.Name,
+			Size: byteSize(uint64(d.Size)),
+		})
+	}
+
+	return view
+}
+
+// Prints the usage every interval until the command is interrupted. Returns the error of the server,
+// empty if the command was interrupted.
+func (c *client) watchTop(interval time.Duration) string {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		top, msg := c.top()
+		if msg != "" {
+			return msg
+		}
+		// clears the screen and moves the cursor to the top left corner
+		fmt.Print("\033[H\033[2J")
+		fmt.Println(c.toOutput(toTopView(top)))
+
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *client) top() (*zeusapiserver.TopResponse, string) {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.TopAPIPath(c.application)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return toObject[zeusapiserver.TopResponse](resp.Body), ""
+	case http.StatusBadRequest:
+		return nil, toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return nil, ""
+}
@@ -0,0 +1,345 @@
+// Copyright 2025 The Zeus Authors.
+// Licensed under the Apache License 2.0. See the LICENSE file for details.
+
+package zeusctl
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/raphaeldichler/zeus/internal/util/assert"
+	"github.com/raphaeldichler/zeus/internal/zeusapiserver"
+	"github.com/spf13/cobra"
+)
+
+/*
+zeus volume ls
+zeus volume delete data
+zeus volume backup data
+zeus volume backup data --schedule 24h --retain 7
+zeus volume backup data --unschedule
+zeus volume snapshots
+zeus volume snapshots delete 20250601T120000Z
+zeus volume restore data 20250601T120000Z
+*/
+
+var (
+	volume = &cobra.Command{
+		Use:   "volume",
+		Short: "Volume management commands",
+	}
+
+	backupSchedule   string
+	backupRetain     int
+	backupUnschedule bool
+)
+
+func volumeCommands(rootCmd *cobra.Command, clientProvider *contextProvider) {
+	listVolumes(clientProvider)
+	deleteVolume(clientProvider)
+	backupVolume(clientProvider)
+	restoreVolume(clientProvider)
+	listSnapshots(clientProvider)
+	rootCmd.AddCommand(volume)
+}
+
+func listVolumes(clientProvider *contextProvider) {
+	listCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List volumes of the application",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.volumeList())
+		},
+	}
+
+	volume.AddCommand(listCmd)
+}
+
+func deleteVolume(clientProvider *contextProvider) {
+	deleteCmd := &cobra.Command{
+		Use:   "delete [volume]",
+		Short: "Delete volume and its data",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.volumeDelete(args[0]))
+		},
+	}
+
+	volume.AddCommand(deleteCmd)
+}
+
+func backupVolume(clientProvider *contextProvider) {
+	backupCmd := &cobra.Command{
+		Use:   "backup [volume]",
+		Short: "Take a snapshot of the volume now or on a schedule",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			switch {
+			case backupUnschedule:
+				fmt.Println(client.volumeUnschedule(args[0]))
+			case backupSchedule != "":
+				fmt.Println(client.volumeSchedule(args[0], zeusapiserver.VolumeScheduleRequestBody{
+					Interval: backupSchedule,
+					Retain:   backupRetain,
+				}))
+			default:
+				fmt.Println(client.volumeBackup(args[0]))
+			}
+		},
+	}
+
+	backupCmd.Flags().StringVar(&backupSchedule, "schedule", "", "Take a snapshot every interval, e.g. 24h")
+	backupCmd.Flags().IntVar(&backupRetain, "retain", 0, "Number of scheduled snapshots which are kept (default 7)")
+	backupCmd.Flags().BoolVar(&backupUnschedule, "unschedule", false, "Stop the schedule of the volume, its snapshots are kept")
+	backupCmd.MarkFlagsMutuallyExclusive("schedule", "unschedule")
+
+	volume.AddCommand(backupCmd)
+}
+
+func restoreVolume(clientProvider *contextProvider) {
+	restoreCmd := &cobra.Command{
+		Use:   "restore [volume] [snapshot]",
+		Short: "Replace the content of the volume with a snapshot",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.volumeRestore(args[0], args[1]))
+		},
+	}
+
+	volume.AddCommand(restoreCmd)
+}
+
+func listSnapshots(clientProvider *contextProvider) {
+	snapshotsCmd := &cobra.Command{
+		Use:   "snapshots",
+		Short: "List snapshots and backup schedules of the application",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.snapshotList())
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [snapshot]",
+		Short: "Delete the snapshot from the store",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := clientProvider.client
+			assert.NotNil(client, "client must not be nil")
+
+			fmt.Println(client.snapshotDelete(args[0]))
+		},
+	}
+	snapshotsCmd.AddCommand(deleteCmd)
+
+	volume.AddCommand(snapshotsCmd)
+}
+
+func (c *client) volumeList() string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.VolumeInspectAllAPIPath(c.application)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.VolumeInspectAllResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) volumeDelete(volume string) string {
+	r, err := http.NewRequest(
+		"DELETE",
+		unixURL(zeusapiserver.VolumeDeleteAPIPath(c.application, volume)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Deleted"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+// Snapshots take as long as streaming the volume, therefore the client without timeout is used.
+func (c *client) volumeBackup(volume string) string {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.VolumeBackupAPIPath(c.application, volume)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.stream.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return c.toOutput(
+			toObject[zeusapiserver.SnapshotInspectResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) volumeRestore(volume string, snapshot string) string {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.VolumeRestoreAPIPath(c.application, volume)),
+		objectToJson(zeusapiserver.VolumeRestoreRequestBody{Snapshot: snapshot}),
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.stream.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Restored"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) volumeSchedule(volume string, schedule zeusapiserver.VolumeScheduleRequestBody) string {
+	r, err := http.NewRequest(
+		"POST",
+		unixURL(zeusapiserver.VolumeScheduleAPIPath(c.application, volume)),
+		objectToJson(schedule),
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.ScheduleInspectResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) volumeUnschedule(volume string) string {
+	r, err := http.NewRequest(
+		"DELETE",
+		unixURL(zeusapiserver.VolumeScheduleAPIPath(c.application, volume)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Unscheduled"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) snapshotList() string {
+	r, err := http.NewRequest(
+		"GET",
+		unixURL(zeusapiserver.SnapshotInspectAllAPIPath(c.application)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return c.toOutput(
+			toObject[zeusapiserver.SnapshotInspectAllResponse](resp.Body),
+		)
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
+
+func (c *client) snapshotDelete(snapshot string) string {
+	r, err := http.NewRequest(
+		"DELETE",
+		unixURL(zeusapiserver.SnapshotDeleteAPIPath(c.application, snapshot)),
+		nil,
+	)
+	assert.ErrNil(err)
+
+	resp, err := c.http.Do(r)
+	failOnError(err, "Request failed: %v", err)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return "Deleted"
+	case http.StatusBadRequest:
+		return toError(resp)
+	default:
+		assert.Unreachable("cover all cases of status code")
+	}
+
+	return ""
+}
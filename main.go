@@ -8,7 +8,7 @@ import (
 )
 
 func main() {
-	client := dnscontroller.NewClient()
+	client := dnscontroller.NewClient(dnscontroller.SocketFileEnvironmentManager.SocketPath())
 	defer client.Close()
 
 	ctx := context.Background()
@@ -17,15 +17,15 @@ func main() {
 		Entries: []*dnscontroller.DNSSetEntryRequest{
 			{
 				Domain: "foo1.com",
-				Type:   dnscontroller.DNSEntryType_Internal,
+				IPv4:   []string{"10.42.0.1"},
 			},
 			{
 				Domain: "bra.com",
-				Type:   dnscontroller.DNSEntryType_Internal,
+				IPv4:   []string{"10.42.0.2", "10.42.0.3"},
 			},
 			{
 				Domain: "got.com",
-				Type:   dnscontroller.DNSEntryType_External,
+				IPv4:   []string{"10.42.255.1"},
 			},
 		},
 	})